@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/adopt"
+	"hpc-toolkit/pkg/logging"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func init() {
+	adoptCmd.Flags().StringVarP(&adoptFlags.out, "out", "o", ".", "Directory to write the blueprint and the imports to")
+	adoptCmd.Flags().StringVar(&adoptFlags.blueprintName, "blueprint-name", "adopted", "Name of the generated blueprint")
+	adoptCmd.Flags().StringVar(&adoptFlags.deploymentName, "deployment-name", "adopted", "Deployment name of the generated blueprint")
+	rootCmd.AddCommand(adoptCmd)
+}
+
+var (
+	adoptFlags = struct {
+		out            string
+		blueprintName  string
+		deploymentName string
+	}{}
+
+	adoptCmd = &cobra.Command{
+		Use:   "adopt STATE_FILE...",
+		Short: "Generate a blueprint managing existing resources.",
+		Long: `Maps resources of Terraform state files (or of "terraform show -json" output)
+to toolkit modules (network/vpc, file-system/filestore, compute/vm-instance and
+compute/gke-node-pool) and generates a starter blueprint with Terraform import
+blocks to bring the resources under management of the deployment.`,
+		Args:         cobra.MinimumNArgs(1),
+		Run:          runAdoptCmd,
+		SilenceUsage: true,
+	}
+)
+
+func runAdoptCmd(cmd *cobra.Command, args []string) {
+	resources := []adopt.Resource{}
+	for _, path := range args {
+		rs, err := adopt.ReadState(path)
+		checkErr(err, nil)
+		resources = append(resources, rs...)
+	}
+
+	res, err := adopt.Adopt(resources, adoptFlags.blueprintName, adoptFlags.deploymentName)
+	checkErr(err, nil)
+
+	skipped := maps.Keys(res.Skipped)
+	sort.Strings(skipped)
+	for _, addr := range skipped {
+		logging.Error("Skipping %s: %s", addr, res.Skipped[addr])
+	}
+
+	checkErr(os.MkdirAll(adoptFlags.out, 0755), nil)
+	bpPath := filepath.Join(adoptFlags.out, fmt.Sprintf("%s.yaml", adoptFlags.blueprintName))
+	checkErr(res.Blueprint.Export(bpPath), nil)
+
+	importsPath := filepath.Join(adoptFlags.out, "imports.tf")
+	f, err := os.Create(importsPath)
+	checkErr(err, nil)
+	defer f.Close()
+	checkErr(adopt.WriteImports(f, res.Imports), nil)
+
+	logging.Info("Adopted %d resources, the blueprint is saved as %s", len(res.Imports), bpPath)
+	logging.Info(`To take over the resources:
+  1. Review the blueprint, then run: gcluster create %s
+  2. Copy %s to %s/%s/
+  3. Run: gcluster deploy %s, the plan should only import the resources`,
+		bpPath, importsPath, adoptFlags.deploymentName, adopt.Group, adoptFlags.deploymentName)
+}
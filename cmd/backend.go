@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/backend"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"path/filepath"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// stateMigratedSuffix is appended to local state files after migration to the
+// bucket, so they are neither used nor restored by later `create -w`
+const stateMigratedSuffix = ".migrated"
+
+var newBackendClient = backend.NewClient
+
+// checkBackendFlags makes sure that --backend-bucket is only given together
+// with --bootstrap-backend
+func checkBackendFlags() error {
+	if createFlags.backendBucket != "" && !createFlags.bootstrapBackend {
+		return config.HintError{
+			Err:  errors.New("--backend-bucket requires --bootstrap-backend"),
+			Hint: "add --bootstrap-backend or drop --backend-bucket"}
+	}
+	return nil
+}
+
+// bootstrapBackend creates the state bucket of the deployment and sets it as
+// backend of groups without an explicit backend. Returns the bucket name.
+func bootstrapBackend(ctx context.Context, bp *config.Blueprint) (string, error) {
+	if bp.TerraformBackendDefaults.Type != "" {
+		return "", config.HintError{
+			Err:  errors.New("--bootstrap-backend cannot be used with a blueprint that sets terraform_backend_defaults"),
+			Hint: "remove terraform_backend_defaults from the blueprint or drop --bootstrap-backend"}
+	}
+	project, err := getStringVar(bp.Vars, "project_id")
+	if err != nil {
+		return "", err
+	}
+	b := backend.Bucket{
+		Name:     createFlags.backendBucket,
+		Project:  project,
+		Location: "US",
+	}
+	if b.Name == "" {
+		b.Name = backend.DefaultBucket(project)
+	}
+	if region := bp.Vars.Get("region"); !region.IsNull() && region.Type() == cty.String {
+		b.Location = region.AsString()
+	}
+
+	members := []string{}
+	if m := backend.Member(detectUsername(ctx)); m != "" {
+		members = append(members, m)
+	}
+
+	c, err := newBackendClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	created, err := backend.Bootstrap(c, b, members)
+	if err != nil {
+		return "", err
+	}
+	if created {
+		logging.Info("Created Terraform state bucket gs://%s in %s", b.Name, b.Location)
+	} else {
+		logging.Info("Using existing Terraform state bucket gs://%s", b.Name)
+	}
+	bp.UseBackendDefaults(backend.TerraformBackend(b.Name))
+	return b.Name, nil
+}
+
+// migrateLocalState moves local state of groups using the bootstrapped bucket,
+// e.g. state of a deployment created before the bucket, into the bucket.
+// Remote deployments are migrated in their working copy, see doCreate.
+func migrateLocalState(dir string, bp config.Blueprint, bucket string) error {
+	for _, g := range bp.Groups {
+		if !usesBucket(g, bucket) {
+			continue
+		}
+		groupDir := filepath.Join(dir, string(g.Name))
+		statePath := filepath.Join(groupDir, "terraform.tfstate")
+		if _, err := os.Stat(statePath); err != nil {
+			continue // no local state
+		}
+		tf, err := shell.ConfigureTerraform(groupDir)
+		if err != nil {
+			return err
+		}
+		if err := shell.MigrateState(tf); err != nil {
+			return err
+		}
+		for _, f := range []string{statePath, statePath + ".backup"} {
+			if err := os.Rename(f, f+stateMigratedSuffix); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rename migrated state file %s: %w", f, err)
+			}
+		}
+	}
+	return nil
+}
+
+func usesBucket(g config.Group, bucket string) bool {
+	if g.Kind() != config.TerraformKind || g.TerraformBackend.Type != "gcs" {
+		return false
+	}
+	b := g.TerraformBackend.Configuration.Get("bucket")
+	return !b.IsNull() && b.Type() == cty.String && b.AsString() == bucket
+}
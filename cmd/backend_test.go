@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"hpc-toolkit/pkg/backend"
+	"hpc-toolkit/pkg/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type fakeBackendClient struct {
+	created []string
+	members []string
+}
+
+func (f *fakeBackendClient) Bucket(string) (*storage.BucketAttrs, error) {
+	return nil, storage.ErrBucketNotExist
+}
+
+func (f *fakeBackendClient) CreateBucket(project string, attrs *storage.BucketAttrs) error {
+	f.created = append(f.created, project+"/"+attrs.Name+"@"+attrs.Location)
+	return nil
+}
+
+func (f *fakeBackendClient) UpdateBucket(string, storage.BucketAttrsToUpdate) error {
+	return nil
+}
+
+func (f *fakeBackendClient) AddBucketMember(name string, role string, member string) error {
+	f.members = append(f.members, member)
+	return nil
+}
+
+func TestCheckBackendFlags(t *testing.T) {
+	bucket, bootstrap := createFlags.backendBucket, createFlags.bootstrapBackend
+	defer func() { createFlags.backendBucket, createFlags.bootstrapBackend = bucket, bootstrap }()
+
+	createFlags.backendBucket, createFlags.bootstrapBackend = "pail", false
+	if err := checkBackendFlags(); err == nil || !strings.Contains(err.Error(), "--backend-bucket requires --bootstrap-backend") {
+		t.Errorf("want error for --backend-bucket without --bootstrap-backend, got %v", err)
+	}
+	createFlags.bootstrapBackend = true
+	if err := checkBackendFlags(); err != nil {
+		t.Error(err)
+	}
+	createFlags.backendBucket, createFlags.bootstrapBackend = "", false
+	if err := checkBackendFlags(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBootstrapBackend(t *testing.T) {
+	fake := &fakeBackendClient{}
+	original := newBackendClient
+	defer func() { newBackendClient = original }()
+	newBackendClient = func(context.Context) (backend.Client, error) { return fake, nil }
+	t.Setenv("CLOUDSDK_CORE_ACCOUNT", "ann@example.com")
+
+	bp := config.Blueprint{
+		BlueprintName: "tree",
+		Vars: config.NewDict(map[string]cty.Value{
+			"project_id": cty.StringVal("apple"),
+			"region":     cty.StringVal("us-east4"),
+		}),
+		Groups: []config.Group{{Name: "primary", Modules: []config.Module{{Kind: config.TerraformKind}}}},
+	}
+
+	bucket, err := bootstrapBackend(context.Background(), &bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket != "apple-gcluster-tfstate" {
+		t.Errorf("got bucket %q", bucket)
+	}
+	if len(fake.created) != 1 || fake.created[0] != "apple/apple-gcluster-tfstate@us-east4" {
+		t.Errorf("got created %v", fake.created)
+	}
+	if len(fake.members) != 1 || fake.members[0] != "user:ann@example.com" {
+		t.Errorf("got members %v", fake.members)
+	}
+	if !usesBucket(bp.Groups[0], bucket) {
+		t.Errorf("group backend is not the bucket: %#v", bp.Groups[0].TerraformBackend)
+	}
+	if !bp.Groups[0].TerraformBackend.Configuration.Has("prefix") {
+		t.Error("group backend has no prefix")
+	}
+
+	// blueprint has backend defaults now
+	if _, err := bootstrapBackend(context.Background(), &bp); err == nil {
+		t.Error("want error for blueprint with terraform_backend_defaults")
+	}
+}
+
+func TestMigrateLocalStateNoState(t *testing.T) {
+	dir := t.TempDir()
+	bp := config.Blueprint{
+		Groups: []config.Group{{Name: "primary", Modules: []config.Module{{Kind: config.TerraformKind}}}},
+	}
+	bp.UseBackendDefaults(backend.TerraformBackend("pail"))
+	if err := os.Mkdir(filepath.Join(dir, "primary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// no local state, terraform is not needed
+	if err := migrateLocalState(dir, bp, "pail"); err != nil {
+		t.Error(err)
+	}
+}
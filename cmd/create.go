@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/modulewriter"
 	"os"
@@ -36,7 +37,7 @@ import (
 
 func addCreateFlags(c *cobra.Command) *cobra.Command {
 	c.Flags().StringVarP(&createFlags.outputDir, "out", "o", "",
-		"Sets the output directory where the HPC deployment directory will be created.")
+		"Sets the output directory where the HPC deployment directory will be created, e.g. `gs://bucket/dir` to keep it in Cloud Storage.")
 	c.Flags().BoolVarP(&createFlags.overwriteDeployment, "overwrite-deployment", "w", false,
 		"If specified, an existing deployment directory is overwritten by the new deployment. \n"+
 			"Note: Terraform state IS preserved. \n"+
@@ -46,6 +47,11 @@ func addCreateFlags(c *cobra.Command) *cobra.Command {
 		"Forces overwrite of existing deployment directory. \n"+
 			"If set, --overwrite-deployment is implied. \n"+
 			"No validation is performed on the existing deployment directory.")
+	c.Flags().BoolVar(&createFlags.bootstrapBackend, "bootstrap-backend", false,
+		"Creates a versioned Cloud Storage bucket for Terraform state, if it does not exist, \n"+
+			"and uses it as backend of all deployment groups. Existing local state is migrated to the bucket.")
+	c.Flags().StringVar(&createFlags.backendBucket, "backend-bucket", "",
+		"Name of the bucket created by --bootstrap-backend, defaults to `<project_id>-gcluster-tfstate`.")
 	return addExpandFlags(c, false /*addOutFlag to avoid clash with "create" `out` flag*/)
 }
 
@@ -58,6 +64,8 @@ var (
 		outputDir           string
 		overwriteDeployment bool
 		forceOverwrite      bool
+		bootstrapBackend    bool
+		backendBucket       string
 	}{}
 
 	createCmd = addCreateFlags(&cobra.Command{
@@ -81,10 +89,32 @@ func runCreateCmd(cmd *cobra.Command, args []string) {
 
 func doCreate(cmd *cobra.Command, path string) string {
 	bp, ctx := expandOrDie(cmd, path)
-	deplDir := filepath.Join(createFlags.outputDir, bp.DeploymentName())
+	checkErr(checkBackendFlags(), ctx)
+	deplDir := deploymentio.Join(createFlags.outputDir, bp.DeploymentName())
 	logging.Info("Creating deployment folder %q ...", deplDir)
-	checkErr(checkOverwriteAllowed(deplDir, bp, createFlags.overwriteDeployment, createFlags.forceOverwrite), ctx)
-	checkErr(modulewriter.WriteDeployment(bp, deplDir), ctx)
+	// remote deployments are pulled once, written and published together with migrated state
+	writeDir := deplDir
+	if deploymentio.IsRemote(deplDir) {
+		local, err := deploymentio.WorkingCopy(deplDir)
+		checkErr(err, ctx)
+		writeDir = local
+	}
+	checkErr(checkOverwriteAllowed(writeDir, bp, createFlags.overwriteDeployment, createFlags.forceOverwrite), ctx)
+	// the bucket and its IAM binding are only created once the deployment
+	// is known to be written, so a refused overwrite leaves nothing behind
+	bucket := ""
+	if createFlags.bootstrapBackend {
+		var err error
+		bucket, err = bootstrapBackend(cmd.Context(), &bp)
+		checkErr(err, ctx)
+	}
+	checkErr(modulewriter.WriteDeployment(bp, writeDir), ctx)
+	if bucket != "" {
+		checkErr(migrateLocalState(writeDir, bp, bucket), ctx)
+	}
+	if writeDir != deplDir {
+		checkErr(deploymentio.Publish(writeDir, deplDir), ctx)
+	}
 	return deplDir
 }
 
@@ -172,6 +202,8 @@ func expandOrDie(cmd *cobra.Command, path string) (config.Blueprint, *config.Yam
 		}
 	}
 
+	checkErr(resolveDeploymentReferences(&bp, expandFlags.deploymentSrcs, deploymentsDir(path)), ctx)
+
 	// Expand the blueprint
 	checkErr(bp.Expand(), ctx)
 	validateMaybeDie(bp, *ctx)
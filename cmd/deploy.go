@@ -18,10 +18,14 @@ package cmd
 import (
 	"fmt"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/events"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/modulewriter"
 	"hpc-toolkit/pkg/shell"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -32,19 +36,28 @@ func addDeployFlags(c *cobra.Command) *cobra.Command {
 		addGroupSelectionFlags(
 			addAutoApproveFlag(
 				addArtifactsDirFlag(
-					addCreateFlags(c)))))
+					addParallelismFlag(
+						addEventsFlag(
+							addModuleSelectionFlags(
+								addCreateFlags(c))))))))
 }
 
 func init() {
+	deployCmd.Flags().BoolVar(&flagResume, "resume", false,
+		"Skip deployment groups that were applied by a previous deploy and have not changed since")
 	rootCmd.AddCommand(deployCmd)
 }
 
 var (
+	flagResume bool
+
+	deployGroupFunc = deployGroup
+
 	deployCmd = addDeployFlags(&cobra.Command{
 		Use:               "deploy (<DEPLOYMENT_DIRECTORY> | <BLUEPRINT_FILE>)",
 		Short:             "deploy all resources in a Toolkit deployment directory.",
 		Long:              "deploy all resources in a Toolkit deployment directory.",
-		Args:              cobra.MatchAll(cobra.ExactArgs(1), checkExists),
+		Args:              cobra.MatchAll(cobra.ExactArgs(1), allowRemote(checkExists)),
 		ValidArgsFunction: filterYaml,
 		Run:               runDeployCmd,
 		SilenceUsage:      true,
@@ -53,8 +66,10 @@ var (
 
 func runDeployCmd(cmd *cobra.Command, args []string) {
 	var deplRoot string
+	finish := startEvents("deploy", args[0])
+	defer finish()
 
-	if checkDir(cmd, args) != nil { // arg[0] is BLUEPRINT_FILE
+	if !deploymentio.IsRemote(args[0]) && checkDir(cmd, args) != nil { // arg[0] is BLUEPRINT_FILE
 		deplRoot = doCreate(cmd, args[0])
 	} else { // arg[0] is DEPLOYMENT_DIRECTORY
 		deplRoot = args[0]
@@ -69,43 +84,121 @@ func runDeployCmd(cmd *cobra.Command, args []string) {
 }
 
 func doDeploy(deplRoot string) {
+	deplRoot, publish := localDeployment(deplRoot)
+	defer publish()
+
 	artDir := getArtifactsDir(deplRoot)
 	checkErr(shell.CheckWritableDir(artDir), nil)
 	bp, ctx := artifactBlueprintOrDie(artDir)
 	groups := bp.Groups
 	checkErr(validateGroupSelectionFlags(bp), ctx)
+	checkErr(validateModuleSelectionFlags(bp, false), ctx)
+	checkErr(validateParallelismFlag(), ctx)
 	checkErr(validateRuntimeDependencies(deplRoot, groups), ctx)
 	checkErr(shell.ValidateDeploymentDirectory(groups, deplRoot), ctx)
 
-	for ig, group := range groups {
+	selected := []config.GroupName{}
+	for _, group := range groups {
 		if !isGroupSelected(group.Name) {
 			logging.Info("skipping group %q", group.Name)
 			continue
 		}
+		selected = append(selected, group.Name)
+	}
+	deps, err := bp.GroupDependencies()
+	checkErr(err, ctx)
+	journal, err := shell.LoadJournal(deplRoot)
+	checkErr(err, ctx)
+	bpHash, err := shell.HashFile(filepath.Join(artDir, modulewriter.ExpandedBlueprintName))
+	checkErr(err, ctx)
 
-		groupDir := filepath.Join(deplRoot, string(group.Name))
-		checkErr(shell.ImportInputs(groupDir, artDir, bp), ctx)
-
-		switch group.Kind() {
-		case config.PackerKind:
-			// Packer groups are enforced to have length 1
-			subPath, e := modulewriter.DeploymentSource(group.Modules[0])
-			checkErr(e, ctx)
-			moduleDir := filepath.Join(groupDir, subPath)
-			checkErr(deployPackerGroup(moduleDir, getApplyBehavior()), ctx)
-		case config.TerraformKind:
-			checkErr(deployTerraformGroup(groupDir, artDir, getApplyBehavior(), getOutputFormat()), ctx)
-		default:
-			checkErr(
-				config.BpError{
-					Err:  fmt.Errorf("group %q is an unsupported kind %q", groupDir, group.Kind()),
-					Path: config.Root.Groups.At(ig).Name}, ctx)
-		}
+	shell.PrefixGroupOutput = flagParallelism > 1
+	checkErr(runGroups(selected, deps, flagParallelism, false, func(g config.GroupName) error {
+		return deployGroupWithJournal(deplRoot, artDir, bp, g, journal, bpHash)
+	}), ctx)
+	checkErr(shell.ExportDeploymentOutputs(artDir, bp), ctx)
+	if flagModules != nil {
+		logging.Warn("Only modules %s were deployed, run \"gcluster deploy\" without --module to reconcile the whole deployment",
+			strings.Join(flagModules, ", "))
 	}
 	logging.Info("\n###############################")
 	printAdvancedInstructionsMessage(deplRoot)
 }
 
+// deployGroupWithJournal deploys the group and records the outcome in the journal,
+// with --resume groups that were applied and have not changed since are skipped
+func deployGroupWithJournal(deplRoot string, artDir string, bp config.Blueprint, name config.GroupName, journal *shell.Journal, bpHash string) error {
+	finish := groupEvents(name, bp.Groups[bp.GroupIndex(name)].Kind())
+	groupDir := filepath.Join(deplRoot, string(name))
+	if err := shell.ImportInputs(groupDir, artDir, bp); err != nil {
+		finish("", err)
+		return err
+	}
+	groupHash, err := shell.HashGroup(groupDir)
+	if err != nil {
+		finish("", err)
+		return err
+	}
+
+	if flagResume && journal.Unchanged(name, groupHash) {
+		r, _ := journal.Record(name)
+		logging.Info("skipping group %q, unchanged since it was applied at %s", name, r.LastApplied.Format(time.RFC3339))
+		// outputs are needed by downstream groups, artifacts may have been recreated since
+		err := journal.RestoreOutputs(name, artDir)
+		finish(events.StatusSkipped, err)
+		return err
+	}
+
+	if err := journal.Start(name, bpHash, groupHash); err != nil {
+		finish("", err)
+		return err
+	}
+	applied, err := deployGroupFunc(deplRoot, artDir, bp, name)
+	switch {
+	case err != nil:
+		finish("", err)
+		if jerr := journal.Fail(name, err); jerr != nil {
+			logging.Error("failed to update deployment journal: %v", jerr)
+		}
+		return err
+	case applied && isPartial(bp.Groups[bp.GroupIndex(name)]):
+		finish(events.StatusPartial, nil)
+		return journal.Partial(name)
+	case applied:
+		finish(events.StatusApplied, nil)
+		return journal.Apply(name, artDir)
+	default:
+		finish(events.StatusDeclined, nil)
+		return journal.Decline(name)
+	}
+}
+
+// deployGroup deploys the group, inputs of the group must be already imported,
+// returns whether the cloud infrastructure matches the group afterwards
+func deployGroup(deplRoot string, artDir string, bp config.Blueprint, name config.GroupName) (bool, error) {
+	ig := bp.GroupIndex(name)
+	group := bp.Groups[ig]
+	groupDir := filepath.Join(deplRoot, string(group.Name))
+
+	switch group.Kind() {
+	case config.PackerKind:
+		// Packer groups are enforced to have length 1
+		subPath, err := modulewriter.DeploymentSource(group.Modules[0])
+		if err != nil {
+			return false, err
+		}
+		moduleDir := filepath.Join(groupDir, subPath)
+		return deployPackerGroup(moduleDir, getApplyBehavior())
+	case config.TerraformKind:
+		warnPartialApply(group, false)
+		return deployTerraformGroup(groupDir, artDir, getApplyBehavior(), getOutputFormat(), moduleTargets(name))
+	default:
+		return false, config.BpError{
+			Err:  fmt.Errorf("group %q is an unsupported kind %q", groupDir, group.Kind()),
+			Path: config.Root.Groups.At(ig).Name}
+	}
+}
+
 func validateRuntimeDependencies(deplDir string, groups []config.Group) error {
 	for ig, group := range groups {
 		var err error
@@ -127,36 +220,49 @@ func validateRuntimeDependencies(deplDir string, groups []config.Group) error {
 	return nil
 }
 
-func deployPackerGroup(moduleDir string, applyBehavior shell.ApplyBehavior) error {
+func deployPackerGroup(moduleDir string, applyBehavior shell.ApplyBehavior) (bool, error) {
 	if err := shell.ConfigurePacker(); err != nil {
-		return err
+		return false, err
 	}
+	group := filepath.Base(filepath.Dir(moduleDir))
 	c := shell.ProposedChanges{
 		Summary: fmt.Sprintf("Proposed change: use packer to build image in %s", moduleDir),
 		Full:    fmt.Sprintf("Proposed change: use packer to build image in %s", moduleDir),
+		Group:   group,
 	}
 	buildImage := applyBehavior == shell.AutomaticApply || shell.ApplyChangesChoice(c)
 	if buildImage {
 		logging.Info("initializing packer module at %s", moduleDir)
-		if err := shell.ExecPackerCmd(moduleDir, false, "init", "."); err != nil {
-			return err
+		if err := execPackerStep(group, moduleDir, false, "init"); err != nil {
+			return false, err
 		}
 		logging.Info("validating packer module at %s", moduleDir)
-		if err := shell.ExecPackerCmd(moduleDir, false, "validate", "."); err != nil {
-			return err
+		if err := execPackerStep(group, moduleDir, false, "validate"); err != nil {
+			return false, err
 		}
 		logging.Info("building image using packer module at %s", moduleDir)
-		if err := shell.ExecPackerCmd(moduleDir, true, "build", "."); err != nil {
-			return err
+		if err := execPackerStep(group, moduleDir, true, "build"); err != nil {
+			return false, err
 		}
 	}
+	return buildImage, nil
+}
+
+func execPackerStep(group string, moduleDir string, printToScreen bool, step string) error {
+	data := map[string]any{"step": step}
+	events.EmitGroup(events.PackerStep, group, events.StatusStarted, data)
+	if err := shell.ExecPackerCmd(moduleDir, printToScreen, step, "."); err != nil {
+		events.Emit(events.Event{Type: events.PackerStep, Group: group, Status: events.StatusFailed, Message: err.Error(), Data: data})
+		return err
+	}
+	events.EmitGroup(events.PackerStep, group, events.StatusFinished, data)
 	return nil
 }
 
-func deployTerraformGroup(groupDir string, artifactsDir string, applyBehavior shell.ApplyBehavior, outputFormat shell.OutputFormat) error {
+func deployTerraformGroup(groupDir string, artifactsDir string, applyBehavior shell.ApplyBehavior, outputFormat shell.OutputFormat, targets []string) (bool, error) {
 	tf, err := shell.ConfigureTerraform(groupDir)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return shell.ExportOutputs(tf, artifactsDir, applyBehavior, outputFormat)
+	return shell.ApplyAndExportOutputs(tf, artifactsDir, applyBehavior, outputFormat, targets...)
 }
@@ -17,8 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/shell"
 	"os"
+	"path/filepath"
 
 	. "gopkg.in/check.v1"
 )
@@ -28,11 +30,62 @@ func (s *MySuite) TestDeployGroups(c *C) {
 	pathEnv := os.Getenv("PATH")
 	os.Setenv("PATH", "")
 
-	err = deployTerraformGroup(".", getArtifactsDir("."), shell.NeverApply, shell.TextOutput)
+	_, err = deployTerraformGroup(".", getArtifactsDir("."), shell.NeverApply, shell.TextOutput, nil)
 	c.Check(err, NotNil)
 
-	err = deployPackerGroup(".", shell.NeverApply)
+	_, err = deployPackerGroup(".", shell.NeverApply)
 	c.Check(err, NotNil)
 
 	os.Setenv("PATH", pathEnv)
 }
+
+func (s *MySuite) TestDeployDestroyResume(c *C) {
+	deplRoot := c.MkDir()
+	artDir := getArtifactsDir(deplRoot)
+	c.Assert(os.MkdirAll(filepath.Join(deplRoot, "primary"), 0755), IsNil)
+	c.Assert(os.MkdirAll(artDir, 0755), IsNil)
+	bp := config.Blueprint{Groups: []config.Group{{
+		Name:    "primary",
+		Modules: []config.Module{{ID: "network", Source: "modules/network/vpc", Kind: config.TerraformKind}},
+	}}}
+
+	defer func(deploy func(string, string, config.Blueprint, config.GroupName) (bool, error), destroy func(string) error, resume bool, robust bool) {
+		deployGroupFunc, destroyTerraformGroupFunc, flagResume, robustDestroy = deploy, destroy, resume, robust
+	}(deployGroupFunc, destroyTerraformGroupFunc, flagResume, robustDestroy)
+	applied := 0
+	deployGroupFunc = func(string, string, config.Blueprint, config.GroupName) (bool, error) {
+		applied++
+		return true, nil
+	}
+	destroyTerraformGroupFunc = func(string) error { return nil }
+	flagResume, robustDestroy = true, false
+
+	deploy := func() shell.GroupRecord {
+		journal, err := shell.LoadJournal(deplRoot)
+		c.Assert(err, IsNil)
+		c.Assert(deployGroupWithJournal(deplRoot, artDir, bp, "primary", journal, "bp"), IsNil)
+		r, _ := journal.Record("primary")
+		return r
+	}
+
+	c.Check(deploy().Status, Equals, shell.GroupApplied)
+	deploy() // unchanged, skipped on resume
+	c.Check(applied, Equals, 1)
+
+	destroyFailed, _ := destroyGroups(deplRoot, artDir, bp, &config.YamlCtx{})
+	c.Assert(destroyFailed, Equals, false)
+	journal, err := shell.LoadJournal(deplRoot)
+	c.Assert(err, IsNil)
+	r, _ := journal.Record("primary")
+	c.Check(r.Status, Equals, shell.GroupDestroyed)
+
+	// destroyed groups are applied again on resume
+	c.Check(deploy().Status, Equals, shell.GroupApplied)
+	c.Check(applied, Equals, 2)
+
+	// selecting every module of the group is a complete apply
+	defer func(m map[config.GroupName][]config.ModuleID) { selectedModules = m }(selectedModules)
+	selectedModules = map[config.GroupName][]config.ModuleID{"primary": {"network"}}
+	flagResume = false
+	c.Check(deploy().Status, Equals, shell.GroupApplied)
+}
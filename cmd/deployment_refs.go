@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+	"path/filepath"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// parseDeploymentSources parses `name=location` values of --deployment-source
+func parseDeploymentSources(s []string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, src := range s {
+		name, loc, ok := strings.Cut(src, "=")
+		if !ok || name == "" || loc == "" {
+			return nil, fmt.Errorf("invalid format: '%s' should follow the 'name=location' format", src)
+		}
+		res[name] = loc
+	}
+	return res, nil
+}
+
+// deploymentsDir returns the directory where deployments referenced by the
+// blueprint at bpPath are looked up by default: the output directory given to
+// create, otherwise the directory of the blueprint.
+func deploymentsDir(bpPath string) string {
+	if createFlags.outputDir != "" {
+		return createFlags.outputDir
+	}
+	return filepath.Dir(bpPath)
+}
+
+// resolveDeploymentReferences substitutes references to outputs of other
+// deployments, e.g. `$(deployment_output("shared-network", "network_self_link"))`,
+// with values exported by these deployments. A deployment is looked up in the
+// location given with --deployment-source, by default in dir.
+func resolveDeploymentReferences(bp *config.Blueprint, sources []string, dir string) error {
+	refs := bp.DeploymentReferences()
+	if len(refs) == 0 {
+		return nil
+	}
+	locs, err := parseDeploymentSources(sources)
+	if err != nil {
+		return err
+	}
+
+	loaded := map[string]shell.DeploymentOutputs{}
+	vals := map[config.Reference]cty.Value{}
+	for _, ref := range refs {
+		d, ok := loaded[ref.Deployment]
+		if !ok {
+			loc, ok := locs[ref.Deployment]
+			if !ok {
+				loc = deploymentio.Join(dir, ref.Deployment)
+			}
+			if d, err = shell.LoadDeploymentOutputs(loc); err != nil {
+				return err
+			}
+			if err := d.CheckIdentity(ref.Deployment); err != nil {
+				return err
+			}
+			logging.Info("Using outputs of deployment %q from %s", ref.Deployment, loc)
+			loaded[ref.Deployment] = d
+		}
+		if vals[ref], err = d.Lookup(ref); err != nil {
+			return err
+		}
+	}
+	return bp.ResolveDeploymentReferences(vals)
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseDeploymentSources(t *testing.T) {
+	got, err := parseDeploymentSources([]string{"net=gs://pail/net", "fs=../fs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["net"] != "gs://pail/net" || got["fs"] != "../fs" {
+		t.Errorf("unexpected sources %#v", got)
+	}
+	for _, bad := range []string{"net", "=dir", "net="} {
+		if _, err := parseDeploymentSources([]string{bad}); err == nil {
+			t.Errorf("want error for %q", bad)
+		}
+	}
+}
+
+// exportTestDeployment writes outputs of a deployment named `name` into `dir`
+func exportTestDeployment(t *testing.T, dir string, name string) {
+	artDir := filepath.Join(dir, ".ghpc", "artifacts")
+	if err := os.MkdirAll(artDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(artDir, "primary_outputs.tfvars"), []byte("network_self_link_network = \"projects/p/networks/n\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bp := config.Blueprint{
+		Vars: config.NewDict(map[string]cty.Value{"deployment_name": cty.StringVal(name)}),
+		Groups: []config.Group{{Name: "primary", Modules: []config.Module{{
+			ID: "network", Source: "modules/network/vpc", Kind: config.TerraformKind,
+			Outputs: []modulereader.OutputInfo{{Name: "network_self_link"}}}}}},
+	}
+	if err := shell.ExportDeploymentOutputs(artDir, bp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveDeploymentReferences(t *testing.T) {
+	out := t.TempDir()
+	exportTestDeployment(t, filepath.Join(out, "shared-network"), "shared-network")
+	exportTestDeployment(t, filepath.Join(out, "impostor"), "other-network")
+
+	newBp := func(ref string) config.Blueprint {
+		return config.Blueprint{Vars: config.NewDict(map[string]cty.Value{
+			"network": config.MustParseExpression(ref).AsValue()})}
+	}
+
+	bp := newBp(`deployment_output("shared-network", "network_self_link")`)
+	if err := resolveDeploymentReferences(&bp, nil, out); err != nil {
+		t.Fatal(err)
+	}
+	if got := bp.Vars.Get("network"); !got.RawEquals(cty.StringVal("projects/p/networks/n")) {
+		t.Errorf("unexpected value %#v", got)
+	}
+
+	bp = newBp(`deployment_output("net", "network", "network_self_link")`)
+	if err := resolveDeploymentReferences(&bp, []string{"net=" + filepath.Join(out, "impostor")}, out); err == nil || !strings.Contains(err.Error(), `expected deployment "net"`) {
+		t.Errorf("want identity error, got %v", err)
+	}
+
+	bp = newBp(`deployment_output("missing", "network_self_link")`)
+	if err := resolveDeploymentReferences(&bp, nil, out); err == nil {
+		t.Error("want error for missing deployment")
+	}
+}
+
+func TestDeploymentsDir(t *testing.T) {
+	original := createFlags.outputDir
+	defer func() { createFlags.outputDir = original }()
+
+	createFlags.outputDir = ""
+	if got := deploymentsDir(filepath.Join("blueprints", "cluster.yaml")); got != "blueprints" {
+		t.Errorf("want blueprint directory, got %q", got)
+	}
+	createFlags.outputDir = "gs://pail/deployments"
+	if got := deploymentsDir(filepath.Join("blueprints", "cluster.yaml")); got != "gs://pail/deployments" {
+		t.Errorf("want output directory, got %q", got)
+	}
+}
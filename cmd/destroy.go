@@ -19,13 +19,18 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"hpc-toolkit/pkg/cleanup"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/events"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/modulewriter"
 	"hpc-toolkit/pkg/shell"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/zclconf/go-cty/cty"
@@ -42,7 +47,10 @@ func init() {
 	rootCmd.AddCommand(
 		addGroupSelectionFlags(
 			addAutoApproveFlag(
-				addArtifactsDirFlag(destroyCmd))))
+				addArtifactsDirFlag(
+					addParallelismFlag(
+						addEventsFlag(
+							addModuleSelectionFlags(destroyCmd)))))))
 	destroyCmd.Flags().BoolVar(&robustDestroy, "robust", false, "Perform a robust destroy, including firewall rule cleanup.")
 }
 
@@ -51,7 +59,7 @@ var (
 		Use:               "destroy DEPLOYMENT_DIRECTORY",
 		Short:             "destroy all resources in a Toolkit deployment directory.",
 		Long:              "destroy all resources in a Toolkit deployment directory.",
-		Args:              cobra.MatchAll(cobra.ExactArgs(1), checkDir),
+		Args:              cobra.MatchAll(cobra.ExactArgs(1), allowRemote(checkDir)),
 		ValidArgsFunction: matchDirs,
 		Run:               runDestroyCmd,
 		SilenceUsage:      true,
@@ -62,10 +70,14 @@ var (
 	destroyGroupsFunc         = destroyGroups
 	cleanupFirewallRulesFunc  = cleanupFirewallRules
 	destroyTerraformGroupFunc = destroyTerraformGroup
+	cleanupOrphansFunc        = cleanupOrphans
 )
 
 func runDestroyCmd(cmd *cobra.Command, args []string) {
-	deplRoot := args[0]
+	finish := startEvents("destroy", args[0])
+	defer finish()
+	deplRoot, publish := localDeployment(args[0])
+	defer publish()
 	artifactsDir := getArtifactsDir(deplRoot)
 
 	if isDir, _ := shell.DirInfo(artifactsDir); !isDir {
@@ -74,6 +86,8 @@ func runDestroyCmd(cmd *cobra.Command, args []string) {
 
 	bp, ctx := artifactBlueprintOrDie(artifactsDir)
 	checkErr(validateGroupSelectionFlags(bp), ctx)
+	checkErr(validateModuleSelectionFlags(bp, true), ctx)
+	checkErr(validateParallelismFlag(), ctx)
 	checkErr(shell.ValidateDeploymentDirectory(bp.Groups, deplRoot), ctx)
 
 	destroyRunner(deplRoot, artifactsDir, bp, ctx)
@@ -99,7 +113,11 @@ func destroyRunner(deplRoot string, artifactsDir string, bp config.Blueprint, ct
 		if attempt == maxRetries {
 			logging.Fatal("Destruction of %q failed after %d attempts", deplRoot, maxRetries)
 		}
+		if err := cleanupDeploymentOrphans(deplRoot, bp); err != nil {
+			logging.Error("%v", err)
+		}
 		logging.Info("Retrying destroy...")
+		events.Emit(events.Event{Type: events.Retry, Data: map[string]any{"attempt": attempt + 1, "max_attempts": maxRetries}})
 	}
 }
 
@@ -113,6 +131,17 @@ func groupHasNetworkModule(group config.Group) bool {
 }
 
 func destroyGroups(deplRoot string, artifactsDir string, bp config.Blueprint, ctx *config.YamlCtx) (bool, []string) {
+	shell.PrefixGroupOutput = flagParallelism > 1
+	if flagParallelism > 1 {
+		return destroyGroupsConcurrently(deplRoot, artifactsDir, bp, ctx)
+	}
+
+	journal, err := shell.LoadJournal(deplRoot)
+	if err != nil {
+		logging.Error("%v", err)
+		return true, nil
+	}
+
 	// destroy in reverse order of creation!
 	packerManifests := []string{}
 	destroyFailed := false
@@ -123,39 +152,16 @@ func destroyGroups(deplRoot string, artifactsDir string, bp config.Blueprint, ct
 			continue
 		}
 
-		if robustDestroy && groupHasNetworkModule(group) {
-			projectID, deploymentName, err := getProjectAndDeploymentVars(bp.Vars)
-			if err != nil {
-				logging.Error("Skipping firewall cleanup: could not get required variables. %v", err)
-				destroyFailed = true
-				break
-			} else if err := cleanupFirewallRulesFunc(projectID, deploymentName); err != nil {
-				logging.Error("Failed to cleanup firewall rules for group %s: %v", group.Name, err)
-				destroyFailed = true
-				break
-			}
-		}
-
-		groupDir := filepath.Join(deplRoot, string(group.Name))
-
-		if err := shell.ImportInputs(groupDir, artifactsDir, bp); err != nil {
-			logging.Error("failed to import inputs for group %q: %v", group.Name, err)
-			// still proceed with destroying the group
+		if err := cleanupGroupFirewallRules(bp, group); err != nil {
+			logging.Error("%v", err)
+			destroyFailed = true
+			break
 		}
 
-		var err error
-		switch group.Kind() {
-		case config.PackerKind:
-			// Packer groups are enforced to have length 1
-			// TODO: destroyPackerGroup(moduleDir)
-			moduleDir := filepath.Join(groupDir, string(group.Modules[0].ID))
-			packerManifests = append(packerManifests, filepath.Join(moduleDir, "packer-manifest.json"))
-		case config.TerraformKind:
-			err = destroyTerraformGroupFunc(groupDir)
-		default:
-			err = fmt.Errorf("group %q is an unsupported kind %q", groupDir, group.Kind().String())
+		manifest, err := destroyGroup(deplRoot, artifactsDir, bp, group, journal)
+		if manifest != "" {
+			packerManifests = append(packerManifests, manifest)
 		}
-
 		if err != nil {
 			logging.Error("failed to destroy group %q:\n%s", group.Name, renderError(err, *ctx))
 			destroyFailed = true
@@ -167,6 +173,113 @@ func destroyGroups(deplRoot string, artifactsDir string, bp config.Blueprint, ct
 	return destroyFailed, packerManifests
 }
 
+// destroyGroupsConcurrently destroys groups once groups using their outputs are destroyed,
+// after a failure no more groups are destroyed.
+func destroyGroupsConcurrently(deplRoot string, artifactsDir string, bp config.Blueprint, ctx *config.YamlCtx) (bool, []string) {
+	deps, err := bp.GroupDependencies()
+	if err != nil {
+		logging.Error("%v", err)
+		return true, nil
+	}
+	journal, err := shell.LoadJournal(deplRoot)
+	if err != nil {
+		logging.Error("%v", err)
+		return true, nil
+	}
+	selected := []config.GroupName{}
+	for i := len(bp.Groups) - 1; i >= 0; i-- {
+		if name := bp.Groups[i].Name; isGroupSelected(name) {
+			selected = append(selected, name)
+		} else {
+			logging.Info("skipping group %q", name)
+		}
+	}
+
+	var mu sync.Mutex
+	packerManifests := []string{}
+	err = runGroups(selected, deps, flagParallelism, true, func(name config.GroupName) error {
+		group := bp.Groups[bp.GroupIndex(name)]
+		if err := cleanupGroupFirewallRules(bp, group); err != nil {
+			return err
+		}
+		manifest, err := destroyGroup(deplRoot, artifactsDir, bp, group, journal)
+		if manifest != "" {
+			mu.Lock()
+			packerManifests = append(packerManifests, manifest)
+			mu.Unlock()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to destroy group %q: %w", group.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Error("%s", renderError(err, *ctx))
+		return true, packerManifests
+	}
+	return false, packerManifests
+}
+
+// cleanupGroupFirewallRules removes firewall rules of the deployment before
+// destroying a network group, if robust destroy is requested.
+func cleanupGroupFirewallRules(bp config.Blueprint, group config.Group) error {
+	if !robustDestroy || !groupHasNetworkModule(group) {
+		return nil
+	}
+	projectID, deploymentName, err := getProjectAndDeploymentVars(bp.Vars)
+	if err != nil {
+		return fmt.Errorf("Skipping firewall cleanup: could not get required variables. %v", err)
+	}
+	if err := cleanupFirewallRulesFunc(projectID, deploymentName); err != nil {
+		return fmt.Errorf("Failed to cleanup firewall rules for group %s: %v", group.Name, err)
+	}
+	return nil
+}
+
+// destroyGroup destroys the group and records it in the journal, Packer groups
+// are not destroyed and the path of their manifest is returned instead.
+func destroyGroup(deplRoot string, artifactsDir string, bp config.Blueprint, group config.Group, journal *shell.Journal) (string, error) {
+	groupDir := filepath.Join(deplRoot, string(group.Name))
+
+	if err := shell.ImportInputs(groupDir, artifactsDir, bp); err != nil {
+		logging.Error("failed to import inputs for group %q: %v", group.Name, err)
+		// still proceed with destroying the group
+	}
+
+	finish := groupEvents(group.Name, group.Kind())
+	switch group.Kind() {
+	case config.PackerKind:
+		// Packer groups are enforced to have length 1
+		// TODO: destroyPackerGroup(moduleDir)
+		manifest, err := packerManifestPath(deplRoot, bp, group.Name, group.Modules[0])
+		if err != nil {
+			logging.Error("cannot locate the Packer manifest of group %q: %v", group.Name, err)
+		}
+		finish(events.StatusSkipped, nil)
+		return manifest, nil
+	case config.TerraformKind:
+		warnPartialApply(group, true)
+		partial := isPartial(group)
+		status := events.StatusDestroyed
+		if partial {
+			status = events.StatusPartial
+		}
+		err := destroyTerraformGroupFunc(groupDir)
+		finish(status, err)
+		if err != nil {
+			return "", err
+		}
+		if partial {
+			return "", journal.Partial(group.Name)
+		}
+		return "", journal.Destroy(group.Name)
+	default:
+		err := fmt.Errorf("group %q is an unsupported kind %q", groupDir, group.Kind().String())
+		finish("", err)
+		return "", err
+	}
+}
+
 func getStringVar(vars config.Dict, key string) (string, error) {
 	val := vars.Get(key)
 	if val.IsNull() {
@@ -202,10 +315,12 @@ func destroyTerraformGroup(groupDir string) error {
 
 	// Always output text when destroying the cluster
 	// The current implementation outputs JSON only for the "deploy" command
-	return shell.Destroy(tf, getApplyBehavior(), shell.TextOutput)
+	return shell.Destroy(tf, getApplyBehavior(), shell.TextOutput, moduleTargets(config.GroupName(filepath.Base(groupDir)))...)
 }
 
+// confirmAction asks a yes/no question, one at a time with concurrently destroyed groups
 func confirmAction(prompt string) bool {
+	defer shell.LockPrompt()()
 	reader := bufio.NewReader(os.Stdin)
 	for {
 		fmt.Print(prompt)
@@ -271,6 +386,122 @@ func cleanupFirewallRules(projectID string, deploymentName string) error {
 	return confirmAndDeleteFirewallRules(projectID, deploymentName, &computeServiceWrapper{computeService}, firewallsToDelete)
 }
 
+// cleanupDeploymentOrphans deletes resources left behind by the failed destroy,
+// that would fail the next attempt, e.g. load balancers created by GKE
+func cleanupDeploymentOrphans(deplRoot string, bp config.Blueprint) error {
+	projectID, deploymentName, err := getProjectAndDeploymentVars(bp.Vars)
+	if err != nil {
+		return fmt.Errorf("Skipping cleanup of leftover resources: could not get required variables. %v", err)
+	}
+	target := cleanup.Target{Project: projectID, Deployment: deploymentName, Modules: selectedModuleNames(bp)}
+	if err := cleanupOrphansFunc(target, deploymentNetworks(deplRoot, bp)); err != nil {
+		return fmt.Errorf("Failed to cleanup leftover resources of deployment %s: %v", deploymentName, err)
+	}
+	return nil
+}
+
+// selectedModuleNames returns names of modules of the selected groups, as in
+// the module label of their resources, or nil if all groups are selected
+func selectedModuleNames(bp config.Blueprint) []string {
+	all := flagModules == nil
+	for _, g := range bp.Groups {
+		all = all && isGroupSelected(g.Name)
+	}
+	if all {
+		return nil
+	}
+
+	res := []string{}
+	bp.WalkModulesSafe(func(_ config.ModulePath, m *config.Module) {
+		g := bp.ModuleGroupOrDie(m.ID)
+		if !isGroupSelected(g.Name) || (flagModules != nil && !slices.Contains(selectedModules[g.Name], m.ID)) {
+			return
+		}
+		src, _, _ := strings.Cut(m.Source, "?")
+		if name := path.Base(src); !slices.Contains(res, name) {
+			res = append(res, name)
+		}
+	})
+	return res
+}
+
+// deploymentNetworks returns names of networks in the Terraform state of the
+// selected groups
+func deploymentNetworks(deplRoot string, bp config.Blueprint) []string {
+	res := []string{}
+	for _, g := range bp.Groups {
+		if !isGroupSelected(g.Name) || g.Kind() != config.TerraformKind || !groupHasNetworkModule(g) {
+			continue
+		}
+		tf, err := shell.ConfigureTerraform(filepath.Join(deplRoot, string(g.Name)))
+		if err == nil {
+			var names []string
+			names, err = shell.StateAttributes(tf, "google_compute_network", "name")
+			res = append(res, names...)
+		}
+		if err != nil {
+			logging.Error("cannot read networks of group %q, resources in them are not cleaned up: %v", g.Name, err)
+		}
+	}
+	return res
+}
+
+func cleanupOrphans(target cleanup.Target, networks []string) error {
+	logging.Info("Looking for resources left behind by deployment %s in project %s", target.Deployment, target.Project)
+	c, err := cleanup.NewClient(context.Background())
+	if err != nil {
+		return err
+	}
+	return confirmAndDeleteOrphans(c, target, networks)
+}
+
+// confirmAndDeleteOrphans discovers leftover resources of the deployment, confirms
+// with the user and deletes them in dependency order. Resources holding data are
+// confirmed separately and never deleted with --auto-approve.
+func confirmAndDeleteOrphans(c cleanup.Client, target cleanup.Target, networks []string) error {
+	var err error
+	if target.Networks, err = cleanup.FindNetworks(c, target.Project, networks); err != nil {
+		return fmt.Errorf("failed to list networks: %v", err)
+	}
+	found, err := cleanup.Discover(c, target)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		logging.Info("No leftover resources found.")
+		return nil
+	}
+
+	// approved kinds of resources by whether they hold data
+	approved := map[bool]bool{}
+	for _, stateful := range []bool{false, true} {
+		rs := slices.DeleteFunc(slices.Clone(found), func(r cleanup.Resource) bool { return cleanup.Stateful(r) != stateful })
+		if len(rs) == 0 {
+			continue
+		}
+		what := "resources"
+		if stateful {
+			what = "resources that may hold data"
+		}
+		logging.Info("Found leftover %s to delete:", what)
+		for _, r := range rs {
+			logging.Info("  %s", r)
+		}
+		prompt := fmt.Sprintf("Do you want to delete these %d %s of deployment %s? [y/n]: ", len(rs), what, target.Deployment)
+		switch {
+		case flagAutoApprove && stateful:
+			logging.Info("Skipping deletion of %s with --auto-approve, delete them manually or destroy again without --auto-approve.", what)
+		case flagAutoApprove || confirmAction(prompt):
+			approved[stateful] = true
+		default:
+			logging.Info("Skipping deletion of leftover %s.", what)
+		}
+	}
+
+	toDelete := slices.DeleteFunc(found, func(r cleanup.Resource) bool { return !approved[cleanup.Stateful(r)] })
+	return cleanup.Delete(c, target, toDelete)
+}
+
 type computeServiceWrapper struct {
 	*compute.Service
 }
@@ -16,12 +16,16 @@ package cmd
 
 import (
 	"fmt"
+	"hpc-toolkit/pkg/cleanup"
 	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
+	"cloud.google.com/go/filestore/apiv1/filestorepb"
 	"github.com/zclconf/go-cty/cty"
 	compute "google.golang.org/api/compute/v1"
 )
@@ -47,6 +51,19 @@ func TestDestroyGroupsUnsupportedKind(t *testing.T) {
 	}
 }
 
+func TestDestroyGroupsPrefixesOutputOnlyInParallel(t *testing.T) {
+	defer func(p int, prefix bool) { flagParallelism, shell.PrefixGroupOutput = p, prefix }(flagParallelism, shell.PrefixGroupOutput)
+	bp := config.Blueprint{Groups: []config.Group{{Name: "unsupported-group"}}}
+
+	for _, p := range []int{2, 1} {
+		flagParallelism = p
+		destroyGroups(t.TempDir(), "", bp, &config.YamlCtx{})
+		if want := p > 1; shell.PrefixGroupOutput != want {
+			t.Errorf("with parallelism %d got PrefixGroupOutput %v, want %v", p, shell.PrefixGroupOutput, want)
+		}
+	}
+}
+
 type mockFirewallDeleter struct {
 	deleteErr error
 }
@@ -378,3 +395,127 @@ func TestFilterString(t *testing.T) {
 		t.Errorf("Filter string mismatch: got %q, want %q", actualFilter, expectedFilter)
 	}
 }
+
+func TestCleanupDeploymentOrphans(t *testing.T) {
+	original := cleanupOrphansFunc
+	defer func() { cleanupOrphansFunc = original }()
+
+	var got cleanup.Target
+	cleanupOrphansFunc = func(target cleanup.Target, networks []string) error {
+		got = target
+		return nil
+	}
+
+	if err := cleanupDeploymentOrphans(t.TempDir(), config.Blueprint{}); err == nil {
+		t.Error("want error without project_id and deployment_name")
+	}
+
+	bp := config.Blueprint{Vars: config.NewDict(map[string]cty.Value{
+		"project_id":      cty.StringVal("test-project"),
+		"deployment_name": cty.StringVal("test-deployment"),
+	})}
+	if err := cleanupDeploymentOrphans(t.TempDir(), bp); err != nil {
+		t.Fatal(err)
+	}
+	if got.Project != "test-project" || got.Deployment != "test-deployment" || got.Modules != nil {
+		t.Errorf("cleaned up %#v", got)
+	}
+
+	cleanupOrphansFunc = func(target cleanup.Target, networks []string) error { return fmt.Errorf("mock cleanup error") }
+	if err := cleanupDeploymentOrphans(t.TempDir(), bp); err == nil {
+		t.Error("want error of cleanup")
+	}
+}
+
+func TestSelectedModuleNames(t *testing.T) {
+	defer func() { flagOnlyGroups, flagSkipGroups, flagModules, selectedModules = nil, nil, nil, nil }()
+	bp := config.Blueprint{Groups: []config.Group{
+		{Name: "primary", Modules: []config.Module{
+			{ID: "network", Source: "modules/network/vpc"},
+			{ID: "homefs", Source: "github.com/org/repo//modules/file-system/filestore?ref=v1"},
+		}},
+		{Name: "cluster", Modules: []config.Module{
+			{ID: "pool", Source: "modules/compute/gke-node-pool"},
+			{ID: "pool2", Source: "modules/compute/gke-node-pool"},
+		}},
+	}}
+
+	if got := selectedModuleNames(bp); got != nil {
+		t.Errorf("all groups selected: got %v, want nil", got)
+	}
+
+	flagOnlyGroups = []string{"cluster"}
+	if got := selectedModuleNames(bp); !slices.Equal(got, []string{"gke-node-pool"}) {
+		t.Errorf("--only cluster: got %v", got)
+	}
+
+	flagOnlyGroups, flagSkipGroups = nil, []string{"cluster"}
+	if got := selectedModuleNames(bp); !slices.Equal(got, []string{"vpc", "filestore"}) {
+		t.Errorf("--skip cluster: got %v", got)
+	}
+
+	flagSkipGroups, flagModules = nil, []string{"homefs"}
+	selectedModules = map[config.GroupName][]config.ModuleID{"primary": {"homefs"}}
+	if got := selectedModuleNames(bp); !slices.Equal(got, []string{"filestore"}) {
+		t.Errorf("--module homefs: got %v", got)
+	}
+}
+
+// orphansClient lists a disk, a firewall rule in network hpc-net and records
+// deleted resources
+type orphansClient struct {
+	cleanup.Client
+	deleted []string
+}
+
+func (c *orphansClient) ListNetworks(project string) ([]*compute.Network, error) {
+	return []*compute.Network{{Name: "hpc-net", SelfLink: "hpc-net-link"}, {Name: "hpc-net-2", SelfLink: "hpc-net-2-link"}}, nil
+}
+
+func (c *orphansClient) ListForwardingRules(project string) ([]*compute.ForwardingRule, error) {
+	return nil, nil
+}
+
+func (c *orphansClient) ListNetworkEndpointGroups(project string) ([]*compute.NetworkEndpointGroup, error) {
+	return nil, nil
+}
+
+func (c *orphansClient) ListRoutes(project string) ([]*compute.Route, error) {
+	return nil, nil
+}
+
+func (c *orphansClient) ListFirewalls(project string) ([]*compute.Firewall, error) {
+	return []*compute.Firewall{{Name: "allow-ssh", Network: "hpc-net-link"}, {Name: "other", Network: "hpc-net-2-link"}}, nil
+}
+
+func (c *orphansClient) DeleteFirewall(project string, name string) error {
+	c.deleted = append(c.deleted, "firewall "+name)
+	return nil
+}
+
+func (c *orphansClient) ListDisks(project string) ([]*compute.Disk, error) {
+	return []*compute.Disk{{Name: "data", Zone: "zones/us-central1-a", Labels: map[string]string{cleanup.DeploymentLabel: "hpc"}}}, nil
+}
+
+func (c *orphansClient) DeleteDisk(project string, zone string, name string) error {
+	c.deleted = append(c.deleted, "disk "+name)
+	return nil
+}
+
+func (c *orphansClient) ListFilestoreInstances(project string) ([]*filestorepb.Instance, error) {
+	return nil, nil
+}
+
+func TestConfirmAndDeleteOrphansAutoApprove(t *testing.T) {
+	defer func(v bool) { flagAutoApprove = v }(flagAutoApprove)
+	flagAutoApprove = true
+
+	c := &orphansClient{}
+	if err := confirmAndDeleteOrphans(c, cleanup.Target{Project: "p", Deployment: "hpc"}, []string{"hpc-net"}); err != nil {
+		t.Fatal(err)
+	}
+	// disks may hold data, they are not deleted without confirmation
+	if !slices.Equal(c.deleted, []string{"firewall allow-ssh"}) {
+		t.Errorf("got deleted %v, want only the firewall rule of hpc-net", c.deleted)
+	}
+}
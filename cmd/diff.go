@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulewriter"
+	"hpc-toolkit/pkg/shell"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+var (
+	diffFlags = struct {
+		format string
+	}{}
+
+	diffCmd = addExpandFlags(&cobra.Command{
+		Use:   "diff BEFORE AFTER",
+		Short: "Show blueprint-level changes between deployments and blueprints.",
+		Long: `Compares two expanded blueprints and reports added, removed and changed
+deployment groups, modules, module "use" edges, settings, deployment variables,
+Terraform backends and providers.
+
+Each argument is either a deployment directory, whose expanded blueprint is read
+from its artifacts, or a blueprint file, which is expanded the same way as by
+"create" (expansion flags such as --vars apply to blueprint arguments only).
+A typical use is previewing "create -w":
+
+  gcluster diff DEPLOYMENT_DIRECTORY BLUEPRINT_FILE`,
+		Args:              cobra.MatchAll(cobra.ExactArgs(2), checkAllExist),
+		ValidArgsFunction: filterYaml,
+		Run:               runDiffCmd,
+		SilenceUsage:      true,
+	}, false /*addOutFlag*/)
+)
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFlags.format, "format", "text", "Output format, one of (\"text\", \"json\")")
+}
+
+func checkAllExist(cmd *cobra.Command, args []string) error {
+	for _, a := range args {
+		if err := checkExists(cmd, []string{a}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffSide is one of the two compared blueprints with the file it was read from.
+type diffSide struct {
+	file string
+	bp   config.Blueprint
+	ctx  *config.YamlCtx
+}
+
+func loadDiffSide(cmd *cobra.Command, path string) diffSide {
+	if isDir, _ := shell.DirInfo(path); isDir {
+		artDir := modulewriter.ArtifactsDir(path)
+		bp, ctx := artifactBlueprintOrDie(artDir)
+		return diffSide{file: filepath.Join(artDir, modulewriter.ExpandedBlueprintName), bp: bp, ctx: ctx}
+	}
+
+	bp, ctx := expandOrDie(cmd, path)
+	// materialize a copy so that it is comparable with an artifact blueprint
+	mat := bp.Clone()
+	checkErr(mat.Materialize(), ctx)
+	return diffSide{file: path, bp: mat, ctx: ctx}
+}
+
+func runDiffCmd(cmd *cobra.Command, args []string) {
+	if diffFlags.format != "text" && diffFlags.format != "json" {
+		checkErr(fmt.Errorf("invalid --format %q, expected one of (\"text\", \"json\")", diffFlags.format), nil)
+	}
+
+	before := loadDiffSide(cmd, args[0])
+	after := loadDiffSide(cmd, args[1])
+	changes := config.Diff(before.bp, after.bp)
+
+	if diffFlags.format == "json" {
+		checkErr(writeDiffJSON(cmd.OutOrStdout(), before, after, changes), nil)
+		return
+	}
+	writeDiffText(cmd.OutOrStdout(), before, after, changes)
+}
+
+type diffPosJSON struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type diffChangeJSON struct {
+	Kind           config.ChangeKind     `json:"kind"`
+	Category       config.ChangeCategory `json:"category"`
+	Address        string                `json:"address"`
+	Before         *string               `json:"before,omitempty"`
+	After          *string               `json:"after,omitempty"`
+	BeforePosition *diffPosJSON          `json:"before_position,omitempty"`
+	AfterPosition  *diffPosJSON          `json:"after_position,omitempty"`
+}
+
+func diffPosition(side diffSide, p config.Path) *diffPosJSON {
+	if p == nil {
+		return nil
+	}
+	src, pos, ok := findSource(p, *side.ctx)
+	if !ok {
+		return nil
+	}
+	file := side.file
+	if src.File != "" { // position in an imported file
+		file = src.File
+	}
+	return &diffPosJSON{File: file, Line: pos.Line, Column: pos.Column}
+}
+
+func diffValue(c config.Change, before bool) *string {
+	if before && c.BeforePath == nil || !before && c.AfterPath == nil {
+		return nil
+	}
+	v := c.After
+	if before {
+		v = c.Before
+	}
+	s := strings.TrimSpace(config.RenderValue(v))
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func writeDiffJSON(w io.Writer, before, after diffSide, changes []config.Change) error {
+	out := struct {
+		Before  string           `json:"before"`
+		After   string           `json:"after"`
+		Changes []diffChangeJSON `json:"changes"`
+	}{Before: before.file, After: after.file, Changes: []diffChangeJSON{}}
+
+	for _, c := range changes {
+		out.Changes = append(out.Changes, diffChangeJSON{
+			Kind:           c.Kind,
+			Category:       c.Category,
+			Address:        c.Address,
+			Before:         diffValue(c, true),
+			After:          diffValue(c, false),
+			BeforePosition: diffPosition(before, c.BeforePath),
+			AfterPosition:  diffPosition(after, c.AfterPath),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeDiffText(w io.Writer, before, after diffSide, changes []config.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No blueprint changes.")
+		return
+	}
+
+	counts := map[config.ChangeKind]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+		var sym, loc string
+		switch c.Kind {
+		case config.ChangeAdded:
+			sym = boldGreen("+")
+		case config.ChangeRemoved:
+			sym = boldRed("-")
+		default:
+			sym = boldYellow("~")
+		}
+		if p := diffPosition(after, c.AfterPath); p != nil {
+			loc = fmt.Sprintf(" (%s:%d:%d)", p.File, p.Line, p.Column)
+		} else if p := diffPosition(before, c.BeforePath); p != nil {
+			loc = fmt.Sprintf(" (%s:%d:%d)", p.File, p.Line, p.Column)
+		}
+		fmt.Fprintf(w, "%s %s %s%s\n", sym, c.Category, c.Address, loc)
+
+		if v := diffValue(c, true); v != nil {
+			fmt.Fprintln(w, indentDiffValue("-", *v))
+		}
+		if v := diffValue(c, false); v != nil {
+			fmt.Fprintln(w, indentDiffValue("+", *v))
+		}
+	}
+	fmt.Fprintf(w, "\n%d added, %d changed, %d removed.\n",
+		counts[config.ChangeAdded], counts[config.ChangeChanged], counts[config.ChangeRemoved])
+}
+
+func indentDiffValue(sym string, v string) string {
+	lines := strings.Split(v, "\n")
+	for i, l := range lines {
+		lines[i] = fmt.Sprintf("    %s %s", sym, l)
+	}
+	return strings.Join(lines, "\n")
+}
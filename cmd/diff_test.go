@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"hpc-toolkit/pkg/config"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func diffTestSides(t *testing.T) (diffSide, diffSide, []config.Change) {
+	bCtx := makeCtx(`
+vars:
+  zone: us-central1-a
+`, t)
+	aCtx := makeCtx(`
+vars:
+  zone: us-central1-b
+  region: us-central1
+`, t)
+	before := diffSide{file: "before.yaml", ctx: &bCtx, bp: config.Blueprint{
+		Vars: config.NewDict(map[string]cty.Value{"zone": cty.StringVal("us-central1-a")})}}
+	after := diffSide{file: "after.yaml", ctx: &aCtx, bp: config.Blueprint{
+		Vars: config.NewDict(map[string]cty.Value{
+			"zone":   cty.StringVal("us-central1-b"),
+			"region": cty.StringVal("us-central1"),
+		})}}
+	return before, after, config.Diff(before.bp, after.bp)
+}
+
+func TestWriteDiffText(t *testing.T) {
+	before, after, changes := diffTestSides(t)
+	var buf bytes.Buffer
+	writeDiffText(&buf, before, after, changes)
+	got := buf.String()
+
+	for _, want := range []string{
+		"var vars.region (after.yaml:4:3)",
+		"var vars.zone (after.yaml:3:3)",
+		`    - "us-central1-a"`,
+		`    + "us-central1-b"`,
+		"1 added, 1 changed, 0 removed.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	buf.Reset()
+	writeDiffText(&buf, before, before, nil)
+	if got := buf.String(); got != "No blueprint changes.\n" {
+		t.Errorf("unexpected output for no changes: %q", got)
+	}
+}
+
+func TestWriteDiffJSON(t *testing.T) {
+	before, after, changes := diffTestSides(t)
+	var buf bytes.Buffer
+	if err := writeDiffJSON(&buf, before, after, changes); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Changes []diffChangeJSON `json:"changes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d:\n%s", len(got.Changes), buf.String())
+	}
+
+	added := got.Changes[0]
+	if added.Kind != config.ChangeAdded || added.Address != "vars.region" || added.Before != nil || added.BeforePosition != nil {
+		t.Errorf("unexpected added change: %+v", added)
+	}
+	changed := got.Changes[1]
+	if changed.Kind != config.ChangeChanged || *changed.Before != `"us-central1-a"` || *changed.After != `"us-central1-b"` {
+		t.Errorf("unexpected changed change: %+v", changed)
+	}
+	if changed.BeforePosition == nil || changed.BeforePosition.File != "before.yaml" || changed.BeforePosition.Line != 3 {
+		t.Errorf("unexpected before position: %+v", changed.BeforePosition)
+	}
+}
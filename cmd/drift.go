@@ -0,0 +1,307 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/modulewriter"
+	"hpc-toolkit/pkg/shell"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zclconf/go-cty/cty"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// driftExitCode is the exit code of `gcluster drift` if drift is detected
+const driftExitCode = 2
+
+func init() {
+	driftCmd.Flags().StringVar(&driftFlags.format, "format", "json", "Output format of the report, one of json or text")
+	driftCmd.Flags().StringVarP(&driftFlags.out, "out", "o", "", "Output file for the report, defaults to stdout")
+	rootCmd.AddCommand(
+		addGroupSelectionFlags(
+			addArtifactsDirFlag(driftCmd)))
+}
+
+var (
+	driftFlags = struct {
+		format string
+		out    string
+	}{}
+
+	driftCmd = &cobra.Command{
+		Use:   "drift DEPLOYMENT_DIRECTORY",
+		Short: "Report resources of a deployment changed outside of gcluster.",
+		Long: `Runs a refresh-only "terraform plan" for every selected Terraform group and
+reports resources whose live state diverged from the Terraform state. Images
+built by Packer groups that other groups depend on are checked to still exist.
+
+Exit codes: 0 - no drift, 1 - error, 2 - drift is detected.`,
+		Args:              cobra.MatchAll(cobra.ExactArgs(1), allowRemote(checkDir)),
+		ValidArgsFunction: matchDirs,
+		PreRunE:           validateDriftFlags,
+		Run:               runDriftCmd,
+		SilenceUsage:      true,
+	}
+)
+
+func validateDriftFlags(cmd *cobra.Command, args []string) error {
+	switch driftFlags.format {
+	case "text", "json":
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, must be one of json or text", driftFlags.format)
+	}
+}
+
+func runDriftCmd(cmd *cobra.Command, args []string) {
+	deplRoot := args[0]
+	if deploymentio.IsRemote(deplRoot) { // drift doesn't modify the deployment
+		local, err := deploymentio.WorkingCopy(deplRoot)
+		checkErr(err, nil)
+		deplRoot = local
+	}
+	if driftFlags.format != "text" && driftFlags.out == "" {
+		logging.SetInfoOutput(os.Stderr) // keep stdout parsable
+	}
+
+	artDir := getArtifactsDir(deplRoot)
+	bp, ctx := artifactBlueprintOrDie(artDir)
+	checkErr(validateGroupSelectionFlags(bp), ctx)
+	checkErr(shell.ValidateDeploymentDirectory(bp.Groups, deplRoot), ctx)
+	groups := []shell.GroupDrift{}
+	for _, group := range bp.Groups {
+		if !isGroupSelected(group.Name) {
+			logging.Info("skipping group %q", group.Name)
+			continue
+		}
+		var gd shell.GroupDrift
+		switch group.Kind() {
+		case config.TerraformKind:
+			gd = driftTerraformGroup(deplRoot, artDir, bp, group)
+		case config.PackerKind:
+			if !imageReferenced(bp, group) {
+				logging.Info("skipping group %q, no later groups refer to its image", group.Name)
+				continue
+			}
+			gd = driftPackerGroup(deplRoot, bp, group)
+		default:
+			gd = shell.GroupDrift{Group: group.Name, Error: fmt.Sprintf("unsupported kind %q", group.Kind())}
+		}
+		groups = append(groups, gd)
+	}
+	report := shell.NewDriftReport(groups)
+
+	w := cmd.OutOrStdout()
+	if driftFlags.out != "" {
+		f, err := os.Create(driftFlags.out)
+		checkErr(err, nil)
+		defer f.Close()
+		w = f
+	}
+	checkErr(writeDriftReport(w, report, driftFlags.format), nil)
+
+	if report.Drifted {
+		logging.ExitWithCode(driftExitCode, "Drift is detected in deployment %s", args[0])
+	}
+	if report.HasErrors() {
+		logging.Fatal("Drift of some groups of deployment %s could not be detected", args[0])
+	}
+}
+
+// imageReferenced returns whether settings of groups after the Packer group
+// mention the family or name of the image it builds. The family defaults to the
+// deployment name, as in the custom-image module; if neither is known, the image
+// is assumed to be referenced.
+func imageReferenced(bp config.Blueprint, group config.Group) bool {
+	mod := group.Modules[0] // Packer groups are enforced to have length 1
+	names := []string{}
+	for _, key := range []string{"image_family", "image_name"} {
+		if s, ok := evalString(bp, mod.Settings.Get(key)); ok {
+			names = append(names, s)
+		}
+	}
+	if !mod.Settings.Has("image_family") {
+		if s, ok := evalString(bp, bp.Vars.Get("deployment_name")); ok {
+			names = append(names, s)
+		}
+	}
+	if len(names) == 0 {
+		return true
+	}
+
+	for _, g := range bp.Groups[bp.GroupIndex(group.Name)+1:] {
+		for _, m := range g.Modules {
+			v, err := bp.Eval(m.Settings.AsObject())
+			if err != nil { // e.g. references to outputs of other modules
+				v = m.Settings.AsObject()
+			}
+			found := false
+			cty.Walk(v, func(_ cty.Path, v cty.Value) (bool, error) {
+				v, _ = v.Unmark()
+				if v.IsKnown() && !v.IsNull() && v.Type() == cty.String {
+					for _, n := range names {
+						found = found || strings.Contains(v.AsString(), n)
+					}
+				}
+				return !found, nil
+			})
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evalString returns the value if it evaluates to a non-empty string
+func evalString(bp config.Blueprint, v cty.Value) (string, bool) {
+	if v == cty.NilVal || v.IsNull() {
+		return "", false
+	}
+	v, err := bp.Eval(v)
+	if err != nil || !v.IsKnown() || v.IsNull() || v.Type() != cty.String || v.AsString() == "" {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+// failures are recorded in the report, so that drift of other groups is still reported
+func driftTerraformGroup(deplRoot string, artDir string, bp config.Blueprint, group config.Group) shell.GroupDrift {
+	groupDir := filepath.Join(deplRoot, string(group.Name))
+	res := shell.GroupDrift{Group: group.Name}
+	if err := shell.ImportInputs(groupDir, artDir, bp); err != nil {
+		res.Error = fmt.Sprintf("outputs of upstream groups are not available: %v", err)
+		return res
+	}
+	tf, err := shell.ConfigureTerraform(groupDir)
+	if err == nil {
+		res, err = shell.DriftGroup(tf, group.Name)
+	}
+	if err != nil {
+		logging.Error("cannot detect drift of group %q: %v", group.Name, err)
+		return shell.GroupDrift{Group: group.Name, Error: err.Error()}
+	}
+	return res
+}
+
+func driftPackerGroup(deplRoot string, bp config.Blueprint, group config.Group) shell.GroupDrift {
+	res := shell.GroupDrift{Group: group.Name}
+	// Packer groups are enforced to have length 1
+	mod := group.Modules[0]
+	manifest, err := packerManifestPath(deplRoot, bp, group.Name, mod)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	images, err := shell.PackerManifestImages(manifest)
+	if errors.Is(err, os.ErrNotExist) {
+		res.Note = "the image was not built, Packer manifest is not found"
+		return res
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	project, err := packerProject(bp, mod)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	for _, img := range images {
+		ok, err := imageExists(project, img)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		if !ok {
+			res.MissingImages = append(res.MissingImages, fmt.Sprintf("projects/%s/global/images/%s", project, img))
+		}
+	}
+	return res
+}
+
+// packerManifestPath returns the path of the manifest the Packer module
+// writes, Packer runs in the module directory of the deployment group.
+func packerManifestPath(deplRoot string, bp config.Blueprint, group config.GroupName, mod config.Module) (string, error) {
+	src, err := modulewriter.DeploymentSource(mod)
+	if err != nil {
+		return "", err
+	}
+	modDir := filepath.Join(deplRoot, string(group), src)
+
+	manifest := "packer-manifest.json"
+	if v := mod.Settings.Get("manifest_file"); !v.IsNull() {
+		ev, err := bp.Eval(v)
+		if err != nil || ev.Type() != cty.String || !ev.IsKnown() || ev.IsNull() {
+			return "", fmt.Errorf("cannot evaluate setting manifest_file of module %q", mod.ID)
+		}
+		manifest = ev.AsString()
+	}
+	if filepath.IsAbs(manifest) {
+		return manifest, nil
+	}
+	return filepath.Join(modDir, manifest), nil
+}
+
+// packerProject returns the project images of the Packer module are built in
+func packerProject(bp config.Blueprint, mod config.Module) (string, error) {
+	if v := mod.Settings.Get("project_id"); !v.IsNull() {
+		ev, err := bp.Eval(v)
+		if err == nil && ev.Type() == cty.String && ev.IsKnown() {
+			return ev.AsString(), nil
+		}
+	}
+	return getStringVar(bp.Vars, "project_id")
+}
+
+// imageExists reports whether the Compute Engine image exists
+var imageExists = func(project string, image string) (bool, error) {
+	s, err := compute.NewService(context.Background())
+	if err != nil {
+		return false, err
+	}
+	_, err = s.Images.Get(project, image).Fields("name").Do()
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) && gErr.Code == 404 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func writeDriftReport(w io.Writer, r shell.DriftReport, format string) error {
+	switch format {
+	case "text":
+		_, err := io.WriteString(w, r.Text())
+		return err
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"hpc-toolkit/pkg/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDriftPackerGroup(t *testing.T) {
+	deplRoot := t.TempDir()
+	bp := config.Blueprint{Vars: config.NewDict(map[string]cty.Value{"project_id": cty.StringVal("my-project")})}
+	group := config.Group{Name: "image", Modules: []config.Module{{ID: "custom-image", Kind: config.PackerKind}}}
+
+	gd := driftPackerGroup(deplRoot, bp, group)
+	if gd.Error != "" || gd.Note == "" || len(gd.MissingImages) > 0 {
+		t.Errorf("want note about missing manifest, got %#v", gd)
+	}
+
+	modDir := filepath.Join(deplRoot, "image", "custom-image")
+	if err := os.MkdirAll(modDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"builds": [{"builder_type": "googlecompute", "artifact_id": "img-1", "packer_run_uuid": "r"}], "last_run_uuid": "r"}`
+	if err := os.WriteFile(filepath.Join(modDir, "packer-manifest.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(f func(string, string) (bool, error)) { imageExists = f }(imageExists)
+	var gotProject, gotImage string
+	imageExists = func(project string, image string) (bool, error) {
+		gotProject, gotImage = project, image
+		return false, nil
+	}
+	gd = driftPackerGroup(deplRoot, bp, group)
+	if gotProject != "my-project" || gotImage != "img-1" {
+		t.Errorf("checked image %q in project %q", gotImage, gotProject)
+	}
+	want := "projects/my-project/global/images/img-1"
+	if len(gd.MissingImages) != 1 || gd.MissingImages[0] != want {
+		t.Errorf("want missing image %q, got %#v", want, gd)
+	}
+}
+
+func TestPackerManifestPath(t *testing.T) {
+	bp := config.Blueprint{Vars: config.NewDict(map[string]cty.Value{"manifest": cty.StringVal("out/images.json")})}
+	type test struct {
+		mod  config.Module
+		want string
+	}
+	tests := []test{
+		{config.Module{ID: "img", Kind: config.PackerKind, Source: "modules/packer/custom-image"},
+			filepath.Join("depl", "image", "img", "packer-manifest.json")},
+		{config.Module{ID: "img", Kind: config.PackerKind, Source: "github.com/org/repo//packer/image?ref=v1"},
+			filepath.Join("depl", "image", "img", "packer", "image", "packer-manifest.json")},
+		{config.Module{ID: "img", Kind: config.PackerKind, Source: "modules/packer/custom-image",
+			Settings: config.NewDict(map[string]cty.Value{"manifest_file": cty.StringVal("m.json")})},
+			filepath.Join("depl", "image", "img", "m.json")},
+		{config.Module{ID: "img", Kind: config.PackerKind, Source: "modules/packer/custom-image",
+			Settings: config.NewDict(map[string]cty.Value{"manifest_file": config.GlobalRef("manifest").AsValue()})},
+			filepath.Join("depl", "image", "img", "out", "images.json")},
+		{config.Module{ID: "img", Kind: config.PackerKind, Source: "modules/packer/custom-image",
+			Settings: config.NewDict(map[string]cty.Value{"manifest_file": cty.StringVal("/tmp/m.json")})},
+			"/tmp/m.json"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.mod.Source, func(t *testing.T) {
+			got, err := packerManifestPath("depl", bp, "image", tc.mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImageReferenced(t *testing.T) {
+	image := config.Group{Name: "image", Modules: []config.Module{{ID: "custom-image", Kind: config.PackerKind,
+		Settings: config.NewDict(map[string]cty.Value{"image_family": config.GlobalRef("family").AsValue()})}}}
+	cluster := func(settings map[string]cty.Value) config.Group {
+		return config.Group{Name: "cluster", Modules: []config.Module{{ID: "nodes", Kind: config.TerraformKind,
+			Settings: config.NewDict(settings)}}}
+	}
+	bp := func(groups ...config.Group) config.Blueprint {
+		return config.Blueprint{
+			Vars: config.NewDict(map[string]cty.Value{
+				"deployment_name": cty.StringVal("green"),
+				"family":          cty.StringVal("slurm-img")}),
+			Groups: groups}
+	}
+
+	type test struct {
+		bp   config.Blueprint
+		want bool
+	}
+	for name, tc := range map[string]test{
+		"last group": {bp(image), false},
+		"unrelated later group": {bp(image, cluster(map[string]cty.Value{
+			"machine_type": cty.StringVal("n2-standard-2")})), false},
+		"literal family": {bp(image, cluster(map[string]cty.Value{
+			"instance_image": cty.ObjectVal(map[string]cty.Value{"family": cty.StringVal("slurm-img")})})), true},
+		"family through vars": {bp(image, cluster(map[string]cty.Value{
+			"instance_image": cty.ObjectVal(map[string]cty.Value{"family": config.GlobalRef("family").AsValue()})})), true},
+		"preceding group": {bp(cluster(map[string]cty.Value{"image": cty.StringVal("slurm-img")}), image), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := tc.bp.Groups[tc.bp.GroupIndex("image")]
+			if got := imageReferenced(tc.bp, g); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// the family defaults to the deployment name
+	noFamily := config.Group{Name: "image", Modules: []config.Module{{ID: "custom-image", Kind: config.PackerKind}}}
+	if !imageReferenced(bp(noFamily, cluster(map[string]cty.Value{"image": cty.StringVal("green")})), noFamily) {
+		t.Error("want image of the deployment name family to be referenced")
+	}
+}
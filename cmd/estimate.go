@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulewriter"
+	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/validators"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	estimateCmd.Flags().StringVar(&estimateFlags.prices, "prices", "", "Pricing table file, its entries override the bundled pricing table")
+	estimateCmd.Flags().StringVar(&estimateFlags.format, "format", "text", "Output format, one of (\"text\", \"json\")")
+	rootCmd.AddCommand(estimateCmd)
+}
+
+var (
+	estimateFlags = struct {
+		prices string
+		format string
+	}{}
+
+	estimateCmd = addExpandFlags(&cobra.Command{
+		Use:   "estimate (<BLUEPRINT_FILE> | <DEPLOYMENT_DIRECTORY>)",
+		Short: "Estimate hourly and monthly costs of a blueprint.",
+		Long: `Estimates hourly and monthly costs of modules and deployment groups from their
+machine types, accelerators, disks and node counts, using a pricing table. The
+bundled table holds approximate list prices; entries of a file passed with
+--prices override it. Costs are shown for on-demand, spot and reservation
+scenarios, together with the scenario configured by the module settings.
+
+The estimate runs offline: blueprint validators are not run unless
+--validation-level is given.`,
+		Args:              cobra.MatchAll(cobra.ExactArgs(1), checkExists),
+		ValidArgsFunction: filterYaml,
+		Run:               runEstimateCmd,
+		SilenceUsage:      true,
+	}, false /*addOutFlag*/)
+)
+
+func runEstimateCmd(cmd *cobra.Command, args []string) {
+	if estimateFlags.format != "text" && estimateFlags.format != "json" {
+		checkErr(fmt.Errorf("invalid --format %q, expected one of (\"text\", \"json\")", estimateFlags.format), nil)
+	}
+
+	table := validators.BundledPriceTable()
+	if estimateFlags.prices != "" {
+		var err error
+		table, err = validators.LoadPriceTable(estimateFlags.prices)
+		checkErr(err, nil)
+	}
+
+	var bp config.Blueprint
+	if isDir, _ := shell.DirInfo(args[0]); isDir {
+		bp, _ = artifactBlueprintOrDie(modulewriter.ArtifactsDir(args[0]))
+	} else {
+		if !cmd.Flags().Changed("validation-level") {
+			expandFlags.validationLevel = "IGNORE" // validators query cloud APIs
+		}
+		bp, _ = expandOrDie(cmd, args[0])
+	}
+
+	est := validators.EstimateCosts(bp, table)
+	if estimateFlags.format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		checkErr(enc.Encode(est), nil)
+		return
+	}
+	checkErr(writeEstimate(cmd.OutOrStdout(), est), nil)
+}
+
+// writeEstimate prints monthly costs of modules and groups, hourly and monthly
+// totals, and notes on resources that are not priced
+func writeEstimate(out io.Writer, est validators.CostEstimate) error {
+	month := func(hourly float64) string { return fmt.Sprintf("%.2f", hourly*est.HoursPerMonth) }
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "GROUP\tMODULE\tCOUNT\tSCENARIO\tCONFIGURED\tON-DEMAND\tSPOT\tRESERVATION\n")
+	notes := []string{}
+	for _, g := range est.Groups {
+		for _, m := range g.Modules {
+			fmt.Fprintf(w, "%s\t%s\t%g\t%s\t%s\t%s\t%s\t%s\n", g.Group, m.Module, m.Count, strings.ReplaceAll(m.Scenario, "_", "-"),
+				month(m.Configured), month(m.Hourly.OnDemand), month(m.Hourly.Spot), month(m.Hourly.Reservation))
+			for _, n := range m.Notes {
+				notes = append(notes, fmt.Sprintf("%s/%s: %s", g.Group, m.Module, n))
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t\t\t%s\t%s\t%s\t%s\n", g.Group, "(total)", month(g.Configured),
+			month(g.Hourly.OnDemand), month(g.Hourly.Spot), month(g.Hourly.Reservation))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nTotal cost in %s, %g hours per month:\n", est.Currency, est.HoursPerMonth)
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "\tCONFIGURED\tON-DEMAND\tSPOT\tRESERVATION\n")
+	fmt.Fprintf(w, "per hour\t%.2f\t%.2f\t%.2f\t%.2f\n", est.Configured, est.Hourly.OnDemand, est.Hourly.Spot, est.Hourly.Reservation)
+	fmt.Fprintf(w, "per month\t%s\t%s\t%s\t%s\n", month(est.Configured), month(est.Hourly.OnDemand), month(est.Hourly.Spot), month(est.Hourly.Reservation))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(notes) > 0 {
+		fmt.Fprintln(out, "\nNot estimated:")
+		for _, n := range notes {
+			fmt.Fprintf(out, "  %s\n", n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"hpc-toolkit/pkg/validators"
+	"strings"
+	"testing"
+)
+
+func TestWriteEstimate(t *testing.T) {
+	est := validators.CostEstimate{
+		Currency:      "USD",
+		HoursPerMonth: 100,
+		Groups: []validators.GroupCost{{
+			Group: "primary",
+			Modules: []validators.ModuleCost{{
+				Module:     "gpu",
+				Count:      2,
+				Scenario:   validators.ScenarioSpot,
+				Hourly:     validators.Costs{OnDemand: 10, Spot: 3, Reservation: 6},
+				Configured: 3,
+				Notes:      []string{"no price for SSD_TOTAL_GB"},
+			}},
+			Hourly:     validators.Costs{OnDemand: 10, Spot: 3, Reservation: 6},
+			Configured: 3,
+		}},
+		Hourly:     validators.Costs{OnDemand: 10, Spot: 3, Reservation: 6},
+		Configured: 3,
+	}
+
+	var out bytes.Buffer
+	if err := writeEstimate(&out, est); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"primary   gpu       2       spot       300.00       1000.00     300.00   600.00",
+		"per hour    3.00         10.00       3.00     6.00",
+		"per month   300.00       1000.00     300.00   600.00",
+		"primary/gpu: no price for SSD_TOTAL_GB",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in output:\n%s", want, got)
+		}
+	}
+}
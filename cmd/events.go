@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/events"
+	"hpc-toolkit/pkg/logging"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var flagEvents string
+
+func addEventsFlag(c *cobra.Command) *cobra.Command {
+	c.Flags().StringVar(&flagEvents, "events", "",
+		"Write a stream of JSON events, one per line, to the file, \"-\" for stdout")
+	return c
+}
+
+// startEvents starts the event stream requested by --events and emits the start
+// of the command. The returned function emits successful completion, failures
+// are emitted by logging.FatalHook.
+func startEvents(command string, deployment string) func() {
+	if flagEvents == "" {
+		return func() {}
+	}
+
+	var w io.Writer
+	var closer io.Closer
+	if flagEvents == "-" {
+		w = os.Stdout
+		// keep stdout parsable, all other output of gcluster, Terraform and Packer goes to stderr
+		os.Stdout = os.Stderr
+		logging.SetInfoOutput(os.Stderr)
+	} else {
+		f, err := os.Create(flagEvents)
+		checkErr(err, nil)
+		w, closer = f, f
+	}
+	events.SetOutput(w)
+
+	var mu sync.Mutex
+	lastFatal := ""
+	logging.MessageHook = func(level string, msg string) {
+		if level == "fatal" {
+			mu.Lock()
+			lastFatal = msg
+			mu.Unlock()
+		}
+		events.Emit(events.Event{Type: events.Log, Message: msg, Data: map[string]any{"level": level}})
+	}
+
+	var once sync.Once
+	finish := func(exitCode int) {
+		once.Do(func() {
+			e := events.Event{Type: events.CommandFinish, Status: events.StatusSuccess, Data: map[string]any{"exit_code": exitCode}}
+			if exitCode != 0 {
+				mu.Lock()
+				e.Status, e.Message = events.StatusFailure, lastFatal
+				mu.Unlock()
+			}
+			events.Emit(e)
+			events.SetOutput(nil)
+			if closer != nil {
+				closer.Close()
+			}
+		})
+	}
+	prevHook := logging.FatalHook
+	logging.FatalHook = func(exitCode int) {
+		finish(exitCode)
+		if prevHook != nil {
+			prevHook(exitCode)
+		}
+	}
+
+	events.Emit(events.Event{Type: events.CommandStart, Data: map[string]any{"command": command, "deployment": deployment}})
+	return func() { finish(0) }
+}
+
+// groupEvents emits the start of the group and returns a function emitting its
+// finish with the status, or failure if err is not nil
+func groupEvents(group config.GroupName, kind config.ModuleKind) func(status string, err error) {
+	start := time.Now()
+	events.EmitGroup(events.GroupStart, string(group), "", map[string]any{"kind": kind.String()})
+	return func(status string, err error) {
+		e := events.Event{Type: events.GroupFinish, Group: string(group), Status: status,
+			Data: map[string]any{"duration_seconds": time.Since(start).Seconds()}}
+		if err != nil {
+			e.Status, e.Message = events.StatusFailed, err.Error()
+		}
+		events.Emit(e)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/events"
+	"hpc-toolkit/pkg/logging"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartEvents(t *testing.T) {
+	defer func(fh func(int), mh func(string, string)) {
+		logging.FatalHook, logging.MessageHook = fh, mh
+		flagEvents = ""
+	}(logging.FatalHook, logging.MessageHook)
+
+	flagEvents = filepath.Join(t.TempDir(), "events.ndjson")
+	finish := startEvents("deploy", "hpc-slurm")
+	logging.Info("hello")
+	groupEvents("primary", config.TerraformKind)("", errors.New("boom"))
+	finish()
+	finish() // emitted once
+	events.EmitGroup(events.GroupStart, "late", "", nil)
+
+	data, err := os.ReadFile(flagEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []events.Event{}
+	for _, l := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var e events.Event
+		if err := json.Unmarshal([]byte(l), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", l, err)
+		}
+		got = append(got, e)
+	}
+
+	want := []events.Type{events.CommandStart, events.Log, events.GroupStart, events.GroupFinish, events.CommandFinish}
+	if len(got) != len(want) {
+		t.Fatalf("want %d events, got %#v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Type != w {
+			t.Errorf("event %d: want type %q, got %q", i, w, got[i].Type)
+		}
+	}
+	if got[1].Message != "hello" || got[3].Status != events.StatusFailed || got[3].Message != "boom" || got[4].Status != events.StatusSuccess {
+		t.Errorf("unexpected events %#v", got)
+	}
+}
@@ -46,6 +46,10 @@ func addExpandFlags(c *cobra.Command, addOutFlag bool) *cobra.Command {
 	c.Flags().StringSliceVar(&expandFlags.validatorsToSkip, "skip-validators", nil, "Validators to skip")
 	c.Flags().BoolVar(&expandFlags.addCreatorLabel, "add-creator-label", false,
 		"Add label ghpc_creator to the expanded blueprint. Defaults to true for @google.com accounts.")
+	c.Flags().StringSliceVar(&expandFlags.deploymentSrcs, "deployment-source", nil,
+		"Comma-separated list of name=location of deployments referenced with $(deployment_output(name, output)), "+
+			"a location is a deployment directory or a gs:// prefix of its Terraform state. "+
+			"Defaults to a directory named after the deployment in the output directory or next to the blueprint. Can be used multiple times.")
 	return c
 }
 
@@ -62,6 +66,7 @@ var (
 		validationLevel  string
 		validatorsToSkip []string
 		addCreatorLabel  bool
+		deploymentSrcs   []string
 	}{}
 
 	expandCmd = addExpandFlags(&cobra.Command{
@@ -73,4 +73,5 @@ func runExportCmd(cmd *cobra.Command, args []string) {
 
 	// Always output text when exporting (never JSON)
 	checkErr(shell.ExportOutputs(tf, artifactsDir, shell.NeverApply, shell.TextOutput), ctx)
+	checkErr(shell.ExportDeploymentOutputs(artifactsDir, bp), ctx)
 }
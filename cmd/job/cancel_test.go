@@ -80,6 +80,30 @@ func (m *mockKubeClient) ListJobSets(labelSelector string) ([]orchestrator.JobSt
 	return []orchestrator.JobStatus{}, m.err
 }
 
+func (m *mockKubeClient) ReleaseJobSet(namespace string, name string) error {
+	return m.err
+}
+
+func (m *mockKubeClient) GetJobSet(namespace string, name string) (map[string]interface{}, error) {
+	return nil, m.err
+}
+
+func (m *mockKubeClient) ListPods(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	return nil, m.err
+}
+
+func (m *mockKubeClient) ListKueueWorkloads(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	return nil, m.err
+}
+
+func (m *mockKubeClient) ListEvents(namespace string) ([]map[string]interface{}, error) {
+	return nil, m.err
+}
+
+func (m *mockKubeClient) GetNodeLabels(name string) (map[string]string, error) {
+	return nil, m.err
+}
+
 func TestCancelCmd_MissingArgs(t *testing.T) {
 	resetSubmitCmdFlags()
 
@@ -118,3 +142,30 @@ func TestCancelCmd_JobNotFound(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+type releaseOrchestrator struct {
+	orchestrator.JobOrchestrator
+	released []string
+}
+
+func (r *releaseOrchestrator) ReleaseJob(name string, opts orchestrator.ReleaseOptions) error {
+	r.released = append(r.released, name)
+	return nil
+}
+
+func TestReleaseCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+
+	oldFactory := slurmOrchestratorFactory
+	defer func() { slurmOrchestratorFactory = oldFactory }()
+
+	r := &releaseOrchestrator{}
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator { return r }
+
+	if _, err := executeCommand(JobCmd, "release", "held-job", "--orchestrator", "slurm"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.released) != 1 || r.released[0] != "held-job" {
+		t.Errorf("released = %v, want [held-job]", r.released)
+	}
+}
@@ -40,7 +40,9 @@ var configSetCmd = &cobra.Command{
 Supported keys:
   project   - Google Cloud Project ID
   cluster   - GKE Cluster Name
-  location  - GKE Cluster Location (region or zone)`,
+  location  - GKE Cluster Location (region or zone)
+  orchestrator - Job orchestrator (gke or slurm)
+  login-node   - Slurm login node VM name`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.ToLower(args[0])
@@ -55,8 +57,16 @@ Supported keys:
 			ctx.ClusterName = value
 		case "location":
 			ctx.Location = value
+		case "orchestrator":
+			value = strings.ToLower(value)
+			if value != gkeOrchestrator && value != slurmOrchestrator {
+				return fmt.Errorf("invalid orchestrator: %s. Supported orchestrators: %s, %s", value, gkeOrchestrator, slurmOrchestrator)
+			}
+			ctx.Orchestrator = value
+		case "login-node":
+			ctx.LoginNode = value
 		default:
-			return fmt.Errorf("invalid configuration key: %s. Supported keys: project, cluster, location", key)
+			return fmt.Errorf("invalid configuration key: %s. Supported keys: project, cluster, location, orchestrator, login-node", key)
 		}
 
 		if err := saveContext(ctx); err != nil {
@@ -78,6 +88,12 @@ var configListCmd = &cobra.Command{
 		fmt.Fprintf(cmd.OutOrStdout(), "  project:  %s\n", ctx.ProjectID)
 		fmt.Fprintf(cmd.OutOrStdout(), "  cluster:  %s\n", ctx.ClusterName)
 		fmt.Fprintf(cmd.OutOrStdout(), "  location: %s\n", ctx.Location)
+		if ctx.Orchestrator != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  orchestrator: %s\n", ctx.Orchestrator)
+		}
+		if ctx.LoginNode != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  login-node: %s\n", ctx.LoginNode)
+		}
 		return nil
 	},
 }
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var describeFormat string
+
+var DescribeJobCmd = &cobra.Command{
+	Use:   "describe [job-name]",
+	Short: "Show the details of a job: its spec, pods, Kueue admission and recent events.",
+	Args:  cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if describeFormat != "text" && describeFormat != "json" {
+			return fmt.Errorf("invalid --format %q, expected one of (\"text\", \"json\")", describeFormat)
+		}
+		return nil
+	},
+	RunE:         runDescribeJob,
+	SilenceUsage: true,
+}
+
+func init() {
+	DescribeJobCmd.Flags().StringVar(&describeFormat, "format", "text", "Output format, one of (\"text\", \"json\")")
+}
+
+func runDescribeJob(cmd *cobra.Command, args []string) error {
+	opts := orchestrator.DescribeOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+	}
+
+	d, err := orc.DescribeJob(args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	if describeFormat == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	return writeJobDescription(cmd.OutOrStdout(), d)
+}
+
+// writeJobDescription prints a job description as sections of fields and
+// tables, leaving out what the orchestrator didn't fill in
+func writeJobDescription(out io.Writer, d *orchestrator.JobDescription) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", name, value)
+		}
+	}
+	field("Name", d.Name)
+	field("Namespace", d.Namespace)
+	field("Status", d.Status)
+	field("Reason", d.Reason)
+	field("Created", d.CreationTime)
+	field("Completed", d.CompletionTime)
+	field("Queue", d.Queue)
+	field("Priority", d.Priority)
+	restarts := fmt.Sprint(d.Restarts)
+	if d.MaxRestarts > 0 {
+		restarts = fmt.Sprintf("%d/%d", d.Restarts, d.MaxRestarts)
+	}
+	field("Restarts", restarts)
+	field("Nodes", d.Nodes)
+
+	if len(d.ReplicatedJobs) > 0 {
+		fmt.Fprintf(w, "\nREPLICATED JOB\tREPLICAS\tPARALLELISM\tPODS\tWAITING\tRESTARTS\tNODE POOLS\tIMAGE\n")
+		for _, rj := range d.ReplicatedJobs {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%d\t%s\t%s\n", rj.Name, rj.Replicas, rj.Parallelism, formatCounts(rj.Pods),
+				formatCounts(rj.WaitingReasons), rj.ContainerRestarts, strings.Join(rj.NodePools, ","), rj.Image)
+		}
+	}
+
+	if a := d.Admission; a != nil {
+		fmt.Fprintf(w, "\nKueue workload:\t%s\n", a.Workload)
+		field("Admission", a.Status)
+		field("ClusterQueue", a.ClusterQueue)
+		field("Flavors", formatFlavors(a.Flavors))
+		if len(a.Conditions) > 0 {
+			fmt.Fprintf(w, "\nCONDITION\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE\n")
+			for _, c := range a.Conditions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.LastTransitionTime, c.Message)
+			}
+		}
+		if len(a.AdmissionChecks) > 0 {
+			fmt.Fprintf(w, "\nADMISSION CHECK\tSTATE\tMESSAGE\n")
+			for _, c := range a.AdmissionChecks {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, c.State, c.Message)
+			}
+		}
+	}
+
+	if len(d.Events) > 0 {
+		fmt.Fprintf(w, "\nTIME\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+		for _, e := range d.Events {
+			reason := e.Reason
+			if e.Count > 1 {
+				reason = fmt.Sprintf("%s (x%d)", e.Reason, e.Count)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time, e.Type, reason, e.Object, e.Message)
+		}
+	}
+	return w.Flush()
+}
+
+// formatCounts renders counts as `key=count` sorted by key
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatFlavors(flavors map[string]string) string {
+	keys := make([]string, 0, len(flavors))
+	for k := range flavors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + flavors[k]
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+)
+
+type describeOrchestrator struct {
+	orchestrator.JobOrchestrator
+	desc *orchestrator.JobDescription
+}
+
+func (d *describeOrchestrator) DescribeJob(name string, opts orchestrator.DescribeOptions) (*orchestrator.JobDescription, error) {
+	return d.desc, nil
+}
+
+var testDescription = &orchestrator.JobDescription{
+	Name:        "train",
+	Namespace:   "default",
+	Status:      "Suspended",
+	Reason:      "couldn't assign flavors to pod set workers",
+	Queue:       "lq",
+	Restarts:    1,
+	MaxRestarts: 3,
+	ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{
+		Name: "workers", Replicas: 2, Parallelism: 4, Image: "trainer:v1",
+		Pods:           map[string]int{"Running": 3, "Pending": 1},
+		WaitingReasons: map[string]int{"ImagePullBackOff": 1},
+		NodePools:      []string{"pool-a"},
+	}},
+	Admission: &orchestrator.AdmissionDescription{
+		Workload:   "jobset-train-1",
+		Status:     "Pending",
+		Flavors:    map[string]string{"workers": "l4"},
+		Conditions: []orchestrator.JobCondition{{Type: "QuotaReserved", Status: "False", Reason: "Pending", Message: "insufficient quota"}},
+	},
+	Events: []orchestrator.JobEvent{{Time: "2026-01-01T00:00:00Z", Type: "Warning", Reason: "BackOff", Object: "pod/train-0", Message: "Back-off pulling image", Count: 4}},
+}
+
+func TestDescribeCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	oldFactory := slurmOrchestratorFactory
+	defer func() { slurmOrchestratorFactory = oldFactory }()
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator {
+		return &describeOrchestrator{desc: testDescription}
+	}
+
+	out, err := executeCommand(JobCmd, "describe", "train", "--orchestrator", "slurm")
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+	for _, want := range []string{
+		"Reason:      couldn't assign flavors to pod set workers\n",
+		"Restarts:    1/3\n",
+		"workers          2          4             Pending=1,Running=3   ImagePullBackOff=1   0          pool-a       trainer:v1\n",
+		"Flavors:",
+		"workers=l4",
+		"QuotaReserved",
+		"BackOff (x4)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	resetSubmitCmdFlags()
+	out, err = executeCommand(JobCmd, "describe", "train", "--orchestrator", "slurm", "--format", "json")
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+	var got orchestrator.JobDescription
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if got.Name != "train" || got.Admission == nil || got.Admission.Flavors["workers"] != "l4" || len(got.Events) != 1 {
+		t.Errorf("unexpected JSON description: %+v", got)
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, "describe", "train", "--orchestrator", "slurm", "--format", "yaml"); err == nil || !strings.Contains(err.Error(), `invalid --format "yaml"`) {
+		t.Errorf("expected invalid format error, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bufio"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	envNameRe       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	secretNameRe    = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+	secretKeyRe     = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	secretVersionRe = regexp.MustCompile(`^projects/[^/]+/secrets/[^/]+(/versions/[^/]+)?$`)
+)
+
+// parseEnv builds the workload environment from --env-file, --env and
+// --secret-env. Variables of --env override the ones of env files, which are
+// read in order. Secret variables cannot be redefined.
+func parseEnv(envs []string, envFiles []string, secretEnvs []string) ([]orchestrator.EnvVar, error) {
+	res := []orchestrator.EnvVar{}
+	index := map[string]int{}
+	set := func(e orchestrator.EnvVar) {
+		if i, ok := index[e.Name]; ok {
+			res[i] = e
+			return
+		}
+		index[e.Name] = len(res)
+		res = append(res, e)
+	}
+
+	for _, f := range envFiles {
+		vars, err := readEnvFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range vars {
+			set(e)
+		}
+	}
+	for _, s := range envs {
+		e, err := parseEnvVar(s, "--env")
+		if err != nil {
+			return nil, err
+		}
+		set(e)
+	}
+	for _, s := range secretEnvs {
+		e, err := parseSecretEnvVar(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := index[e.Name]; ok {
+			return nil, fmt.Errorf("environment variable %q is defined more than once, secret variables cannot be redefined", e.Name)
+		}
+		set(e)
+	}
+	return res, nil
+}
+
+func parseEnvVar(s string, flagName string) (orchestrator.EnvVar, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return orchestrator.EnvVar{}, fmt.Errorf("invalid %s %q, expected format NAME=VALUE", flagName, s)
+	}
+	if !envNameRe.MatchString(name) {
+		return orchestrator.EnvVar{}, fmt.Errorf("invalid environment variable name %q, names consist of letters, digits and '_' and don't start with a digit", name)
+	}
+	return orchestrator.EnvVar{Name: name, Value: value}, nil
+}
+
+// parseSecretEnvVar parses NAME=SECRET:KEY, a key of a Kubernetes Secret, or
+// NAME=projects/PROJECT/secrets/SECRET[/versions/VERSION], a Secret Manager secret.
+func parseSecretEnvVar(s string) (orchestrator.EnvVar, error) {
+	e, err := parseEnvVar(s, "--secret-env")
+	if err != nil {
+		return orchestrator.EnvVar{}, err
+	}
+	ref := e.Value
+	e.Value = ""
+	if strings.HasPrefix(ref, "projects/") {
+		if !secretVersionRe.MatchString(ref) {
+			return orchestrator.EnvVar{}, fmt.Errorf("invalid Secret Manager secret %q, expected format projects/PROJECT/secrets/SECRET[/versions/VERSION]", ref)
+		}
+		if !strings.Contains(ref, "/versions/") {
+			ref += "/versions/latest"
+		}
+		e.SecretVersion = ref
+		return e, nil
+	}
+
+	secret, key, ok := strings.Cut(ref, ":")
+	if !ok || !secretNameRe.MatchString(secret) || !secretKeyRe.MatchString(key) {
+		return orchestrator.EnvVar{}, fmt.Errorf("invalid --secret-env %q, expected format NAME=SECRET:KEY or NAME=projects/PROJECT/secrets/SECRET[/versions/VERSION]", s)
+	}
+	e.SecretName, e.SecretKey = secret, key
+	return e, nil
+}
+
+// readEnvFile reads NAME=VALUE lines, blank lines and lines starting with '#'
+// are skipped. Values in matching single or double quotes are unquoted.
+func readEnvFile(path string) ([]orchestrator.EnvVar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	defer f.Close()
+
+	res := []orchestrator.EnvVar{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := parseEnvVar(strings.TrimPrefix(line, "export "), "--env-file")
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if v := e.Value; len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			e.Value = v[1 : len(v)-1]
+		}
+		res = append(res, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file %s: %w", path, err)
+	}
+	return res, nil
+}
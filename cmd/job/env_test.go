@@ -0,0 +1,88 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "app.env")
+	content := "# comment\n\nexport LOG_LEVEL=debug\nGREETING=\"hello world\"\nMODE='train'\n"
+	if err := os.WriteFile(envFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseEnv(
+		[]string{"LOG_LEVEL=info", "EMPTY=", "EQ=a=b"},
+		[]string{envFile},
+		[]string{"HF_TOKEN=hf-secret:token", "API_KEY=projects/p/secrets/api-key", "DB=projects/p/secrets/db/versions/3"})
+	if err != nil {
+		t.Fatalf("parseEnv() failed: %v", err)
+	}
+	want := []orchestrator.EnvVar{
+		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "GREETING", Value: "hello world"},
+		{Name: "MODE", Value: "train"},
+		{Name: "EMPTY", Value: ""},
+		{Name: "EQ", Value: "a=b"},
+		{Name: "HF_TOKEN", SecretName: "hf-secret", SecretKey: "token"},
+		{Name: "API_KEY", SecretVersion: "projects/p/secrets/api-key/versions/latest"},
+		{Name: "DB", SecretVersion: "projects/p/secrets/db/versions/3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEnv() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseEnv_Errors(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "bad.env")
+	if err := os.WriteFile(envFile, []byte("OK=1\nNOT_A_VAR\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		envs       []string
+		envFiles   []string
+		secretEnvs []string
+		wantErr    string
+	}{
+		{"MissingValue", []string{"FOO"}, nil, nil, "expected format NAME=VALUE"},
+		{"InvalidName", []string{"1FOO=bar"}, nil, nil, "invalid environment variable name"},
+		{"DashInName", []string{"MY-VAR=bar"}, nil, nil, "invalid environment variable name"},
+		{"MissingFile", nil, []string{"/does/not/exist.env"}, nil, "failed to read env file"},
+		{"BadFileLine", nil, []string{envFile}, nil, "bad.env:2"},
+		{"SecretWithoutKey", nil, nil, []string{"TOKEN=my-secret"}, "expected format NAME=SECRET:KEY"},
+		{"SecretUppercase", nil, nil, []string{"TOKEN=My-Secret:key"}, "expected format NAME=SECRET:KEY"},
+		{"BadSecretManagerRef", nil, nil, []string{"TOKEN=projects/p/secret/s"}, "invalid Secret Manager secret"},
+		{"SecretRedefinesEnv", []string{"TOKEN=plain"}, nil, []string{"TOKEN=s:k"}, "defined more than once"},
+		{"DuplicateSecret", nil, nil, []string{"TOKEN=s:k", "TOKEN=s:other"}, "defined more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseEnv(tt.envs, tt.envFiles, tt.secretEnvs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseEnv() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -18,20 +18,30 @@ import (
 	"fmt"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/orchestrator/gke"
+	"hpc-toolkit/pkg/orchestrator/slurm"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	clusterName string
-	location    string
-	projectID   string
+	clusterName      string
+	location         string
+	projectID        string
+	orchestratorName string
+	loginNode        string
 )
 
 var gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
 	return gke.NewGKEOrchestrator()
 }
 
+var slurmOrchestratorFactory = func(loginNode string) orchestrator.JobOrchestrator {
+	s := slurm.NewSlurmOrchestrator()
+	s.SetLoginNode(loginNode)
+	return s
+}
+
 var orc orchestrator.JobOrchestrator
 
 // JobCmd represents the base command for job-related operations
@@ -40,9 +50,26 @@ var JobCmd = &cobra.Command{
 	Short: "[EXPERIMENTAL/ALPHA] Manage jobs on the cluster. Alpha version and not yet supported for production use.",
 	Long:  `[EXPERIMENTAL/ALPHA] Manage jobs on the cluster. This is the alpha version of the feature and is under active development. The feature is not yet supported for production use.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		orc = gkeOrchestratorFactory()
-
 		ctx := loadContext()
+		if orchestratorName == "" {
+			orchestratorName = ctx.Orchestrator
+		}
+		if loginNode == "" {
+			loginNode = ctx.LoginNode
+		}
+
+		switch strings.ToLower(orchestratorName) {
+		case "", gkeOrchestrator:
+			orchestratorName = gkeOrchestrator
+			orc = gkeOrchestratorFactory()
+		case slurmOrchestrator:
+			orchestratorName = slurmOrchestrator
+			orc = slurmOrchestratorFactory(loginNode)
+			return resolveSlurmContext(ctx)
+		default:
+			return fmt.Errorf("invalid orchestrator %q; supported orchestrators: %s, %s", orchestratorName, gkeOrchestrator, slurmOrchestrator)
+		}
+
 		if clusterName == "" {
 			clusterName = ctx.ClusterName
 		}
@@ -71,10 +98,40 @@ func init() {
 	JobCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", "Name of the GKE cluster.")
 	JobCmd.PersistentFlags().StringVarP(&location, "location", "l", "", "Location (region or zone) of the GKE cluster.")
 	JobCmd.PersistentFlags().StringVarP(&projectID, "project", "p", "", "Google Cloud Project ID.")
+	JobCmd.PersistentFlags().StringVar(&orchestratorName, "orchestrator", "", "Job orchestrator to use: 'gke' (default) or 'slurm'.")
+	JobCmd.PersistentFlags().StringVar(&loginNode, "login-node", "", "Slurm login node VM to run Slurm commands on. If empty, Slurm commands are run locally.")
 
 	JobCmd.AddCommand(SubmitCmd)
 	JobCmd.AddCommand(CancelJobCmd)
+	JobCmd.AddCommand(ReleaseJobCmd)
+	JobCmd.AddCommand(WorkflowCmd)
 	JobCmd.AddCommand(ListWorkloadsCmd)
+	JobCmd.AddCommand(DescribeJobCmd)
+	JobCmd.AddCommand(WhyJobCmd)
 	JobCmd.AddCommand(LogsCmd)
 	JobCmd.AddCommand(ConfigCmd)
 }
+
+// resolveSlurmContext fills in defaults for Slurm clusters. The cluster name is
+// informational only; project and location are needed to reach the login node.
+func resolveSlurmContext(ctx Context) error {
+	if clusterName == "" {
+		clusterName = ctx.ClusterName
+	}
+	if loginNode == "" {
+		return nil
+	}
+	if location == "" {
+		location = ctx.Location
+	}
+	if projectID == "" {
+		projectID = ctx.ProjectID
+	}
+	if location == "" {
+		return fmt.Errorf("location is required to reach the Slurm login node; please specify the login node zone using the --location flag or set a default value using 'gcluster job config set location <value>'")
+	}
+	if projectID == "" {
+		return fmt.Errorf("project ID is required to reach the Slurm login node; please specify it using the --project flag or set a default value using 'gcluster job config set project <value>'")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var ReleaseJobCmd = &cobra.Command{
+	Use:   "release [job-name]",
+	Short: "Release a job submitted with --hold or --after.",
+	Long: `Releases a held job so that it can start. On GKE, a job submitted with --after
+is released once its dependencies finish, the command waits for them.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runReleaseJob,
+	SilenceUsage: true,
+}
+
+func runReleaseJob(cmd *cobra.Command, args []string) error {
+	jobName := args[0]
+
+	opts := orchestrator.ReleaseOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+	}
+
+	return orc.ReleaseJob(jobName, opts)
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	specFile string
+	specVars map[string]string
+	dumpSpec bool
+
+	// spec loaded by applyJobSpec, used to point errors to the spec file
+	loadedSpec jobSpec
+)
+
+// flags of the submit command that configure how the spec is read and can't be set in it
+var nonSpecFlags = []string{"file", "vars", "dump-spec", "help"}
+
+var specVarRe = regexp.MustCompile(`(\\?)\$\(vars\.([A-Za-z0-9_-]+)\)`)
+
+// -f used to be the shorthand of --platform, values such as linux/arm64 are
+// recognized to point users of the old form to --platform
+var platformRe = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/v[0-9]+)?$`)
+
+// jobSpec is a job spec file given with --file. The keys of the spec are the
+// long names of the submit flags, except for `vars`, which holds values for
+// `$(vars.name)` references in the spec, and `pathways`, which can be a map of
+// the `pathways-*` flags with the prefix removed.
+type jobSpec struct {
+	path string
+	vars map[string]string
+	// positions of the keys of the flags set from the spec
+	pos map[string]config.Pos
+}
+
+// applyJobSpec sets the submit flags from the spec file. Flags set on the
+// command line are left as they are.
+func applyJobSpec(cmd *cobra.Command) error {
+	loadedSpec = jobSpec{}
+	if specFile == "" {
+		if len(specVars) > 0 {
+			return fmt.Errorf("--vars can only be used with --file")
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(specFile); os.IsNotExist(err) && platformRe.MatchString(specFile) {
+		return fmt.Errorf("-f is the shorthand of --file, not of --platform: use --platform %s to set the platform of the image build", specFile)
+	}
+	doc, err := readSpecFile(specFile, "job spec")
+	if err != nil {
+		return err
+	}
+
+	s := jobSpec{path: specFile, vars: map[string]string{}, pos: map[string]config.Pos{}}
+	if doc.Kind != yaml.MappingNode {
+		return s.errAt(doc, fmt.Errorf("job spec must be a map of submit flags"))
+	}
+	if err := s.apply(cmd, doc, specVars); err != nil {
+		return err
+	}
+
+	loadedSpec = s
+	return nil
+}
+
+// readSpecFile reads the YAML document of a job spec or workflow file
+func readSpecFile(path string, kind string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s %s: %w", kind, path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("%s %s is empty", kind, path)
+	}
+	return root.Content[0], nil
+}
+
+// apply sets the submit flags from the map of a spec, overrides are applied
+// on top of the vars of the spec
+func (s jobSpec) apply(cmd *cobra.Command, doc *yaml.Node, overrides map[string]string) error {
+	cliFlags := map[string]bool{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) { cliFlags[f.Name] = f.Changed })
+
+	for i := 0; i < len(doc.Content); i += 2 {
+		if key, val := doc.Content[i], doc.Content[i+1]; key.Value == "vars" {
+			if err := s.readVars(val); err != nil {
+				return err
+			}
+		}
+	}
+	for k, v := range overrides {
+		s.vars[k] = v
+	}
+
+	for i := 0; i < len(doc.Content); i += 2 {
+		key, val := doc.Content[i], doc.Content[i+1]
+		switch {
+		case key.Value == "vars":
+			continue
+		case key.Value == "pathways" && val.Kind == yaml.MappingNode:
+			if err := s.setFlag(cmd, cliFlags, "pathways", key, &yaml.Node{Kind: yaml.ScalarNode, Value: "true", Line: key.Line, Column: key.Column}); err != nil {
+				return err
+			}
+			for j := 0; j < len(val.Content); j += 2 {
+				if err := s.setFlag(cmd, cliFlags, "pathways-"+val.Content[j].Value, val.Content[j], val.Content[j+1]); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := s.setFlag(cmd, cliFlags, key.Value, key, val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s jobSpec) readVars(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return s.errAt(n, fmt.Errorf("vars must be a map"))
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if v := n.Content[i+1]; v.Kind != yaml.ScalarNode {
+			return s.errAt(v, fmt.Errorf("var %q must be a string, number or boolean", n.Content[i].Value))
+		}
+		s.vars[n.Content[i].Value] = n.Content[i+1].Value
+	}
+	return nil
+}
+
+func (s jobSpec) setFlag(cmd *cobra.Command, cliFlags map[string]bool, name string, key *yaml.Node, val *yaml.Node) error {
+	f := cmd.LocalNonPersistentFlags().Lookup(name)
+	if f == nil || slices.Contains(nonSpecFlags, name) {
+		return s.errAt(key, fmt.Errorf("unknown field %q, fields are the long names of the submit flags", name))
+	}
+	if _, ok := s.pos[name]; ok {
+		return s.errAt(key, fmt.Errorf("field %q is set more than once", name))
+	}
+	s.pos[name] = config.Pos{Line: key.Line, Column: key.Column}
+	if cliFlags[name] || val.Tag == "!!null" {
+		return nil // set on the command line or left empty
+	}
+
+	values, err := s.values(val)
+	if err != nil {
+		return err
+	}
+	if _, isSlice := f.Value.(pflag.SliceValue); !isSlice && f.Value.Type() != "stringToString" && len(values) != 1 {
+		return s.errAt(val, fmt.Errorf("field %q expects a single value", name))
+	}
+	for _, v := range values {
+		if err := cmd.Flags().Set(name, v); err != nil {
+			return s.errAt(val, fmt.Errorf("invalid value for %q: %w", name, err))
+		}
+	}
+	return nil
+}
+
+// values returns interpolated values of a scalar, a list of scalars, or a map
+// of scalars given as KEY=VALUE
+func (s jobSpec) values(n *yaml.Node) ([]string, error) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		v, err := s.interpolate(n)
+		return []string{v}, err
+	case yaml.SequenceNode:
+		res := []string{}
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, s.errAt(item, fmt.Errorf("list items must be strings, numbers or booleans"))
+			}
+			v, err := s.interpolate(item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	case yaml.MappingNode:
+		res := []string{}
+		for i := 0; i < len(n.Content); i += 2 {
+			k, item := n.Content[i], n.Content[i+1]
+			if item.Kind != yaml.ScalarNode {
+				return nil, s.errAt(item, fmt.Errorf("map values must be strings, numbers or booleans"))
+			}
+			v, err := s.interpolate(item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, k.Value+"="+v)
+		}
+		return res, nil
+	default:
+		return nil, s.errAt(n, fmt.Errorf("unsupported value"))
+	}
+}
+
+// interpolate substitutes `$(vars.name)` references, `\$(` escapes a reference
+func (s jobSpec) interpolate(n *yaml.Node) (string, error) {
+	var err error
+	res := specVarRe.ReplaceAllStringFunc(n.Value, func(m string) string {
+		sub := specVarRe.FindStringSubmatch(m)
+		if sub[1] != "" {
+			return m[1:]
+		}
+		v, ok := s.vars[sub[2]]
+		if !ok && err == nil {
+			err = s.errAt(n, fmt.Errorf("var %q is not defined, set it in the vars of the spec or with --vars", sub[2]))
+		}
+		return v
+	})
+	return res, err
+}
+
+func (s jobSpec) errAt(n *yaml.Node, err error) error {
+	return fmt.Errorf("%s: %w", s.path, config.PosError{Pos: config.Pos{Line: n.Line, Column: n.Column}, Err: err})
+}
+
+// withSpecPos points an error caused by the given flags to the position of
+// the first of them that is set in the spec file
+func withSpecPos(err error, flags ...string) error {
+	if err == nil || loadedSpec.path == "" {
+		return err
+	}
+	for _, f := range flags {
+		if p, ok := loadedSpec.pos[f]; ok {
+			return fmt.Errorf("%s: %w", loadedSpec.path, config.PosError{Pos: p, Err: err})
+		}
+	}
+	return err
+}
+
+// pathwaysFlagNames returns the names of the --pathways flag and the
+// pathways-* flags
+func pathwaysFlagNames(cmd *cobra.Command) []string {
+	res := []string{}
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if strings.HasPrefix(f.Name, "pathways") {
+			res = append(res, f.Name)
+		}
+	})
+	return res
+}
+
+// dumpJobSpec writes the flags set in the spec file or on the command line as
+// a spec that can be submitted with --file
+func dumpJobSpec(cmd *cobra.Command, w io.Writer) error {
+	fs := cmd.LocalNonPersistentFlags()
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	var pw *yaml.Node
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if err != nil || !f.Changed || slices.Contains(nonSpecFlags, f.Name) {
+			return
+		}
+		val := &yaml.Node{}
+		var v interface{}
+		if v, err = specValue(fs, f); err != nil {
+			return
+		}
+		if err = val.Encode(v); err != nil {
+			return
+		}
+
+		target, key := doc, f.Name
+		if name, ok := strings.CutPrefix(f.Name, "pathways-"); ok && isPathwaysJob {
+			if pw == nil {
+				pw = &yaml.Node{Kind: yaml.MappingNode}
+				doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "pathways"}, pw)
+			}
+			target, key = pw, name
+		} else if f.Name == "pathways" && isPathwaysJob {
+			return // the pathways map implies --pathways
+		}
+		target.Content = append(target.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, val)
+	})
+	if err != nil {
+		return err
+	}
+	if isPathwaysJob && pw == nil {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "pathways"}, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func specValue(fs *pflag.FlagSet, f *pflag.Flag) (interface{}, error) {
+	switch f.Value.Type() {
+	case "bool":
+		return fs.GetBool(f.Name)
+	case "int":
+		return fs.GetInt(f.Name)
+	case "intSlice":
+		return fs.GetIntSlice(f.Name)
+	case "stringToString":
+		return fs.GetStringToString(f.Name)
+	}
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice(), nil
+	}
+	return f.Value.String(), nil
+}
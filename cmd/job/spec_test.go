@@ -0,0 +1,266 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "job.yaml")
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func submitSlurmSpec(t *testing.T, args ...string) (orchestrator.JobDefinition, string, error) {
+	t.Helper()
+	oldFactory := slurmOrchestratorFactory
+	t.Cleanup(func() { slurmOrchestratorFactory = oldFactory })
+
+	var gotJob orchestrator.JobDefinition
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &gotJob}
+	}
+
+	resetSubmitCmdFlags()
+	t.Cleanup(resetSubmitCmdFlags)
+
+	base := []string{"submit", "--orchestrator", "slurm", "--login-node", "hpc-login-0", "--location", "us-central1-a", "--project", "test-project"}
+	out, err := executeCommand(JobCmd, append(base, args...)...)
+	return gotJob, out, err
+}
+
+func TestSubmitCmd_Spec(t *testing.T) {
+	spec := writeSpec(t, `
+vars:
+  dataset: gs://my-bucket/data
+  epochs: 3
+name: from-spec
+command: python train.py --data=$(vars.dataset) --epochs=$(vars.epochs) --literal=\$(vars.x)
+compute-type: compute
+num-nodes: 2
+mount:
+- $(vars.dataset):/data
+restart-on-exit-codes: [42, 137]
+node-constraint:
+  a100: "true"
+env:
+  LOG_LEVEL: debug
+`)
+
+	job, _, err := submitSlurmSpec(t, "-f", spec, "--name", "from-cli", "--vars", "epochs=10")
+	if err != nil {
+		t.Fatalf("command failed with error: %v", err)
+	}
+
+	if job.WorkloadName != "from-cli" {
+		t.Errorf("name = %q, command line flags should override the spec", job.WorkloadName)
+	}
+	if want := "python train.py --data=gs://my-bucket/data --epochs=10 --literal=$(vars.x)"; job.CommandToRun != want {
+		t.Errorf("command = %q, want %q", job.CommandToRun, want)
+	}
+	if job.ComputeType != "compute" || job.NodesPerSlice != 2 {
+		t.Errorf("unexpected job definition: %+v", job)
+	}
+	if !reflect.DeepEqual(job.RawMounts, []string{"gs://my-bucket/data:/data"}) {
+		t.Errorf("mounts = %v", job.RawMounts)
+	}
+	if !reflect.DeepEqual(job.RestartOnExitCodes, []int{42, 137}) {
+		t.Errorf("restart-on-exit-codes = %v", job.RestartOnExitCodes)
+	}
+	if job.NodeConstraint["a100"] != "true" {
+		t.Errorf("node constraint = %v", job.NodeConstraint)
+	}
+	if !reflect.DeepEqual(job.Env, []orchestrator.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}) {
+		t.Errorf("env = %+v", job.Env)
+	}
+}
+
+func TestSubmitCmd_DumpSpec(t *testing.T) {
+	spec := writeSpec(t, `
+name: dump-test
+command: hostname
+compute-type: compute
+restarts: 3
+`)
+
+	job, out, err := submitSlurmSpec(t, "-f", spec, "--env", "A=1", "--dump-spec")
+	if err != nil {
+		t.Fatalf("command failed with error: %v", err)
+	}
+	if job.WorkloadName != "" {
+		t.Errorf("--dump-spec should not submit the job, got %+v", job)
+	}
+	want := `command: hostname
+compute-type: compute
+env:
+  - A=1
+name: dump-test
+restarts: 3
+`
+	if out != want {
+		t.Errorf("dumped spec =\n%s\nwant\n%s", out, want)
+	}
+
+	// the dumped spec can be submitted as it is
+	job, _, err = submitSlurmSpec(t, "-f", writeSpec(t, out))
+	if err != nil {
+		t.Fatalf("submitting dumped spec failed: %v", err)
+	}
+	if job.WorkloadName != "dump-test" || job.MaxRestarts != 3 || len(job.Env) != 1 {
+		t.Errorf("unexpected job definition: %+v", job)
+	}
+}
+
+func TestSubmitCmd_DumpSpecPathways(t *testing.T) {
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator { return &mockOrchestrator{} }
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	spec := writeSpec(t, `
+name: pw-test
+image: busybox
+command: echo hello
+compute-type: v6e-8
+pathways:
+  gcs-location: gs://my-bucket
+  headless: true
+`)
+	out, err := executeCommand(JobCmd, "submit", "-f", spec, "--dump-spec",
+		"--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err != nil {
+		t.Fatalf("command failed with error: %v", err)
+	}
+	if !isPathwaysJob || pathways.GCSLocation != "gs://my-bucket" || !pathways.Headless {
+		t.Errorf("pathways flags not set from the spec: %v %+v", isPathwaysJob, pathways)
+	}
+	if !strings.Contains(out, "pathways:\n  gcs-location: gs://my-bucket\n  headless: true\n") {
+		t.Errorf("dumped spec does not contain the pathways map:\n%s", out)
+	}
+}
+
+func TestSubmitCmd_SpecErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "UnknownField",
+			spec:    "name: x\ncommand: y\nimagee: busybox\n",
+			wantErr: "line 3 column 1: unknown field \"imagee\"",
+		},
+		{
+			name:    "NotAFlag",
+			spec:    "dump-spec: true\n",
+			wantErr: "line 1 column 1: unknown field \"dump-spec\"",
+		},
+		{
+			name:    "UndefinedVar",
+			spec:    "name: x\ncommand: echo $(vars.missing)\n",
+			wantErr: "line 2 column 10: var \"missing\" is not defined",
+		},
+		{
+			name:    "InvalidValue",
+			spec:    "num-nodes: two\n",
+			wantErr: "line 1 column 12: invalid value for \"num-nodes\"",
+		},
+		{
+			name:    "ListForSingleValue",
+			spec:    "command: [a, b]\n",
+			wantErr: "line 1 column 10: field \"command\" expects a single value",
+		},
+		{
+			name:    "Duplicate",
+			spec:    "name: a\nname: b\n",
+			wantErr: "line 2 column 1: field \"name\" is set more than once",
+		},
+		{
+			name:    "NotAMap",
+			spec:    "- name: a\n",
+			wantErr: "job spec must be a map of submit flags",
+		},
+		{
+			name:    "MissingRequired",
+			spec:    "name: a\n",
+			wantErr: `required flag(s) "command", "compute-type" not set`,
+		},
+		{
+			name:    "InvalidDuration",
+			spec:    "name: a\ncommand: b\ncompute-type: c\ngrace-period: soon\n",
+			wantErr: "line 4 column 1: invalid duration format for --grace-period",
+		},
+		{
+			name:    "InvalidEnv",
+			spec:    "name: a\ncommand: b\ncompute-type: c\nenv: [NOT-VALID=1]\n",
+			wantErr: "line 4 column 1: invalid environment variable name",
+		},
+		{
+			name:    "OldPlatformShorthand",
+			args:    []string{"-f", "linux/arm64"},
+			wantErr: "use --platform linux/arm64",
+		},
+		{
+			name:    "VarsWithoutFile",
+			args:    []string{"--vars", "a=b"},
+			wantErr: "--vars can only be used with --file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.spec != "" {
+				args = append([]string{"-f", writeSpec(t, tt.spec)}, args...)
+			}
+			_, _, err := submitSlurmSpec(t, args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubmitCmd_SpecValidationPosition(t *testing.T) {
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	spec := writeSpec(t, `
+name: pw-test
+image: busybox
+command: echo hello
+compute-type: v6e-8
+pathways:
+  headless: true
+`)
+	_, err := executeCommand(JobCmd, "submit", "-f", spec,
+		"--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	want := "job.yaml: line 6 column 1: pathways-gcs-location is required"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %v, want error containing %q", err, want)
+	}
+}
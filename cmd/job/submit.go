@@ -68,6 +68,13 @@ var (
 	volumeStr []string
 	pathways  orchestrator.PathwaysJobDefinition
 
+	envVars    []string
+	envFiles   []string
+	secretEnvs []string
+
+	afterDeps []string
+	holdJob   bool
+
 	gkeNapProvisioning string
 	gkeNapReservation  string
 )
@@ -84,29 +91,18 @@ and JobSet/Kueue specific configurations like workload name, queue, nodes, and r
 	RunE: runSubmitCmd,
 
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if len(workloadName) > 28 {
-			return fmt.Errorf("workload name cannot exceed 28 characters due to Kubernetes/GCE resource name limits. The provided name %q has %d characters", workloadName, len(workloadName))
-		}
-
-		if err := validateImageFlags(); err != nil {
+		if err := applyJobSpec(cmd); err != nil {
 			return err
 		}
 
-		if err := validatePathwaysFlags(); err != nil {
+		if err := validateSubmitFlags(cmd); err != nil {
 			return err
 		}
 
-		if err := ensurePrerequisites(cmd, &projectID, location); err != nil {
-			return err
-		}
-
-		if err := validateGKENAPFlags(); err != nil {
-			return err
+		if orchestratorName == slurmOrchestrator || dumpSpec {
+			return nil
 		}
-
-		priorityClassName = strings.ToLower(priorityClassName)
-
-		return nil
+		return ensurePrerequisites(cmd, &projectID, location)
 	},
 	SilenceUsage: true,
 }
@@ -116,11 +112,21 @@ func init() {
 	SubmitCmd.Flags().StringVarP(&baseImage, "base-image", "B", "", "Name of the base image for Crane to build upon (e.g., python:3.9-slim). Requires --build-context.")
 	SubmitCmd.Flags().StringVarP(&buildContext, "build-context", "b", "", "Path to the build context directory for Crane (e.g., .). Required with --base-image.")
 	SubmitCmd.Flags().StringVarP(&commandToRun, "command", "e", "", "Command to execute in the container (e.g., 'python train.py'). Required.")
-	SubmitCmd.Flags().StringVar(&computeType, "compute-type", "", "Type of compute to request (e.g., 'n2-standard-32', 'nvidia-l4', 'v6e-8').")
+	SubmitCmd.Flags().StringVar(&computeType, "compute-type", "", "Type of compute to request (e.g., 'n2-standard-32', 'nvidia-l4', 'v6e-8'). For Slurm, the partition to submit to.")
 	SubmitCmd.Flags().StringVarP(&dryRunManifest, "dry-run-out", "o", "", "Path to output the generated Kubernetes manifest instead of applying it.")
-	SubmitCmd.Flags().StringVarP(&platform, "platform", "f", "linux/amd64", "Target platform for the image build (e.g., 'linux/amd64', 'linux/arm64'). Used with --base-image.")
+	SubmitCmd.Flags().StringVar(&platform, "platform", "linux/amd64", "Target platform for the image build (e.g., 'linux/amd64', 'linux/arm64'). Used with --base-image. Its former shorthand -f now selects --file.")
+
+	SubmitCmd.Flags().StringVarP(&specFile, "file", "f", "", "Path to a YAML job spec. Its keys are the long names of the submit flags, flags given on the command line override values of the spec. Note that -f used to be the shorthand of --platform.")
+	SubmitCmd.Flags().StringToStringVar(&specVars, "vars", nil, "Values of $(vars.name) references in the job spec (format: name=value). Overrides the vars defined in the spec.")
+	SubmitCmd.Flags().BoolVar(&dumpSpec, "dump-spec", false, "Print the resolved job spec, combining --file and the command line flags, instead of submitting the job.")
 
 	SubmitCmd.Flags().StringSliceVar(&volumeStr, "mount", nil, "Volumes to mount (format: <src>:<dest>[:<mode>], mode can be 'ro' or 'rw', default 'ro').")
+	SubmitCmd.Flags().StringArrayVar(&envVars, "env", nil, "Environment variable of the workload (format: NAME=VALUE). Can be repeated, overrides variables of --env-file.")
+	SubmitCmd.Flags().StringArrayVar(&envFiles, "env-file", nil, "File with environment variables of the workload, one NAME=VALUE per line. Can be repeated.")
+	SubmitCmd.Flags().StringArrayVar(&secretEnvs, "secret-env", nil, "Environment variable set from a secret (format: NAME=SECRET:KEY for a Kubernetes Secret, or NAME=projects/PROJECT/secrets/SECRET[/versions/VERSION] for Secret Manager). Can be repeated.")
+
+	SubmitCmd.Flags().StringArrayVar(&afterDeps, "after", nil, "Job that has to finish before this job starts (format: NAME[:succeeded|:any], default succeeded). Can be repeated. On GKE, the job stays held until 'gcluster job release' waits for the dependencies and releases it.")
+	SubmitCmd.Flags().BoolVar(&holdJob, "hold", false, "Submit the job held; it does not start until it is released with 'gcluster job release'.")
 
 	SubmitCmd.Flags().StringVarP(&workloadName, "name", "n", "", "Name of the workload to create. Required.")
 	SubmitCmd.Flags().StringVarP(&kueueQueueName, "queue", "q", "", "Name of the Kueue LocalQueue to submit the workload to. If empty, it will be auto-discovered.")
@@ -140,8 +146,8 @@ func init() {
 	SubmitCmd.Flags().StringVar(&topology, "topology", "", "TPU slice topology (e.g., 2x2x1).")
 	SubmitCmd.Flags().StringVar(&gkeScheduler, "gke-scheduler", "", "Kubernetes Scheduler name (e.g., gke.io/topology-aware-auto).")
 	SubmitCmd.Flags().BoolVar(&awaitJobCompletion, "await-job-completion", false, "If true, gcluster will wait for the submitted job to complete.")
-	SubmitCmd.Flags().StringVar(&timeoutStr, "timeout", "-1s", "Time to wait for job in seconds or string format (e.g. 1h, 10m). Default is max timeout (-1s).")
-	SubmitCmd.Flags().StringVar(&priorityClassName, "priority", "", "A priority class name (e.g., low, medium, high, or any custom PriorityClass defined in the cluster). If empty, the cluster's default priority class will be used.")
+	SubmitCmd.Flags().StringVar(&timeoutStr, "timeout", "-1s", "Time to wait for job in seconds or string format (e.g. 1h, 10m). Default is max timeout (-1s). For Slurm, also the job time limit.")
+	SubmitCmd.Flags().StringVar(&priorityClassName, "priority", "", "A priority class name (e.g., low, medium, high, or any custom PriorityClass defined in the cluster). If empty, the cluster's default priority class will be used. For Slurm, the QOS to submit with.")
 	SubmitCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging for the workload (TPUs and GPUs).")
 	SubmitCmd.Flags().StringVar(&gkeNapProvisioning, "gke-nap-provisioning", "", "Compute provisioning model for GKE NAP. Allowed values: on-demand, spot, reservation.")
 	SubmitCmd.Flags().StringVar(&gkeNapReservation, "gke-nap-reservation", "", "Name of the Google Cloud Reservation for GKE NAP (required if --gke-nap-provisioning=reservation).")
@@ -159,27 +165,82 @@ func init() {
 	SubmitCmd.Flags().StringVar(&pathways.WorkerArgs, "pathways-worker-args", "", "Arbitrary additional command-line arguments to pass directly to the `pathways-worker` executable.")
 	SubmitCmd.Flags().StringVar(&pathways.ColocatedPythonSidecarImage, "pathways-colocated-python-sidecar-image", "", "Image for an optional Python-based sidecar container to run alongside the Pathways head components.")
 	SubmitCmd.Flags().StringVar(&pathways.HeadNodePool, "pathways-head-np", "", "The node pool to use for the Pathways head job. If empty, it will be auto-detected (looking for 'cpu-np' or 'pathways-np').")
+}
 
-	_ = SubmitCmd.MarkFlagRequired("command")
-	_ = SubmitCmd.MarkFlagRequired("name")
-	_ = SubmitCmd.MarkFlagRequired("compute-type")
+// checkRequiredSubmitFlags replaces cobra's required flags, which are checked
+// before the flags are set from the job spec
+func checkRequiredSubmitFlags(cmd *cobra.Command) error {
+	missing := []string{}
+	for _, name := range []string{"command", "name", "compute-type"} {
+		if !cmd.Flags().Changed(name) {
+			missing = append(missing, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required flag(s) %s not set, set them on the command line or in the job spec (--file)", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateSubmitFlags checks the submit flags that don't depend on the
+// orchestrator being reachable
+func validateSubmitFlags(cmd *cobra.Command) error {
+	if err := checkRequiredSubmitFlags(cmd); err != nil {
+		return err
+	}
+
+	if len(workloadName) > 28 {
+		return withSpecPos(fmt.Errorf("workload name cannot exceed 28 characters due to Kubernetes/GCE resource name limits. The provided name %q has %d characters", workloadName, len(workloadName)), "name")
+	}
+
+	priorityClassName = strings.ToLower(priorityClassName)
+
+	// Image builds, Pathways and NAP are GKE features; the Slurm
+	// orchestrator rejects unsupported job definitions itself.
+	if orchestratorName == slurmOrchestrator {
+		return nil
+	}
+
+	if err := validateImageFlags(); err != nil {
+		return withSpecPos(err, "image", "base-image", "build-context")
+	}
+
+	if err := validatePathwaysFlags(); err != nil {
+		return withSpecPos(err, pathwaysFlagNames(cmd)...)
+	}
+
+	return withSpecPos(validateGKENAPFlags(), "gke-nap-provisioning", "gke-nap-reservation")
 }
 
 func runSubmitCmd(cmd *cobra.Command, args []string) error {
-	if dryRunManifest != "" {
+	if dryRunManifest != "" && !dumpSpec {
 		if err := ensureDryRunDir(dryRunManifest); err != nil {
 			return err
 		}
 	}
 
-	ttlSeconds, err := parseDurationToSeconds(ttlAfterFinished, "--gke-ttl-after-finished")
+	jobDef, err := buildJobDefinition(cmd)
 	if err != nil {
 		return err
 	}
 
+	if dumpSpec {
+		return dumpJobSpec(cmd, cmd.OutOrStdout())
+	}
+
+	return orc.SubmitJob(jobDef)
+}
+
+// buildJobDefinition builds the job from the submit flags
+func buildJobDefinition(cmd *cobra.Command) (orchestrator.JobDefinition, error) {
+	ttlSeconds, err := parseDurationToSeconds(ttlAfterFinished, "--gke-ttl-after-finished")
+	if err != nil {
+		return orchestrator.JobDefinition{}, withSpecPos(err, "gke-ttl-after-finished")
+	}
+
 	gracePeriodSeconds, err := parseDurationToSeconds(gracePeriodStr, "--grace-period")
 	if err != nil {
-		return err
+		return orchestrator.JobDefinition{}, withSpecPos(err, "grace-period")
 	}
 
 	affinity := map[string]string{}
@@ -191,11 +252,21 @@ func runSubmitCmd(cmd *cobra.Command, args []string) error {
 		awaitJobCompletion = true
 	}
 
+	env, err := parseEnv(envVars, envFiles, secretEnvs)
+	if err != nil {
+		return orchestrator.JobDefinition{}, withSpecPos(err, "env", "env-file", "secret-env")
+	}
+
+	after, err := parseAfter(afterDeps)
+	if err != nil {
+		return orchestrator.JobDefinition{}, withSpecPos(err, "after")
+	}
+
 	if config.IsTPU(computeType) && cmd.Flags().Changed("num-nodes") {
-		return fmt.Errorf("--num-nodes cannot be used with TPU jobs (it is calculated automatically from topology)")
+		return orchestrator.JobDefinition{}, withSpecPos(fmt.Errorf("--num-nodes cannot be used with TPU jobs (it is calculated automatically from topology)"), "num-nodes")
 	}
 
-	jobDef := orchestrator.JobDefinition{
+	return orchestrator.JobDefinition{
 		ImageName:                     imageName,
 		BaseImage:                     baseImage,
 		BuildContext:                  buildContext,
@@ -230,10 +301,11 @@ func runSubmitCmd(cmd *cobra.Command, args []string) error {
 		IsPathwaysJob:                 isPathwaysJob,
 		Pathways:                      pathways,
 		RawMounts:                     volumeStr,
+		Env:                           env,
+		After:                         after,
+		Hold:                          holdJob,
 		Verbose:                       verbose,
-	}
-
-	return orc.SubmitJob(jobDef)
+	}, nil
 }
 
 func parseDurationToSeconds(dStr string, flagName string) (int, error) {
@@ -250,6 +322,25 @@ func parseDurationToSeconds(dStr string, flagName string) (int, error) {
 	return 0, fmt.Errorf("invalid duration format for %s: %s. Expected formats: 1h, 30m, 3600", flagName, dStr)
 }
 
+// parseAfter parses dependencies given as NAME[:succeeded|:any]
+func parseAfter(deps []string) ([]orchestrator.JobDependency, error) {
+	var res []orchestrator.JobDependency
+	for _, d := range deps {
+		name, cond, _ := strings.Cut(d, ":")
+		if cond == "" {
+			cond = orchestrator.AfterSucceeded
+		}
+		if name == "" || (cond != orchestrator.AfterSucceeded && cond != orchestrator.AfterAny) {
+			return nil, fmt.Errorf("invalid dependency %q, expected NAME[:%s|:%s]", d, orchestrator.AfterSucceeded, orchestrator.AfterAny)
+		}
+		if name == workloadName {
+			return nil, fmt.Errorf("job %s cannot depend on itself", name)
+		}
+		res = append(res, orchestrator.JobDependency{Name: name, Condition: cond})
+	}
+	return res, nil
+}
+
 func validatePathwaysFlags() error {
 	if isPathwaysJob {
 		if pathways.GCSLocation == "" {
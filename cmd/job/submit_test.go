@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func executeCommand(root *cobra.Command, args ...string) (string, error) {
@@ -180,6 +181,144 @@ func TestSubmitCmd_RegularDryRun(t *testing.T) {
 	}
 }
 
+func TestSubmitCmd_SlurmOrchestrator(t *testing.T) {
+	oldFactory := slurmOrchestratorFactory
+	defer func() { slurmOrchestratorFactory = oldFactory }()
+
+	var gotLoginNode string
+	var gotJob orchestrator.JobDefinition
+	slurmOrchestratorFactory = func(node string) orchestrator.JobOrchestrator {
+		gotLoginNode = node
+		return &recordingOrchestrator{job: &gotJob}
+	}
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	// No image, no cluster name and no prerequisite checks are needed for Slurm.
+	_, err := executeCommand(JobCmd,
+		"submit",
+		"--orchestrator", "slurm",
+		"--login-node", "hpc-login-0",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--name", "slurm-test",
+		"--command", "hostname",
+		"--compute-type", "compute",
+		"--num-nodes", "2",
+		"--node-constraint", "a100=true",
+	)
+	if err != nil {
+		t.Fatalf("command failed with error: %v", err)
+	}
+
+	if gotLoginNode != "hpc-login-0" {
+		t.Errorf("login node = %q, want %q", gotLoginNode, "hpc-login-0")
+	}
+	if gotJob.WorkloadName != "slurm-test" || gotJob.ComputeType != "compute" || gotJob.NodesPerSlice != 2 {
+		t.Errorf("unexpected job definition: %+v", gotJob)
+	}
+	if gotJob.NodeConstraint["a100"] != "true" {
+		t.Errorf("node constraint not passed through: %v", gotJob.NodeConstraint)
+	}
+}
+
+func TestSubmitCmd_After(t *testing.T) {
+	oldFactory := slurmOrchestratorFactory
+	defer func() { slurmOrchestratorFactory = oldFactory }()
+
+	var gotJob orchestrator.JobDefinition
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &gotJob}
+	}
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	args := []string{"submit", "--orchestrator", "slurm", "--name", "train", "--command", "true", "--compute-type", "compute"}
+	_, err := executeCommand(JobCmd, append(args, "--after", "prep", "--after", "fetch:any", "--hold")...)
+	if err != nil {
+		t.Fatalf("command failed with error: %v", err)
+	}
+
+	want := []orchestrator.JobDependency{{Name: "prep", Condition: orchestrator.AfterSucceeded}, {Name: "fetch", Condition: orchestrator.AfterAny}}
+	if len(gotJob.After) != 2 || gotJob.After[0] != want[0] || gotJob.After[1] != want[1] || !gotJob.Hold {
+		t.Errorf("after = %+v, hold = %v, want %+v and hold", gotJob.After, gotJob.Hold, want)
+	}
+
+	for dep, wantErr := range map[string]string{
+		"prep:done": `invalid dependency "prep:done"`,
+		":any":      `invalid dependency ":any"`,
+		"train":     "job train cannot depend on itself",
+	} {
+		resetSubmitCmdFlags()
+		_, err := executeCommand(JobCmd, append(args, "--after", dep)...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("--after %s: expected error containing %q, got %v", dep, wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_Env(t *testing.T) {
+	oldFactory := slurmOrchestratorFactory
+	defer func() { slurmOrchestratorFactory = oldFactory }()
+
+	var gotJob orchestrator.JobDefinition
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &gotJob}
+	}
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	_, err := executeCommand(JobCmd,
+		"submit",
+		"--orchestrator", "slurm",
+		"--login-node", "hpc-login-0",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--name", "slurm-env",
+		"--command", "env",
+		"--compute-type", "compute",
+		"--env", "A=1",
+		"--env", "B=x,y",
+	)
+	if err != nil {
+		t.Fatalf("command failed with error: %v", err)
+	}
+
+	want := []orchestrator.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "x,y"}}
+	if len(gotJob.Env) != 2 || gotJob.Env[0] != want[0] || gotJob.Env[1] != want[1] {
+		t.Errorf("env = %+v, want %+v", gotJob.Env, want)
+	}
+
+	resetSubmitCmdFlags()
+	_, err = executeCommand(JobCmd,
+		"submit",
+		"--orchestrator", "slurm",
+		"--login-node", "hpc-login-0",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--name", "slurm-env",
+		"--command", "env",
+		"--compute-type", "compute",
+		"--env", "NOT-VALID=1",
+	)
+	if err == nil || !strings.Contains(err.Error(), "invalid environment variable name") {
+		t.Errorf("expected invalid name error, got %v", err)
+	}
+}
+
+func TestJobCmd_InvalidOrchestrator(t *testing.T) {
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	_, err := executeCommand(JobCmd, "list", "--orchestrator", "lsf")
+	if err == nil || !strings.Contains(err.Error(), "invalid orchestrator") {
+		t.Fatalf("expected invalid orchestrator error, got %v", err)
+	}
+}
+
 func TestSubmitCmd_TPUWithNumNodes_Fails(t *testing.T) {
 	resetSubmitCmdFlags()
 
@@ -270,7 +409,7 @@ func resetSubmitCmdFlags() {
 	ttlAfterFinished = "1h"
 	gracePeriodStr = "30s"
 	placementPolicy = ""
-	nodeConstraint = nil
+	nodeConstraint = map[string]string{}
 	cpuAffinityStr = ""
 	restartOnExitCodes = nil
 	imagePullSecrets = ""
@@ -284,6 +423,22 @@ func resetSubmitCmdFlags() {
 	pathways = orchestrator.PathwaysJobDefinition{MaxSliceRestarts: 1}
 	gkeNapProvisioning = ""
 	gkeNapReservation = ""
+	orchestratorName = ""
+	loginNode = ""
+	envVars = nil
+	envFiles = nil
+	secretEnvs = nil
+	specFile = ""
+	specVars = map[string]string{}
+	dumpSpec = false
+	afterDeps = nil
+	holdJob = false
+	workflowVars = map[string]string{}
+	restartWorkflow = false
+	describeFormat = "text"
+	whyFormat = "text"
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	WorkflowRunCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
 }
 
 type mockOrchestrator struct {
@@ -303,6 +458,16 @@ func (m *mockOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
 	return nil
 }
 
+type recordingOrchestrator struct {
+	orchestrator.JobOrchestrator
+	job *orchestrator.JobDefinition
+}
+
+func (r *recordingOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
+	*r.job = job
+	return nil
+}
+
 type MockPrereqStore struct {
 	State PrereqState
 }
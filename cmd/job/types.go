@@ -17,10 +17,14 @@ package job
 import "time"
 
 const (
-	contextFileName = "context.json"
-	stateDirName    = ".gcluster"
-	stateFileName   = "job_prereq_state.json"
-	stateFreshness  = 24 * time.Hour // State is considered fresh for 24 hours
+	contextFileName  = "context.json"
+	stateDirName     = ".gcluster"
+	stateFileName    = "job_prereq_state.json"
+	workflowsDirName = "workflows"
+	stateFreshness   = 24 * time.Hour // State is considered fresh for 24 hours
+
+	gkeOrchestrator   = "gke"
+	slurmOrchestrator = "slurm"
 )
 
 type missingPrereq struct {
@@ -30,9 +34,11 @@ type missingPrereq struct {
 
 // Context holds the active CLI context.
 type Context struct {
-	ProjectID   string `json:"project_id"`
-	ClusterName string `json:"cluster_name"`
-	Location    string `json:"location"`
+	ProjectID    string `json:"project_id"`
+	ClusterName  string `json:"cluster_name"`
+	Location     string `json:"location"`
+	Orchestrator string `json:"orchestrator,omitempty"`
+	LoginNode    string `json:"login_node,omitempty"`
 }
 
 // PrereqState holds the current state of prerequisite checks.
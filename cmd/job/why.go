@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+var whyFormat string
+
+var WhyJobCmd = &cobra.Command{
+	Use:   "why [job-name]",
+	Short: "Explain why a job is pending and how to get it scheduled.",
+	Long: `Explain why a job is pending and how to get it scheduled.
+
+On GKE the Kueue workload, the pods and their events are checked against the
+ClusterQueue, the node pools and the Node Auto-Provisioning limits of the
+cluster. On Slurm the pending reason of the job is explained.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if whyFormat != "text" && whyFormat != "json" {
+			return fmt.Errorf("invalid --format %q, expected one of (\"text\", \"json\")", whyFormat)
+		}
+		return nil
+	},
+	RunE:         runWhyJob,
+	SilenceUsage: true,
+}
+
+func init() {
+	WhyJobCmd.Flags().StringVar(&whyFormat, "format", "text", "Output format, one of (\"text\", \"json\")")
+}
+
+func runWhyJob(cmd *cobra.Command, args []string) error {
+	opts := orchestrator.DescribeOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+	}
+
+	diag, err := orc.DiagnoseJob(args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	if whyFormat == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(diag)
+	}
+	writeJobDiagnosis(cmd.OutOrStdout(), diag)
+	return nil
+}
+
+// writeJobDiagnosis prints the blockers of a job with their fixes
+func writeJobDiagnosis(out io.Writer, diag *orchestrator.JobDiagnosis) {
+	if len(diag.Blockers) == 0 {
+		switch diag.Status {
+		case "Pending", "Suspended", "Held", "QuotaReserved", "Evicted":
+			fmt.Fprintf(out, "Job %s is %s, but no reason was found. Inspect it with `gcluster job describe %s`.\n", diag.Name, diag.Status, diag.Name)
+		default:
+			fmt.Fprintf(out, "Job %s is %s, it isn't waiting to be scheduled.\n", diag.Name, diag.Status)
+		}
+		return
+	}
+
+	fmt.Fprintf(out, "Job %s is %s:\n", diag.Name, diag.Status)
+	for i, b := range diag.Blockers {
+		fmt.Fprintf(out, "\n%d. [%s] %s\n", i+1, b.Kind, b.Message)
+		for _, f := range b.Fixes {
+			fmt.Fprintf(out, "   - %s\n", f)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+)
+
+type diagnoseOrchestrator struct {
+	orchestrator.JobOrchestrator
+	diag *orchestrator.JobDiagnosis
+}
+
+func (d *diagnoseOrchestrator) DiagnoseJob(name string, opts orchestrator.DescribeOptions) (*orchestrator.JobDiagnosis, error) {
+	return d.diag, nil
+}
+
+func TestWhyCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	oldFactory := slurmOrchestratorFactory
+	defer func() { slurmOrchestratorFactory = oldFactory }()
+	fake := &diagnoseOrchestrator{diag: &orchestrator.JobDiagnosis{
+		Name:   "train",
+		Status: "Pending",
+		Blockers: []orchestrator.JobBlocker{{
+			Kind:    orchestrator.BlockerQuota,
+			Message: "The job would exceed a limit of QOS \"normal\".",
+			Fixes:   []string{"Submit with another QOS using `--priority`."},
+		}},
+	}}
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator { return fake }
+
+	out, err := executeCommand(JobCmd, "why", "train", "--orchestrator", "slurm")
+	if err != nil {
+		t.Fatalf("why failed: %v", err)
+	}
+	want := "Job train is Pending:\n\n1. [quota] The job would exceed a limit of QOS \"normal\".\n   - Submit with another QOS using `--priority`.\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("output = %q, want it to contain %q", out, want)
+	}
+
+	resetSubmitCmdFlags()
+	out, err = executeCommand(JobCmd, "why", "train", "--orchestrator", "slurm", "--format", "json")
+	if err != nil {
+		t.Fatalf("why failed: %v", err)
+	}
+	var got orchestrator.JobDiagnosis
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(got.Blockers) != 1 || got.Blockers[0].Kind != orchestrator.BlockerQuota {
+		t.Errorf("unexpected JSON diagnosis: %+v", got)
+	}
+
+	resetSubmitCmdFlags()
+	fake.diag = &orchestrator.JobDiagnosis{Name: "train", Status: "Running"}
+	if out, err = executeCommand(JobCmd, "why", "train", "--orchestrator", "slurm"); err != nil {
+		t.Fatalf("why failed: %v", err)
+	}
+	if !strings.Contains(out, "Job train is Running, it isn't waiting to be scheduled.") {
+		t.Errorf("unexpected output for a running job: %q", out)
+	}
+}
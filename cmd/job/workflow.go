@@ -0,0 +1,486 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	workflowVars    map[string]string
+	restartWorkflow bool
+
+	workflowPollInterval = 30 * time.Second
+)
+
+const workflowSkipped = "Skipped"
+
+// submit flags that control how a single job is submitted, the workflow
+// decides on them for its jobs
+var nonWorkflowFlags = []string{"dry-run-out", "hold", "await-job-completion"}
+
+var workflowNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+var WorkflowCmd = &cobra.Command{
+	Use:   "workflow",
+	Short: "Run workflows of jobs that depend on each other.",
+}
+
+var WorkflowRunCmd = &cobra.Command{
+	Use:   "run [workflow-file]",
+	Short: "Submit the jobs of a workflow and track them until they finish.",
+	Long: `The 'run' command submits the jobs of a workflow file. Jobs that depend on
+other jobs (their 'after' field) are submitted held and released once their
+dependencies finish; when a dependency does not finish as required, the jobs
+depending on it are canceled and skipped.
+
+The progress of the workflow is saved under ~/.gcluster/workflows. Running a
+workflow again resumes it: jobs that succeeded are not submitted again, jobs
+still queued or running are tracked, and failed or skipped jobs are submitted
+again. Use --restart to run all jobs again.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runWorkflowCmd,
+	SilenceUsage: true,
+}
+
+func init() {
+	WorkflowRunCmd.Flags().StringToStringVar(&workflowVars, "vars", nil, "Values of $(vars.name) references in the workflow (format: name=value). Overrides the vars defined in the workflow.")
+	WorkflowRunCmd.Flags().BoolVar(&restartWorkflow, "restart", false, "Discard the saved progress of the workflow and submit all of its jobs again.")
+
+	WorkflowCmd.AddCommand(WorkflowRunCmd)
+}
+
+// workflowState is the progress of a workflow, saved so that the workflow
+// can be resumed
+type workflowState struct {
+	Jobs map[string]*workflowJobState `json:"jobs"`
+}
+
+type workflowJobState struct {
+	Status string `json:"status"`
+	// Held is set while the job waits for its dependencies
+	Held bool `json:"held,omitempty"`
+}
+
+func runWorkflowCmd(cmd *cobra.Command, args []string) error {
+	name, jobs, err := loadWorkflow(args[0])
+	if err != nil {
+		return err
+	}
+
+	if orchestratorName != slurmOrchestrator {
+		if err := ensurePrerequisites(cmd, &projectID, location); err != nil {
+			return err
+		}
+	}
+
+	path, err := workflowStatePath(name)
+	if err != nil {
+		return err
+	}
+	state, err := loadWorkflowState(path)
+	if err != nil {
+		return err
+	}
+	save := func(s *workflowState) error { return saveWorkflowState(path, s) }
+	return runWorkflow(name, jobs, state, save)
+}
+
+// loadWorkflow reads a workflow file and returns its name and its jobs, ordered
+// so that every job comes after its dependencies. The workflow file holds the
+// `jobs` as job specs, `defaults` for fields the jobs don't set, `vars` for
+// all jobs and an optional `name`, which defaults to the file name.
+func loadWorkflow(path string) (string, []orchestrator.JobDefinition, error) {
+	doc, err := readSpecFile(path, "workflow")
+	if err != nil {
+		return "", nil, err
+	}
+
+	s := jobSpec{path: path, vars: map[string]string{}}
+	if doc.Kind != yaml.MappingNode {
+		return "", nil, s.errAt(doc, fmt.Errorf("workflow must be a map with the jobs of the workflow"))
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	defaults := &yaml.Node{Kind: yaml.MappingNode}
+	var jobsNode *yaml.Node
+	for i := 0; i < len(doc.Content); i += 2 {
+		key, val := doc.Content[i], doc.Content[i+1]
+		switch key.Value {
+		case "name":
+			if val.Kind != yaml.ScalarNode || !workflowNameRe.MatchString(val.Value) {
+				return "", nil, s.errAt(val, fmt.Errorf("workflow name must consist of letters, digits, '_', '.' and '-'"))
+			}
+			name = val.Value
+		case "vars":
+			if err := s.readVars(val); err != nil {
+				return "", nil, err
+			}
+		case "defaults":
+			if val.Kind != yaml.MappingNode {
+				return "", nil, s.errAt(val, fmt.Errorf("defaults must be a map of submit flags"))
+			}
+			defaults = val
+		case "jobs":
+			if val.Kind != yaml.SequenceNode {
+				return "", nil, s.errAt(val, fmt.Errorf("jobs must be a list of job specs"))
+			}
+			jobsNode = val
+		default:
+			return "", nil, s.errAt(key, fmt.Errorf("unknown field %q, fields are name, vars, defaults and jobs", key.Value))
+		}
+	}
+	if jobsNode == nil || len(jobsNode.Content) == 0 {
+		return "", nil, s.errAt(doc, fmt.Errorf("workflow has no jobs"))
+	}
+	if !workflowNameRe.MatchString(name) {
+		return "", nil, fmt.Errorf("invalid workflow name %q derived from the file name, set a name in the workflow", name)
+	}
+
+	jobs := []orchestrator.JobDefinition{}
+	for _, n := range jobsNode.Content {
+		if n.Kind != yaml.MappingNode {
+			return "", nil, s.errAt(n, fmt.Errorf("jobs must be maps of submit flags"))
+		}
+		job, err := loadWorkflowJob(s, mergeSpecs(defaults, n))
+		if err != nil {
+			return "", nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	ordered, err := orderWorkflowJobs(jobs)
+	return name, ordered, err
+}
+
+// loadWorkflowJob builds a job of a workflow through the submit flags, the
+// same way submit does for a job spec
+func loadWorkflowJob(wf jobSpec, doc *yaml.Node) (orchestrator.JobDefinition, error) {
+	if err := resetSubmitFlags(SubmitCmd.LocalNonPersistentFlags()); err != nil {
+		return orchestrator.JobDefinition{}, err
+	}
+
+	s := jobSpec{path: wf.path, vars: maps.Clone(wf.vars), pos: map[string]config.Pos{}}
+	if err := s.apply(SubmitCmd, doc, workflowVars); err != nil {
+		return orchestrator.JobDefinition{}, err
+	}
+	loadedSpec = s
+	defer func() { loadedSpec = jobSpec{} }()
+
+	for _, f := range nonWorkflowFlags {
+		if SubmitCmd.Flags().Changed(f) {
+			return orchestrator.JobDefinition{}, withSpecPos(fmt.Errorf("%q can't be set for jobs of a workflow", f), f)
+		}
+	}
+	if err := validateSubmitFlags(SubmitCmd); err != nil {
+		return orchestrator.JobDefinition{}, err
+	}
+
+	job, err := buildJobDefinition(SubmitCmd)
+	job.AwaitJobCompletion = false
+	return job, err
+}
+
+// resetSubmitFlags sets the submit flags back to their defaults
+func resetSubmitFlags(fs *pflag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			err = errors.Join(err, sv.Replace([]string{}))
+		} else if f.Value.Type() != "stringToString" {
+			err = errors.Join(err, f.Value.Set(f.DefValue))
+		}
+		f.Changed = false
+	})
+	// map flags add to the map they set before, so start with new maps
+	nodeConstraint = map[string]string{}
+	specVars = map[string]string{}
+	return err
+}
+
+// mergeSpecs returns the fields of a job spec together with the defaults for
+// the fields it doesn't set
+func mergeSpecs(defaults *yaml.Node, job *yaml.Node) *yaml.Node {
+	set := map[string]bool{}
+	for i := 0; i < len(job.Content); i += 2 {
+		set[job.Content[i].Value] = true
+	}
+	res := &yaml.Node{Kind: yaml.MappingNode, Line: job.Line, Column: job.Column}
+	for i := 0; i < len(defaults.Content); i += 2 {
+		if !set[defaults.Content[i].Value] {
+			res.Content = append(res.Content, defaults.Content[i], defaults.Content[i+1])
+		}
+	}
+	res.Content = append(res.Content, job.Content...)
+	return res
+}
+
+// orderWorkflowJobs sorts the jobs so that every job comes after its
+// dependencies, keeping the order of the workflow file otherwise
+func orderWorkflowJobs(jobs []orchestrator.JobDefinition) ([]orchestrator.JobDefinition, error) {
+	names := map[string]bool{}
+	for _, j := range jobs {
+		if names[j.WorkloadName] {
+			return nil, fmt.Errorf("job %q is defined more than once in the workflow", j.WorkloadName)
+		}
+		names[j.WorkloadName] = true
+	}
+	for _, j := range jobs {
+		for _, d := range j.After {
+			if !names[d.Name] {
+				return nil, fmt.Errorf("job %q depends on %q, which is not a job of the workflow", j.WorkloadName, d.Name)
+			}
+		}
+	}
+
+	res := []orchestrator.JobDefinition{}
+	done := map[string]bool{}
+	for len(res) < len(jobs) {
+		added := false
+		for _, j := range jobs {
+			if done[j.WorkloadName] || !dependenciesDone(j, done) {
+				continue
+			}
+			res = append(res, j)
+			done[j.WorkloadName] = true
+			added = true
+		}
+		if !added {
+			left := []string{}
+			for _, j := range jobs {
+				if !done[j.WorkloadName] {
+					left = append(left, j.WorkloadName)
+				}
+			}
+			return nil, fmt.Errorf("the dependencies of jobs %s form a cycle", strings.Join(left, ", "))
+		}
+	}
+	return res, nil
+}
+
+func dependenciesDone(job orchestrator.JobDefinition, done map[string]bool) bool {
+	for _, d := range job.After {
+		if !done[d.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// runWorkflow submits the jobs that aren't submitted yet and tracks the jobs
+// until all of them finished or were skipped
+func runWorkflow(name string, jobs []orchestrator.JobDefinition, state *workflowState, save func(*workflowState) error) error {
+	cancelOpts := orchestrator.CancelOptions{ProjectID: projectID, ClusterName: clusterName, ClusterLocation: location}
+
+	for _, job := range jobs {
+		js := state.Jobs[job.WorkloadName]
+		if js != nil && js.Status != "Failed" && js.Status != workflowSkipped {
+			continue // submitted by an earlier run
+		}
+		if js != nil && js.Status == "Failed" {
+			// the failed run may still be in the cluster, which blocks the name
+			if err := orc.CancelJob(job.WorkloadName, cancelOpts); err != nil {
+				logging.Warn("Could not remove the failed run of job '%s': %v", job.WorkloadName, err)
+			}
+		}
+
+		pending, failed := workflowDependencies(job, state)
+		if failed != "" {
+			logging.Info("Skipping job '%s' of workflow '%s', its dependency '%s' did not finish as required.", job.WorkloadName, name, failed)
+			state.Jobs[job.WorkloadName] = &workflowJobState{Status: workflowSkipped}
+		} else {
+			job.After, job.Hold = pending, len(pending) > 0
+			if err := orc.SubmitJob(job); err != nil {
+				return fmt.Errorf("failed to submit job %s of workflow %s: %w", job.WorkloadName, name, err)
+			}
+			state.Jobs[job.WorkloadName] = &workflowJobState{Status: "Submitted", Held: job.Hold}
+		}
+		if err := save(state); err != nil {
+			return err
+		}
+	}
+
+	return trackWorkflow(name, jobs, state, save)
+}
+
+// trackWorkflow polls the status of the jobs, releases held jobs once their
+// dependencies are satisfied, and cancels the ones whose dependencies failed
+func trackWorkflow(name string, jobs []orchestrator.JobDefinition, state *workflowState, save func(*workflowState) error) error {
+	listOpts := orchestrator.ListOptions{ProjectID: projectID, ClusterName: clusterName, ClusterLocation: location}
+	cancelOpts := orchestrator.CancelOptions{ProjectID: projectID, ClusterName: clusterName, ClusterLocation: location}
+	releaseOpts := orchestrator.ReleaseOptions{ProjectID: projectID, ClusterName: clusterName, ClusterLocation: location}
+
+	for {
+		list, err := orc.ListJobs(listOpts)
+		if err != nil {
+			return fmt.Errorf("failed to get the status of the jobs of workflow %s: %w", name, err)
+		}
+		statuses := map[string]string{}
+		for _, j := range list {
+			statuses[j.Name] = j.Status
+		}
+
+		active := 0
+		for _, job := range jobs {
+			js := state.Jobs[job.WorkloadName]
+			if workflowJobDone(js.Status) {
+				continue
+			}
+
+			if js.Held {
+				pending, failed := workflowDependencies(job, state)
+				switch {
+				case failed != "":
+					logging.Info("Skipping job '%s' of workflow '%s', its dependency '%s' did not finish as required.", job.WorkloadName, name, failed)
+					if err := orc.CancelJob(job.WorkloadName, cancelOpts); err != nil {
+						logging.Warn("Could not cancel job '%s': %v", job.WorkloadName, err)
+					}
+					js.Held, js.Status = false, workflowSkipped
+				case len(pending) == 0:
+					if err := orc.ReleaseJob(job.WorkloadName, releaseOpts); err != nil {
+						return fmt.Errorf("failed to release job %s of workflow %s: %w", job.WorkloadName, name, err)
+					}
+					js.Held = false
+					active++
+				default:
+					active++
+				}
+				continue
+			}
+
+			status, ok := statuses[job.WorkloadName]
+			if !ok {
+				logging.Error("Job '%s' of workflow '%s' is no longer in the cluster.", job.WorkloadName, name)
+				status = "Failed"
+			}
+			if status != js.Status {
+				logging.Info("Job '%s' of workflow '%s' is %s.", job.WorkloadName, name, status)
+				js.Status = status
+			}
+			if !workflowJobDone(status) {
+				active++
+			}
+		}
+
+		if err := save(state); err != nil {
+			return err
+		}
+		if active == 0 {
+			break
+		}
+		time.Sleep(workflowPollInterval)
+	}
+
+	failed, skipped := 0, 0
+	for _, js := range state.Jobs {
+		switch js.Status {
+		case "Failed":
+			failed++
+		case workflowSkipped:
+			skipped++
+		}
+	}
+	if failed+skipped > 0 {
+		return fmt.Errorf("workflow %s finished with %d failed and %d skipped jobs, run it again to resubmit them", name, failed, skipped)
+	}
+	logging.Info("Workflow '%s' completed successfully.", name)
+	return nil
+}
+
+// workflowDependencies returns the dependencies of a job that didn't finish
+// yet, or the name of a dependency that failed the job
+func workflowDependencies(job orchestrator.JobDefinition, state *workflowState) ([]orchestrator.JobDependency, string) {
+	var pending []orchestrator.JobDependency
+	for _, d := range job.After {
+		ds := state.Jobs[d.Name]
+		if ds == nil {
+			pending = append(pending, d)
+			continue
+		}
+		satisfied, failed := d.Check(ds.Status)
+		switch {
+		case failed || ds.Status == workflowSkipped:
+			return nil, d.Name
+		case !satisfied:
+			pending = append(pending, d)
+		}
+	}
+	return pending, ""
+}
+
+func workflowJobDone(status string) bool {
+	return status == "Succeeded" || status == "Failed" || status == workflowSkipped
+}
+
+func workflowStatePath(name string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	stateDir := filepath.Join(homeDir, stateDirName, workflowsDirName)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return "", fmt.Errorf("could not create state directory %s: %w", stateDir, err)
+	}
+	return filepath.Join(stateDir, name+".json"), nil
+}
+
+func loadWorkflowState(path string) (*workflowState, error) {
+	state := &workflowState{Jobs: map[string]*workflowJobState{}}
+	if restartWorkflow {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow state from %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow state from %s: %w, run the workflow with --restart to start over", path, err)
+	}
+	if state.Jobs == nil {
+		state.Jobs = map[string]*workflowJobState{}
+	}
+	logging.Info("Resuming workflow from %s.", path)
+	return state, nil
+}
+
+func saveWorkflowState(path string, state *workflowState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write workflow state to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// workflowOrchestrator runs jobs instantly: a job listed as running by one
+// ListJobs call is listed with its final status by the next
+type workflowOrchestrator struct {
+	orchestrator.JobOrchestrator
+	final     map[string]string
+	statuses  map[string]string
+	submitted []orchestrator.JobDefinition
+	released  []string
+	canceled  []string
+}
+
+func newWorkflowOrchestrator(final map[string]string) *workflowOrchestrator {
+	return &workflowOrchestrator{final: final, statuses: map[string]string{}}
+}
+
+func (w *workflowOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
+	w.submitted = append(w.submitted, job)
+	w.statuses[job.WorkloadName] = "Pending"
+	if job.Hold {
+		w.statuses[job.WorkloadName] = "Held"
+	}
+	return nil
+}
+
+func (w *workflowOrchestrator) ReleaseJob(name string, opts orchestrator.ReleaseOptions) error {
+	w.released = append(w.released, name)
+	w.statuses[name] = "Pending"
+	return nil
+}
+
+func (w *workflowOrchestrator) CancelJob(name string, opts orchestrator.CancelOptions) error {
+	w.canceled = append(w.canceled, name)
+	delete(w.statuses, name)
+	return nil
+}
+
+func (w *workflowOrchestrator) ListJobs(opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+	res := []orchestrator.JobStatus{}
+	for name, status := range w.statuses {
+		res = append(res, orchestrator.JobStatus{Name: name, Status: status})
+		if status == "Pending" {
+			w.statuses[name] = "Succeeded"
+			if f, ok := w.final[name]; ok {
+				w.statuses[name] = f
+			}
+		}
+	}
+	return res, nil
+}
+
+func setupWorkflowTest(t *testing.T, w *workflowOrchestrator) {
+	t.Helper()
+	resetSubmitCmdFlags()
+	t.Cleanup(resetSubmitCmdFlags)
+	t.Setenv("HOME", t.TempDir())
+
+	oldFactory, oldInterval := slurmOrchestratorFactory, workflowPollInterval
+	t.Cleanup(func() { slurmOrchestratorFactory, workflowPollInterval = oldFactory, oldInterval })
+	slurmOrchestratorFactory = func(string) orchestrator.JobOrchestrator { return w }
+	workflowPollInterval = 0
+}
+
+func writeWorkflow(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readWorkflowState(t *testing.T, name string) map[string]*workflowJobState {
+	t.Helper()
+	path, err := workflowStatePath(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state workflowState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	return state.Jobs
+}
+
+const testWorkflow = `
+vars:
+  data: gs://bucket/data
+defaults:
+  compute-type: compute
+  command: echo $(vars.data)
+jobs:
+- name: eval
+  after: [train]
+- name: train
+  after: [prep]
+  command: train $(vars.data)
+- name: prep
+- name: report
+  after: [eval:any]
+`
+
+func TestWorkflowRun(t *testing.T) {
+	w := newWorkflowOrchestrator(nil)
+	setupWorkflowTest(t, w)
+
+	path := writeWorkflow(t, testWorkflow)
+	if _, err := executeCommand(JobCmd, "workflow", "run", path, "--orchestrator", "slurm", "--vars", "data=/tmp/data"); err != nil {
+		t.Fatalf("workflow run failed: %v", err)
+	}
+
+	got := []string{}
+	for _, j := range w.submitted {
+		got = append(got, j.WorkloadName)
+		if j.Hold != (j.WorkloadName != "prep") || j.AwaitJobCompletion {
+			t.Errorf("job %s: hold = %v, await = %v", j.WorkloadName, j.Hold, j.AwaitJobCompletion)
+		}
+	}
+	if strings.Join(got, ",") != "prep,train,eval,report" {
+		t.Errorf("submitted %v, want dependencies first", got)
+	}
+	if w.submitted[0].CommandToRun != "echo /tmp/data" || w.submitted[1].CommandToRun != "train /tmp/data" {
+		t.Errorf("commands = %q, %q", w.submitted[0].CommandToRun, w.submitted[1].CommandToRun)
+	}
+	if strings.Join(w.released, ",") != "train,eval,report" {
+		t.Errorf("released %v", w.released)
+	}
+	for name, js := range readWorkflowState(t, "pipeline") {
+		if js.Status != "Succeeded" || js.Held {
+			t.Errorf("state of %s = %+v, want Succeeded", name, js)
+		}
+	}
+}
+
+func TestWorkflowRun_Failure(t *testing.T) {
+	w := newWorkflowOrchestrator(map[string]string{"train": "Failed"})
+	setupWorkflowTest(t, w)
+
+	path := writeWorkflow(t, testWorkflow)
+	_, err := executeCommand(JobCmd, "workflow", "run", path, "--orchestrator", "slurm")
+	if err == nil || !strings.Contains(err.Error(), "workflow pipeline finished with 1 failed and 2 skipped jobs") {
+		t.Fatalf("expected failed workflow, got %v", err)
+	}
+	if strings.Join(w.canceled, ",") != "eval,report" {
+		t.Errorf("canceled %v, want the held jobs [eval report]", w.canceled)
+	}
+
+	state := readWorkflowState(t, "pipeline")
+	want := map[string]string{"prep": "Succeeded", "train": "Failed", "eval": "Skipped", "report": "Skipped"}
+	for name, status := range want {
+		if state[name] == nil || state[name].Status != status {
+			t.Errorf("state of %s = %+v, want %s", name, state[name], status)
+		}
+	}
+
+	// Running the workflow again resubmits the failed and skipped jobs only.
+	w.final = nil
+	w.submitted, w.canceled = nil, nil
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, "workflow", "run", path, "--orchestrator", "slurm"); err != nil {
+		t.Fatalf("resumed workflow failed: %v", err)
+	}
+	got := []string{}
+	for _, j := range w.submitted {
+		got = append(got, j.WorkloadName)
+	}
+	if strings.Join(got, ",") != "train,eval,report" {
+		t.Errorf("resubmitted %v, want [train eval report]", got)
+	}
+	if w.submitted[0].Hold || len(w.submitted[0].After) != 0 {
+		t.Errorf("train should not wait for prep, which succeeded before: %+v", w.submitted[0])
+	}
+	if strings.Join(w.canceled, ",") != "train" {
+		t.Errorf("canceled %v, want the failed run of train removed", w.canceled)
+	}
+}
+
+func TestLoadWorkflow_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		wantErr  string
+	}{
+		{"no jobs", "name: empty\n", "line 1 column 1: workflow has no jobs"},
+		{"unknown field", "job: []\n", `line 1 column 1: unknown field "job"`},
+		{"bad name", "name: a/b\njobs: []\n", "line 1 column 7: workflow name must consist of"},
+		{"not a spec", "jobs: [a]\n", "line 1 column 8: jobs must be maps of submit flags"},
+		{"unknown flag", "jobs:\n- name: a\n  cmd: x\n", `line 3 column 3: unknown field "cmd"`},
+		{"hold", "jobs:\n- {name: a, command: x, compute-type: c, hold: true}\n", `line 2 column 42: "hold" can't be set for jobs of a workflow`},
+		{"duplicate", "defaults: {command: x, compute-type: c}\njobs:\n- name: a\n- name: a\n", `job "a" is defined more than once`},
+		{"missing dependency", "defaults: {command: x, compute-type: c}\njobs:\n- {name: a, after: [b]}\n", `job "a" depends on "b", which is not a job of the workflow`},
+		{"cycle", "defaults: {command: x, compute-type: c}\njobs:\n- {name: a, after: [c]}\n- {name: b}\n- {name: c, after: [a]}\n", "the dependencies of jobs a, c form a cycle"},
+		{"invalid dependency", "defaults: {command: x, compute-type: c}\njobs:\n- {name: a, after: [b:done]}\n", `line 3 column 13: invalid dependency "b:done"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resetSubmitCmdFlags()
+			defer resetSubmitCmdFlags()
+			orchestratorName = slurmOrchestrator
+
+			_, _, err := loadWorkflow(writeWorkflow(t, tc.workflow))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/lsp"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	// accepted for compatibility with editors that always pass it, stdio is the only transport
+	lspCmd.Flags().Bool("stdio", true, "Communicate over stdin and stdout")
+	rootCmd.AddCommand(lspCmd)
+}
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Start a language server for blueprints.",
+	Long: `Starts a Language Server Protocol server for blueprint YAML files communicating over stdio.
+It provides diagnostics, completion of module sources, settings and references,
+hover documentation of module settings and go-to-definition of modules and variables.`,
+	Args:         cobra.NoArgs,
+	Run:          runLspCmd,
+	SilenceUsage: true,
+}
+
+func runLspCmd(cmd *cobra.Command, args []string) {
+	logging.SetInfoOutput(os.Stderr) // stdout is reserved for the protocol
+	checkErr(lsp.NewServer(os.Stdin, os.Stdout).Serve(), nil)
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+var flagParallelism int
+
+func addParallelismFlag(c *cobra.Command) *cobra.Command {
+	c.Flags().IntVar(&flagParallelism, "parallelism", 1,
+		"Maximum number of deployment groups processed concurrently, groups wait for groups whose outputs they use.")
+	return c
+}
+
+func validateParallelismFlag() error {
+	if flagParallelism < 1 {
+		return fmt.Errorf("--parallelism must be at least 1, got %d", flagParallelism)
+	}
+	return nil
+}
+
+// runGroups runs fn for the groups, in order, as soon as groups they depend on
+// are done, at most `parallelism` at a time. If reverse is set, groups wait for
+// groups depending on them instead, e.g. to destroy them.
+// Dependencies on groups not in the list are ignored. After a failure no more
+// groups are started and groups in flight are let to finish.
+func runGroups(groups []config.GroupName, deps map[config.GroupName][]config.GroupName, parallelism int, reverse bool, fn func(config.GroupName) error) error {
+	selected := map[config.GroupName]bool{}
+	for _, g := range groups {
+		selected[g] = true
+	}
+	waitFor := map[config.GroupName][]config.GroupName{}
+	for _, g := range groups {
+		for _, d := range deps[g] {
+			if !selected[d] {
+				continue
+			}
+			if reverse {
+				waitFor[d] = append(waitFor[d], g)
+			} else {
+				waitFor[g] = append(waitFor[g], d)
+			}
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		cond    = sync.NewCond(&mu)
+		done    = map[config.GroupName]bool{}
+		started = map[config.GroupName]bool{}
+		running = 0
+		failed  = []config.GroupName{}
+		errs    = []error{}
+		wg      sync.WaitGroup
+	)
+	ready := func(g config.GroupName) bool {
+		for _, d := range waitFor[g] {
+			if !done[d] {
+				return false
+			}
+		}
+		return true
+	}
+
+	mu.Lock()
+	for len(failed) == 0 && len(started) < len(groups) {
+		var next config.GroupName
+		for _, g := range groups {
+			if !started[g] && ready(g) {
+				next = g
+				break
+			}
+		}
+		if next == "" || running >= parallelism {
+			cond.Wait()
+			continue
+		}
+
+		started[next] = true
+		running++
+		wg.Add(1)
+		go func(g config.GroupName) {
+			defer wg.Done()
+			err := fn(g)
+
+			mu.Lock()
+			defer mu.Unlock()
+			running--
+			if err != nil {
+				failed = append(failed, g)
+				errs = append(errs, err)
+			} else {
+				done[g] = true
+			}
+			cond.Broadcast()
+		}(next)
+	}
+	mu.Unlock()
+	wg.Wait()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		names := []string{}
+		for i, g := range failed {
+			logging.Error("group %q failed: %v", g, errs[i])
+			names = append(names, string(g))
+		}
+		return fmt.Errorf("groups %s failed", strings.Join(names, ", "))
+	}
+}
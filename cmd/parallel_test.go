@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"hpc-toolkit/pkg/config"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunGroups(t *testing.T) {
+	// b and c use outputs of a, d uses outputs of c
+	groups := []config.GroupName{"a", "b", "c", "d"}
+	deps := map[config.GroupName][]config.GroupName{"a": {}, "b": {"a"}, "c": {"a"}, "d": {"c"}}
+
+	type event struct {
+		group config.GroupName
+		start bool
+	}
+	run := func(parallelism int, reverse bool, fail config.GroupName) ([]event, error) {
+		var mu sync.Mutex
+		events := []event{}
+		order := slices.Clone(groups)
+		if reverse {
+			slices.Reverse(order)
+		}
+		err := runGroups(order, deps, parallelism, reverse, func(g config.GroupName) error {
+			mu.Lock()
+			events = append(events, event{g, true})
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			events = append(events, event{g, false})
+			mu.Unlock()
+			if g == fail {
+				return errors.New("boom")
+			}
+			return nil
+		})
+		return events, err
+	}
+	// checks that every group starts after its dependencies finish
+	checkOrder := func(t *testing.T, events []event, reverse bool) {
+		finished := map[config.GroupName]bool{}
+		for _, e := range events {
+			if !e.start {
+				finished[e.group] = true
+				continue
+			}
+			for g, ds := range deps {
+				for _, d := range ds {
+					first, second := d, g
+					if reverse {
+						first, second = g, d
+					}
+					if second == e.group && !finished[first] {
+						t.Errorf("group %q started before %q finished: %v", second, first, events)
+					}
+				}
+			}
+		}
+	}
+	maxRunning := func(events []event) int {
+		running, res := 0, 0
+		for _, e := range events {
+			if e.start {
+				running++
+			} else {
+				running--
+			}
+			res = max(res, running)
+		}
+		return res
+	}
+
+	t.Run("sequential", func(t *testing.T) {
+		events, err := run(1, false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []config.GroupName{}
+		for _, e := range events {
+			if e.start {
+				got = append(got, e.group)
+			}
+		}
+		if !slices.Equal(got, groups) {
+			t.Errorf("got order %v, want %v", got, groups)
+		}
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		events, err := run(4, false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkOrder(t, events, false)
+		if got := maxRunning(events); got != 2 { // b and c, or b and d
+			t.Errorf("got %d groups running concurrently, want 2", got)
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		events, err := run(4, true, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkOrder(t, events, true)
+		if len(events) != 8 {
+			t.Errorf("not all groups were run: %v", events)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		events, err := run(4, false, "c")
+		if err == nil || err.Error() != "boom" {
+			t.Errorf("got error %v, want boom", err)
+		}
+		for _, e := range events {
+			if e.group == "d" {
+				t.Errorf("d should not run after failure of c: %v", events)
+			}
+		}
+		if len(events)%2 != 0 {
+			t.Errorf("groups in flight should finish: %v", events)
+		}
+	})
+}
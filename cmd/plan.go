@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// planChangesExitCode is the exit code of `gcluster plan` if the deployment has changes,
+// errors exit with 1, following `terraform plan -detailed-exitcode`
+const planChangesExitCode = 2
+
+func init() {
+	planCmd.Flags().StringVar(&planFlags.format, "format", "text", "Output format of the report, one of text, json or markdown")
+	planCmd.Flags().StringVarP(&planFlags.out, "out", "o", "", "Output file for the report, defaults to stdout")
+	planCmd.Flags().BoolVar(&planFlags.unknownIsChange, "unknown-is-change", false,
+		"Exit with code 2 if changes of a group are unknown, e.g. of Packer groups")
+	rootCmd.AddCommand(
+		addGroupSelectionFlags(
+			addArtifactsDirFlag(planCmd)))
+}
+
+var (
+	planFlags = struct {
+		format          string
+		out             string
+		unknownIsChange bool
+	}{}
+
+	planCmd = &cobra.Command{
+		Use:   "plan DEPLOYMENT_DIRECTORY",
+		Short: "Report changes proposed by a deployment without applying them.",
+		Long: `Runs "terraform plan" for every selected group of the deployment and reports
+proposed changes of resources, without changing cloud infrastructure.
+
+Exit codes: 0 - no changes, 1 - error, 2 - changes are proposed.
+Groups that cannot be planned, e.g. Packer groups, are reported as unknown
+and don't change the exit code unless --unknown-is-change is set.`,
+		Args:              cobra.MatchAll(cobra.ExactArgs(1), allowRemote(checkDir)),
+		ValidArgsFunction: matchDirs,
+		PreRunE:           validatePlanFlags,
+		Run:               runPlanCmd,
+		SilenceUsage:      true,
+	}
+)
+
+func validatePlanFlags(cmd *cobra.Command, args []string) error {
+	switch planFlags.format {
+	case "text", "json", "markdown":
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, must be one of text, json or markdown", planFlags.format)
+	}
+}
+
+func runPlanCmd(cmd *cobra.Command, args []string) {
+	deplRoot := args[0]
+	if deploymentio.IsRemote(deplRoot) { // changes are not published, plan doesn't modify the deployment
+		local, err := deploymentio.WorkingCopy(deplRoot)
+		checkErr(err, nil)
+		deplRoot = local
+	}
+	if planFlags.format != "text" && planFlags.out == "" {
+		logging.SetInfoOutput(os.Stderr) // keep stdout parsable
+	}
+
+	artDir := getArtifactsDir(deplRoot)
+	bp, ctx := artifactBlueprintOrDie(artDir)
+	checkErr(validateGroupSelectionFlags(bp), ctx)
+	checkErr(shell.ValidateDeploymentDirectory(bp.Groups, deplRoot), ctx)
+
+	report := shell.PlanReport{Groups: []shell.GroupPlan{}}
+	for _, group := range bp.Groups {
+		if !isGroupSelected(group.Name) {
+			logging.Info("skipping group %q", group.Name)
+			continue
+		}
+		gp, err := planGroup(deplRoot, artDir, bp, group)
+		checkErr(err, ctx)
+		report.Groups = append(report.Groups, gp)
+	}
+
+	w := cmd.OutOrStdout()
+	if planFlags.out != "" {
+		f, err := os.Create(planFlags.out)
+		checkErr(err, nil)
+		defer f.Close()
+		w = f
+	}
+	checkErr(writePlanReport(w, report, planFlags.format), nil)
+
+	if unknown := report.UnknownGroups(); len(unknown) > 0 {
+		logging.Info("changes of groups %v are unknown", unknown)
+	}
+	if code := planExitCode(report, planFlags.unknownIsChange); code != 0 {
+		logging.ExitWithCode(code, "Deployment %s has proposed changes: %s", args[0], report.Summary())
+	}
+}
+
+// planExitCode returns the exit code of the command for the report, groups with
+// unknown changes count as changes only if unknownIsChange is set
+func planExitCode(r shell.PlanReport, unknownIsChange bool) int {
+	if r.HasChanges() || (unknownIsChange && len(r.UnknownGroups()) > 0) {
+		return planChangesExitCode
+	}
+	return 0
+}
+
+func planGroup(deplRoot string, artDir string, bp config.Blueprint, group config.Group) (shell.GroupPlan, error) {
+	groupDir := filepath.Join(deplRoot, string(group.Name))
+	if group.Kind() != config.TerraformKind {
+		return shell.GroupPlan{Group: group.Name, Unknown: true, Note: fmt.Sprintf("%s groups are not planned", group.Kind())}, nil
+	}
+	if err := shell.ImportInputs(groupDir, artDir, bp); err != nil {
+		// outputs of upstream groups are exported on deployment
+		logging.Error("cannot plan group %q: %v", group.Name, err)
+		return shell.GroupPlan{Group: group.Name, Unknown: true, Note: "outputs of upstream groups are not available, deploy them first"}, nil
+	}
+	tf, err := shell.ConfigureTerraform(groupDir)
+	if err != nil {
+		return shell.GroupPlan{}, err
+	}
+	return shell.PlanGroup(tf, group.Name, false)
+}
+
+func writePlanReport(w io.Writer, r shell.PlanReport, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "markdown":
+		_, err := io.WriteString(w, r.Markdown())
+		return err
+	default:
+		_, err := io.WriteString(w, r.Text())
+		return err
+	}
+}
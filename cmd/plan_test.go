@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"hpc-toolkit/pkg/shell"
+	"testing"
+)
+
+func TestWritePlanReport(t *testing.T) {
+	r := shell.PlanReport{Groups: []shell.GroupPlan{{Group: "primary", Changes: []shell.ResourceChange{
+		{Address: "module.a.google_compute_network.net", Type: "google_compute_network", Action: shell.ActionCreate},
+	}}}}
+
+	var buf bytes.Buffer
+	if err := writePlanReport(&buf, r, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got shell.PlanReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("report is not a valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got.Groups) != 1 || len(got.Groups[0].Changes) != 1 || got.Groups[0].Changes[0].Action != "create" {
+		t.Errorf("unexpected report: %#v", got)
+	}
+
+	for _, f := range []string{"text", "markdown"} {
+		buf.Reset()
+		if err := writePlanReport(&buf, r, f); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte("module.a.google_compute_network.net")) {
+			t.Errorf("%s report misses the change:\n%s", f, buf.String())
+		}
+	}
+}
+
+func TestValidatePlanFlags(t *testing.T) {
+	defer func(f string) { planFlags.format = f }(planFlags.format)
+	for _, tc := range []struct {
+		format string
+		err    bool
+	}{{"text", false}, {"json", false}, {"markdown", false}, {"html", true}} {
+		planFlags.format = tc.format
+		if err := validatePlanFlags(planCmd, nil); (err != nil) != tc.err {
+			t.Errorf("format %q: got error %v, want error %v", tc.format, err, tc.err)
+		}
+	}
+}
+
+func TestPlanExitCode(t *testing.T) {
+	changes := shell.GroupPlan{Group: "primary", Changes: []shell.ResourceChange{
+		{Address: "module.a.google_compute_network.net", Type: "google_compute_network", Action: shell.ActionCreate},
+	}}
+	none := shell.GroupPlan{Group: "primary", Changes: []shell.ResourceChange{}}
+	unknown := shell.GroupPlan{Group: "image", Unknown: true, Note: "packer groups are not planned"}
+
+	for _, tc := range []struct {
+		name            string
+		groups          []shell.GroupPlan
+		unknownIsChange bool
+		want            int
+	}{
+		{"no changes", []shell.GroupPlan{none}, false, 0},
+		{"changes", []shell.GroupPlan{changes}, false, planChangesExitCode},
+		{"changes and unknown", []shell.GroupPlan{changes, unknown}, false, planChangesExitCode},
+		{"unknown", []shell.GroupPlan{none, unknown}, false, 0},
+		{"unknown is change", []shell.GroupPlan{none, unknown}, true, planChangesExitCode},
+		{"unknown is change without unknown", []shell.GroupPlan{none}, true, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := shell.PlanReport{Groups: tc.groups}
+			if got := planExitCode(r, tc.unknownIsChange); got != tc.want {
+				t.Errorf("got exit code %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -21,13 +21,15 @@ import (
 	"strings"
 )
 
-func findPos(path config.Path, ctx config.YamlCtx) (config.Pos, bool) {
-	pos, ok := ctx.Pos(path)
+// findSource finds position of the path or its closest ancestor and
+// the context of the (imported) file the position belongs to.
+func findSource(path config.Path, ctx config.YamlCtx) (config.YamlCtx, config.Pos, bool) {
+	src, pos, ok := ctx.Source(path)
 	for !ok && path.Parent() != nil {
 		path = path.Parent()
-		pos, ok = ctx.Pos(path)
+		src, pos, ok = ctx.Source(path)
 	}
-	return pos, ok
+	return src, pos, ok
 }
 
 func renderError(err error, ctx config.YamlCtx) string {
@@ -40,6 +42,8 @@ func renderError(err error, ctx config.YamlCtx) string {
 		return renderHintError(te, ctx)
 	case config.BpError:
 		return renderBpError(te, ctx)
+	case config.ImportError:
+		return renderImportError(te, ctx)
 	case config.PosError:
 		return renderPosError(te, ctx)
 	default:
@@ -66,26 +70,48 @@ func renderHintError(err config.HintError, ctx config.YamlCtx) string {
 }
 
 func renderBpError(err config.BpError, ctx config.YamlCtx) string {
-	if pos, ok := findPos(err.Path, ctx); ok {
+	if src, pos, ok := findSource(err.Path, ctx); ok {
 		posErr := config.PosError{Pos: pos, Err: err.Err}
-		return renderPosError(posErr, ctx)
+		return renderPosError(posErr, src)
 	}
 	return renderError(err.Err, ctx)
 }
 
+func renderImportError(err config.ImportError, ctx config.YamlCtx) string {
+	msg := renderError(err.Err, err.Ctx)
+	if src, pos, ok := findSource(err.Path, ctx); ok {
+		if line, ok := renderLine(pos, src); ok {
+			return fmt.Sprintf("%s\n%s:\n%s", msg, boldYellow("Imported at"), line)
+		}
+	}
+	return msg
+}
+
 func renderPosError(err config.PosError, ctx config.YamlCtx) string {
-	pos := err.Pos
+	line, ok := renderLine(err.Pos, ctx)
+	if !ok {
+		return renderError(err.Err, ctx)
+	}
+	return fmt.Sprintf("%s\n%s", renderError(err.Err, ctx), line)
+}
+
+// renderLine renders the line at given position with an arrow pointing to the column.
+// Lines of imported files are prefixed with the file name.
+func renderLine(pos config.Pos, ctx config.YamlCtx) (string, bool) {
 	line := pos.Line - 1
 	if line < 0 || line >= len(ctx.Lines) {
-		return renderError(err.Err, ctx)
+		return "", false
 	}
 
 	pref := fmt.Sprintf("%d: ", pos.Line)
+	if ctx.File != "" {
+		pref = fmt.Sprintf("%s:%s", ctx.File, pref)
+	}
 	arrow := " "
 	if pos.Column > 0 {
 		spaces := strings.Repeat(" ", len(pref)+pos.Column-1)
 		arrow = spaces + "^"
 	}
 
-	return fmt.Sprintf("%s\n%s%s\n%s", renderError(err.Err, ctx), pref, ctx.Lines[line], arrow)
+	return fmt.Sprintf("%s%s\n%s", pref, ctx.Lines[line], arrow), true
 }
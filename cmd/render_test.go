@@ -67,6 +67,26 @@ vars:
 			want: `Error: arbuz
 Hint: did you mean 'kale'?
 3:   kale: dos
+     ^`},
+		{ // error in imported file
+			err: config.ImportError{
+				Path: config.Root.Imports.At(0),
+				Ctx: func() config.YamlCtx {
+					c := makeCtx(`
+vars:
+  kale: dos`, t)
+					c.File = "frag.yaml"
+					return c
+				}(),
+				Err: config.BpError{Path: config.Root.Vars.Dot("kale"), Err: errors.New("arbuz")}},
+			ctx: makeCtx(`
+imports:
+- ./frag.yaml`, t),
+			want: `Error: arbuz
+frag.yaml:3:   kale: dos
+               ^
+Imported at:
+3: - ./frag.yaml
      ^`},
 	}
 	for _, tc := range tests {
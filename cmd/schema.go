@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/inspect"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/modulereader"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	schemaCmd.Flags().StringVarP(&schemaFlags.out, "out", "o", "", "Output file for the schema, defaults to stdout")
+	rootCmd.AddCommand(schemaCmd)
+}
+
+var (
+	schemaFlags = struct {
+		out string
+	}{}
+
+	schemaCmd = &cobra.Command{
+		Use:   "schema",
+		Short: "Generate JSON Schema of blueprints.",
+		Long: `Generates JSON Schema (draft-07) of blueprints, including settings of every
+embedded module, to validate blueprints in editors. E.g. for yaml-language-server:
+
+  gcluster schema -o blueprint.schema.json
+
+and add the following comment at the top of a blueprint:
+
+  # yaml-language-server: $schema=./blueprint.schema.json`,
+		Args:         cobra.NoArgs,
+		Run:          runSchemaCmd,
+		SilenceUsage: true,
+	}
+)
+
+func runSchemaCmd(cmd *cobra.Command, args []string) {
+	sks, err := inspect.EmbeddedModules()
+	checkErr(err, nil)
+
+	mods := []inspect.ModuleSchemaInfo{}
+	for _, sk := range sks {
+		info, err := modulereader.GetModuleInfo(sk.Source, sk.Kind)
+		if err != nil {
+			logging.Error("Skipping settings schema of module %q: %v", sk.Source, err)
+			continue
+		}
+		mods = append(mods, inspect.ModuleSchemaInfo{SourceAndKind: sk, Info: info})
+	}
+
+	w := cmd.OutOrStdout()
+	if schemaFlags.out != "" {
+		f, err := os.Create(schemaFlags.out)
+		checkErr(err, nil)
+		defer f.Close()
+		w = f
+	}
+	checkErr(writeSchema(w, inspect.BlueprintSchema(mods)), nil)
+	if schemaFlags.out != "" {
+		logging.Info("Blueprint schema is saved as %s", schemaFlags.out)
+	}
+}
+
+func writeSchema(w io.Writer, s inspect.Schema) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(s)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd defines command line utilities for gcluster
+package cmd
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/modulewriter"
+	"hpc-toolkit/pkg/shell"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(addArtifactsDirFlag(statusCmd))
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status DEPLOYMENT_DIRECTORY",
+	Short: "Print deployment status of groups recorded by previous deploys.",
+	Long: `Prints the status of every deployment group recorded in the deployment journal,
+the time it was last applied and its exported outputs.`,
+	Args:              cobra.MatchAll(cobra.ExactArgs(1), allowRemote(checkDir)),
+	ValidArgsFunction: matchDirs,
+	Run:               runStatusCmd,
+	SilenceUsage:      true,
+}
+
+func runStatusCmd(cmd *cobra.Command, args []string) {
+	deplRoot := args[0]
+	if deploymentio.IsRemote(deplRoot) { // status doesn't modify the deployment
+		local, err := deploymentio.WorkingCopy(deplRoot)
+		checkErr(err, nil)
+		deplRoot = local
+	}
+
+	artDir := getArtifactsDir(deplRoot)
+	bp, ctx := artifactBlueprintOrDie(artDir)
+	bpHash, err := shell.HashFile(filepath.Join(artDir, modulewriter.ExpandedBlueprintName))
+	checkErr(err, ctx)
+	journal, err := shell.LoadJournal(deplRoot)
+	checkErr(err, ctx)
+
+	groups := []config.GroupName{}
+	for _, g := range bp.Groups {
+		groups = append(groups, g.Name)
+	}
+	checkErr(writeStatus(cmd.OutOrStdout(), groups, journal, bpHash), ctx)
+}
+
+// writeStatus prints journal records of groups in blueprint order
+func writeStatus(out io.Writer, groups []config.GroupName, journal *shell.Journal, bpHash string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tSTATUS\tLAST APPLIED\tOUTPUTS\tNOTE")
+	for _, g := range groups {
+		r, ok := journal.Record(g)
+		if !ok {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", g, "not deployed", "-", "-", "")
+			continue
+		}
+
+		applied := "-"
+		if r.LastApplied != nil {
+			applied = r.LastApplied.Local().Format(time.RFC3339)
+		}
+		outputs := "-"
+		if len(r.Outputs) > 0 {
+			outputs = fmt.Sprint(len(r.Outputs))
+		}
+		note := r.Error
+		if note == "" && r.BlueprintHash != bpHash {
+			note = "blueprint changed since last deploy"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", g, r.Status, applied, outputs, note)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/shell"
+	"strings"
+	"testing"
+)
+
+func TestWriteStatus(t *testing.T) {
+	dir := t.TempDir()
+	j, err := shell.LoadJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Start("primary", "bp", "h1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Apply("primary", dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Start("cluster", "old", "h2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Fail("cluster", errors.New("quota exceeded")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeStatus(&buf, []config.GroupName{"primary", "cluster", "image"}, j, "bp"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want header and 3 groups, got:\n%s", buf.String())
+	}
+	for i, want := range [][]string{
+		{"GROUP", "STATUS"},
+		{"primary", "applied"},
+		{"cluster", "failed", "quota exceeded"},
+		{"image", "not deployed"},
+	} {
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("line %q doesn't contain %q", lines[i], w)
+			}
+		}
+	}
+	if strings.Contains(lines[1], "blueprint changed") {
+		t.Errorf("unexpected note in %q", lines[1])
+	}
+}
@@ -18,10 +18,14 @@ import (
 	"errors"
 	"fmt"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/modulewriter"
 	"hpc-toolkit/pkg/shell"
 	"os"
 	"slices"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 )
@@ -71,6 +75,45 @@ func checkDir(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// allowRemote extends the check of arguments to accept remote deployment directories, e.g. `gs://bucket/dir`
+func allowRemote(check cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && deploymentio.IsRemote(args[0]) {
+			return nil
+		}
+		return check(cmd, args)
+	}
+}
+
+// localDeployment returns a local working copy of the remote deployment directory,
+// the returned function publishes changes back and is also run if gcluster fails.
+// Local deployment directories are returned as is.
+func localDeployment(deplRoot string) (string, func()) {
+	if !deploymentio.IsRemote(deplRoot) {
+		return deplRoot, func() {}
+	}
+	local, err := deploymentio.WorkingCopy(deplRoot)
+	checkErr(err, nil)
+	logging.Info("Using local working copy %s of %s", local, deplRoot)
+
+	var once sync.Once
+	publish := func() {
+		once.Do(func() {
+			if err := deploymentio.Publish(local, deplRoot); err != nil {
+				logging.Error("failed to publish the deployment to %s: %v", deplRoot, err)
+			}
+		})
+	}
+	prevHook := logging.FatalHook
+	logging.FatalHook = func(exitCode int) {
+		publish() // e.g. to keep Terraform state of partially applied changes
+		if prevHook != nil {
+			prevHook(exitCode)
+		}
+	}
+	return local, publish
+}
+
 func matchDirs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return nil, cobra.ShellCompDirectiveFilterDirs | cobra.ShellCompDirectiveNoFileComp
 }
@@ -125,6 +168,10 @@ func validateGroupSelectionFlags(bp config.Blueprint) error {
 }
 
 func isGroupSelected(g config.GroupName) bool {
+	if flagModules != nil {
+		_, ok := selectedModules[g]
+		return ok
+	}
 	if flagOnlyGroups != nil {
 		return slices.Contains(flagOnlyGroups, string(g))
 	}
@@ -133,3 +180,102 @@ func isGroupSelected(g config.GroupName) bool {
 	}
 	return true
 }
+
+var flagModules []string
+var flagExcludeModules []string
+
+// selectedModules holds modules selected by --module by their group
+var selectedModules map[config.GroupName][]config.ModuleID
+
+func addModuleSelectionFlags(c *cobra.Command) *cobra.Command {
+	c.Flags().StringSliceVar(&flagModules, "module", nil,
+		"Only apply to modules with the given IDs, groups without selected modules are skipped")
+	c.Flags().StringSliceVar(&flagExcludeModules, "exclude-module", nil,
+		"Modules using outputs of modules selected by --module that are intentionally not applied")
+	return c
+}
+
+// validateModuleSelectionFlags validates modules selected by --module, modules of
+// the same group using their outputs must be selected as well or, unless destroying,
+// intentionally excluded with --exclude-module
+func validateModuleSelectionFlags(bp config.Blueprint, destroy bool) error {
+	selectedModules = nil
+	if flagModules == nil {
+		if flagExcludeModules != nil {
+			return errors.New("--exclude-module can only be specified with --module")
+		}
+		return nil
+	}
+	if flagOnlyGroups != nil || flagSkipGroups != nil {
+		return errors.New("cannot specify --module with --only or --skip")
+	}
+	if destroy && flagExcludeModules != nil {
+		return errors.New("cannot specify --exclude-module with destroy, modules using outputs of destroyed modules are destroyed as well")
+	}
+
+	dict := []string{}
+	bp.WalkModulesSafe(func(_ config.ModulePath, m *config.Module) {
+		dict = append(dict, string(m.ID))
+	})
+	for _, id := range append(slices.Clone(flagModules), flagExcludeModules...) {
+		if !slices.Contains(dict, id) {
+			return config.HintSpelling(id, dict, fmt.Errorf("module %q not found", id))
+		}
+	}
+
+	selectedModules = map[config.GroupName][]config.ModuleID{}
+	for _, id := range flagModules {
+		g := bp.ModuleGroupOrDie(config.ModuleID(id))
+		selectedModules[g.Name] = append(selectedModules[g.Name], config.ModuleID(id))
+	}
+	for _, id := range flagModules {
+		g := bp.ModuleGroupOrDie(config.ModuleID(id))
+		for _, d := range g.ModuleDependents(config.ModuleID(id)) {
+			if slices.Contains(flagModules, string(d)) || slices.Contains(flagExcludeModules, string(d)) {
+				continue
+			}
+			if destroy {
+				return fmt.Errorf("module %q uses outputs of module %q and would be destroyed as well, select it with --module", d, id)
+			}
+			return config.HintError{
+				Hint: fmt.Sprintf("select it with --module or leave it as is with --exclude-module %s", d),
+				Err:  fmt.Errorf("module %q uses outputs of module %q", d, id)}
+		}
+	}
+	return nil
+}
+
+// moduleTargets returns Terraform targets of modules of the group selected by
+// --module, nil if modules are not selected
+func moduleTargets(g config.GroupName) []string {
+	if selectedModules == nil {
+		return nil
+	}
+	targets := []string{}
+	for _, id := range selectedModules[g] {
+		targets = append(targets, "module."+string(id))
+	}
+	return targets
+}
+
+// isPartial returns true if --module selects only some modules of the group
+func isPartial(g config.Group) bool {
+	targets := moduleTargets(g.Name)
+	return targets != nil && len(targets) != len(g.Modules)
+}
+
+// warnPartialApply warns that only some modules of the group are applied or destroyed
+func warnPartialApply(g config.Group, destroy bool) {
+	if !isPartial(g) || g.Kind() != config.TerraformKind {
+		return
+	}
+	targets := moduleTargets(g.Name)
+	modules := strings.Join(targets, ", ")
+	if destroy {
+		logging.Warn("PARTIAL DESTROY of group %q: only %s and resources depending on them are destroyed, "+
+			"other modules of the group are kept.", g.Name, modules)
+		return
+	}
+	logging.Warn("PARTIAL APPLY of group %q: only %s and resources they depend on are applied, "+
+		"other modules of the group may be out of date until the next full \"gcluster deploy\".", g.Name, modules)
+}
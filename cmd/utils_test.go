@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"hpc-toolkit/pkg/config"
 	"testing"
+
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestIsGroupSelected(t *testing.T) {
@@ -81,3 +83,62 @@ func TestValidateGroupSelectionFlags(t *testing.T) {
 	}
 
 }
+
+func TestValidateModuleSelectionFlags(t *testing.T) {
+	mod := func(id string, uses ...string) config.Module {
+		settings := map[string]cty.Value{}
+		for _, u := range uses {
+			settings[u] = config.ModuleRef(config.ModuleID(u), "id").AsValue()
+		}
+		return config.Module{ID: config.ModuleID(id), Kind: config.TerraformKind, Settings: config.NewDict(settings)}
+	}
+	bp := config.Blueprint{Groups: []config.Group{
+		{Name: "primary", Modules: []config.Module{mod("network")}},
+		{Name: "cluster", Modules: []config.Module{mod("pool_a"), mod("pool_b"), mod("jobset", "pool_a")}},
+	}}
+
+	type test struct {
+		modules []string
+		exclude []string
+		destroy bool
+		err     bool
+	}
+	tests := []test{
+		{nil, nil, false, false},
+		{nil, []string{"jobset"}, false, true},
+		{[]string{"pool_b"}, nil, false, false},
+		{[]string{"pool_x"}, nil, false, true},
+		{[]string{"pool_a"}, nil, false, true},
+		{[]string{"pool_a"}, []string{"jobset"}, false, false},
+		{[]string{"pool_a", "jobset"}, nil, false, false},
+		{[]string{"pool_a"}, nil, true, true},
+		{[]string{"pool_a"}, []string{"jobset"}, true, true},
+		{[]string{"pool_a", "jobset"}, nil, true, false},
+	}
+	defer func() { flagModules, flagExcludeModules, selectedModules = nil, nil, nil }()
+	flagOnlyGroups, flagSkipGroups = nil, nil
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%v;%v;%v", tc.modules, tc.exclude, tc.destroy), func(t *testing.T) {
+			flagModules, flagExcludeModules = tc.modules, tc.exclude
+			err := validateModuleSelectionFlags(bp, tc.destroy)
+			if tc.err && err == nil {
+				t.Error("want error")
+			}
+			if !tc.err && err != nil {
+				t.Errorf("want no error, got %v", err)
+			}
+		})
+	}
+
+	flagModules, flagExcludeModules = []string{"pool_b"}, nil
+	if err := validateModuleSelectionFlags(bp, false); err != nil {
+		t.Fatal(err)
+	}
+	if isGroupSelected("primary") || !isGroupSelected("cluster") {
+		t.Error("want only group cluster selected")
+	}
+	if got := moduleTargets("cluster"); len(got) != 1 || got[0] != "module.pool_b" {
+		t.Errorf("moduleTargets(cluster) = %v; want [module.pool_b]", got)
+	}
+}
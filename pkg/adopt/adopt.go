@@ -0,0 +1,273 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adopt maps existing infrastructure, described by Terraform states,
+// to toolkit modules to produce a blueprint managing it.
+package adopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulereader"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyJson "github.com/zclconf/go-cty/cty/json"
+	"golang.org/x/exp/maps"
+)
+
+// Group is the name of the deployment group containing adopted modules.
+const Group config.GroupName = "primary"
+
+// Import is a Terraform `import` block adopting an existing resource
+// into the deployment group.
+type Import struct {
+	To hcl.Traversal
+	ID string
+}
+
+// Result is a blueprint managing adopted resources and imports to
+// bring them under management of the deployment.
+type Result struct {
+	Blueprint config.Blueprint
+	Imports   []Import
+	// Skipped are resources that could not be mapped to toolkit modules, with reasons.
+	Skipped map[string]string
+}
+
+// candidate is a toolkit module to adopt resources.
+type candidate struct {
+	name     string // name of the resource used to derive the module ID
+	source   string
+	settings map[string]any
+	use      []*candidate
+	imports  []Import // relative to the module
+	project  string
+	region   string
+	zone     string
+
+	id config.ModuleID
+}
+
+// Adopt maps resources to toolkit modules.
+func Adopt(resources []Resource, blueprintName string, deploymentName string) (Result, error) {
+	a := adopter{skipped: map[string]string{}}
+	byType := map[string][]Resource{}
+	for _, r := range resources {
+		byType[r.Type] = append(byType[r.Type], r)
+	}
+	for t, rs := range byType {
+		if !slices.Contains(supportedTypes, t) {
+			for _, r := range rs {
+				a.skipped[r.Address] = fmt.Sprintf("resource type %q is not supported", t)
+			}
+		}
+	}
+
+	a.adoptNetworks(byType["google_compute_network"], byType["google_compute_subnetwork"])
+	a.adoptFilestores(byType["google_filestore_instance"])
+	a.adoptInstances(byType["google_compute_instance"])
+	a.adoptNodePools(byType["google_container_node_pool"])
+	return a.result(blueprintName, deploymentName)
+}
+
+var supportedTypes = []string{
+	"google_compute_network",
+	"google_compute_subnetwork",
+	"google_filestore_instance",
+	"google_compute_instance",
+	"google_container_node_pool",
+}
+
+type adopter struct {
+	candidates []*candidate
+	networks   map[string]*candidate // by network self link, e.g. `projects/p/global/networks/n`
+	skipped    map[string]string
+}
+
+func (a *adopter) add(c *candidate) {
+	a.candidates = append(a.candidates, c)
+}
+
+func (a *adopter) skip(r Resource, reason string, args ...any) {
+	a.skipped[r.Address] = fmt.Sprintf(reason, args...)
+}
+
+var invalidIDCharsRe = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// assignIDs derives unique module IDs from names of resources.
+func (a *adopter) assignIDs() {
+	seen := map[config.ModuleID]bool{"vars": true, "each": true}
+	for _, c := range a.candidates {
+		base := invalidIDCharsRe.ReplaceAllString(c.name, "_")
+		id := config.ModuleID(base)
+		for i := 2; seen[id]; i++ {
+			id = config.ModuleID(fmt.Sprintf("%s_%d", base, i))
+		}
+		seen[id] = true
+		c.id = id
+	}
+}
+
+// mostCommon returns the most common non-empty value, ties are broken alphabetically.
+func mostCommon(vals []string) string {
+	count := map[string]int{}
+	for _, v := range vals {
+		if v != "" {
+			count[v]++
+		}
+	}
+	keys := maps.Keys(count)
+	sort.Strings(keys)
+	best := ""
+	for _, k := range keys {
+		if count[k] > count[best] {
+			best = k
+		}
+	}
+	return best
+}
+
+func (a *adopter) result(blueprintName string, deploymentName string) (Result, error) {
+	a.assignIDs()
+	vars := map[string]string{"deployment_name": deploymentName}
+	var projects, regions, zones []string
+	for _, c := range a.candidates {
+		projects, regions, zones = append(projects, c.project), append(regions, c.region), append(zones, c.zone)
+	}
+	vars["project_id"], vars["region"], vars["zone"] = mostCommon(projects), mostCommon(regions), mostCommon(zones)
+
+	bpVars := config.Dict{}
+	for k, v := range vars {
+		if v != "" {
+			bpVars = bpVars.With(k, cty.StringVal(v))
+		}
+	}
+
+	res := Result{Skipped: a.skipped}
+	mods := []config.Module{}
+	for _, c := range a.candidates {
+		for k, v := range map[string]string{"project_id": c.project, "region": c.region, "zone": c.zone} {
+			if v != "" && v != vars[k] {
+				c.settings[k] = v
+			}
+		}
+		settings, err := moduleSettings(c.source, c.settings)
+		if err != nil {
+			return Result{}, err
+		}
+		m := config.Module{ID: c.id, Source: c.source, Kind: config.TerraformKind, Settings: settings}
+		for _, u := range c.use {
+			m.Use = append(m.Use, u.id)
+		}
+		mods = append(mods, m)
+
+		for _, im := range c.imports {
+			to := append(hcl.Traversal{
+				hcl.TraverseRoot{Name: "module"},
+				hcl.TraverseAttr{Name: string(c.id)},
+			}, im.To...)
+			res.Imports = append(res.Imports, Import{To: to, ID: im.ID})
+		}
+	}
+
+	res.Blueprint = config.Blueprint{
+		BlueprintName: blueprintName,
+		Vars:          bpVars,
+		Groups:        []config.Group{{Name: Group, Modules: mods}},
+	}
+	return res, nil
+}
+
+// moduleSettings matches settings against inputs of the module, settings
+// that are not inputs of the module or equal to defaults are omitted.
+func moduleSettings(source string, settings map[string]any) (config.Dict, error) {
+	info, err := modulereader.GetModuleInfo(source, config.TerraformKind.String())
+	if err != nil {
+		return config.Dict{}, err
+	}
+	inputs := map[string]modulereader.VarInfo{}
+	for _, v := range info.Inputs {
+		inputs[v.Name] = v
+	}
+
+	res := config.Dict{}
+	for k, v := range settings {
+		input, ok := inputs[k]
+		if !ok || v == nil || isDefault(v, input.Default) {
+			continue
+		}
+		cv, err := toCty(v)
+		if err != nil {
+			return config.Dict{}, fmt.Errorf("setting %q of %s: %w", k, source, err)
+		}
+		res = res.With(k, cv)
+	}
+	return res, nil
+}
+
+// isDefault compares values as JSON, to not depend on numeric types.
+func isDefault(v any, def any) bool {
+	var a, b any
+	if err := roundTrip(v, &a); err != nil {
+		return false
+	}
+	if err := roundTrip(def, &b); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func roundTrip(v any, dst *any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func toCty(v any) (cty.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	ty, err := ctyJson.ImpliedType(data)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyJson.Unmarshal(data, ty)
+}
+
+// WriteImports writes `import` blocks to be placed into the directory of the deployment group.
+func WriteImports(w io.Writer, imports []Import) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	for i, im := range imports {
+		if i > 0 {
+			body.AppendNewline()
+		}
+		b := body.AppendNewBlock("import", nil).Body()
+		b.SetAttributeTraversal("to", im.To)
+		b.SetAttributeValue("id", cty.StringVal(im.ID))
+	}
+	_, err := w.Write(hclwrite.Format(f.Bytes()))
+	return err
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adopt
+
+import (
+	"bytes"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/sourcereader"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const testState = `{
+  "format_version": "1.0",
+  "values": {"root_module": {"resources": [
+    {"address": "google_compute_network.net", "mode": "managed", "type": "google_compute_network", "values": {
+      "id": "projects/proj/global/networks/net", "name": "net", "project": "proj", "mtu": 8896,
+      "routing_mode": "GLOBAL", "auto_create_subnetworks": false, "description": "",
+      "self_link": "https://www.googleapis.com/compute/v1/projects/proj/global/networks/net"}},
+    {"address": "google_compute_subnetwork.sub", "mode": "managed", "type": "google_compute_subnetwork", "values": {
+      "id": "projects/proj/regions/us-central1/subnetworks/sub", "name": "sub", "region": "us-central1",
+      "ip_cidr_range": "10.0.0.0/16", "private_ip_google_access": true, "secondary_ip_range": [],
+      "network": "https://www.googleapis.com/compute/v1/projects/proj/global/networks/net"}},
+    {"address": "google_filestore_instance.home", "mode": "managed", "type": "google_filestore_instance", "values": {
+      "id": "projects/proj/locations/us-central1-a/instances/home", "name": "home", "project": "proj",
+      "location": "us-central1-a", "tier": "BASIC_HDD", "protocol": "NFS_V3",
+      "file_shares": [{"name": "nfsshare", "capacity_gb": 1024}],
+      "networks": [{"network": "net", "connect_mode": "DIRECT_PEERING"}]}},
+    {"address": "google_compute_instance.login[0]", "mode": "managed", "type": "google_compute_instance", "values": {
+      "id": "projects/proj/zones/us-central1-a/instances/login-0", "name": "login-0", "project": "proj",
+      "zone": "us-central1-a", "machine_type": "n2-standard-4",
+      "boot_disk": [{"initialize_params": [{"size": 50, "type": "pd-ssd",
+        "image": "https://www.googleapis.com/compute/v1/projects/debian-cloud/global/images/debian-12-bookworm-v20240110"}]}],
+      "network_interface": [{"network": "https://www.googleapis.com/compute/v1/projects/proj/global/networks/net"}],
+      "scheduling": [{"provisioning_model": "STANDARD"}]}},
+    {"address": "google_compute_instance.lonely", "mode": "managed", "type": "google_compute_instance", "values": {
+      "name": "lonely-1", "project": "proj", "zone": "us-central1-a", "machine_type": "e2-small"}},
+    {"address": "google_container_node_pool.np", "mode": "managed", "type": "google_container_node_pool", "values": {
+      "id": "projects/other/locations/us-east1/clusters/gke/nodePools/np", "name": "np", "project": "other",
+      "location": "us-east1", "cluster": "gke", "node_count": 2, "autoscaling": [],
+      "node_locations": ["us-east1-b"],
+      "node_config": [{"machine_type": "a2-highgpu-1g", "disk_size_gb": 100, "disk_type": "pd-standard", "image_type": "COS_CONTAINERD", "spot": false}]}},
+    {"address": "google_storage_bucket.b", "mode": "managed", "type": "google_storage_bucket", "values": {"name": "b"}}
+  ]}}
+}`
+
+func TestAdopt(t *testing.T) {
+	defer func(fs sourcereader.BaseFS) { sourcereader.ModuleFS = fs }(sourcereader.ModuleFS)
+	sourcereader.ModuleFS = os.DirFS("../..").(sourcereader.BaseFS)
+
+	resources, err := ParseState([]byte(testState))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Adopt(resources, "bp", "dep")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bp := res.Blueprint
+	wantVars := config.NewDict(map[string]cty.Value{
+		"deployment_name": cty.StringVal("dep"),
+		"project_id":      cty.StringVal("proj"),
+		"region":          cty.StringVal("us-central1"),
+		"zone":            cty.StringVal("us-central1-a"),
+	})
+	if diff := cmp.Diff(wantVars.Items(), bp.Vars.Items(), ctyCmp); diff != "" {
+		t.Errorf("vars diff (-want +got):\n%s", diff)
+	}
+
+	mods := bp.Groups[0].Modules
+	ids := []config.ModuleID{}
+	for _, m := range mods {
+		ids = append(ids, m.ID)
+	}
+	if diff := cmp.Diff([]config.ModuleID{"net", "home", "login", "np"}, ids); diff != "" {
+		t.Fatalf("module IDs diff (-want +got):\n%s", diff)
+	}
+
+	if got := mods[1].Use; len(got) != 1 || got[0] != "net" {
+		t.Errorf("filestore should use the network, got %v", got)
+	}
+	if got := mods[1].Settings.Get("size_gb"); !got.RawEquals(cty.NumberIntVal(1024)) {
+		t.Errorf("filestore size_gb: got %#v", got)
+	}
+	if mods[1].Settings.Has("connect_mode") { // equals to the default
+		t.Error("connect_mode should be omitted")
+	}
+	if got := mods[2].Settings.Get("instance_image"); !got.Equals(cty.ObjectVal(map[string]cty.Value{
+		"project": cty.StringVal("debian-cloud"),
+		"name":    cty.StringVal("debian-12-bookworm-v20240110"),
+	})).True() {
+		t.Errorf("instance_image: got %#v", got)
+	}
+	if got := mods[3].Settings.Get("project_id"); !got.RawEquals(cty.StringVal("other")) {
+		t.Errorf("node pool of another project should set project_id, got %#v", got)
+	}
+	if got := mods[3].Settings.Get("cluster_id"); !got.RawEquals(cty.StringVal("projects/other/locations/us-east1/clusters/gke")) {
+		t.Errorf("cluster_id: got %#v", got)
+	}
+
+	wantSkipped := []string{"google_compute_instance.lonely", "google_storage_bucket.b"}
+	for _, s := range wantSkipped {
+		if _, ok := res.Skipped[s]; !ok {
+			t.Errorf("%s should be skipped", s)
+		}
+	}
+	if len(res.Skipped) != len(wantSkipped) {
+		t.Errorf("unexpected skipped resources: %v", res.Skipped)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteImports(&buf, res.Imports); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`to = module.net.module.vpc.module.vpc.google_compute_network.network`,
+		`to = module.net.module.vpc.module.subnets.google_compute_subnetwork.subnetwork["us-central1/sub"]`,
+		`to = module.home.google_filestore_instance.filestore_instance`,
+		`to = module.login.google_compute_instance.compute_vm[0]`,
+		`to = module.np.google_container_node_pool.node_pool[0]`,
+		`id = "projects/proj/zones/us-central1-a/instances/login-0"`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("imports should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+var ctyCmp = cmp.Comparer(func(a, b cty.Value) bool { return a.RawEquals(b) })
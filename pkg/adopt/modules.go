@@ -0,0 +1,366 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adopt
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// attr returns the nested attribute, lists (and single nested blocks) are indexed by numbers.
+func attr(attrs map[string]any, path ...any) any {
+	var cur any = attrs
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			m, ok := cur.(map[string]any)
+			if !ok {
+				return nil
+			}
+			cur = m[k]
+		case int:
+			l, ok := cur.([]any)
+			if !ok || k >= len(l) {
+				return nil
+			}
+			cur = l[k]
+		}
+	}
+	return cur
+}
+
+func str(attrs map[string]any, path ...any) string {
+	s, _ := attr(attrs, path...).(string)
+	return s
+}
+
+// nonEmpty drops empty strings, e.g. to omit unset descriptions.
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+var selfLinkRe = regexp.MustCompile(`projects/[^/]+/.*$`)
+
+// selfLinkKey drops the API prefix of the self link, e.g.
+// `https://www.googleapis.com/compute/v1/projects/p/global/networks/n` -> `projects/p/global/networks/n`
+func selfLinkKey(s string) string {
+	if m := selfLinkRe.FindString(s); m != "" {
+		return m
+	}
+	return s
+}
+
+var zoneRe = regexp.MustCompile(`^[a-z]+-[a-z]+\d+-[a-z]$`)
+
+// setLocation sets the region or the zone of the candidate by the location.
+func (c *candidate) setLocation(loc string) {
+	if zoneRe.MatchString(loc) {
+		c.zone = loc
+		c.region = loc[:strings.LastIndex(loc, "-")]
+	} else {
+		c.region = loc
+	}
+}
+
+// rel is an address of the resource relative to the module.
+func rel(names ...string) hcl.Traversal {
+	res := hcl.Traversal{}
+	for _, n := range names {
+		res = append(res, hcl.TraverseAttr{Name: n})
+	}
+	return res
+}
+
+func indexed(t hcl.Traversal, key cty.Value) hcl.Traversal {
+	return append(t, hcl.TraverseIndex{Key: key})
+}
+
+func (a *adopter) networkByName(project string, name string) *candidate {
+	return a.networks[fmt.Sprintf("projects/%s/global/networks/%s", project, name)]
+}
+
+// adoptNetworks maps networks and their subnetworks to the `network/vpc` module.
+// Firewall rules, routers and NAT are not adopted and are disabled in the module.
+func (a *adopter) adoptNetworks(networks []Resource, subnetworks []Resource) {
+	a.networks = map[string]*candidate{}
+	subnets := map[string][]Resource{}
+	for _, s := range subnetworks {
+		k := selfLinkKey(str(s.Attributes, "network"))
+		subnets[k] = append(subnets[k], s)
+	}
+
+	for _, n := range networks {
+		at := n.Attributes
+		key := selfLinkKey(str(at, "self_link"))
+		if auto, _ := at["auto_create_subnetworks"].(bool); auto {
+			a.skip(n, "networks with auto-created subnetworks are not supported")
+			continue
+		}
+		c := &candidate{
+			name:    str(at, "name"),
+			source:  "modules/network/vpc",
+			project: str(at, "project"),
+			settings: map[string]any{
+				"network_name":            str(at, "name"),
+				"mtu":                     at["mtu"],
+				"network_routing_mode":    at["routing_mode"],
+				"network_description":     nonEmpty(str(at, "description")),
+				"enable_cloud_router":     false,
+				"enable_cloud_nat":        false,
+				"enable_iap_ssh_ingress":  false,
+				"enable_iap_rdp_ingress":  false,
+				"enable_internal_traffic": false,
+			},
+			imports: []Import{{rel("module", "vpc", "module", "vpc", "google_compute_network", "network"), str(at, "id")}},
+		}
+
+		list := []any{}
+		secondary := []any{}
+		for _, s := range subnets[key] {
+			sa := s.Attributes
+			name, region := str(sa, "name"), str(sa, "region")
+			if c.region == "" {
+				c.region = region
+			}
+			list = append(list, map[string]any{
+				"subnet_name":           name,
+				"subnet_region":         region,
+				"subnet_ip":             str(sa, "ip_cidr_range"),
+				"subnet_private_access": sa["private_ip_google_access"],
+				"description":           nonEmpty(str(sa, "description")),
+			})
+			if ranges, _ := sa["secondary_ip_range"].([]any); len(ranges) > 0 {
+				rs := []any{}
+				for i := range ranges {
+					rs = append(rs, map[string]any{
+						"range_name":    str(sa, "secondary_ip_range", i, "range_name"),
+						"ip_cidr_range": str(sa, "secondary_ip_range", i, "ip_cidr_range"),
+					})
+				}
+				secondary = append(secondary, map[string]any{"subnetwork_name": name, "ranges": rs})
+			}
+			to := indexed(rel("module", "vpc", "module", "subnets", "google_compute_subnetwork", "subnetwork"),
+				cty.StringVal(fmt.Sprintf("%s/%s", region, name)))
+			c.imports = append(c.imports, Import{to, str(sa, "id")})
+		}
+		delete(subnets, key)
+		if len(list) > 0 {
+			c.settings["subnetworks"] = dropNils(list)
+		}
+		if len(secondary) > 0 {
+			c.settings["secondary_ranges_list"] = secondary
+		}
+		a.networks[key] = c
+		a.add(c)
+	}
+
+	for _, ss := range subnets {
+		for _, s := range ss {
+			a.skip(s, "network %q of the subnetwork is not adopted", str(s.Attributes, "network"))
+		}
+	}
+}
+
+// dropNils removes nil values of maps in the list.
+func dropNils(list []any) []any {
+	for _, e := range list {
+		if m, ok := e.(map[string]any); ok {
+			for k, v := range m {
+				if v == nil {
+					delete(m, k)
+				}
+			}
+		}
+	}
+	return list
+}
+
+// adoptFilestores maps Filestore instances to the `file-system/filestore` module.
+func (a *adopter) adoptFilestores(instances []Resource) {
+	for _, r := range instances {
+		at := r.Attributes
+		c := &candidate{
+			name:    str(at, "name"),
+			source:  "modules/file-system/filestore",
+			project: str(at, "project"),
+			settings: map[string]any{
+				"name":                 str(at, "name"),
+				"filestore_tier":       at["tier"],
+				"size_gb":              attr(at, "file_shares", 0, "capacity_gb"),
+				"filestore_share_name": attr(at, "file_shares", 0, "name"),
+				"connect_mode":         attr(at, "networks", 0, "connect_mode"),
+				"protocol":             at["protocol"],
+				"description":          nonEmpty(str(at, "description")),
+			},
+			imports: []Import{{rel("google_filestore_instance", "filestore_instance"), str(at, "id")}},
+		}
+		loc := str(at, "location")
+		if loc == "" { // older versions of the provider
+			loc = str(at, "zone")
+		}
+		c.setLocation(loc)
+
+		net := str(at, "networks", 0, "network")
+		if n := a.networkByName(c.project, net); n != nil {
+			c.use = append(c.use, n)
+		} else if net != "" {
+			c.settings["network_id"] = fmt.Sprintf("projects/%s/global/networks/%s", c.project, net)
+		}
+		a.add(c)
+	}
+}
+
+var instanceNameRe = regexp.MustCompile(`^(.+)-(\d+)$`)
+
+type instanceSet struct {
+	prefix    string
+	instances map[int]Resource
+}
+
+// adoptInstances maps VMs named `<name_prefix>-<index>` to the `compute/vm-instance` module,
+// VMs sharing the prefix, the zone and the machine type are adopted by a single module.
+func (a *adopter) adoptInstances(instances []Resource) {
+	sets := map[string]*instanceSet{}
+	for _, r := range instances {
+		at := r.Attributes
+		m := instanceNameRe.FindStringSubmatch(str(at, "name"))
+		if m == nil {
+			a.skip(r, "name %q doesn't follow `<name_prefix>-<index>` naming of the vm-instance module", str(at, "name"))
+			continue
+		}
+		idx, _ := strconv.Atoi(m[2])
+		key := strings.Join([]string{m[1], str(at, "project"), str(at, "zone"), str(at, "machine_type")}, "|")
+		if sets[key] == nil {
+			sets[key] = &instanceSet{prefix: m[1], instances: map[int]Resource{}}
+		}
+		sets[key].instances[idx] = r
+	}
+
+	keys := []string{}
+	for k := range sets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		a.adoptInstanceSet(*sets[k])
+	}
+}
+
+var imageRe = regexp.MustCompile(`projects/([^/]+)/global/images/(family/)?([^/]+)$`)
+
+func imageSetting(image string) any {
+	m := imageRe.FindStringSubmatch(image)
+	if m == nil {
+		return nil
+	}
+	if m[2] != "" {
+		return map[string]any{"project": m[1], "family": m[3]}
+	}
+	return map[string]any{"project": m[1], "name": m[3]}
+}
+
+func (a *adopter) adoptInstanceSet(s instanceSet) {
+	for i := 0; i < len(s.instances); i++ {
+		if _, ok := s.instances[i]; !ok {
+			for _, r := range s.instances {
+				a.skip(r, "indices of instances %q must be consecutive from 0", s.prefix)
+			}
+			return
+		}
+	}
+
+	at := s.instances[0].Attributes
+	c := &candidate{
+		name:    s.prefix,
+		source:  "modules/compute/vm-instance",
+		project: str(at, "project"),
+		settings: map[string]any{
+			"name_prefix":        s.prefix,
+			"instance_count":     len(s.instances),
+			"machine_type":       at["machine_type"],
+			"disk_size_gb":       attr(at, "boot_disk", 0, "initialize_params", 0, "size"),
+			"disk_type":          attr(at, "boot_disk", 0, "initialize_params", 0, "type"),
+			"instance_image":     imageSetting(str(at, "boot_disk", 0, "initialize_params", 0, "image")),
+			"provisioning_model": nonEmpty(str(at, "scheduling", 0, "provisioning_model")),
+		},
+	}
+	c.setLocation(str(at, "zone"))
+
+	net := selfLinkKey(str(at, "network_interface", 0, "network"))
+	if n, ok := a.networks[net]; ok {
+		c.use = append(c.use, n)
+	} else {
+		c.settings["network_self_link"] = nonEmpty(str(at, "network_interface", 0, "network"))
+		c.settings["subnetwork_self_link"] = nonEmpty(str(at, "network_interface", 0, "subnetwork"))
+	}
+
+	idx := make([]int, 0, len(s.instances))
+	for i := range s.instances {
+		idx = append(idx, i)
+	}
+	slices.Sort(idx)
+	for _, i := range idx {
+		to := indexed(rel("google_compute_instance", "compute_vm"), cty.NumberIntVal(int64(i)))
+		c.imports = append(c.imports, Import{to, str(s.instances[i].Attributes, "id")})
+	}
+	a.add(c)
+}
+
+// adoptNodePools maps GKE node pools to the `compute/gke-node-pool` module,
+// the cluster is referred by its ID.
+func (a *adopter) adoptNodePools(pools []Resource) {
+	for _, r := range pools {
+		at := r.Attributes
+		c := &candidate{
+			name:    str(at, "name"),
+			source:  "modules/compute/gke-node-pool",
+			project: str(at, "project"),
+			settings: map[string]any{
+				"name":         str(at, "name"),
+				"machine_type": attr(at, "node_config", 0, "machine_type"),
+				"zones":        at["node_locations"],
+				"disk_size_gb": attr(at, "node_config", 0, "disk_size_gb"),
+				"disk_type":    attr(at, "node_config", 0, "disk_type"),
+				"image_type":   attr(at, "node_config", 0, "image_type"),
+				"spot":         attr(at, "node_config", 0, "spot"),
+			},
+			imports: []Import{{indexed(rel("google_container_node_pool", "node_pool"), cty.NumberIntVal(0)), str(at, "id")}},
+		}
+		if as, _ := at["autoscaling"].([]any); len(as) > 0 {
+			c.settings["autoscaling_total_min_nodes"] = attr(at, "autoscaling", 0, "total_min_node_count")
+			c.settings["autoscaling_total_max_nodes"] = attr(at, "autoscaling", 0, "total_max_node_count")
+		} else {
+			c.settings["static_node_count"] = at["node_count"]
+		}
+
+		loc, cluster := str(at, "location"), str(at, "cluster")
+		if strings.HasPrefix(cluster, "projects/") {
+			c.settings["cluster_id"] = cluster
+		} else {
+			c.settings["cluster_id"] = fmt.Sprintf("projects/%s/locations/%s/clusters/%s", c.project, loc, cluster)
+		}
+		a.add(c)
+	}
+}
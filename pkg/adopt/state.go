@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Resource is a managed resource found in a Terraform state.
+type Resource struct {
+	Address    string // e.g. `module.net.google_compute_network.main`
+	Type       string
+	Attributes map[string]any
+}
+
+// String returns a string representation of the resource.
+func (r Resource) String() string {
+	return r.Address
+}
+
+// stateV4 is a Terraform state file, see `terraform state pull`
+type stateV4 struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// showModule is a module of the `terraform show -json` output
+type showModule struct {
+	Resources []struct {
+		Address string         `json:"address"`
+		Mode    string         `json:"mode"`
+		Type    string         `json:"type"`
+		Values  map[string]any `json:"values"`
+	} `json:"resources"`
+	ChildModules []showModule `json:"child_modules"`
+}
+
+type showOutput struct {
+	FormatVersion string `json:"format_version"`
+	Values        *struct {
+		RootModule showModule `json:"root_module"`
+	} `json:"values"`
+}
+
+// ReadState reads managed resources from a Terraform state file
+// or from the `terraform show -json` output.
+func ReadState(path string) ([]Resource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ParseState(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return res, nil
+}
+
+// ParseState parses managed resources from a Terraform state
+// or from the `terraform show -json` output.
+func ParseState(data []byte) ([]Resource, error) {
+	var show showOutput
+	if err := json.Unmarshal(data, &show); err != nil {
+		return nil, err
+	}
+	if show.FormatVersion != "" {
+		if show.Values == nil { // empty state
+			return []Resource{}, nil
+		}
+		return showResources(show.Values.RootModule), nil
+	}
+
+	var state stateV4
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("unsupported state version %d, only version 4 and `terraform show -json` output are supported", state.Version)
+	}
+	res := []Resource{}
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		addr := fmt.Sprintf("%s.%s", r.Type, r.Name)
+		if r.Module != "" {
+			addr = fmt.Sprintf("%s.%s", r.Module, addr)
+		}
+		for _, inst := range r.Instances {
+			a := addr
+			switch k := inst.IndexKey.(type) {
+			case string:
+				a = fmt.Sprintf("%s[%q]", addr, k)
+			case float64:
+				a = fmt.Sprintf("%s[%d]", addr, int(k))
+			}
+			res = append(res, Resource{Address: a, Type: r.Type, Attributes: inst.Attributes})
+		}
+	}
+	return res, nil
+}
+
+func showResources(m showModule) []Resource {
+	res := []Resource{}
+	for _, r := range m.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		res = append(res, Resource{Address: r.Address, Type: r.Type, Attributes: r.Values})
+	}
+	for _, c := range m.ChildModules {
+		res = append(res, showResources(c)...)
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adopt
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseStateV4(t *testing.T) {
+	data := []byte(`{
+  "version": 4,
+  "resources": [
+    {"mode": "data", "type": "google_project", "name": "p", "instances": [{"attributes": {"id": "p"}}]},
+    {"mode": "managed", "type": "google_compute_network", "name": "net", "instances": [{"attributes": {"name": "net"}}]},
+    {"module": "module.vm", "mode": "managed", "type": "google_compute_instance", "name": "vm", "instances": [
+      {"index_key": 0, "attributes": {"name": "vm-0"}},
+      {"index_key": "a", "attributes": {"name": "vm-a"}}
+    ]}
+  ]
+}`)
+	got, err := ParseState(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Resource{
+		{"google_compute_network.net", "google_compute_network", map[string]any{"name": "net"}},
+		{"module.vm.google_compute_instance.vm[0]", "google_compute_instance", map[string]any{"name": "vm-0"}},
+		{`module.vm.google_compute_instance.vm["a"]`, "google_compute_instance", map[string]any{"name": "vm-a"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseStateShow(t *testing.T) {
+	data := []byte(`{
+  "format_version": "1.0",
+  "values": {"root_module": {
+    "resources": [
+      {"address": "google_compute_network.net", "mode": "managed", "type": "google_compute_network", "values": {"name": "net"}},
+      {"address": "data.google_project.p", "mode": "data", "type": "google_project", "values": {}}
+    ],
+    "child_modules": [{"resources": [
+      {"address": "module.fs.google_filestore_instance.fs", "mode": "managed", "type": "google_filestore_instance", "values": {"name": "fs"}}
+    ]}]
+  }}
+}`)
+	got, err := ParseState(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Resource{
+		{"google_compute_network.net", "google_compute_network", map[string]any{"name": "net"}},
+		{"module.fs.google_filestore_instance.fs", "google_filestore_instance", map[string]any{"name": "fs"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := ParseState([]byte(`{"version": 3}`)); err == nil {
+		t.Error("expected error for unsupported state version")
+	}
+}
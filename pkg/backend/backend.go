@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backend bootstraps Cloud Storage buckets holding Terraform state of
+// deployments, so the first deployment in a project needs no manual steps.
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// StateRole is granted on the bucket to the identity creating the deployment
+const StateRole = "roles/storage.objectAdmin"
+
+// RoleLabel is set on buckets created by Bootstrap
+const RoleLabel = "ghpc_role"
+
+// DefaultBucket is the name of the state bucket used if none is given
+func DefaultBucket(project string) string {
+	return project + "-gcluster-tfstate"
+}
+
+// Bucket describes the state bucket to bootstrap
+type Bucket struct {
+	Name    string
+	Project string
+	// location of a new bucket, a region or a multi-region
+	Location string
+}
+
+// Member returns the IAM member of the account, see `gcloud auth list`.
+// Returns empty string if account is not an email, e.g. a local username.
+func Member(account string) string {
+	if !strings.Contains(account, "@") {
+		return ""
+	}
+	if strings.HasSuffix(account, ".gserviceaccount.com") {
+		return "serviceAccount:" + account
+	}
+	return "user:" + account
+}
+
+// Bootstrap makes sure the bucket exists and is fit to hold Terraform state:
+// object versioning to recover earlier states, uniform bucket-level access
+// and enforced public access prevention. State locking needs no setup, the
+// gcs backend uses lock objects. Existing buckets are reused, versioning is
+// enabled if it is off. Returns whether the bucket was created.
+func Bootstrap(c Client, b Bucket, members []string) (bool, error) {
+	created := false
+	attrs, err := c.Bucket(b.Name)
+	switch {
+	case errors.Is(err, storage.ErrBucketNotExist):
+		err = c.CreateBucket(b.Project, &storage.BucketAttrs{
+			Name:                     b.Name,
+			Location:                 b.Location,
+			VersioningEnabled:        true,
+			UniformBucketLevelAccess: storage.UniformBucketLevelAccess{Enabled: true},
+			PublicAccessPrevention:   storage.PublicAccessPreventionEnforced,
+			Labels:                   map[string]string{RoleLabel: "terraform-state"},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to create state bucket %q: %w", b.Name, err)
+		}
+		created = true
+	case err != nil:
+		return false, fmt.Errorf("failed to get state bucket %q: %w", b.Name, err)
+	case !attrs.VersioningEnabled:
+		if err := c.UpdateBucket(b.Name, storage.BucketAttrsToUpdate{VersioningEnabled: true}); err != nil {
+			return false, fmt.Errorf("failed to enable versioning of state bucket %q: %w", b.Name, err)
+		}
+	}
+
+	for _, m := range members {
+		if err := c.AddBucketMember(b.Name, StateRole, m); err != nil {
+			return created, fmt.Errorf("failed to grant %s on state bucket %q to %s: %w", StateRole, b.Name, m, err)
+		}
+	}
+	return created, nil
+}
+
+// TerraformBackend returns backend defaults storing state in the bucket,
+// per-group prefixes are added by expansion, see config.Blueprint.UseBackendDefaults
+func TerraformBackend(bucket string) config.TerraformBackend {
+	return config.TerraformBackend{
+		Type: "gcs",
+		Configuration: config.NewDict(map[string]cty.Value{
+			"bucket": cty.StringVal(bucket)}),
+	}
+}
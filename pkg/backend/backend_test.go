@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeClient struct {
+	buckets map[string]*storage.BucketAttrs
+	members map[string][]string
+	project string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{buckets: map[string]*storage.BucketAttrs{}, members: map[string][]string{}}
+}
+
+func (f *fakeClient) Bucket(name string) (*storage.BucketAttrs, error) {
+	if a, ok := f.buckets[name]; ok {
+		return a, nil
+	}
+	return nil, storage.ErrBucketNotExist
+}
+
+func (f *fakeClient) CreateBucket(project string, attrs *storage.BucketAttrs) error {
+	f.project = project
+	f.buckets[attrs.Name] = attrs
+	return nil
+}
+
+func (f *fakeClient) UpdateBucket(name string, attrs storage.BucketAttrsToUpdate) error {
+	f.buckets[name].VersioningEnabled = attrs.VersioningEnabled.(bool)
+	return nil
+}
+
+func (f *fakeClient) AddBucketMember(name string, role string, member string) error {
+	f.members[name] = append(f.members[name], role+" "+member)
+	return nil
+}
+
+func TestBootstrapCreates(t *testing.T) {
+	f := newFakeClient()
+	b := Bucket{Name: "pail", Project: "apple", Location: "us-central1"}
+	created, err := Bootstrap(f, b, []string{"user:ann@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Error("expected bucket to be created")
+	}
+	got := f.buckets["pail"]
+	if f.project != "apple" || got.Location != "us-central1" {
+		t.Errorf("got project %q, location %q", f.project, got.Location)
+	}
+	if !got.VersioningEnabled || !got.UniformBucketLevelAccess.Enabled || got.PublicAccessPrevention != storage.PublicAccessPreventionEnforced {
+		t.Errorf("bucket is not fit for state: %#v", got)
+	}
+	if diff := cmp.Diff([]string{"roles/storage.objectAdmin user:ann@example.com"}, f.members["pail"]); diff != "" {
+		t.Errorf("members (-want +got):\n%s", diff)
+	}
+}
+
+func TestBootstrapReusesBucket(t *testing.T) {
+	f := newFakeClient()
+	f.buckets["pail"] = &storage.BucketAttrs{Name: "pail"}
+	created, err := Bootstrap(f, Bucket{Name: "pail", Project: "apple"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Error("expected existing bucket to be reused")
+	}
+	if !f.buckets["pail"].VersioningEnabled {
+		t.Error("expected versioning to be enabled")
+	}
+}
+
+type brokenClient struct{ fakeClient }
+
+func (brokenClient) Bucket(string) (*storage.BucketAttrs, error) {
+	return nil, errors.New("permission denied")
+}
+
+func TestBootstrapError(t *testing.T) {
+	if _, err := Bootstrap(&brokenClient{}, Bucket{Name: "pail"}, nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMember(t *testing.T) {
+	for account, want := range map[string]string{
+		"ann@example.com":                     "user:ann@example.com",
+		"sa@apple.iam.gserviceaccount.com":    "serviceAccount:sa@apple.iam.gserviceaccount.com",
+		"ann":                                 "",
+		"":                                    "",
+		"robot@developer.gserviceaccount.com": "serviceAccount:robot@developer.gserviceaccount.com",
+	} {
+		if got := Member(account); got != want {
+			t.Errorf("Member(%q) = %q, want %q", account, got, want)
+		}
+	}
+}
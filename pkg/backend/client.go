@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/iam"
+	"cloud.google.com/go/storage"
+)
+
+// Client is the subset of Cloud Storage API used by Bootstrap,
+// tests use a fake implementation.
+type Client interface {
+	// Bucket returns storage.ErrBucketNotExist if the bucket does not exist
+	Bucket(name string) (*storage.BucketAttrs, error)
+	CreateBucket(project string, attrs *storage.BucketAttrs) error
+	UpdateBucket(name string, attrs storage.BucketAttrsToUpdate) error
+	AddBucketMember(name string, role string, member string) error
+}
+
+// NewClient returns a Client of Cloud Storage using default credentials
+func NewClient(ctx context.Context) (Client, error) {
+	c, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+	return &gcsClient{ctx: ctx, c: c}, nil
+}
+
+type gcsClient struct {
+	ctx context.Context
+	c   *storage.Client
+}
+
+func (g *gcsClient) Bucket(name string) (*storage.BucketAttrs, error) {
+	return g.c.Bucket(name).Attrs(g.ctx)
+}
+
+func (g *gcsClient) CreateBucket(project string, attrs *storage.BucketAttrs) error {
+	return g.c.Bucket(attrs.Name).Create(g.ctx, project, attrs)
+}
+
+func (g *gcsClient) UpdateBucket(name string, attrs storage.BucketAttrsToUpdate) error {
+	_, err := g.c.Bucket(name).Update(g.ctx, attrs)
+	return err
+}
+
+func (g *gcsClient) AddBucketMember(name string, role string, member string) error {
+	h := g.c.Bucket(name).IAM()
+	p, err := h.Policy(g.ctx)
+	if err != nil {
+		return err
+	}
+	if p.HasRole(member, iam.RoleName(role)) {
+		return nil
+	}
+	p.Add(member, iam.RoleName(role))
+	return h.SetPolicy(g.ctx, p)
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cleanup
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/filestore/apiv1/filestorepb"
+)
+
+// Kinds of resources
+const (
+	ForwardingRule       = "forwarding_rule"
+	NetworkEndpointGroup = "network_endpoint_group"
+	Route                = "route"
+	Firewall             = "firewall"
+	Disk                 = "disk"
+	FilestoreInstance    = "filestore_instance"
+)
+
+func init() {
+	// load balancers created by GKE are matched by the network of their forwarding rules
+	Register(Cleaner{
+		Kind: ForwardingRule,
+		List: func(c Client, t Target) ([]Resource, error) {
+			rules, err := c.ListForwardingRules(t.Project)
+			if err != nil {
+				return nil, err
+			}
+			res := []Resource{}
+			for _, r := range rules {
+				if t.hasLabel(r.Labels) || t.inNetwork(r.Network) {
+					res = append(res, Resource{Kind: ForwardingRule, Name: r.Name, Location: location(r.Region)})
+				}
+			}
+			return res, nil
+		},
+		Delete: func(c Client, t Target, r Resource) error {
+			return c.DeleteForwardingRule(t.Project, r.Location, r.Name)
+		},
+	})
+
+	Register(Cleaner{
+		Kind:  NetworkEndpointGroup,
+		After: []string{ForwardingRule},
+		List: func(c Client, t Target) ([]Resource, error) {
+			negs, err := c.ListNetworkEndpointGroups(t.Project)
+			if err != nil {
+				return nil, err
+			}
+			res := []Resource{}
+			for _, n := range negs {
+				if t.inNetwork(n.Network) && n.Zone != "" {
+					res = append(res, Resource{Kind: NetworkEndpointGroup, Name: n.Name, Location: location(n.Zone)})
+				}
+			}
+			return res, nil
+		},
+		Delete: func(c Client, t Target, r Resource) error {
+			return c.DeleteNetworkEndpointGroup(t.Project, r.Location, r.Name)
+		},
+	})
+
+	Register(Cleaner{
+		Kind: Route,
+		List: func(c Client, t Target) ([]Resource, error) {
+			routes, err := c.ListRoutes(t.Project)
+			if err != nil {
+				return nil, err
+			}
+			res := []Resource{}
+			for _, r := range routes {
+				// default routes are deleted with the network
+				if t.inNetwork(r.Network) && !strings.HasPrefix(r.Name, "default-route-") {
+					res = append(res, Resource{Kind: Route, Name: r.Name})
+				}
+			}
+			return res, nil
+		},
+		Delete: func(c Client, t Target, r Resource) error {
+			return c.DeleteRoute(t.Project, r.Name)
+		},
+	})
+
+	Register(Cleaner{
+		Kind:  Firewall,
+		After: []string{ForwardingRule},
+		List: func(c Client, t Target) ([]Resource, error) {
+			rules, err := c.ListFirewalls(t.Project)
+			if err != nil {
+				return nil, err
+			}
+			res := []Resource{}
+			for _, r := range rules {
+				if t.inNetwork(r.Network) {
+					res = append(res, Resource{Kind: Firewall, Name: r.Name})
+				}
+			}
+			return res, nil
+		},
+		Delete: func(c Client, t Target, r Resource) error {
+			return c.DeleteFirewall(t.Project, r.Name)
+		},
+	})
+
+	// disks of deleted node pools, disks attached to instances are left to Terraform
+	Register(Cleaner{
+		Kind:     Disk,
+		Stateful: true,
+		List: func(c Client, t Target) ([]Resource, error) {
+			disks, err := c.ListDisks(t.Project)
+			if err != nil {
+				return nil, err
+			}
+			res := []Resource{}
+			for _, d := range disks {
+				if t.hasLabel(d.Labels) && len(d.Users) == 0 && d.Zone != "" {
+					res = append(res, Resource{Kind: Disk, Name: d.Name, Location: location(d.Zone)})
+				}
+			}
+			return res, nil
+		},
+		Delete: func(c Client, t Target, r Resource) error {
+			return c.DeleteDisk(t.Project, r.Location, r.Name)
+		},
+	})
+
+	// only instances stuck in deletion or failed ones, they are deleted again
+	// with force; healthy instances are left to Terraform
+	Register(Cleaner{
+		Kind:     FilestoreInstance,
+		Stateful: true,
+		List: func(c Client, t Target) ([]Resource, error) {
+			instances, err := c.ListFilestoreInstances(t.Project)
+			if err != nil {
+				return nil, err
+			}
+			res := []Resource{}
+			for _, inst := range instances {
+				// projects/PROJECT/locations/LOCATION/instances/NAME
+				parts := strings.Split(inst.Name, "/")
+				broken := inst.State == filestorepb.Instance_DELETING || inst.State == filestorepb.Instance_ERROR
+				if t.hasLabel(inst.Labels) && broken && len(parts) == 6 {
+					res = append(res, Resource{Kind: FilestoreInstance, Name: parts[5], Location: parts[3]})
+				}
+			}
+			return res, nil
+		},
+		Delete: func(c Client, t Target, r Resource) error {
+			return c.DeleteFilestoreInstance(fmt.Sprintf("projects/%s/locations/%s/instances/%s", t.Project, r.Location, r.Name))
+		},
+	})
+}
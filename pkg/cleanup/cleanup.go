@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cleanup discovers and deletes cloud resources left behind by failed
+// destroys of deployments, e.g. load balancers created by GKE or disks of
+// deleted node pools. Resources of every kind are handled by a Cleaner
+// registered by the kind.
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+)
+
+// DeploymentLabel is the label of resources of a deployment, see blueprint vars.labels
+const DeploymentLabel = "ghpc_deployment"
+
+// ModuleLabel is the label of resources set by toolkit modules to the name of the module
+const ModuleLabel = "ghpc_module"
+
+// Resource is a leftover cloud resource
+type Resource struct {
+	Kind string
+	Name string
+	// zone or region of the resource, empty for global resources
+	Location string
+}
+
+func (r Resource) String() string {
+	if r.Location == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Location, r.Name)
+}
+
+// Target identifies resources of a deployment. Resources are matched by the
+// deployment label, resources that don't support labels by their network.
+type Target struct {
+	Project    string
+	Deployment string
+	// self links of networks of the deployment
+	Networks []string
+	// names of modules of the selected deployment groups, see ModuleLabel;
+	// nil if all groups of the deployment are selected
+	Modules []string
+}
+
+func (t Target) hasLabel(labels map[string]string) bool {
+	if labels[DeploymentLabel] != t.Deployment {
+		return false
+	}
+	return t.Modules == nil || slices.Contains(t.Modules, labels[ModuleLabel])
+}
+
+func (t Target) inNetwork(network string) bool {
+	return network != "" && slices.Contains(t.Networks, network)
+}
+
+// Cleaner discovers and deletes leftover resources of a single kind
+type Cleaner struct {
+	Kind string
+	// kinds of resources that must be deleted before resources of this kind,
+	// e.g. forwarding rules referring to them
+	After []string
+	// resources of stateful kinds hold data, e.g. disks, they are never
+	// deleted without confirmation
+	Stateful bool
+	List     func(c Client, t Target) ([]Resource, error)
+	Delete   func(c Client, t Target, r Resource) error
+}
+
+var registry = map[string]Cleaner{}
+
+// Register adds the cleaner of the kind to the registry
+func Register(c Cleaner) {
+	if _, ok := registry[c.Kind]; ok {
+		panic(fmt.Sprintf("cleaner of kind %q is already registered", c.Kind))
+	}
+	registry[c.Kind] = c
+}
+
+// Kinds returns registered kinds in the order their resources are deleted,
+// the first kind in alphabetical order whose dependencies are deleted goes next
+func Kinds() []string {
+	kinds := []string{}
+	for k := range registry {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	res := []string{}
+	done := map[string]bool{}
+	ready := func(k string) bool {
+		for _, a := range registry[k].After {
+			if _, known := registry[a]; known && !done[a] {
+				return false
+			}
+		}
+		return true
+	}
+	for len(res) < len(kinds) {
+		i := slices.IndexFunc(kinds, func(k string) bool { return !done[k] && ready(k) })
+		if i == -1 {
+			panic("cleaners have cyclic dependencies")
+		}
+		res, done[kinds[i]] = append(res, kinds[i]), true
+	}
+	return res
+}
+
+// Stateful reports whether the resource holds data, see Cleaner.Stateful
+func Stateful(r Resource) bool {
+	return registry[r.Kind].Stateful
+}
+
+// Discover lists leftover resources of the deployment in the order they are to
+// be deleted
+func Discover(c Client, t Target) ([]Resource, error) {
+	res := []Resource{}
+	for _, k := range Kinds() {
+		rs, err := registry[k].List(c, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources of kind %s: %w", k, err)
+		}
+		res = append(res, rs...)
+	}
+	return res, nil
+}
+
+// Delete deletes resources in the given order. Failures don't stop deletion of
+// other resources, all of them are returned.
+func Delete(c Client, t Target, resources []Resource) error {
+	errs := []error{}
+	for _, r := range resources {
+		cl, ok := registry[r.Kind]
+		if !ok {
+			errs = append(errs, fmt.Errorf("no cleaner of kind %q for %s", r.Kind, r))
+			continue
+		}
+		if err := cl.Delete(c, t, r); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", r, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FindNetworks returns self links of existing networks with the given names,
+// e.g. networks in the Terraform state of the deployment
+func FindNetworks(c Client, project string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+	networks, err := c.ListNetworks(project)
+	if err != nil {
+		return nil, err
+	}
+	res := []string{}
+	for _, n := range networks {
+		if slices.Contains(names, n.Name) {
+			res = append(res, n.SelfLink)
+		}
+	}
+	return res, nil
+}
+
+// location returns the last segment of zone or region URLs
+func location(url string) string {
+	if url == "" {
+		return ""
+	}
+	return path.Base(url)
+}
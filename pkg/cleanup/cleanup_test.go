@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cleanup
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/filestore/apiv1/filestorepb"
+	compute "google.golang.org/api/compute/v1"
+)
+
+const (
+	net   = "https://www.googleapis.com/compute/v1/projects/p/global/networks/hpc-net"
+	other = "https://www.googleapis.com/compute/v1/projects/p/global/networks/default"
+	zone  = "https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a"
+)
+
+var labels = map[string]string{DeploymentLabel: "hpc", ModuleLabel: "gke-node-pool"}
+
+type fakeClient struct {
+	deleted []string
+	failOn  string
+}
+
+func (f *fakeClient) del(kind string, name string) error {
+	if name == f.failOn {
+		return errors.New("boom")
+	}
+	f.deleted = append(f.deleted, kind+" "+name)
+	return nil
+}
+
+func (f *fakeClient) ListNetworks(project string) ([]*compute.Network, error) {
+	return []*compute.Network{
+		{Name: "hpc-net", SelfLink: net},
+		{Name: "default", SelfLink: other},
+		{Name: "hpc-net-2", SelfLink: net + "-2"},
+	}, nil
+}
+
+func (f *fakeClient) ListForwardingRules(project string) ([]*compute.ForwardingRule, error) {
+	return []*compute.ForwardingRule{
+		{Name: "k8s-lb", Network: net, Region: "https://www.googleapis.com/compute/v1/projects/p/regions/us-central1"},
+		{Name: "labeled", Labels: labels},
+		{Name: "unrelated", Network: other},
+	}, nil
+}
+
+func (f *fakeClient) DeleteForwardingRule(project string, region string, name string) error {
+	return f.del(ForwardingRule, name)
+}
+
+func (f *fakeClient) ListNetworkEndpointGroups(project string) ([]*compute.NetworkEndpointGroup, error) {
+	return []*compute.NetworkEndpointGroup{{Name: "k8s-neg", Network: net, Zone: zone}}, nil
+}
+
+func (f *fakeClient) DeleteNetworkEndpointGroup(project string, zone string, name string) error {
+	return f.del(NetworkEndpointGroup, name)
+}
+
+func (f *fakeClient) ListRoutes(project string) ([]*compute.Route, error) {
+	return []*compute.Route{{Name: "default-route-123", Network: net}, {Name: "peering", Network: net}}, nil
+}
+
+func (f *fakeClient) DeleteRoute(project string, name string) error {
+	return f.del(Route, name)
+}
+
+func (f *fakeClient) ListFirewalls(project string) ([]*compute.Firewall, error) {
+	return []*compute.Firewall{{Name: "allow-ssh", Network: net}, {Name: "default-allow", Network: other}}, nil
+}
+
+func (f *fakeClient) DeleteFirewall(project string, name string) error {
+	return f.del(Firewall, name)
+}
+
+func (f *fakeClient) ListDisks(project string) ([]*compute.Disk, error) {
+	return []*compute.Disk{
+		{Name: "pool-disk", Labels: labels, Zone: zone},
+		{Name: "attached", Labels: labels, Zone: zone, Users: []string{"vm"}},
+		{Name: "foreign", Zone: zone},
+	}, nil
+}
+
+func (f *fakeClient) DeleteDisk(project string, zone string, name string) error {
+	return f.del(Disk, zone+"/"+name)
+}
+
+func (f *fakeClient) ListFilestoreInstances(project string) ([]*filestorepb.Instance, error) {
+	return []*filestorepb.Instance{
+		{Name: "projects/p/locations/us-central1-a/instances/homefs", Labels: labels, State: filestorepb.Instance_DELETING},
+		{Name: "projects/p/locations/us-central1-a/instances/healthy", Labels: labels, State: filestorepb.Instance_READY},
+		{Name: "projects/p/locations/us-central1-a/instances/other"},
+	}, nil
+}
+
+func (f *fakeClient) DeleteFilestoreInstance(name string) error {
+	return f.del(FilestoreInstance, name)
+}
+
+func TestKinds(t *testing.T) {
+	kinds := Kinds()
+	if len(kinds) != len(registry) {
+		t.Fatalf("want all %d kinds, got %v", len(registry), kinds)
+	}
+	for _, k := range kinds {
+		for _, a := range registry[k].After {
+			if slices.Index(kinds, a) > slices.Index(kinds, k) {
+				t.Errorf("%s must precede %s in %v", a, k, kinds)
+			}
+		}
+	}
+}
+
+func TestDiscoverAndDelete(t *testing.T) {
+	c := &fakeClient{}
+	networks, err := FindNetworks(c, "p", []string{"hpc-net"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(networks, []string{net}) {
+		t.Errorf("want exactly network hpc-net, got %v", networks)
+	}
+	target := Target{Project: "p", Deployment: "hpc", Networks: networks}
+
+	found, err := Discover(c, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, r := range found {
+		names = append(names, r.String())
+	}
+	want := []string{
+		"disk us-central1-a/pool-disk",
+		"filestore_instance us-central1-a/homefs",
+		"forwarding_rule us-central1/k8s-lb",
+		"forwarding_rule labeled",
+		"firewall allow-ssh",
+		"network_endpoint_group us-central1-a/k8s-neg",
+		"route peering",
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(names, "\n"), strings.Join(want, "\n"))
+	}
+
+	c.failOn = "labeled"
+	err = Delete(c, target, found)
+	if err == nil || !strings.Contains(err.Error(), "forwarding_rule labeled") {
+		t.Errorf("want error of forwarding rule, got %v", err)
+	}
+	if len(c.deleted) != len(found)-1 {
+		t.Errorf("want other resources deleted, got %v", c.deleted)
+	}
+	if !slices.Contains(c.deleted, "filestore_instance projects/p/locations/us-central1-a/instances/homefs") {
+		t.Errorf("want filestore instance deleted, got %v", c.deleted)
+	}
+}
+
+func TestDiscoverSelectedModules(t *testing.T) {
+	c := &fakeClient{}
+	found, err := Discover(c, Target{Project: "p", Deployment: "hpc", Modules: []string{"filestore"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("want no resources of other modules, got %v", found)
+	}
+
+	found, err = Discover(c, Target{Project: "p", Deployment: "hpc", Modules: []string{"gke-node-pool"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Errorf("want labeled resources of gke-node-pool, got %v", found)
+	}
+}
+
+func TestStateful(t *testing.T) {
+	for kind, want := range map[string]bool{Disk: true, FilestoreInstance: true, Firewall: false, ForwardingRule: false} {
+		if got := Stateful(Resource{Kind: kind}); got != want {
+			t.Errorf("%s: got %v, want %v", kind, got, want)
+		}
+	}
+}
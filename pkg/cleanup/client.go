@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cleanup
+
+import (
+	"context"
+	"fmt"
+
+	filestore "cloud.google.com/go/filestore/apiv1"
+	"cloud.google.com/go/filestore/apiv1/filestorepb"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// Client is the subset of Compute Engine and Filestore APIs used by cleaners,
+// tests use a fake implementation. Delete methods wait for completion.
+type Client interface {
+	ListNetworks(project string) ([]*compute.Network, error)
+	ListForwardingRules(project string) ([]*compute.ForwardingRule, error)
+	DeleteForwardingRule(project string, region string, name string) error
+	ListNetworkEndpointGroups(project string) ([]*compute.NetworkEndpointGroup, error)
+	DeleteNetworkEndpointGroup(project string, zone string, name string) error
+	ListRoutes(project string) ([]*compute.Route, error)
+	DeleteRoute(project string, name string) error
+	ListFirewalls(project string) ([]*compute.Firewall, error)
+	DeleteFirewall(project string, name string) error
+	ListDisks(project string) ([]*compute.Disk, error)
+	DeleteDisk(project string, zone string, name string) error
+	ListFilestoreInstances(project string) ([]*filestorepb.Instance, error)
+	DeleteFilestoreInstance(name string) error
+}
+
+// NewClient returns a Client of Google Cloud APIs using default credentials
+func NewClient(ctx context.Context) (Client, error) {
+	s, err := compute.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute service: %w", err)
+	}
+	return &gcpClient{ctx: ctx, s: s}, nil
+}
+
+type gcpClient struct {
+	ctx context.Context
+	s   *compute.Service
+}
+
+func (g *gcpClient) ListNetworks(project string) ([]*compute.Network, error) {
+	res := []*compute.Network{}
+	err := g.s.Networks.List(project).Pages(g.ctx, func(l *compute.NetworkList) error {
+		res = append(res, l.Items...)
+		return nil
+	})
+	return res, err
+}
+
+func (g *gcpClient) ListForwardingRules(project string) ([]*compute.ForwardingRule, error) {
+	res := []*compute.ForwardingRule{}
+	err := g.s.ForwardingRules.AggregatedList(project).Pages(g.ctx, func(l *compute.ForwardingRuleAggregatedList) error {
+		for _, scoped := range l.Items {
+			res = append(res, scoped.ForwardingRules...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = g.s.GlobalForwardingRules.List(project).Pages(g.ctx, func(l *compute.ForwardingRuleList) error {
+		res = append(res, l.Items...)
+		return nil
+	})
+	return res, err
+}
+
+func (g *gcpClient) DeleteForwardingRule(project string, region string, name string) error {
+	if region == "" {
+		return g.wait(project, "", "", g.s.GlobalForwardingRules.Delete(project, name).Do)
+	}
+	return g.wait(project, region, "", g.s.ForwardingRules.Delete(project, region, name).Do)
+}
+
+func (g *gcpClient) ListNetworkEndpointGroups(project string) ([]*compute.NetworkEndpointGroup, error) {
+	res := []*compute.NetworkEndpointGroup{}
+	err := g.s.NetworkEndpointGroups.AggregatedList(project).Pages(g.ctx, func(l *compute.NetworkEndpointGroupAggregatedList) error {
+		for _, scoped := range l.Items {
+			res = append(res, scoped.NetworkEndpointGroups...)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (g *gcpClient) DeleteNetworkEndpointGroup(project string, zone string, name string) error {
+	return g.wait(project, "", zone, g.s.NetworkEndpointGroups.Delete(project, zone, name).Do)
+}
+
+func (g *gcpClient) ListRoutes(project string) ([]*compute.Route, error) {
+	res := []*compute.Route{}
+	err := g.s.Routes.List(project).Pages(g.ctx, func(l *compute.RouteList) error {
+		res = append(res, l.Items...)
+		return nil
+	})
+	return res, err
+}
+
+func (g *gcpClient) DeleteRoute(project string, name string) error {
+	return g.wait(project, "", "", g.s.Routes.Delete(project, name).Do)
+}
+
+func (g *gcpClient) ListFirewalls(project string) ([]*compute.Firewall, error) {
+	res := []*compute.Firewall{}
+	err := g.s.Firewalls.List(project).Pages(g.ctx, func(l *compute.FirewallList) error {
+		res = append(res, l.Items...)
+		return nil
+	})
+	return res, err
+}
+
+func (g *gcpClient) DeleteFirewall(project string, name string) error {
+	return g.wait(project, "", "", g.s.Firewalls.Delete(project, name).Do)
+}
+
+func (g *gcpClient) ListDisks(project string) ([]*compute.Disk, error) {
+	res := []*compute.Disk{}
+	err := g.s.Disks.AggregatedList(project).Pages(g.ctx, func(l *compute.DiskAggregatedList) error {
+		for _, scoped := range l.Items {
+			res = append(res, scoped.Disks...)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (g *gcpClient) DeleteDisk(project string, zone string, name string) error {
+	return g.wait(project, "", zone, g.s.Disks.Delete(project, zone, name).Do)
+}
+
+func (g *gcpClient) ListFilestoreInstances(project string) ([]*filestorepb.Instance, error) {
+	c, err := filestore.NewCloudFilestoreManagerClient(g.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create filestore client: %w", err)
+	}
+	defer c.Close()
+
+	res := []*filestorepb.Instance{}
+	it := c.ListInstances(g.ctx, &filestorepb.ListInstancesRequest{Parent: fmt.Sprintf("projects/%s/locations/-", project)})
+	for {
+		inst, err := it.Next()
+		if err == iterator.Done {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, inst)
+	}
+}
+
+func (g *gcpClient) DeleteFilestoreInstance(name string) error {
+	c, err := filestore.NewCloudFilestoreManagerClient(g.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create filestore client: %w", err)
+	}
+	defer c.Close()
+
+	// force deletes snapshots of the instance as well
+	op, err := c.DeleteInstance(g.ctx, &filestorepb.DeleteInstanceRequest{Name: name, Force: true})
+	if err != nil {
+		return err
+	}
+	return op.Wait(g.ctx)
+}
+
+// wait starts the operation and waits for its completion
+func (g *gcpClient) wait(project string, region string, zone string, start func(...googleapi.CallOption) (*compute.Operation, error)) error {
+	op, err := start()
+	if err != nil {
+		return err
+	}
+	for op.Status != "DONE" {
+		switch {
+		case zone != "":
+			op, err = g.s.ZoneOperations.Wait(project, zone, op.Name).Context(g.ctx).Do()
+		case region != "":
+			op, err = g.s.RegionOperations.Wait(project, region, op.Name).Context(g.ctx).Do()
+		default:
+			op, err = g.s.GlobalOperations.Wait(project, op.Name).Context(g.ctx).Do()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		return fmt.Errorf("operation %s failed: %s", op.Name, op.Error.Errors[0].Message)
+	}
+	return nil
+}
@@ -86,6 +86,8 @@ type Group struct {
 	TerraformBackend   TerraformBackend             `yaml:"terraform_backend,omitempty"`
 	TerraformProviders map[string]TerraformProvider `yaml:"terraform_providers,omitempty"`
 	Modules            []Module                     `yaml:"modules"`
+	// Enabled is a boolean expression, the group is pruned during expansion if it evaluates to false
+	Enabled *YamlValue `yaml:"enabled,omitempty"`
 	// DEPRECATED fields
 	deprecatedKind interface{} `yaml:"kind,omitempty"` //lint:ignore U1000 keep in the struct for backwards compatibility
 }
@@ -266,6 +268,10 @@ type Module struct {
 	Use      ModuleIDs                 `yaml:"use,omitempty"`
 	Outputs  []modulereader.OutputInfo `yaml:"outputs,omitempty"`
 	Settings Dict                      `yaml:"settings,omitempty"`
+	// Enabled is a boolean expression, the module is pruned during expansion if it evaluates to false
+	Enabled *YamlValue `yaml:"enabled,omitempty"`
+	// ForEach is a map or a list of strings, the module is replaced by an instance per element during expansion
+	ForEach *YamlValue `yaml:"for_each,omitempty"`
 	// DEPRECATED fields, keep in the struct for backwards compatibility
 	RequiredApis     interface{} `yaml:"required_apis,omitempty"`
 	WrapSettingsWith interface{} `yaml:"wrapsettingswith,omitempty"`
@@ -295,6 +301,7 @@ func (m Module) InfoOrDie() modulereader.ModuleInfo {
 type Blueprint struct {
 	BlueprintName            string      `yaml:"blueprint_name"`
 	GhpcVersion              string      `yaml:"ghpc_version,omitempty"`
+	Imports                  []string    `yaml:"imports,omitempty"`
 	Validators               []Validator `yaml:"validators,omitempty"`
 	ValidationLevel          int         `yaml:"validation_level,omitempty"`
 	Vars                     Dict
@@ -380,24 +387,45 @@ type DeploymentSettings struct {
 
 // Expand expands the config in place
 func (bp *Blueprint) Expand() error {
-	// expand the blueprint in dependency order:
-	// BlueprintName -> DefaultBackend -> Vars -> Groups
-	errs := (&Errors{}).
-		Add(checkStringLiterals(bp)).
-		Add(bp.checkBlueprintName()).
-		Add(bp.checkToolkitModulesUrlAndVersion()).
-		Add(checkProviders(Root.Provider, bp.TerraformProviders))
-	if errs.Any() {
-		return *errs
+	for _, stage := range bp.expandStages(false) {
+		if err := stage(); err != nil {
+			return err
+		}
 	}
+	return bp.expandGroups()
+}
 
-	if err := bp.expandVars(); err != nil {
-		return err
+// expandStages returns stages of Expand preceding the expansion of groups, in dependency order:
+// BlueprintName -> DefaultBackend -> Vars -> Enabled -> Groups.
+// With lint, stages that require outputs of other deployments or module sources are skipped, see Lint.
+func (bp *Blueprint) expandStages(lint bool) []func() error {
+	checks := func() error {
+		errs := (&Errors{}).
+			Add(checkStringLiterals(bp)).
+			Add(bp.checkBlueprintName())
+		if !lint {
+			errs.Add(bp.checkDeploymentReferences())
+		}
+		errs.
+			Add(bp.checkToolkitModulesUrlAndVersion()).
+			Add(checkProviders(Root.Provider, bp.TerraformProviders))
+		if errs.Any() {
+			return *errs
+		}
+		return nil
 	}
-	if err := bp.checkReferences(); err != nil {
-		return err
+	stages := []func() error{
+		checks,
+		bp.expandVars,
+		bp.pruneDisabled,
+		bp.checkReferences,
+		bp.expandForEach,
+		func() error { bp.addKindToModules(); return nil },
 	}
-	return bp.expandGroups()
+	if !lint {
+		stages = append(stages, func() error { bp.substituteModuleSources(); return nil })
+	}
+	return append(stages, func() error { return checkModulesAndGroups(*bp) })
 }
 
 // ListUnusedModules provides a list modules that are in the
@@ -488,11 +516,36 @@ func NewBlueprint(path string) (Blueprint, *YamlCtx, error) {
 	if err != nil {
 		return Blueprint{}, &YamlCtx{}, err
 	}
-	bp, ctx, err := parseYamlFile[Blueprint](absPath)
+	y, err := os.ReadFile(absPath)
+	if err != nil {
+		return Blueprint{}, &YamlCtx{}, fmt.Errorf("failed to read the input yaml, filename=%s: %v", absPath, err)
+	}
+	return ParseBlueprint(absPath, y)
+}
+
+// ParseBlueprint is a constructor for Blueprint from the content of the file at the path,
+// e.g. of a file being edited. Imports are resolved relative to the path.
+func ParseBlueprint(path string, data []byte) (Blueprint, *YamlCtx, error) {
+	return ParseBlueprintCached(path, data, nil)
+}
+
+// ParseBlueprintCached is ParseBlueprint keeping remote imports in the cache,
+// to parse the blueprint repeatedly without downloading them again.
+func ParseBlueprintCached(path string, data []byte, cache *ImportCache) (Blueprint, *YamlCtx, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Blueprint{}, &YamlCtx{}, err
+	}
+	bp, ctx, err := parseYaml[Blueprint](data)
 	if err != nil {
 		return Blueprint{}, &ctx, err
 	}
 	bp.path = absPath
+	if len(bp.Imports) > 0 {
+		if err := resolveImports(&bp, &ctx, cache); err != nil {
+			return Blueprint{}, &ctx, err
+		}
+	}
 	// Attach parsed YAML context to the Blueprint so validators can determine
 	// whether a module.setting path was explicitly present in the user's YAML.
 	bp.YamlCtx = &ctx
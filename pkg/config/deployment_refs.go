@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DeploymentReferences returns references to outputs of other deployments,
+// e.g. `$(deployment_output("shared-network", "network_self_link"))`, sorted by their text
+func (bp *Blueprint) DeploymentReferences() []Reference {
+	seen := map[Reference]bool{}
+	bp.visitDicts(func(_ dictPath, d *Dict) {
+		for _, v := range d.Items() {
+			for ref := range valueReferences(v) {
+				if ref.Deployment != "" {
+					seen[ref] = true
+				}
+			}
+		}
+	})
+	res := make([]Reference, 0, len(seen))
+	for r := range seen {
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].String() < res[j].String() })
+	return res
+}
+
+// ResolveDeploymentReferences substitutes references to outputs of other
+// deployments with their values. Must be called before Expand, all references
+// returned by DeploymentReferences have to be given a value.
+func (bp *Blueprint) ResolveDeploymentReferences(vals map[Reference]cty.Value) error {
+	errs := Errors{}
+	bp.mutateDicts(func(dp dictPath, d *Dict) Dict {
+		res := Dict{}
+		for k, v := range d.Items() {
+			nv, err := cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+				e, is := IsExpressionValue(v)
+				if !is {
+					return v, nil
+				}
+				return substituteDeploymentRefs(e, vals)
+			})
+			if err != nil {
+				errs.At(dp.Dot(k), err)
+				nv = v
+			}
+			res = res.With(k, nv)
+		}
+		return res
+	})
+	return errs.OrNil()
+}
+
+func substituteDeploymentRefs(e Expression, vals map[Reference]cty.Value) (cty.Value, error) {
+	for _, ref := range e.References() {
+		if ref.Deployment == "" {
+			continue
+		}
+		val, ok := vals[ref]
+		if !ok {
+			return cty.NilVal, fmt.Errorf("output %q of deployment %q is not resolved", ref.Name, ref.Deployment)
+		}
+		if e.key() == ref.AsExpression().key() {
+			return val, nil // whole expression is the reference, keep the value type
+		}
+		lit, err := ParseExpression(string(TokensForValue(val).Bytes()))
+		if err != nil {
+			return cty.NilVal, err
+		}
+		if e, err = ReplaceSubExpressions(e, ref.AsExpression(), lit); err != nil {
+			return cty.NilVal, err
+		}
+	}
+	return e.AsValue(), nil
+}
+
+// checkDeploymentReferences makes sure that no references to outputs
+// of other deployments are left, see ResolveDeploymentReferences
+func (bp *Blueprint) checkDeploymentReferences() error {
+	errs := Errors{}
+	bp.visitDicts(func(dp dictPath, d *Dict) {
+		for k, v := range d.Items() {
+			for ref, rp := range valueReferences(v) {
+				if ref.Deployment != "" {
+					errs.At(dp.Dot(k).Cty(rp), fmt.Errorf("output %q of deployment %q is not resolved", ref.Name, ref.Deployment))
+				}
+			}
+		}
+	})
+	return errs.OrNil()
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	. "gopkg.in/check.v1"
+)
+
+func deploymentRefsBlueprint() Blueprint {
+	return Blueprint{
+		BlueprintName: "tree",
+		Vars: NewDict(map[string]cty.Value{
+			"zone": MustParseExpression(`deployment_output("net", "zone")`).AsValue()}),
+		Groups: []Group{{Name: "green", Modules: []Module{{
+			ID: "leaf",
+			Settings: NewDict(map[string]cty.Value{
+				"network": MustParseExpression(`deployment_output("net", "vpc", "self_link")`).AsValue(),
+				"label":   MustParseExpression(`"in-${deployment_output("net","zone")}"`).AsValue(),
+				"sizes":   cty.TupleVal([]cty.Value{MustParseExpression(`deployment_output("net", "size") + 1`).AsValue()}),
+				"plain":   cty.StringVal("ok"),
+			})}}}},
+	}
+}
+
+func (s *zeroSuite) TestDeploymentReferences(c *C) {
+	bp := deploymentRefsBlueprint()
+	c.Check(bp.DeploymentReferences(), DeepEquals, []Reference{
+		DeploymentRef("net", "", "size"),
+		DeploymentRef("net", "vpc", "self_link"),
+		DeploymentRef("net", "", "zone"),
+	})
+	c.Check((&Blueprint{}).DeploymentReferences(), DeepEquals, []Reference{})
+}
+
+func (s *zeroSuite) TestResolveDeploymentReferences(c *C) {
+	bp := deploymentRefsBlueprint()
+	c.Check(bp.checkDeploymentReferences(), NotNil)
+
+	err := bp.ResolveDeploymentReferences(map[Reference]cty.Value{
+		DeploymentRef("net", "", "zone"):         cty.StringVal("us-central1-a"),
+		DeploymentRef("net", "", "size"):         cty.NumberIntVal(2),
+		DeploymentRef("net", "vpc", "self_link"): cty.StringVal("projects/p/networks/n"),
+	})
+	c.Assert(err, IsNil)
+	c.Check(bp.checkDeploymentReferences(), IsNil)
+
+	c.Check(bp.Vars.Get("zone"), DeepEquals, cty.StringVal("us-central1-a"))
+	set := bp.Groups[0].Modules[0].Settings
+	c.Check(set.Get("network"), DeepEquals, cty.StringVal("projects/p/networks/n"))
+	c.Check(set.Get("label"), DeepEquals, MustParseExpression(`"in-${"us-central1-a"}"`).AsValue())
+	c.Check(set.Get("sizes"), DeepEquals, cty.TupleVal([]cty.Value{MustParseExpression("2 + 1").AsValue()}))
+	c.Check(set.Get("plain"), DeepEquals, cty.StringVal("ok"))
+}
+
+func (s *zeroSuite) TestResolveDeploymentReferencesMissing(c *C) {
+	bp := deploymentRefsBlueprint()
+	err := bp.ResolveDeploymentReferences(map[Reference]cty.Value{
+		DeploymentRef("net", "", "zone"): cty.StringVal("us-central1-a"),
+	})
+	c.Check(err, ErrorMatches, `(?s).*output "size" of deployment "net" is not resolved.*`)
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ChangeKind is the type of a difference between two blueprints
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// ChangeCategory is the part of the blueprint a Change applies to
+type ChangeCategory string
+
+const (
+	CategoryGroup    ChangeCategory = "group"
+	CategoryModule   ChangeCategory = "module"
+	CategoryUse      ChangeCategory = "use"
+	CategorySetting  ChangeCategory = "setting"
+	CategoryVar      ChangeCategory = "var"
+	CategoryBackend  ChangeCategory = "backend"
+	CategoryProvider ChangeCategory = "provider"
+)
+
+// Change is a single semantic difference between two blueprints.
+// Address identifies the changed item independently of its position in either
+// blueprint, e.g. `vars.region` or `primary.homefs.settings.size_gb`.
+// BeforePath and AfterPath locate the item in the respective blueprint and are
+// nil when the item is absent from that side.
+type Change struct {
+	Kind       ChangeKind
+	Category   ChangeCategory
+	Address    string
+	BeforePath Path
+	AfterPath  Path
+	Before     cty.Value
+	After      cty.Value
+}
+
+// Diff compares two blueprints semantically and returns the differences in
+// groups, modules, `use` edges, module settings, deployment variables,
+// Terraform backends and providers. Groups are matched by name and modules by ID,
+// so reordering alone is not reported. Values are compared by their HCL
+// rendering, which treats expressions and literals uniformly.
+func Diff(before, after Blueprint) []Change {
+	d := differ{}
+	d.dict(CategoryVar, "vars", Root.Vars, Root.Vars, before.Vars, after.Vars)
+	d.backend("terraform_backend_defaults", Root.Backend, Root.Backend, before.TerraformBackendDefaults, after.TerraformBackendDefaults)
+	d.providers("terraform_providers", Root.Provider, Root.Provider, before.TerraformProviders, after.TerraformProviders)
+
+	for ai, ag := range after.Groups {
+		ap := Root.Groups.At(ai)
+		bi := before.GroupIndex(ag.Name)
+		if bi < 0 {
+			d.add(Change{Kind: ChangeAdded, Category: CategoryGroup, Address: string(ag.Name), AfterPath: ap})
+			continue
+		}
+		d.group(before.Groups[bi], ag, Root.Groups.At(bi), ap)
+	}
+	for bi, bg := range before.Groups {
+		if after.GroupIndex(bg.Name) < 0 {
+			d.add(Change{Kind: ChangeRemoved, Category: CategoryGroup, Address: string(bg.Name), BeforePath: Root.Groups.At(bi)})
+		}
+	}
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) group(bg, ag Group, bp, ap groupPath) {
+	pref := string(ag.Name)
+	d.backend(pref+".terraform_backend", bp.Backend, ap.Backend, bg.TerraformBackend, ag.TerraformBackend)
+	d.providers(pref+".terraform_providers", bp.Provider, ap.Provider, bg.TerraformProviders, ag.TerraformProviders)
+
+	for ami, am := range ag.Modules {
+		amp := ap.Modules.At(ami)
+		addr := fmt.Sprintf("%s.%s", pref, am.ID)
+		bmi := bg.ModuleIndex(am.ID)
+		if bmi < 0 {
+			d.add(Change{Kind: ChangeAdded, Category: CategoryModule, Address: addr, AfterPath: amp})
+			continue
+		}
+		d.module(addr, bg.Modules[bmi], am, bp.Modules.At(bmi), amp)
+	}
+	for bmi, bm := range bg.Modules {
+		if ag.ModuleIndex(bm.ID) < 0 {
+			d.add(Change{Kind: ChangeRemoved, Category: CategoryModule, Address: fmt.Sprintf("%s.%s", pref, bm.ID), BeforePath: bp.Modules.At(bmi)})
+		}
+	}
+}
+
+func (d *differ) module(addr string, bm, am Module, bp, ap ModulePath) {
+	if bm.Source != am.Source {
+		d.add(Change{Kind: ChangeChanged, Category: CategoryModule, Address: addr + ".source",
+			BeforePath: bp.Source, AfterPath: ap.Source,
+			Before: cty.StringVal(bm.Source), After: cty.StringVal(am.Source)})
+	}
+	if bm.Kind != am.Kind {
+		d.add(Change{Kind: ChangeChanged, Category: CategoryModule, Address: addr + ".kind",
+			BeforePath: bp.Kind, AfterPath: ap.Kind,
+			Before: cty.StringVal(bm.Kind.String()), After: cty.StringVal(am.Kind.String())})
+	}
+
+	for i, u := range am.Use {
+		if !slices.Contains(bm.Use, u) {
+			d.add(Change{Kind: ChangeAdded, Category: CategoryUse, Address: fmt.Sprintf("%s.use[%s]", addr, u),
+				AfterPath: ap.Use.At(i), After: cty.StringVal(string(u))})
+		}
+	}
+	for i, u := range bm.Use {
+		if !slices.Contains(am.Use, u) {
+			d.add(Change{Kind: ChangeRemoved, Category: CategoryUse, Address: fmt.Sprintf("%s.use[%s]", addr, u),
+				BeforePath: bp.Use.At(i), Before: cty.StringVal(string(u))})
+		}
+	}
+
+	d.dict(CategorySetting, addr+".settings", bp.Settings, ap.Settings, bm.Settings, am.Settings)
+}
+
+func (d *differ) backend(addr string, bp, ap backendPath, bb, ab TerraformBackend) {
+	bv, av := backendValue(bb), backendValue(ab)
+	switch {
+	case bb.Type == "" && ab.Type == "":
+		return
+	case bb.Type == "":
+		d.add(Change{Kind: ChangeAdded, Category: CategoryBackend, Address: addr, AfterPath: ap, After: av})
+	case ab.Type == "":
+		d.add(Change{Kind: ChangeRemoved, Category: CategoryBackend, Address: addr, BeforePath: bp, Before: bv})
+	case !sameValue(bv, av):
+		d.add(Change{Kind: ChangeChanged, Category: CategoryBackend, Address: addr, BeforePath: bp, AfterPath: ap, Before: bv, After: av})
+	}
+}
+
+func (d *differ) providers(addr string, bp, ap mapPath[providerPath], bps, aps map[string]TerraformProvider) {
+	for _, n := range unionKeys(bps, aps) {
+		b, inB := bps[n]
+		a, inA := aps[n]
+		bv, av := providerValue(b), providerValue(a)
+		c := Change{Category: CategoryProvider, Address: fmt.Sprintf("%s.%s", addr, n), Before: bv, After: av}
+		switch {
+		case !inB:
+			c.Kind, c.AfterPath, c.Before = ChangeAdded, ap.Dot(n), cty.NilVal
+		case !inA:
+			c.Kind, c.BeforePath, c.After = ChangeRemoved, bp.Dot(n), cty.NilVal
+		case !sameValue(bv, av):
+			c.Kind, c.BeforePath, c.AfterPath = ChangeChanged, bp.Dot(n), ap.Dot(n)
+		default:
+			continue
+		}
+		d.add(c)
+	}
+}
+
+func (d *differ) dict(cat ChangeCategory, addr string, bp, ap dictPath, bd, ad Dict) {
+	bi, ai := bd.Items(), ad.Items()
+	for _, k := range unionKeys(bi, ai) {
+		b, inB := bi[k]
+		a, inA := ai[k]
+		c := Change{Category: cat, Address: fmt.Sprintf("%s.%s", addr, k), Before: b, After: a}
+		switch {
+		case !inB:
+			c.Kind, c.AfterPath = ChangeAdded, ap.Dot(k)
+		case !inA:
+			c.Kind, c.BeforePath = ChangeRemoved, bp.Dot(k)
+		case !sameValue(b, a):
+			c.Kind, c.BeforePath, c.AfterPath = ChangeChanged, bp.Dot(k), ap.Dot(k)
+		default:
+			continue
+		}
+		d.add(c)
+	}
+}
+
+func backendValue(be TerraformBackend) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"type":          cty.StringVal(be.Type),
+		"configuration": be.Configuration.AsObject(),
+	})
+}
+
+func providerValue(p TerraformProvider) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"source":        cty.StringVal(p.Source),
+		"version":       cty.StringVal(p.Version),
+		"configuration": p.Configuration.AsObject(),
+	})
+}
+
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := []string{}
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sameValue(a, b cty.Value) bool {
+	return RenderValue(a) == RenderValue(b)
+}
+
+// RenderValue returns the HCL representation of a blueprint value,
+// or an empty string for cty.NilVal.
+func RenderValue(v cty.Value) string {
+	if v == cty.NilVal {
+		return ""
+	}
+	return string(hclwrite.Format(TokensForValue(v).Bytes()))
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func diffTestBlueprint() Blueprint {
+	return Blueprint{
+		Vars: NewDict(map[string]cty.Value{
+			"project_id": cty.StringVal("alpha"),
+			"region":     cty.StringVal("us-central1"),
+		}),
+		Groups: []Group{{
+			Name: "primary",
+			Modules: []Module{{
+				ID:       "network",
+				Source:   "modules/network/vpc",
+				Kind:     TerraformKind,
+				Settings: NewDict(map[string]cty.Value{"mtu": cty.NumberIntVal(1460)}),
+			}, {
+				ID:     "homefs",
+				Source: "modules/file-system/filestore",
+				Kind:   TerraformKind,
+				Use:    ModuleIDs{"network"},
+				Settings: NewDict(map[string]cty.Value{
+					"size_gb": cty.NumberIntVal(1024),
+					"region":  GlobalRef("region").AsValue(),
+				}),
+			}},
+		}},
+	}
+}
+
+func diffSummary(changes []Change) []string {
+	res := []string{}
+	for _, c := range changes {
+		res = append(res, string(c.Kind)+" "+string(c.Category)+" "+c.Address)
+	}
+	return res
+}
+
+func TestDiffIdentical(t *testing.T) {
+	if got := Diff(diffTestBlueprint(), diffTestBlueprint()); len(got) != 0 {
+		t.Errorf("Diff() of identical blueprints = %v, want none", diffSummary(got))
+	}
+}
+
+func TestDiffIgnoresModuleOrder(t *testing.T) {
+	after := diffTestBlueprint()
+	mods := after.Groups[0].Modules
+	mods[0], mods[1] = mods[1], mods[0]
+	if got := Diff(diffTestBlueprint(), after); len(got) != 0 {
+		t.Errorf("Diff() of reordered modules = %v, want none", diffSummary(got))
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	before := diffTestBlueprint()
+	after := diffTestBlueprint()
+
+	after.Vars = after.Vars.With("zone", cty.StringVal("us-central1-a"))
+	after.Vars = after.Vars.With("project_id", cty.StringVal("beta"))
+	after.TerraformBackendDefaults = TerraformBackend{
+		Type:          "gcs",
+		Configuration: NewDict(map[string]cty.Value{"bucket": cty.StringVal("state")}),
+	}
+
+	g := &after.Groups[0]
+	g.Modules[0].Source = "modules/network/pre-existing-vpc"
+	g.Modules[1].Use = ModuleIDs{"compute"}
+	g.Modules[1].Settings = NewDict(map[string]cty.Value{
+		"size_gb": cty.NumberIntVal(2048),
+		"name":    cty.StringVal("home"),
+	})
+	g.Modules = append(g.Modules, Module{ID: "compute", Source: "modules/compute/vm-instance", Kind: TerraformKind})
+	after.Groups = append(after.Groups, Group{Name: "image", Modules: []Module{{ID: "build", Source: "modules/packer/custom-image", Kind: PackerKind}}})
+
+	got := Diff(before, after)
+	want := []string{
+		"changed var vars.project_id",
+		"added var vars.zone",
+		"added backend terraform_backend_defaults",
+		"changed module primary.network.source",
+		"added use primary.homefs.use[compute]",
+		"removed use primary.homefs.use[network]",
+		"added setting primary.homefs.settings.name",
+		"removed setting primary.homefs.settings.region",
+		"changed setting primary.homefs.settings.size_gb",
+		"added module primary.compute",
+		"added group image",
+	}
+	if diff := cmp.Diff(want, diffSummary(got)); diff != "" {
+		t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+	}
+
+	// check paths and values of a changed setting
+	c := got[8]
+	if c.BeforePath.String() != "deployment_groups[0].modules[1].settings.size_gb" {
+		t.Errorf("unexpected before path %q", c.BeforePath.String())
+	}
+	if c.AfterPath.String() != "deployment_groups[0].modules[1].settings.size_gb" {
+		t.Errorf("unexpected after path %q", c.AfterPath.String())
+	}
+	if RenderValue(c.Before) != "1024" || RenderValue(c.After) != "2048" {
+		t.Errorf("unexpected values %q -> %q", RenderValue(c.Before), RenderValue(c.After))
+	}
+	// removed items have no after path
+	if got[7].AfterPath != nil || got[7].BeforePath == nil {
+		t.Errorf("removed setting has unexpected paths %v, %v", got[7].BeforePath, got[7].AfterPath)
+	}
+}
+
+func TestDiffRemovedGroupAndProvider(t *testing.T) {
+	before := diffTestBlueprint()
+	before.Groups = append(before.Groups, Group{Name: "extra"})
+	before.TerraformProviders = map[string]TerraformProvider{
+		"google": {Source: "hashicorp/google", Version: "~> 6.0"},
+	}
+	after := diffTestBlueprint()
+	after.TerraformProviders = map[string]TerraformProvider{
+		"google": {Source: "hashicorp/google", Version: "~> 7.0"},
+	}
+
+	want := []string{
+		"changed provider terraform_providers.google",
+		"removed group extra",
+	}
+	if diff := cmp.Diff(want, diffSummary(Diff(before, after))); diff != "" {
+		t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// evalVarsExpression evaluates value of the `field` that can only reference deployment variables.
+func (bp *Blueprint) evalVarsExpression(p Path, field string, v cty.Value) (cty.Value, error) {
+	for ref := range valueReferences(v) {
+		if !ref.GlobalVar {
+			return cty.NilVal, BpError{p, fmt.Errorf("`%s` can only reference deployment variables, got %q", field, ref)}
+		}
+		if !bp.Vars.Has(ref.Name) {
+			return cty.NilVal, BpError{p, fmt.Errorf("variable %q not found", ref.Name)}
+		}
+	}
+	ev, err := bp.Eval(v)
+	if err != nil {
+		return cty.NilVal, BpError{p, err}
+	}
+	return ev, nil
+}
+
+// evalEnabled evaluates `enabled` expression, absence of it means enabled.
+func (bp *Blueprint) evalEnabled(p Path, y *YamlValue) (bool, error) {
+	if y == nil {
+		return true, nil
+	}
+	ev, err := bp.evalVarsExpression(p, "enabled", y.Unwrap())
+	if err != nil {
+		return false, err
+	}
+	if !ev.IsKnown() || ev.IsNull() {
+		return false, BpError{p, fmt.Errorf("`enabled` must be a boolean, got %#v", ev)}
+	}
+	b, err := convert.Convert(ev, cty.Bool)
+	if err != nil {
+		return false, BpError{p, fmt.Errorf("`enabled` must be a boolean: %w", err)}
+	}
+	return b.True(), nil
+}
+
+// pruneDisabled removes groups and modules with `enabled` evaluated to false.
+// Any reference from a remaining module to a pruned one is an error.
+func (bp *Blueprint) pruneDisabled() error {
+	errs := Errors{}
+	disabled := map[ModuleID]string{} // module ID -> reason
+	keepGroups := []int{}
+	keepModules := [][]int{} // original indexes of kept modules of each kept group
+
+	for ig, g := range bp.Groups {
+		gp := Root.Groups.At(ig)
+		en, err := bp.evalEnabled(gp.Enabled, g.Enabled)
+		if errs.Add(err); err != nil {
+			continue
+		}
+		if !en {
+			for _, m := range g.Modules {
+				disabled[m.ID] = fmt.Sprintf("its group %q is disabled", g.Name)
+			}
+			continue
+		}
+		keep := []int{}
+		for im, m := range g.Modules {
+			en, err := bp.evalEnabled(gp.Modules.At(im).Enabled, m.Enabled)
+			if errs.Add(err); err != nil {
+				continue
+			}
+			if !en {
+				disabled[m.ID] = "it is disabled"
+				continue
+			}
+			keep = append(keep, im)
+		}
+		keepGroups = append(keepGroups, ig)
+		keepModules = append(keepModules, keep)
+	}
+	if errs.Any() {
+		return errs
+	}
+	if len(disabled) == 0 {
+		bp.clearEnabled()
+		return nil
+	}
+
+	for ng, ig := range keepGroups {
+		for _, im := range keepModules[ng] {
+			mp := Root.Groups.At(ig).Modules.At(im)
+			m := bp.Groups[ig].Modules[im]
+			for iu, u := range m.Use {
+				if reason, ok := disabled[u]; ok {
+					errs.At(mp.Use.At(iu), disabledModuleError(m.ID, u, reason))
+				}
+			}
+			for k, v := range m.Settings.Items() {
+				for ref, rp := range valueReferences(v) {
+					if reason, ok := disabled[ref.Module]; !ref.GlobalVar && ok {
+						errs.At(mp.Settings.Dot(k).Cty(rp), disabledModuleError(m.ID, ref.Module, reason))
+					}
+				}
+			}
+		}
+	}
+	if errs.Any() {
+		return errs
+	}
+
+	groups := []Group{}
+	for ng, ig := range keepGroups {
+		g := bp.Groups[ig]
+		mods := []Module{}
+		for _, im := range keepModules[ng] {
+			mods = append(mods, g.Modules[im])
+		}
+		g.Modules = mods
+		groups = append(groups, g)
+	}
+	bp.Groups = groups
+	bp.clearEnabled()
+	if bp.YamlCtx != nil {
+		bp.YamlCtx.relocate(keepGroups, keepModules)
+	}
+	return nil
+}
+
+func disabledModuleError(from ModuleID, to ModuleID, reason string) error {
+	return HintError{
+		Hint: fmt.Sprintf("enable module %q or remove the reference to it", to),
+		Err:  fmt.Errorf("module %q references module %q, but %s", from, to, reason)}
+}
+
+// clearEnabled drops evaluated `enabled` expressions, so they are not
+// present in the expanded blueprint.
+func (bp *Blueprint) clearEnabled() {
+	for ig := range bp.Groups {
+		bp.Groups[ig].Enabled = nil
+	}
+	bp.WalkModulesSafe(func(_ ModulePath, m *Module) {
+		m.Enabled = nil
+	})
+}
+
+var groupModuleYPathRe = regexp.MustCompile(`^deployment_groups\[(\d+)\](\.modules\[(\d+)\])?`)
+
+// relocate updates positions to match the blueprint with rearranged groups and modules,
+// groups[ng] is an original index of the group ng and modules[ng][nm] is an original
+// index of the module nm in the group ng. Original modules may appear more than once.
+func (c *YamlCtx) relocate(groups []int, modules [][]int) {
+	type entry struct {
+		suffix yPath
+		loc    yamlLoc
+	}
+	byModule := map[[2]int][]entry{} // {group, module or -1} -> entries
+	locs := map[yPath]yamlLoc{}
+	for p, l := range c.locations() {
+		sm := groupModuleYPathRe.FindStringSubmatch(string(p))
+		if sm == nil {
+			locs[p] = l
+			continue
+		}
+		ig, _ := strconv.Atoi(sm[1])
+		im := -1
+		if sm[3] != "" {
+			im, _ = strconv.Atoi(sm[3])
+		}
+		byModule[[2]int{ig, im}] = append(byModule[[2]int{ig, im}], entry{p[len(sm[0]):], l})
+	}
+
+	for ng, ig := range groups {
+		gp := yPath(fmt.Sprintf("deployment_groups[%d]", ng))
+		for _, e := range byModule[[2]int{ig, -1}] {
+			locs[gp+e.suffix] = e.loc
+		}
+		for nm, im := range modules[ng] {
+			mp := gp + yPath(fmt.Sprintf(".modules[%d]", nm))
+			for _, e := range byModule[[2]int{ig, im}] {
+				locs[mp+e.suffix] = e.loc
+			}
+		}
+	}
+	c.imported = locs
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPruneDisabled(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"bp.yaml": `
+blueprint_name: conditional
+vars:
+  deployment_name: dep
+  enable_gpu: false
+  enable_lustre: true
+deployment_groups:
+- group: primary
+  modules:
+  - id: network
+    source: modules/network/vpc
+  - id: gpu_pool
+    source: modules/compute/gke-node-pool
+    enabled: $(vars.enable_gpu)
+  - id: lustre
+    source: modules/file-system/managed-lustre
+    enabled: $(vars.enable_lustre && true)
+    use: [network]
+- group: gpu_images
+  enabled: $(vars.enable_gpu)
+  modules:
+  - id: image
+    source: modules/packer/custom-image
+- group: last
+  enabled: true
+  modules:
+  - id: vm
+    source: modules/compute/vm-instance
+    use: [network]
+`})
+
+	bp, ctx, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bp.pruneDisabled(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, g := range bp.Groups {
+		if g.Enabled != nil {
+			t.Errorf("group %q: enabled should be cleared", g.Name)
+		}
+		for _, m := range g.Modules {
+			if m.Enabled != nil {
+				t.Errorf("module %q: enabled should be cleared", m.ID)
+			}
+			got = append(got, string(g.Name)+"."+string(m.ID))
+		}
+	}
+	want := []string{"primary.network", "primary.lustre", "last.vm"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("modules mismatch (-want +got):\n%s", diff)
+	}
+
+	// positions follow the pruned blueprint
+	for _, tc := range []struct {
+		p    Path
+		line int
+	}{
+		{Root.Groups.At(0).Modules.At(1).ID, 15},
+		{Root.Groups.At(0).Modules.At(1).Use.At(0), 18},
+		{Root.Groups.At(1).Name, 24},
+		{Root.Groups.At(1).Modules.At(0).Source, 28},
+		{Root.Vars.Dot("enable_gpu"), 5},
+	} {
+		pos, ok := ctx.Pos(tc.p)
+		if !ok || pos.Line != tc.line {
+			t.Errorf("%s: got line %d (found=%t), want %d", tc.p, pos.Line, ok, tc.line)
+		}
+	}
+	if _, ok := ctx.Pos(Root.Groups.At(2)); ok {
+		t.Errorf("pruned group should have no position")
+	}
+}
+
+func TestPruneDisabledErrors(t *testing.T) {
+	type test struct {
+		yaml string
+		path string
+		err  string
+	}
+	for name, tc := range map[string]test{
+		"use": {`
+  - id: network
+    source: modules/network/vpc
+    enabled: false
+  - id: vm
+    source: modules/compute/vm-instance
+    use: [network]`,
+			"deployment_groups[0].modules[1].use[0]",
+			`module "vm" references module "network", but it is disabled`},
+		"reference": {`
+  - id: network
+    source: modules/network/vpc
+    enabled: $(!vars.on)
+  - id: vm
+    source: modules/compute/vm-instance
+    settings:
+      subnetwork: $(network.subnetwork_self_link)`,
+			"deployment_groups[0].modules[1].settings.subnetwork",
+			`module "vm" references module "network", but it is disabled`},
+		"not bool": {`
+  - id: network
+    source: modules/network/vpc
+    enabled: $(vars.deployment_name)`,
+			"deployment_groups[0].modules[0].enabled",
+			"`enabled` must be a boolean"},
+		"unknown var": {`
+  - id: network
+    source: modules/network/vpc
+    enabled: $(vars.nope)`,
+			"deployment_groups[0].modules[0].enabled",
+			`variable "nope" not found`},
+		"module ref": {`
+  - id: network
+    source: modules/network/vpc
+  - id: vm
+    source: modules/compute/vm-instance
+    enabled: $(network.enabled)`,
+			"deployment_groups[0].modules[1].enabled",
+			"`enabled` can only reference deployment variables"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{"bp.yaml": `
+blueprint_name: conditional
+vars:
+  deployment_name: dep
+  on: true
+deployment_groups:
+- group: primary
+  modules:` + tc.yaml + "\n"})
+			bp, _, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = bp.pruneDisabled()
+			errs, ok := err.(Errors)
+			if !ok || len(errs.Errors) != 1 {
+				t.Fatalf("expected single error, got %#v", err)
+			}
+			be, ok := errs.Errors[0].(BpError)
+			if !ok {
+				t.Fatalf("expected BpError, got %#v", errs.Errors[0])
+			}
+			if be.Path.String() != tc.path {
+				t.Errorf("got path %q, want %q", be.Path, tc.path)
+			}
+			if !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("got error %q, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestPruneDisabledGroupReference(t *testing.T) {
+	bp := Blueprint{
+		Groups: []Group{
+			{Name: "first", Enabled: &YamlValue{}, Modules: []Module{{ID: "pool"}}},
+			{Name: "second", Modules: []Module{{ID: "vm", Use: ModuleIDs{"pool"}}}},
+		}}
+	bp.Groups[0].Enabled.Wrap(MustParseExpression("1 > 2").AsValue())
+
+	err := bp.pruneDisabled()
+	if err == nil || !strings.Contains(err.Error(), `but its group "first" is disabled`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
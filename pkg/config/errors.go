@@ -49,6 +49,26 @@ func (e PosError) Unwrap() error {
 	return e.Err
 }
 
+// ImportError is an error wrapper for errors in an imported blueprint file.
+// Path points to the import in the importing blueprint, Ctx is the
+// context of the imported file that Err refers to.
+type ImportError struct {
+	Path Path
+	Ctx  YamlCtx
+	Err  error
+}
+
+func (e ImportError) Error() string {
+	if e.Ctx.File == "" {
+		return fmt.Sprintf("%s: %s", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s: in %s: %s", e.Path, e.Ctx.File, e.Err)
+}
+
+func (e ImportError) Unwrap() error {
+	return e.Err
+}
+
 // HintError wraps another error to suggest other values
 type HintError struct {
 	Hint string
@@ -109,7 +109,7 @@ func (bp *Blueprint) expandVars() error {
 		return err
 	}
 	bp.expandGlobalLabels()
-	return nil
+	return bp.evalFileFunctions()
 }
 
 func (bp *Blueprint) substituteModuleSources() {
@@ -126,12 +126,6 @@ func (bp Blueprint) transformSource(s string) string {
 }
 
 func (bp *Blueprint) expandGroups() error {
-	bp.addKindToModules()
-	bp.substituteModuleSources()
-	if err := checkModulesAndGroups(*bp); err != nil {
-		return err
-	}
-
 	var errs Errors
 	for ig := range bp.Groups {
 		errs.Add(bp.expandGroup(Root.Groups.At(ig), &bp.Groups[ig]))
@@ -210,6 +204,16 @@ func (bp Blueprint) expandBackend(grp *Group) {
 	}
 }
 
+// UseBackendDefaults sets TerraformBackendDefaults of the expanded blueprint
+// and inserts them into groups without an explicit backend, with the same
+// per-group prefixes as an expansion of a blueprint that sets the defaults.
+func (bp *Blueprint) UseBackendDefaults(be TerraformBackend) {
+	bp.TerraformBackendDefaults = be
+	for ig := range bp.Groups {
+		bp.expandBackend(&bp.Groups[ig])
+	}
+}
+
 func getDefaultGoogleProviders(bp Blueprint) map[string]TerraformProvider {
 	gglConf := Dict{}
 	for s, v := range map[string]string{
@@ -554,6 +558,55 @@ func OutputNamesByGroup(g Group, bp Blueprint) (map[GroupName][]string, error) {
 	return res, nil
 }
 
+// GroupDependencies returns groups each group depends on, i.e. groups whose
+// outputs it uses. Packer groups precede all later groups, as images they build
+// are usually referred by name rather than by output.
+func (bp Blueprint) GroupDependencies() (map[GroupName][]GroupName, error) {
+	res := map[GroupName][]GroupName{}
+	for i, g := range bp.Groups {
+		outputs, err := OutputNamesByGroup(g, bp)
+		if err != nil {
+			return nil, err
+		}
+		deps := []GroupName{}
+		for _, pg := range bp.Groups[:i] {
+			if len(outputs[pg.Name]) > 0 || pg.Kind() == PackerKind {
+				deps = append(deps, pg.Name)
+			}
+		}
+		res[g.Name] = deps
+	}
+	return res, nil
+}
+
+// ModuleDependents returns modules of the group that use outputs of the module,
+// directly or through other modules of the group, in the order of the group
+func (g Group) ModuleDependents(id ModuleID) []ModuleID {
+	dependent := map[ModuleID]bool{id: true}
+	for changed := true; changed; {
+		changed = false
+		for _, m := range g.Modules {
+			if dependent[m.ID] {
+				continue
+			}
+			for r := range valueReferences(m.Settings.AsObject()) {
+				if !r.GlobalVar && dependent[r.Module] {
+					dependent[m.ID], changed = true, true
+					break
+				}
+			}
+		}
+	}
+
+	res := []ModuleID{}
+	for _, m := range g.Modules {
+		if m.ID != id && dependent[m.ID] {
+			res = append(res, m.ID)
+		}
+	}
+	return res
+}
+
 // return sorted list of elements common to s1 and s2
 func intersection(s1 []string, s2 []string) []string {
 	first := make(map[string]bool)
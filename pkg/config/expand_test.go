@@ -74,6 +74,27 @@ func (s *zeroSuite) TestExpandBackend(c *C) {
 	}
 }
 
+func (s *zeroSuite) TestUseBackendDefaults(c *C) {
+	type BE = TerraformBackend // alias for brevity
+	bp := Blueprint{
+		BlueprintName: "tree",
+		Groups: []Group{
+			{Name: "clown"},
+			{Name: "jester", TerraformBackend: BE{Type: "local"}}}}
+
+	bp.UseBackendDefaults(BE{
+		Type:          "gcs",
+		Configuration: NewDict(map[string]cty.Value{"bucket": cty.StringVal("pail")})})
+
+	c.Check(bp.TerraformBackendDefaults.Type, Equals, "gcs")
+	c.Check(bp.Groups[0].TerraformBackend, DeepEquals, BE{
+		Type: "gcs",
+		Configuration: NewDict(map[string]cty.Value{
+			"bucket": cty.StringVal("pail"),
+			"prefix": MustParseExpression(`"tree/${var.deployment_name}/clown"`).AsValue()})})
+	c.Check(bp.Groups[1].TerraformBackend, DeepEquals, BE{Type: "local"}) // explicit backend is kept
+}
+
 func (s *zeroSuite) TestExpandProviders(c *C) {
 	type PR = TerraformProvider // alias for brevity
 	noDefPr := Blueprint{BlueprintName: "tree"}
@@ -446,6 +467,50 @@ func (s *zeroSuite) TestOutputNamesByGroup(c *C) {
 	}
 }
 
+func (s *zeroSuite) TestGroupDependencies(c *C) {
+	zebra := Group{
+		Name: "zebra",
+		Modules: []Module{
+			{
+				ID:   "stripes",
+				Kind: TerraformKind,
+				Outputs: []modulereader.OutputInfo{
+					{Name: "length"}}}}}
+	image := Group{
+		Name:    "image",
+		Modules: []Module{{ID: "builder", Kind: PackerKind}}}
+	pony := Group{
+		Name: "pony",
+		Modules: []Module{
+			tMod("bucephalus").set("width", ModuleRef("stripes", "length")).build(),
+		}}
+	bp := Blueprint{Groups: []Group{zebra, image, pony}}
+
+	got, err := bp.GroupDependencies()
+	c.Check(err, IsNil)
+	c.Check(got, DeepEquals, map[GroupName][]GroupName{
+		"zebra": {},
+		"image": {},
+		"pony":  {"zebra", "image"},
+	})
+}
+
+func (s *zeroSuite) TestModuleDependents(c *C) {
+	g := Group{
+		Name: "cluster",
+		Modules: []Module{
+			tMod("network").build(),
+			tMod("nodeset").set("subnet", ModuleRef("network", "subnet")).build(),
+			tMod("partition").set("nodeset", ModuleRef("nodeset", "nodeset")).build(),
+			tMod("storage").set("net", ModuleRef("network", "id")).build(),
+			tMod("login").set("project", GlobalRef("project_id")).build(),
+		}}
+
+	c.Check(g.ModuleDependents("network"), DeepEquals, []ModuleID{"nodeset", "partition", "storage"})
+	c.Check(g.ModuleDependents("nodeset"), DeepEquals, []ModuleID{"partition"})
+	c.Check(g.ModuleDependents("login"), DeepEquals, []ModuleID{})
+}
+
 func (s *zeroSuite) TestExpandGlobalLabels(c *C) {
 	{ // AddCreatorLabel false
 		bp := Blueprint{
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -35,8 +36,10 @@ import (
 // representation of a reference text
 type Reference struct {
 	GlobalVar bool
-	Module    ModuleID // should be empty if GlobalVar. otherwise required
-	Name      string   // required
+	// name of another deployment, set for references to its outputs, see DeploymentRef
+	Deployment string
+	Module     ModuleID // should be empty if GlobalVar. otherwise required, optional for Deployment
+	Name       string   // required
 }
 
 // GlobalRef returns a reference to a global variable
@@ -49,8 +52,22 @@ func ModuleRef(m ModuleID, n string) Reference {
 	return Reference{Module: m, Name: n}
 }
 
+// DeploymentRef returns a reference to an output of another deployment,
+// the module may be empty if the output name identifies the output
+func DeploymentRef(d string, m ModuleID, n string) Reference {
+	return Reference{Deployment: d, Module: m, Name: n}
+}
+
 // AsExpression returns a expression that represents the reference
 func (r Reference) AsExpression() Expression {
+	if r.Deployment != "" {
+		args := []hclwrite.Tokens{TokensForValue(cty.StringVal(r.Deployment))}
+		if r.Module != "" {
+			args = append(args, TokensForValue(cty.StringVal(string(r.Module))))
+		}
+		args = append(args, TokensForValue(cty.StringVal(r.Name)))
+		return MustParseExpression(string(hclwrite.TokensForFunctionCall(deploymentOutputFunction, args...).Bytes()))
+	}
 	if r.GlobalVar {
 		return MustParseExpression(fmt.Sprintf("var.%s", r.Name))
 	}
@@ -204,7 +221,47 @@ func ParseExpression(s string) (Expression, error) {
 			return nil, err
 		}
 	}
-	return BaseExpression{e: e, toks: toks, rs: rs}, nil
+	drs, err := deploymentOutputReferences(e)
+	if err != nil {
+		return nil, err
+	}
+	return BaseExpression{e: e, toks: toks, rs: append(rs, drs...)}, nil
+}
+
+// deploymentOutputFunction references an output of another deployment, e.g.
+// `deployment_output("net", "network_id")`. It is not a real function, its
+// calls are substituted with values before expansion.
+const deploymentOutputFunction = "deployment_output"
+
+// deploymentOutputReferences returns references of all `deployment_output` calls
+// in the expression, arguments of the calls have to be string literals
+func deploymentOutputReferences(e hclsyntax.Expression) ([]Reference, error) {
+	rs := []Reference{}
+	var err error
+	hclsyntax.VisitAll(e, func(n hclsyntax.Node) hcl.Diagnostics {
+		call, ok := n.(*hclsyntax.FunctionCallExpr)
+		if !ok || call.Name != deploymentOutputFunction || err != nil {
+			return nil
+		}
+		args := []string{}
+		for _, a := range call.Args {
+			v, diag := a.Value(nil)
+			if diag.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+				break
+			}
+			args = append(args, v.AsString())
+		}
+		switch {
+		case len(args) != len(call.Args) || len(args) < 2 || len(args) > 3 || call.ExpandFinal:
+			err = fmt.Errorf(`expected %s("<deployment_name>", ["<module_id>",] "<output>") with string literal arguments`, deploymentOutputFunction)
+		case len(args) == 2:
+			rs = append(rs, DeploymentRef(args[0], "", args[1]))
+		default:
+			rs = append(rs, DeploymentRef(args[0], ModuleID(args[1]), args[2]))
+		}
+		return nil
+	})
+	return rs, err
 }
 
 // MustParseExpression is "errorless" version of ParseExpression
@@ -231,7 +288,9 @@ func handleEvalErr(diag hcl.Diagnostics) error {
 	}
 	err := diag.Errs()[0]
 	if match := regexp.MustCompile(`There is no function named "(\w+)"`).FindStringSubmatch(err.Error()); match != nil {
-		sf := strings.Join(maps.Keys(availableFunctions), ", ")
+		names := maps.Keys(availableFunctions)
+		sort.Strings(names)
+		sf := strings.Join(names, ", ")
 		return HintError{
 			Err:  fmt.Errorf("unsupported function %q", match[1]),
 			Hint: fmt.Sprintf("this context only supports following functions: %v", sf)}
@@ -383,16 +442,46 @@ func FunctionCallExpression(n string, args ...cty.Value) Expression {
 }
 
 var availableFunctions = map[string]struct{}{
-	"flatten":    {},
-	"merge":      {},
-	"ghpc_stage": {}}
+	"cidrsubnet":   {},
+	"coalesce":     {},
+	"concat":       {},
+	"file":         {},
+	"flatten":      {},
+	"format":       {},
+	"ghpc_stage":   {},
+	"join":         {},
+	"jsonencode":   {},
+	"lookup":       {},
+	"lower":        {},
+	"merge":        {},
+	"replace":      {},
+	"split":        {},
+	"templatefile": {},
+	"upper":        {},
+	"yamlencode":   {}}
 
 func (bp *Blueprint) functions() map[string]function.Function {
-	return map[string]function.Function{
+	fs := map[string]function.Function{
+		"cidrsubnet": cidrSubnetFunc,
+		"coalesce":   coalesceFunc,
+		"concat":     stdlib.ConcatFunc,
+		"file":       bp.makeFileFunc(),
 		"flatten":    stdlib.FlattenFunc,
-		"merge":      stdlib.MergeFunc,
+		"format":     stdlib.FormatFunc,
 		"ghpc_stage": bp.makeGhpcStageFunc(),
-	}
+		"join":       stdlib.JoinFunc,
+		"jsonencode": stdlib.JSONEncodeFunc,
+		"lookup":     stdlib.LookupFunc,
+		"lower":      stdlib.LowerFunc,
+		"merge":      stdlib.MergeFunc,
+		"replace":    stdlib.ReplaceFunc,
+		"split":      stdlib.SplitFunc,
+		"upper":      stdlib.UpperFunc,
+		"yamlencode": yamlEncodeFunc,
+	}
+	// templates can use all functions but `templatefile` itself
+	fs["templatefile"] = bp.makeTemplateFileFunc(maps.Clone(fs))
+	return fs
 }
 
 func valueReferences(v cty.Value) map[Reference]cty.Path {
@@ -94,6 +94,10 @@ func TestParseBpLit(t *testing.T) {
 		{"$(box.green.sleeve[3])", "module.box.green.sleeve[3]", false},
 		{`$(box.green["sleeve"])`, `module.box.green["sleeve"]`, false},
 
+		{"$(deployment.net.id)", "module.deployment.net.id", false}, // `deployment` is a regular module ID
+		{`$(deployment_output("net", "id"))`, `deployment_output("net","id")`, false},
+		{`$(deployment_output("net", "vpc", "id").name)`, `deployment_output("net","vpc","id").name`, false},
+
 		// String interpolation
 		{`1gold was here`, `"1gold was here"`, false},
 		{`2gold $(vars.here)`, `"2gold ${var.here}"`, false},
@@ -131,10 +135,12 @@ echo "Hello $(vars.project_id)"
 		// Untranslatable expressions
 		{"$(vars)", "", true},
 		{"$(sleeve)", "", true},
-		{"$(box[3])", "", true},        // can't index module
-		{`$(box["green"])`, "", true},  // can't index module
-		{"$(vars[3]])", "", true},      // can't index vars
-		{`$(vars["green"])`, "", true}, // can't index module
+		{"$(box[3])", "", true},                               // can't index module
+		{`$(box["green"])`, "", true},                         // can't index module
+		{"$(vars[3]])", "", true},                             // can't index vars
+		{`$(vars["green"])`, "", true},                        // can't index module
+		{`$(deployment_output("net"))`, "", true},             // missing output name
+		{`$(deployment_output("net", vars.green))`, "", true}, // not a literal
 
 		// TODO: uncomment
 		// see comment to `BlueprintExpressionLiteralToExpression`
@@ -165,6 +171,39 @@ echo "Hello $(vars.project_id)"
 	}
 }
 
+func TestDeploymentOutputReferences(t *testing.T) {
+	type test struct {
+		expr string
+		want []Reference
+		err  bool
+	}
+	tests := []test{
+		{`deployment_output("net", "id")`, []Reference{DeploymentRef("net", "", "id")}, false},
+		{`deployment_output("net", "vpc", "id")`, []Reference{DeploymentRef("net", "vpc", "id")}, false},
+		{`deployment_output("net", "vpc", "id").name`, []Reference{DeploymentRef("net", "vpc", "id")}, false},
+		{`deployment_output("net", "vpc")["id"]`, []Reference{DeploymentRef("net", "", "vpc")}, false},
+		{`"${deployment_output("net", "zone")}-${var.zone}"`, []Reference{GlobalRef("zone"), DeploymentRef("net", "", "zone")}, false},
+		{`deployment_output("net")`, nil, true},
+		{`deployment_output("net", "vpc", "id", "name")`, nil, true},
+		{`deployment_output("net", var.output)`, nil, true},
+		{`deployment_output(["net", "id"]...)`, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := ParseExpression(tc.expr)
+			if tc.err != (err != nil) {
+				t.Errorf("got unexpected error: %s", err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, e.References()); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTokensForValueNoLiteral(t *testing.T) {
 	val := cty.ObjectVal(map[string]cty.Value{
 		"tan": cty.TupleVal([]cty.Value{
@@ -0,0 +1,249 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/maps"
+)
+
+// eachModule is a pseudo-module that refers to the current instance of
+// replicated module, e.g. `$(each.key)` or `$(each.value.zone)`.
+const eachModule ModuleID = "each"
+
+var invalidModuleIDCharsRe = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// InstanceID returns ID of the instance of module replicated with `for_each`.
+func InstanceID(id ModuleID, key string) ModuleID {
+	return ModuleID(fmt.Sprintf("%s_%s", id, invalidModuleIDCharsRe.ReplaceAllString(key, "_")))
+}
+
+type forEachInstance struct {
+	key string
+	val cty.Value
+}
+
+// evalForEach evaluates `for_each` expression into ordered instances.
+// Same as Terraform, it accepts either a map (object) or a set (list) of strings.
+func (bp *Blueprint) evalForEach(p Path, y *YamlValue) ([]forEachInstance, error) {
+	ev, err := bp.evalVarsExpression(p, "for_each", y.Unwrap())
+	if err != nil {
+		return nil, err
+	}
+	if !ev.IsWhollyKnown() || ev.IsNull() {
+		return nil, BpError{p, fmt.Errorf("`for_each` must be a map or a list of strings, got %#v", ev)}
+	}
+
+	res := []forEachInstance{}
+	ty := ev.Type()
+	switch {
+	case ty.IsMapType() || ty.IsObjectType():
+		vm := ev.AsValueMap()
+		keys := maps.Keys(vm)
+		slices.Sort(keys)
+		for _, k := range keys {
+			res = append(res, forEachInstance{k, vm[k]})
+		}
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		seen := map[string]bool{}
+		for _, v := range ev.AsValueSlice() {
+			if v.Type() != cty.String {
+				return nil, BpError{p, fmt.Errorf("`for_each` list must only contain strings, got %#v", v)}
+			}
+			k := v.AsString()
+			if seen[k] {
+				return nil, BpError{p, fmt.Errorf("`for_each` list contains duplicate %q", k)}
+			}
+			seen[k] = true
+			res = append(res, forEachInstance{k, v})
+		}
+	default:
+		return nil, BpError{p, fmt.Errorf("`for_each` must be a map or a list of strings, got %s", ty.FriendlyName())}
+	}
+	return res, nil
+}
+
+// expandForEach replaces every module with `for_each` by its instances.
+// Instances get IDs derived from the module ID and the instance key,
+// `each.key` and `each.value` in instance settings are substituted.
+// `use` of the replicated module fans out to all of its instances.
+func (bp *Blueprint) expandForEach() error {
+	errs := Errors{}
+	instances := map[ModuleID][]ModuleID{}
+	groups := make([]int, len(bp.Groups))
+	modules := make([][]int, len(bp.Groups))
+	replicated := false
+
+	for ig := range bp.Groups {
+		g := &bp.Groups[ig]
+		groups[ig] = ig
+		mods := []Module{}
+		for im, m := range g.Modules {
+			mp := Root.Groups.At(ig).Modules.At(im)
+			if m.ForEach == nil {
+				errs.Add(checkNoEachReferences(mp, m))
+				mods = append(mods, m)
+				modules[ig] = append(modules[ig], im)
+				continue
+			}
+
+			replicated = true
+			fe, err := bp.evalForEach(mp.ForEach, m.ForEach)
+			if errs.Add(err); err != nil {
+				continue
+			}
+			instances[m.ID] = []ModuleID{}
+			for _, inst := range fe {
+				c, err := bp.makeInstance(mp, m, inst)
+				if errs.Add(err); err != nil {
+					continue
+				}
+				instances[m.ID] = append(instances[m.ID], c.ID)
+				mods = append(mods, c)
+				modules[ig] = append(modules[ig], im)
+			}
+		}
+		g.Modules = mods
+	}
+	if errs.Any() || !replicated {
+		return errs.OrNil()
+	}
+
+	for ig := range bp.Groups {
+		for im := range bp.Groups[ig].Modules {
+			m := &bp.Groups[ig].Modules[im]
+			errs.Add(checkNoReplicatedReferences(Root.Groups.At(ig).Modules.At(im), *m, instances))
+			use := ModuleIDs{}
+			for _, u := range m.Use {
+				if ids, ok := instances[u]; ok {
+					use = append(use, ids...)
+				} else {
+					use = append(use, u)
+				}
+			}
+			m.Use = use
+		}
+	}
+	if bp.YamlCtx != nil {
+		bp.YamlCtx.relocate(groups, modules)
+	}
+	return errs.OrNil()
+}
+
+func (bp *Blueprint) makeInstance(mp ModulePath, m Module, inst forEachInstance) (Module, error) {
+	c := m.Clone()
+	c.ID = InstanceID(m.ID, inst.key)
+	c.ForEach = nil
+
+	errs := Errors{}
+	settings := map[string]cty.Value{}
+	for k, v := range m.Settings.Items() {
+		sv, err := cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+			e, is := IsExpressionValue(v)
+			if !is {
+				return v, nil
+			}
+			se, err := substituteEach(e, inst)
+			if err != nil {
+				return cty.NilVal, BpError{mp.Settings.Dot(k).Cty(p), err}
+			}
+			return se, nil
+		})
+		errs.Add(err)
+		settings[k] = sv
+	}
+	c.Settings = NewDict(settings)
+	return c, errs.OrNil()
+}
+
+func eachTraversal(attr string) hcl.Traversal {
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: "module"},
+		hcl.TraverseAttr{Name: string(eachModule)},
+		hcl.TraverseAttr{Name: attr}}
+}
+
+// substituteEach replaces `each.key` and `each.value` in the expression with
+// values of the instance, the result is evaluated if no references are left.
+func substituteEach(e Expression, inst forEachInstance) (cty.Value, error) {
+	uses := false
+	for _, r := range e.References() {
+		if r.GlobalVar || r.Module != eachModule {
+			continue
+		}
+		if r.Name != "key" && r.Name != "value" {
+			return cty.NilVal, fmt.Errorf("unsupported attribute %q of `each`, only `each.key` and `each.value` are available", r.Name)
+		}
+		uses = true
+	}
+	if !uses {
+		return e.AsValue(), nil
+	}
+
+	toks := e.Tokenize()
+	toks = replaceTokens(toks, hclwrite.TokensForTraversal(eachTraversal("key")), TokensForValue(cty.StringVal(inst.key)))
+	toks = replaceTokens(toks, hclwrite.TokensForTraversal(eachTraversal("value")), TokensForValue(inst.val))
+	se, err := ParseExpression(string(toks.Bytes()))
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if len(se.References()) > 0 {
+		return se.AsValue(), nil
+	}
+	// evaluate if possible, keep function calls intact for later evaluation (e.g. `ghpc_stage`)
+	if v, err := se.Eval(&hcl.EvalContext{}); err == nil {
+		return v, nil
+	}
+	return se.AsValue(), nil
+}
+
+func checkNoEachReferences(mp ModulePath, m Module) error {
+	errs := Errors{}
+	for k, v := range m.Settings.Items() {
+		for r, rp := range valueReferences(v) {
+			if !r.GlobalVar && r.Module == eachModule {
+				errs.At(mp.Settings.Dot(k).Cty(rp), errors.New("`each` can only be used in settings of modules with `for_each`"))
+			}
+		}
+	}
+	return errs.OrNil()
+}
+
+// checkNoReplicatedReferences checks that settings refer to instances
+// of replicated modules rather than to the replicated module itself.
+func checkNoReplicatedReferences(mp ModulePath, m Module, instances map[ModuleID][]ModuleID) error {
+	errs := Errors{}
+	for k, v := range m.Settings.Items() {
+		for r, rp := range valueReferences(v) {
+			ids, ok := instances[r.Module]
+			if r.GlobalVar || !ok {
+				continue
+			}
+			err := fmt.Errorf("module %q is replicated with `for_each`, refer to one of its instances", r.Module)
+			if len(ids) > 0 {
+				err = HintError{Hint: fmt.Sprintf("e.g. $(%s.%s)", ids[0], r.Name), Err: err}
+			}
+			errs.At(mp.Settings.Dot(k).Cty(rp), err)
+		}
+	}
+	return errs.OrNil()
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExpandForEach(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"bp.yaml": `
+blueprint_name: replicated
+vars:
+  deployment_name: dep
+  zones: [us-central1-a, us-central1-b]
+  partitions:
+    debug: {count: 2}
+    compute: {count: 10}
+deployment_groups:
+- group: primary
+  modules:
+  - id: network
+    source: modules/network/vpc
+  - id: nodeset
+    source: community/modules/compute/schedmd-slurm-gcp-v6-nodeset
+    for_each: $(vars.zones)
+    use: [network]
+    settings:
+      name: ns_$(each.key)
+      zone: $(each.value)
+      subnetwork: $(network.subnetwork_self_link)
+  - id: partition
+    source: community/modules/compute/schedmd-slurm-gcp-v6-partition
+    for_each: $(vars.partitions)
+    settings:
+      partition_name: $(each.key)
+      node_count: $(each.value.count)
+  - id: controller
+    source: community/modules/scheduler/schedmd-slurm-gcp-v6-controller
+    use: [network, nodeset]
+`})
+
+	bp, ctx, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bp.expandForEach(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []ModuleID{}
+	for _, m := range bp.Groups[0].Modules {
+		if m.ForEach != nil {
+			t.Errorf("module %q: for_each should be cleared", m.ID)
+		}
+		got = append(got, m.ID)
+	}
+	want := []ModuleID{"network", "nodeset_us-central1-a", "nodeset_us-central1-b", "partition_compute", "partition_debug", "controller"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("modules mismatch (-want +got):\n%s", diff)
+	}
+
+	ns := bp.Groups[0].Modules[2]
+	wantSettings := map[string]cty.Value{
+		"name":       cty.StringVal("ns_us-central1-b"),
+		"zone":       cty.StringVal("us-central1-b"),
+		"subnetwork": MustParseExpression("module.network.subnetwork_self_link").AsValue(),
+	}
+	if diff := cmp.Diff(wantSettings, ns.Settings.Items(), ctydebug.CmpOptions); diff != "" {
+		t.Errorf("settings mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(ModuleIDs{"network"}, ns.Use); diff != "" {
+		t.Errorf("use mismatch (-want +got):\n%s", diff)
+	}
+	if v := bp.Groups[0].Modules[3].Settings.Get("node_count"); !v.RawEquals(cty.NumberIntVal(10)) {
+		t.Errorf("got node_count=%#v", v)
+	}
+
+	ctrl := bp.Groups[0].Modules[5]
+	if diff := cmp.Diff(ModuleIDs{"network", "nodeset_us-central1-a", "nodeset_us-central1-b"}, ctrl.Use); diff != "" {
+		t.Errorf("controller use mismatch (-want +got):\n%s", diff)
+	}
+
+	// instances are located at the replicated module
+	for _, tc := range []struct {
+		p    Path
+		line int
+	}{
+		{Root.Groups.At(0).Modules.At(1).ID, 14},
+		{Root.Groups.At(0).Modules.At(2).Settings.Dot("zone"), 20},
+		{Root.Groups.At(0).Modules.At(4).ID, 22},
+		{Root.Groups.At(0).Modules.At(5).ID, 28},
+	} {
+		pos, ok := ctx.Pos(tc.p)
+		if !ok || pos.Line != tc.line {
+			t.Errorf("%s: got line %d (found=%t), want %d", tc.p, pos.Line, ok, tc.line)
+		}
+	}
+}
+
+func TestSubstituteEach(t *testing.T) {
+	inst := forEachInstance{"a", cty.ObjectVal(map[string]cty.Value{
+		"zone": cty.StringVal("z"),
+		"tags": cty.TupleVal([]cty.Value{cty.StringVal("x")}),
+	})}
+	type test struct {
+		input string
+		want  cty.Value
+		err   bool
+	}
+	for _, tc := range []test{
+		{"module.each.key", cty.StringVal("a"), false},
+		{`"${module.each.key}-${module.each.value.zone}"`, cty.StringVal("a-z"), false},
+		{"module.each.value.tags[0]", cty.StringVal("x"), false},
+		{"module.each.value", inst.val, false},
+		{"var.green", MustParseExpression("var.green").AsValue(), false},
+		{`"${module.each.key}${var.green}"`, MustParseExpression(`"${"a"}${var.green}"`).AsValue(), false},
+		{`upper(module.each.key)`, MustParseExpression(`upper("a")`).AsValue(), false},
+		{"module.each.index", cty.NilVal, true},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := substituteEach(MustParseExpression(tc.input), inst)
+			if (err != nil) != tc.err {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, ctydebug.CmpOptions); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExpandForEachErrors(t *testing.T) {
+	type test struct {
+		yaml string
+		err  string
+	}
+	for name, tc := range map[string]test{
+		"not a collection": {`
+  - id: a
+    source: modules/network/vpc
+    for_each: $(vars.deployment_name)`,
+			"`for_each` must be a map or a list of strings"},
+		"not strings": {`
+  - id: a
+    source: modules/network/vpc
+    for_each: [1, 2]`,
+			"`for_each` list must only contain strings"},
+		"duplicates": {`
+  - id: a
+    source: modules/network/vpc
+    for_each: [x, x]`,
+			"`for_each` list contains duplicate \"x\""},
+		"each outside for_each": {`
+  - id: a
+    source: modules/network/vpc
+    settings:
+      name: $(each.key)`,
+			"`each` can only be used in settings of modules with `for_each`"},
+		"reference to replicated": {`
+  - id: a
+    source: modules/network/vpc
+    for_each: [x, y]
+  - id: b
+    source: modules/network/vpc
+    settings:
+      network: $(a.network_self_link)`,
+			`module "a" is replicated with ` + "`for_each`" + `, refer to one of its instances - e.g. $(a_x.network_self_link)`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeTestFiles(t, map[string]string{"bp.yaml": `
+blueprint_name: replicated
+vars:
+  deployment_name: dep
+deployment_groups:
+- group: primary
+  modules:` + tc.yaml + "\n"})
+			bp, _, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = bp.expandForEach()
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("got error %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyJson "github.com/zclconf/go-cty/cty/json"
+	"gopkg.in/yaml.v3"
+)
+
+// fileFunctions read files relative to the blueprint, they are evaluated
+// in deployment variables and module settings during expansion, see evalFileFunctions.
+var fileFunctions = []string{"file", "templatefile"}
+
+// cidrSubnetFunc calculates a subnet address within a given IP network prefix,
+// same as Terraform `cidrsubnet`.
+var cidrSubnetFunc = function.New(&function.Spec{
+	Description: `Calculates a subnet address within given IP network address prefix`,
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "newbits", Type: cty.Number},
+		{Name: "netnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		_, network, err := net.ParseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, function.NewArgErrorf(0, "invalid CIDR expression: %s", err)
+		}
+		newbits, acc := args[1].AsBigFloat().Int64()
+		if acc != big.Exact {
+			return cty.NilVal, function.NewArgErrorf(1, "newbits must be a whole number")
+		}
+		netnum, acc := args[2].AsBigFloat().Int(nil)
+		if acc != big.Exact || netnum.Sign() < 0 {
+			return cty.NilVal, function.NewArgErrorf(2, "netnum must be a non-negative whole number")
+		}
+
+		ones, bits := network.Mask.Size()
+		newLen := ones + int(newbits)
+		if newbits < 0 || newLen > bits {
+			return cty.NilVal, function.NewArgErrorf(1, "insufficient address space to extend prefix of %d by %d", ones, newbits)
+		}
+		if netnum.BitLen() > int(newbits) {
+			return cty.NilVal, function.NewArgErrorf(2, "prefix extension of %d does not accommodate a subnet numbered %s", newbits, netnum)
+		}
+
+		ip := new(big.Int).SetBytes(network.IP)
+		ip.Or(ip, netnum.Lsh(netnum, uint(bits-newLen)))
+		subnet := net.IPNet{
+			IP:   ip.FillBytes(make([]byte, len(network.IP))),
+			Mask: net.CIDRMask(newLen, bits),
+		}
+		return cty.StringVal(subnet.String()), nil
+	},
+})
+
+// coalesceFunc returns the first argument that is not null or an empty string,
+// same as Terraform `coalesce` (stdlib.CoalesceFunc doesn't skip empty strings).
+var coalesceFunc = function.New(&function.Spec{
+	Description: `Returns the first of the given arguments that isn't null or an empty string`,
+	VarParam:    &function.Parameter{Name: "vals", Type: cty.DynamicPseudoType, AllowNull: true},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		tys := make([]cty.Type, len(args))
+		for i, a := range args {
+			tys[i] = a.Type()
+		}
+		ty, _ := convert.UnifyUnsafe(tys)
+		if ty == cty.NilType {
+			return cty.NilType, errors.New("all arguments must have the same type")
+		}
+		return ty, nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		for _, a := range args {
+			if a.IsNull() || (a.Type() == cty.String && a.AsString() == "") {
+				continue
+			}
+			return convert.Convert(a, retType)
+		}
+		return cty.NilVal, errors.New("no non-null, non-empty-string arguments")
+	},
+})
+
+// yamlEncodeFunc encodes a given value to a string using YAML syntax.
+var yamlEncodeFunc = function.New(&function.Spec{
+	Description: `Encodes a given value to a string using YAML syntax`,
+	Params:      []function.Parameter{{Name: "val", Type: cty.DynamicPseudoType, AllowNull: true}},
+	Type:        function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		j, err := ctyJson.SimpleJSONValue{Value: args[0]}.MarshalJSON()
+		if err != nil {
+			return cty.NilVal, err
+		}
+		var g interface{}
+		if err := json.Unmarshal(j, &g); err != nil {
+			return cty.NilVal, err
+		}
+		y, err := yaml.Marshal(g)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.StringVal(string(y)), nil
+	},
+})
+
+// resolvePath resolves relative paths against the blueprint directory.
+func (bp *Blueprint) resolvePath(p string) string {
+	if filepath.IsAbs(p) || bp.path == "" {
+		return p
+	}
+	return filepath.Join(filepath.Dir(bp.path), p)
+}
+
+func (bp *Blueprint) readFile(p string) (string, error) {
+	b, err := os.ReadFile(bp.resolvePath(p))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %q: %w", p, err)
+	}
+	return string(b), nil
+}
+
+// Makes a `file` function that reads files relative to the blueprint
+func (bp *Blueprint) makeFileFunc() function.Function {
+	return function.New(&function.Spec{
+		Description: `Reads the contents of a file, relative to the blueprint, as a string`,
+		Params:      []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:        function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			s, err := bp.readFile(args[0].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(s), nil
+		},
+	})
+}
+
+// Makes a `templatefile` function that renders HCL templates relative to the blueprint.
+// Templates can use all other functions, except `templatefile` itself.
+func (bp *Blueprint) makeTemplateFileFunc(funcs map[string]function.Function) function.Function {
+	return function.New(&function.Spec{
+		Description: `Renders a template file, relative to the blueprint, with a given set of variables`,
+		Params: []function.Parameter{
+			{Name: "path", Type: cty.String},
+			{Name: "vars", Type: cty.DynamicPseudoType},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			p := args[0].AsString()
+			s, err := bp.readFile(p)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vars := args[1]
+			if !vars.Type().IsObjectType() && !vars.Type().IsMapType() {
+				return cty.NilVal, function.NewArgErrorf(1, "invalid vars value: must be a map or an object")
+			}
+
+			tmpl, diag := hclsyntax.ParseTemplate([]byte(s), p, hcl.InitialPos)
+			if diag.HasErrors() {
+				return cty.NilVal, diag
+			}
+			ctx := &hcl.EvalContext{Variables: vars.AsValueMap(), Functions: funcs}
+			v, diag := tmpl.Value(ctx)
+			if diag.HasErrors() {
+				return cty.NilVal, diag
+			}
+			if v, err = convert.Convert(v, cty.String); err != nil {
+				return cty.NilVal, fmt.Errorf("template %q must produce a string: %w", p, err)
+			}
+			return v, nil
+		},
+	})
+}
+
+// Evaluate all `file` and `templatefile` calls in deployment variables and
+// module settings, so expanded blueprint doesn't depend on files relative to the blueprint.
+func (bp *Blueprint) evalFileFunctions() error {
+	var ctx *hcl.EvalContext
+	errs := Errors{}
+	vars, err := bp.evalFileFunctionsIn(bp.Vars, Root.Vars, &ctx, nil)
+	if errs.Add(err); err == nil {
+		bp.Vars = vars
+	}
+	bp.WalkModulesSafe(func(p ModulePath, m *Module) {
+		settings, err := bp.evalFileFunctionsIn(m.Settings, p.Settings, &ctx, bp.readsBlueprintFile)
+		if errs.Add(err); err == nil {
+			m.Settings = settings
+		}
+	})
+	return errs.OrNil()
+}
+
+// readsBlueprintFile checks if the call of a file function reads a file relative
+// to the blueprint. Calls referencing module outputs or files staged with
+// `ghpc_stage` are evaluated by Terraform instead.
+func (bp *Blueprint) readsBlueprintFile(call Expression) bool {
+	if callsFunction(call, "ghpc_stage") {
+		return false
+	}
+	for _, ref := range call.References() {
+		if !ref.GlobalVar {
+			return false
+		}
+		if e, is := IsExpressionValue(bp.Vars.Get(ref.Name)); is && !bp.readsBlueprintFile(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// evalFileFunctionsIn evaluates `file` and `templatefile` calls in values of
+// the dictionary, calls for which cond returns false are kept as is.
+// The evaluation context is only built if needed.
+func (bp *Blueprint) evalFileFunctionsIn(d Dict, dp dictPath, ctx **hcl.EvalContext, cond func(Expression) bool) (Dict, error) {
+	if len(d.Items()) == 0 {
+		return d, nil
+	}
+	errs := Errors{}
+	res := map[string]cty.Value{}
+	for k, v := range d.Items() {
+		ev, err := cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+			e, is := IsExpressionValue(v)
+			if !is || !slices.ContainsFunc(fileFunctions, func(fn string) bool { return callsFunction(e, fn) }) {
+				return v, nil
+			}
+			if *ctx == nil { // only evaluate vars if needed
+				var err error
+				if *ctx, err = bp.evalCtx(); err != nil {
+					return cty.NilVal, err
+				}
+			}
+			for _, fn := range fileFunctions {
+				var err error
+				if e, err = partialEvalIf(e, fn, *ctx, cond); err != nil {
+					return cty.NilVal, BpError{dp.Dot(k).Cty(p), err}
+				}
+			}
+			kept := slices.ContainsFunc(fileFunctions, func(fn string) bool { return callsFunction(e, fn) })
+			if len(e.References()) == 0 && !kept { // store fully evaluated expression as a plain value
+				return e.Eval(*ctx)
+			}
+			return e.AsValue(), nil
+		})
+		errs.Add(err)
+		res[k] = ev
+	}
+	if errs.Any() {
+		return d, errs.OrNil()
+	}
+	return NewDict(res), nil
+}
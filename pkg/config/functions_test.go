@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFunctions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "motd.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "startup.tftpl"), []byte(`#!/bin/bash
+echo ${upper(name)} > %{ for f in files }${f} %{ endfor }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bp := Blueprint{
+		path: filepath.Join(dir, "bp.yaml"),
+		Vars: NewDict(map[string]cty.Value{
+			"name":    cty.StringVal("Pink"),
+			"network": cty.StringVal("10.0.0.0/16"),
+		}),
+	}
+
+	type test struct {
+		input string
+		want  cty.Value
+	}
+	tests := []test{
+		{`format("%s-%03d", var.name, 7)`, cty.StringVal("Pink-007")},
+		{`join(",", ["a", "b"])`, cty.StringVal("a,b")},
+		{`split(",", "a,b")`, cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")})},
+		{`lower(var.name)`, cty.StringVal("pink")},
+		{`upper(var.name)`, cty.StringVal("PINK")},
+		{`replace("a-b-c", "-", "_")`, cty.StringVal("a_b_c")},
+		{`concat(["a"], ["b"])`, cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")})},
+		{`lookup({a = "x"}, "b", "y")`, cty.StringVal("y")},
+		{`coalesce("", var.name)`, cty.StringVal("Pink")},
+		{`cidrsubnet(var.network, 8, 2)`, cty.StringVal("10.0.2.0/24")},
+		{`cidrsubnet("fd00::/56", 8, 255)`, cty.StringVal("fd00:0:0:ff::/64")},
+		{`jsonencode({a = [1, 2]})`, cty.StringVal(`{"a":[1,2]}`)},
+		{`yamlencode({a = [1, 2]})`, cty.StringVal("a:\n    - 1\n    - 2\n")},
+		{`file("motd.txt")`, cty.StringVal("hello")},
+		{`templatefile("startup.tftpl", {name = var.name, files = ["x", "y"]})`,
+			cty.StringVal("#!/bin/bash\necho PINK > x y ")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := bp.Eval(MustParseExpression(tc.input).AsValue())
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, got, ctydebug.CmpOptions); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFunctionsErrors(t *testing.T) {
+	bp := Blueprint{path: filepath.Join(t.TempDir(), "bp.yaml")}
+	tests := map[string]string{
+		`cidrsubnet("10.0.0.0/30", 8, 1)`:   "insufficient address space",
+		`cidrsubnet("10.0.0.0/16", 2, 4)`:   "does not accommodate a subnet numbered 4",
+		`cidrsubnet("nope", 2, 1)`:          "invalid CIDR expression",
+		`file("missing.txt")`:               "failed to read file",
+		`templatefile("missing.tftpl", {})`: "failed to read file",
+		`sha256("x")`:                       "unsupported function",
+		`coalesce("", "")`:                  "no non-null, non-empty-string arguments",
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := bp.Eval(MustParseExpression(input).AsValue())
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("expected error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
+
+func TestEvalFileFunctions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "key.pub"), []byte("ssh-rsa AAA"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bp := Blueprint{
+		path: filepath.Join(dir, "bp.yaml"),
+		Vars: NewDict(map[string]cty.Value{
+			"key_file": cty.StringVal("key.pub"),
+			"key":      MustParseExpression(`file(var.key_file)`).AsValue(),
+			"user":     MustParseExpression(`"admin:${file("key.pub")}"`).AsValue(),
+			"upper":    MustParseExpression(`upper(var.key_file)`).AsValue(),
+		}),
+	}
+	if err := bp.evalFileFunctions(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]cty.Value{
+		"key_file": cty.StringVal("key.pub"),
+		"key":      cty.StringVal("ssh-rsa AAA"),
+		"user":     cty.StringVal("admin:ssh-rsa AAA"),
+		"upper":    MustParseExpression(`upper(var.key_file)`).AsValue(), // kept as is
+	}
+	if diff := cmp.Diff(want, bp.Vars.Items(), ctydebug.CmpOptions); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvalFileFunctionsInSettings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "startup.sh"), []byte("#!/bin/bash"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bp := Blueprint{
+		path: filepath.Join(dir, "bp.yaml"),
+		Vars: NewDict(map[string]cty.Value{
+			"script": cty.StringVal("startup.sh"),
+			"staged": MustParseExpression(`ghpc_stage("startup.sh")`).AsValue(),
+		}),
+		Groups: []Group{{Name: "primary", Modules: []Module{{
+			ID: "vm",
+			Settings: NewDict(map[string]cty.Value{
+				"startup_script": MustParseExpression(`file(var.script)`).AsValue(),
+				"metadata": cty.ObjectVal(map[string]cty.Value{
+					"script": MustParseExpression(`file("startup.sh")`).AsValue(),
+				}),
+				"network": MustParseExpression(`module.net.network_id`).AsValue(),
+				// evaluated by Terraform
+				"stage":  MustParseExpression(`file(ghpc_stage("startup.sh"))`).AsValue(),
+				"staged": MustParseExpression(`file(var.staged)`).AsValue(),
+				"output": MustParseExpression(`file(module.net.path)`).AsValue(),
+			}),
+		}}}},
+	}
+	if err := bp.evalFileFunctions(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]cty.Value{
+		"startup_script": cty.StringVal("#!/bin/bash"),
+		"metadata":       cty.ObjectVal(map[string]cty.Value{"script": cty.StringVal("#!/bin/bash")}),
+		"network":        MustParseExpression(`module.net.network_id`).AsValue(), // kept as is
+		"stage":          MustParseExpression(`file(ghpc_stage("startup.sh"))`).AsValue(),
+		"staged":         MustParseExpression(`file(var.staged)`).AsValue(),
+		"output":         MustParseExpression(`file(module.net.path)`).AsValue(),
+	}
+	if diff := cmp.Diff(want, bp.Groups[0].Modules[0].Settings.Items(), ctydebug.CmpOptions); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+
+	bp.Groups[0].Modules[0].Settings = NewDict(map[string]cty.Value{
+		"startup_script": MustParseExpression(`file("missing.sh")`).AsValue(),
+	})
+	err := bp.evalFileFunctions()
+	var berr BpError
+	if !errors.As(err, &berr) {
+		t.Fatalf("want BpError, got %v", err)
+	}
+	if want := Root.Groups.At(0).Modules.At(0).Settings.Dot("startup_script"); berr.Path.String() != want.String() {
+		t.Errorf("got error at %q, want %q", berr.Path, want)
+	}
+}
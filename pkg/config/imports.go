@@ -0,0 +1,375 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hpc-toolkit/pkg/sourcereader"
+
+	"github.com/hashicorp/go-getter"
+)
+
+// importer resolves `imports` of a blueprint.
+type importer struct {
+	// names of the files being imported, used to detect import cycles
+	chain []string
+	// temporary directories with downloaded remote imports
+	tmpDirs []string
+	// keeps downloads between parses of blueprints, may be nil
+	cache *ImportCache
+}
+
+// downloadImport downloads the go-getter package of a remote import, replaced in tests.
+var downloadImport = func(dir string, dst string) error {
+	return (sourcereader.GoGetterSourceReader{}).GetModule(dir, dst)
+}
+
+// ErrNotFetched is returned by parsing with an ImportCache that doesn't have
+// a remote import yet, see ImportCache.Fetch.
+var ErrNotFetched = errors.New("the remote import is not downloaded yet")
+
+// ImportCache keeps remote imports to parse blueprints repeatedly, e.g. a
+// blueprint being edited on every change. Parsing doesn't download, imports
+// missing in the cache fail it with ErrNotFetched and are downloaded by Fetch,
+// which may run concurrently with parsing. Failed downloads are retried after
+// RetryAfter. Close removes the downloaded files.
+type ImportCache struct {
+	RetryAfter time.Duration
+	mu         sync.Mutex
+	dirs       map[string]string // downloaded packages by go-getter address
+	failures   map[string]importFailure
+	missing    map[string]bool // requested by parsing since the last Fetch
+}
+
+type importFailure struct {
+	err error
+	at  time.Time
+}
+
+// NewImportCache returns an empty ImportCache.
+func NewImportCache(retryAfter time.Duration) *ImportCache {
+	return &ImportCache{
+		RetryAfter: retryAfter,
+		dirs:       map[string]string{},
+		failures:   map[string]importFailure{},
+		missing:    map[string]bool{},
+	}
+}
+
+// Close removes the downloaded imports, it must not run concurrently with Fetch.
+func (c *ImportCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range c.dirs {
+		os.RemoveAll(filepath.Dir(d))
+	}
+	c.dirs = map[string]string{}
+}
+
+// lookup returns a local directory with the go-getter package,
+// packages that are not downloaded yet are recorded for Fetch.
+func (c *ImportCache) lookup(dir string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d, ok := c.dirs[dir]; ok {
+		return d, nil
+	}
+	if f, ok := c.failures[dir]; ok && time.Since(f.at) < c.RetryAfter {
+		return "", f.err
+	}
+	c.missing[dir] = true
+	return "", ErrNotFetched
+}
+
+// Fetch downloads imports requested by parsing since the last Fetch,
+// the cache isn't locked during downloads.
+func (c *ImportCache) Fetch() {
+	c.mu.Lock()
+	missing := c.missing
+	c.missing = map[string]bool{}
+	c.mu.Unlock()
+
+	for dir := range missing {
+		c.mu.Lock()
+		_, done := c.dirs[dir]
+		f, failed := c.failures[dir]
+		c.mu.Unlock()
+		if done || failed && time.Since(f.at) < c.RetryAfter {
+			continue // requested again while fetched by the previous Fetch
+		}
+		dst, err := download(dir)
+
+		c.mu.Lock()
+		if err != nil {
+			c.failures[dir] = importFailure{err, time.Now()}
+		} else {
+			delete(c.failures, dir)
+			c.dirs[dir] = dst
+		}
+		c.mu.Unlock()
+	}
+}
+
+// download downloads the go-getter package into a new temporary directory.
+func download(dir string) (string, error) {
+	tmp, err := os.MkdirTemp("", "gcluster-import-*")
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(tmp, "src")
+	if err := downloadImport(dir, dst); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return dst, nil
+}
+
+// resolveImports merges all (transitively) imported blueprint files into bp.
+// Imports are applied in the order they are listed, later imports take
+// precedence over earlier ones and the importing blueprint takes precedence
+// over all of its imports:
+// * deployment variables, providers and modules (matched by group name and
+// module ID) are replaced as a whole;
+// * groups with new names and modules with new IDs are appended;
+// * validators are concatenated.
+// The ctx is updated to locate every path of the resolved blueprint in the file
+// that defines it.
+// Remote imports are looked up in the cache, if any, otherwise downloaded.
+func resolveImports(bp *Blueprint, ctx *YamlCtx, cache *ImportCache) error {
+	im := importer{chain: []string{bp.path}, cache: cache}
+	defer im.cleanup()
+	return im.resolve(bp, ctx)
+}
+
+func (im *importer) cleanup() {
+	for _, d := range im.tmpDirs {
+		os.RemoveAll(d)
+	}
+}
+
+func (im *importer) resolve(bp *Blueprint, ctx *YamlCtx) error {
+	o := overlay{locs: map[yPath]yamlLoc{}}
+	for i, src := range bp.Imports {
+		frag, fctx, err := im.load(Root.Imports.At(i), src, filepath.Dir(bp.path))
+		if err != nil {
+			return err
+		}
+		o.add(frag, fctx.locations())
+	}
+	o.add(*bp, ctx.locations())
+
+	res := o.bp
+	res.Imports = nil // all imports are resolved
+	res.path = bp.path
+	*bp = res
+	ctx.imported = o.locs
+	return nil
+}
+
+func (im *importer) load(p Path, src string, baseDir string) (Blueprint, YamlCtx, error) {
+	file, name, err := im.fetch(src, baseDir)
+	if err != nil {
+		return Blueprint{}, YamlCtx{}, BpError{p, err}
+	}
+	if slices.Contains(im.chain, name) {
+		cycle := strings.Join(append(slices.Clone(im.chain), name), " -> ")
+		return Blueprint{}, YamlCtx{}, BpError{p, fmt.Errorf("import cycle detected: %s", cycle)}
+	}
+
+	frag, ctx, err := parseYamlFile[Blueprint](file)
+	ctx.File = name
+	if err != nil {
+		return Blueprint{}, YamlCtx{}, ImportError{p, ctx, err}
+	}
+	frag.path = file
+
+	if len(frag.Imports) > 0 {
+		im.chain = append(im.chain, name)
+		err = im.resolve(&frag, &ctx)
+		im.chain = im.chain[:len(im.chain)-1]
+		if err != nil {
+			return Blueprint{}, YamlCtx{}, ImportError{p, ctx, err}
+		}
+	}
+	return frag, ctx, nil
+}
+
+// fetch returns a local path to the imported file and its name.
+// Local paths are relative to the importing blueprint, any other source
+// is downloaded with go-getter and must point to a file using `//` subdir syntax,
+// e.g. `github.com/org/repo//fragments/network.yaml?ref=v1.0.0`.
+func (im *importer) fetch(src string, baseDir string) (string, string, error) {
+	if sourcereader.IsLocalPath(src) {
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(baseDir, src)
+		}
+		return filepath.Clean(src), filepath.Clean(src), nil
+	}
+
+	dir, sub := getter.SourceDirSubdir(src)
+	if sub == "" {
+		return "", "", fmt.Errorf("remote import %q must point to a file, e.g. github.com/org/repo//path/to/file.yaml", src)
+	}
+	if im.cache != nil {
+		dst, err := im.cache.lookup(dir)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to import %q: %w", src, err)
+		}
+		return filepath.Join(dst, sub), src, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "gcluster-import-*")
+	if err != nil {
+		return "", "", err
+	}
+	im.tmpDirs = append(im.tmpDirs, tmp)
+
+	dst := filepath.Join(tmp, "src")
+	if err := downloadImport(dir, dst); err != nil {
+		return "", "", fmt.Errorf("failed to import %q: %w", src, err)
+	}
+	return filepath.Join(dst, sub), src, nil
+}
+
+// overlay accumulates blueprints, each added blueprint takes precedence
+// over previously added ones.
+type overlay struct {
+	bp   Blueprint
+	locs map[yPath]yamlLoc
+}
+
+func (o *overlay) add(src Blueprint, locs map[yPath]yamlLoc) {
+	b := &o.bp
+	// top-level scalars and positions of top-level blocks
+	for p, l := range locs {
+		if !strings.ContainsAny(string(p), ".[") {
+			o.locs[p] = l
+		}
+	}
+	if src.BlueprintName != "" {
+		b.BlueprintName = src.BlueprintName
+	}
+	if src.GhpcVersion != "" {
+		b.GhpcVersion = src.GhpcVersion
+	}
+	if src.ValidationLevel != 0 {
+		b.ValidationLevel = src.ValidationLevel
+	}
+	if src.ToolkitModulesURL != "" {
+		b.ToolkitModulesURL = src.ToolkitModulesURL
+	}
+	if src.ToolkitModulesVersion != "" {
+		b.ToolkitModulesVersion = src.ToolkitModulesVersion
+	}
+
+	for i, v := range src.Validators {
+		b.Validators = append(b.Validators, v)
+		o.move(Root.Validators.At(len(b.Validators)-1), Root.Validators.At(i), locs)
+	}
+
+	for k, v := range src.Vars.Items() {
+		b.Vars = b.Vars.With(k, v)
+		o.move(Root.Vars.Dot(k), Root.Vars.Dot(k), locs)
+	}
+
+	if src.TerraformBackendDefaults.Type != "" {
+		b.TerraformBackendDefaults = src.TerraformBackendDefaults
+		o.move(Root.Backend, Root.Backend, locs)
+	}
+
+	for k, p := range src.TerraformProviders {
+		if b.TerraformProviders == nil {
+			b.TerraformProviders = map[string]TerraformProvider{}
+		}
+		b.TerraformProviders[k] = p
+		o.move(Root.Provider.Dot(k), Root.Provider.Dot(k), locs)
+	}
+
+	for gi, g := range src.Groups {
+		sp := Root.Groups.At(gi)
+		di := b.GroupIndex(g.Name)
+		if di < 0 {
+			b.Groups = append(b.Groups, g.Clone())
+			o.move(Root.Groups.At(len(b.Groups)-1), sp, locs)
+		} else {
+			o.group(di, g, sp, locs)
+		}
+	}
+}
+
+func (o *overlay) group(di int, g Group, sp groupPath, locs map[yPath]yamlLoc) {
+	dg := &o.bp.Groups[di]
+	dp := Root.Groups.At(di)
+
+	if g.TerraformBackend.Type != "" {
+		dg.TerraformBackend = g.TerraformBackend
+		o.move(dp.Backend, sp.Backend, locs)
+	}
+
+	for k, p := range g.TerraformProviders {
+		if dg.TerraformProviders == nil {
+			dg.TerraformProviders = map[string]TerraformProvider{}
+		}
+		dg.TerraformProviders[k] = p
+		// NOTE: groupPath.Provider doesn't match the YAML key, build the path explicitly
+		o.moveY(yPath(dp.String()).Dot("terraform_providers").Dot(k), yPath(sp.String()).Dot("terraform_providers").Dot(k), locs)
+	}
+
+	for mi, m := range g.Modules {
+		dmi := dg.ModuleIndex(m.ID)
+		if dmi < 0 {
+			dg.Modules = append(dg.Modules, m.Clone())
+			dmi = len(dg.Modules) - 1
+		} else {
+			dg.Modules[dmi] = m.Clone()
+		}
+		o.move(dp.Modules.At(dmi), sp.Modules.At(mi), locs)
+	}
+}
+
+func (o *overlay) move(dst Path, src Path, locs map[yPath]yamlLoc) {
+	o.moveY(yPath(dst.String()), yPath(src.String()), locs)
+}
+
+// moveY replaces positions of dst and all its descendants with
+// positions of src and its descendants.
+func (o *overlay) moveY(dst yPath, src yPath, locs map[yPath]yamlLoc) {
+	for p := range o.locs {
+		if isYPathWithin(p, dst) {
+			delete(o.locs, p)
+		}
+	}
+	for p, l := range locs {
+		if isYPathWithin(p, src) {
+			o.locs[dst+p[len(src):]] = l
+		}
+	}
+}
+
+func isYPathWithin(p yPath, pref yPath) bool {
+	if !strings.HasPrefix(string(p), string(pref)) {
+		return false
+	}
+	rest := p[len(pref):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImports(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"fragments/network.yaml": `
+vars:
+  region: us-central1
+  zone: us-central1-a
+deployment_groups:
+- group: primary
+  modules:
+  - id: network
+    source: modules/network/vpc
+  - id: homefs
+    source: modules/file-system/filestore
+    use: [network]
+    settings:
+      size_gb: 1024
+`,
+		"fragments/monitoring.yaml": `
+vars:
+  zone: us-central1-b
+deployment_groups:
+- group: monitoring
+  modules:
+  - id: dashboard
+    source: modules/monitoring/dashboard
+`,
+		"bp.yaml": `
+blueprint_name: imported
+imports:
+- ./fragments/network.yaml
+- ./fragments/monitoring.yaml
+vars:
+  deployment_name: dep
+  region: europe-west4
+deployment_groups:
+- group: primary
+  modules:
+  - id: homefs
+    source: modules/file-system/filestore
+    use: [network]
+    settings:
+      size_gb: 2048
+  - id: vm
+    source: modules/compute/vm-instance
+    use: [network]
+`,
+	})
+
+	bp, ctx, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bp.Imports != nil {
+		t.Errorf("imports should be resolved, got %v", bp.Imports)
+	}
+	wantVars := map[string]cty.Value{
+		"deployment_name": cty.StringVal("dep"),
+		"region":          cty.StringVal("europe-west4"),  // importing blueprint wins
+		"zone":            cty.StringVal("us-central1-b"), // later import wins
+	}
+	if diff := cmp.Diff(wantVars, bp.Vars.Items(), cmp.Comparer(func(a, b cty.Value) bool { return a.Equals(b).True() })); diff != "" {
+		t.Errorf("vars mismatch (-want +got):\n%s", diff)
+	}
+
+	got := []string{}
+	for _, g := range bp.Groups {
+		for _, m := range g.Modules {
+			got = append(got, string(g.Name)+"."+string(m.ID))
+		}
+	}
+	want := []string{"primary.network", "primary.homefs", "primary.vm", "monitoring.dashboard"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("modules mismatch (-want +got):\n%s", diff)
+	}
+	if s := bp.Groups[0].Modules[1].Settings.Get("size_gb"); !s.Equals(cty.NumberIntVal(2048)).True() {
+		t.Errorf("homefs should be overridden by importing blueprint, got size_gb=%#v", s)
+	}
+
+	// positions point back to the file that defines the path
+	type loc struct {
+		file string
+		line int
+	}
+	for _, tc := range []struct {
+		p    Path
+		want loc
+	}{
+		{Root.Groups.At(0).Modules.At(0).Source, loc{filepath.Join(dir, "fragments/network.yaml"), 9}},
+		{Root.Groups.At(0).Modules.At(1).Settings.Dot("size_gb"), loc{"", 16}},
+		{Root.Groups.At(1).Modules.At(0).ID, loc{filepath.Join(dir, "fragments/monitoring.yaml"), 7}},
+		{Root.Vars.Dot("zone"), loc{filepath.Join(dir, "fragments/monitoring.yaml"), 3}},
+		{Root.Vars.Dot("region"), loc{"", 8}},
+		{Root.BlueprintName, loc{"", 2}},
+	} {
+		src, pos, ok := ctx.Source(tc.p)
+		if !ok {
+			t.Errorf("%s: no position found", tc.p)
+			continue
+		}
+		if got := (loc{src.File, pos.Line}); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestImportsNested(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"base.yaml": `
+vars:
+  machine_type: n2-standard-2
+`,
+		"mid/mid.yaml": `
+imports: [../base.yaml]
+vars:
+  machine_type: n2-standard-4
+  disk_size_gb: 100
+`,
+		"bp.yaml": `
+blueprint_name: nested
+imports: [./mid/mid.yaml]
+vars:
+  disk_size_gb: 200
+deployment_groups: []
+`,
+	})
+
+	bp, ctx, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := bp.Vars.Get("machine_type"); !v.Equals(cty.StringVal("n2-standard-4")).True() {
+		t.Errorf("got machine_type=%#v", v)
+	}
+	if v := bp.Vars.Get("disk_size_gb"); !v.Equals(cty.NumberIntVal(200)).True() {
+		t.Errorf("got disk_size_gb=%#v", v)
+	}
+	if src, _, _ := ctx.Source(Root.Vars.Dot("machine_type")); src.File != filepath.Join(dir, "mid/mid.yaml") {
+		t.Errorf("machine_type is located in %q", src.File)
+	}
+}
+
+func TestImportsCycle(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"a.yaml":  "imports: [./b.yaml]\n",
+		"b.yaml":  "imports: [./a.yaml]\n",
+		"bp.yaml": "blueprint_name: cycle\nimports: [./a.yaml]\n",
+	})
+
+	_, _, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "import cycle detected") {
+		t.Fatalf("expected import cycle error, got %v", err)
+	}
+	var ie ImportError
+	if !errors.As(err, &ie) || ie.Path.String() != "imports[0]" {
+		t.Errorf("expected ImportError at imports[0], got %#v", err)
+	}
+}
+
+func TestImportsErrors(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"bad.yaml": `
+deployment_groups:
+- group: primary
+  modulez: []
+`,
+		"bp.yaml": `
+blueprint_name: broken
+imports:
+- ./missing.yaml
+`,
+		"bp2.yaml": `
+blueprint_name: broken
+imports:
+- ./bad.yaml
+`,
+	})
+
+	_, _, err := NewBlueprint(filepath.Join(dir, "bp.yaml"))
+	var ie ImportError
+	if !errors.As(err, &ie) || ie.Path.String() != "imports[0]" || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("expected ImportError at imports[0], got %#v", err)
+	}
+
+	_, _, err = NewBlueprint(filepath.Join(dir, "bp2.yaml"))
+	if !errors.As(err, &ie) {
+		t.Fatalf("expected ImportError, got %#v", err)
+	}
+	if ie.Ctx.File != filepath.Join(dir, "bad.yaml") {
+		t.Errorf("unexpected file %q", ie.Ctx.File)
+	}
+	errs, ok := ie.Err.(Errors)
+	if !ok || len(errs.Errors) != 1 {
+		t.Fatalf("expected single error in imported file, got %#v", ie.Err)
+	}
+	if pe, ok := errs.Errors[0].(PosError); !ok || pe.Pos.Line != 4 {
+		t.Errorf("expected error at line 4 of imported file, got %#v", ie.Err)
+	}
+}
+
+func TestImportCache(t *testing.T) {
+	downloads := 0
+	fail := true
+	defer func(f func(string, string) error) { downloadImport = f }(downloadImport)
+	downloadImport = func(dir string, dst string) error {
+		downloads++
+		if fail {
+			return errors.New("no network")
+		}
+		return os.CopyFS(dst, os.DirFS(writeTestFiles(t, map[string]string{"net.yaml": "vars:\n  zone: us-central1-a\n"})))
+	}
+
+	cache := NewImportCache(time.Hour)
+	defer cache.Close()
+	path := filepath.Join(t.TempDir(), "bp.yaml")
+	data := []byte("blueprint_name: cached\nimports: [github.com/org/repo//net.yaml]\n")
+
+	notFetched := func() {
+		t.Helper()
+		if _, _, err := ParseBlueprintCached(path, data, cache); !errors.Is(err, ErrNotFetched) {
+			t.Fatalf("expected the import not to be fetched, got %v", err)
+		}
+	}
+	notFetched()
+	if downloads != 0 {
+		t.Errorf("parsing downloaded the import")
+	}
+	for i := 0; i < 2; i++ { // the failure is kept
+		cache.Fetch()
+		if _, _, err := ParseBlueprintCached(path, data, cache); err == nil || !strings.Contains(err.Error(), "no network") {
+			t.Fatalf("expected download error, got %v", err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("got %d downloads of failed import, want 1", downloads)
+	}
+
+	fail, cache.RetryAfter = false, 0
+	notFetched()
+	cache.Fetch()
+	for i := 0; i < 2; i++ {
+		bp, _, err := ParseBlueprintCached(path, data, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bp.Vars.Get("zone"); !got.Equals(cty.StringVal("us-central1-a")).True() {
+			t.Errorf("got zone %#v", got)
+		}
+	}
+	if downloads != 2 {
+		t.Errorf("got %d downloads, want 2", downloads)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// Lint performs checks of Expand that don't require access to the cloud or
+// to the toolkit modules repository, e.g. to check a blueprint being edited.
+// The blueprint is partially expanded in place, use a clone to keep the original.
+func (bp *Blueprint) Lint() error {
+	for _, stage := range bp.expandStages(true) {
+		if err := stage(); err != nil {
+			return err
+		}
+	}
+
+	errs := Errors{}
+	bp.WalkModulesSafe(func(mp ModulePath, m *Module) {
+		bp.applyUseModules(m)
+		bp.applyGlobalVarsInModule(m)
+		errs.Add(validateModuleInputs(mp, *m, *bp))
+	})
+	if errs.Any() {
+		return errs.OrNil()
+	}
+	return validateModulesAreUsed(*bp)
+}
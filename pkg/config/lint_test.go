@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"hpc-toolkit/pkg/modulereader"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLint(t *testing.T) {
+	modulereader.SetModuleInfo("./modules/lint", TerraformKind.String(), modulereader.ModuleInfo{
+		Inputs: []modulereader.VarInfo{
+			{Name: "zone", Type: cty.String, Required: true},
+			{Name: "size", Type: cty.Number},
+		},
+	})
+	parse := func(settings string) Blueprint {
+		bp, _, err := ParseBlueprint("bp.yaml", []byte(`
+blueprint_name: lint
+vars:
+  deployment_name: dep
+  zone: us-central1-a
+deployment_groups:
+- group: primary
+  modules:
+  - id: a
+    source: ./modules/lint
+    settings:`+settings))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bp
+	}
+
+	ok := parse("\n      size: 5\n")
+	if err := ok.Lint(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	bad := parse("\n      sise: 5\n")
+	err := bad.Lint()
+	var bpe BpError
+	if !errors.As(err, &bpe) || bpe.Path.String() != "deployment_groups[0].modules[0].settings.sise" {
+		t.Errorf("got error %v, want unknown setting error", err)
+	}
+
+	wrongType := parse("\n      size: five\n")
+	err = wrongType.Lint()
+	if !errors.As(err, &bpe) || bpe.Path.String() != "deployment_groups[0].modules[0].settings.size" {
+		t.Errorf("got error %v, want type error", err)
+	}
+}
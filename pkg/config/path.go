@@ -130,6 +130,7 @@ type rootPath struct {
 	basePath
 	BlueprintName         basePath                    `path:"blueprint_name"`
 	GhpcVersion           basePath                    `path:"ghpc_version"`
+	Imports               arrayPath[basePath]         `path:"imports"`
 	Validators            arrayPath[validatorCfgPath] `path:"validators"`
 	ValidationLevel       basePath                    `path:"validation_level"`
 	Vars                  dictPath                    `path:"vars"`
@@ -168,6 +169,7 @@ type groupPath struct {
 	Backend  backendPath           `path:".terraform_backend"`
 	Provider mapPath[providerPath] `path:".terraform_provider"`
 	Modules  arrayPath[ModulePath] `path:".modules"`
+	Enabled  basePath              `path:".enabled"`
 }
 
 type ModulePath struct {
@@ -178,6 +180,8 @@ type ModulePath struct {
 	Use      arrayPath[basePath]   `path:".use"`
 	Outputs  arrayPath[outputPath] `path:".outputs"`
 	Settings dictPath              `path:".settings"`
+	Enabled  basePath              `path:".enabled"`
+	ForEach  basePath              `path:".for_each"`
 }
 
 type outputPath struct {
@@ -31,6 +31,8 @@ func TestPath(t *testing.T) {
 		{r, ""},
 		{r.BlueprintName, "blueprint_name"},
 		{r.GhpcVersion, "ghpc_version"},
+		{r.Imports, "imports"},
+		{r.Imports.At(1), "imports[1]"},
 		{r.Validators, "validators"},
 		{r.ValidationLevel, "validation_level"},
 		{r.Vars, "vars"},
@@ -58,6 +60,7 @@ func TestPath(t *testing.T) {
 		{r.Groups.At(3).Backend, "deployment_groups[3].terraform_backend"},
 		{r.Groups.At(3).Modules, "deployment_groups[3].modules"},
 		{r.Groups.At(3).Modules.At(1), "deployment_groups[3].modules[1]"},
+		{r.Groups.At(3).Enabled, "deployment_groups[3].enabled"},
 		// m := r.Groups.At(3).Modules.At(1)
 		{m.Source, "deployment_groups[3].modules[1].source"},
 		{m.ID, "deployment_groups[3].modules[1].id"},
@@ -71,6 +74,8 @@ func TestPath(t *testing.T) {
 		{m.Outputs.At(2).Sensitive, "deployment_groups[3].modules[1].outputs[2].sensitive"},
 		{m.Settings, "deployment_groups[3].modules[1].settings"},
 		{m.Settings.Dot("lime"), "deployment_groups[3].modules[1].settings.lime"},
+		{m.Enabled, "deployment_groups[3].modules[1].enabled"},
+		{m.ForEach, "deployment_groups[3].modules[1].for_each"},
 
 		{r.Backend.Type, "terraform_backend_defaults.type"},
 		{r.Backend.Configuration, "terraform_backend_defaults.configuration"},
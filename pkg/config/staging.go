@@ -124,15 +124,19 @@ func evalGhpcStageInValue(pPref ctyPath, v cty.Value, ctx *hcl.EvalContext) (cty
 }
 
 func partialEval(exp Expression, fn string, ctx *hcl.EvalContext) (Expression, error) {
+	return partialEvalIf(exp, fn, ctx, nil)
+}
+
+// partialEvalIf is partialEval, but calls for which cond returns false are kept as is
+func partialEvalIf(exp Expression, fn string, ctx *hcl.EvalContext, cond func(call Expression) bool) (Expression, error) {
 	tail := exp.Tokenize()
 	line := string(tail.Bytes())
 	mutated := false
 	acc := hclwrite.Tokens{}
 
 	for len(tail) > 0 {
-		tok := tail[0]
-		if tok.Type != hclsyntax.TokenIdent || string(tok.Bytes) != fn {
-			acc = append(acc, tok)
+		if !isFunctionCallAt(acc, tail, fn) {
+			acc = append(acc, tail[0])
 			tail = tail[1:]
 			continue
 		}
@@ -144,6 +148,10 @@ func partialEval(exp Expression, fn string, ctx *hcl.EvalContext) (Expression, e
 		if err != nil {
 			return nil, prepareParseHclErr(err, line, offset)
 		}
+		if cond != nil && !cond(sub) {
+			acc = append(acc, sub.Tokenize()...)
+			continue
+		}
 
 		for _, ref := range sub.References() {
 			if !ref.GlobalVar {
@@ -168,6 +176,26 @@ func partialEval(exp Expression, fn string, ctx *hcl.EvalContext) (Expression, e
 	return ParseExpression(string(acc.Bytes()))
 }
 
+// isFunctionCallAt checks if tail starts with a call of function fn,
+// e.g. `fn(`, but not an attribute named the same, e.g. `var.fn`.
+func isFunctionCallAt(head hclwrite.Tokens, tail hclwrite.Tokens, fn string) bool {
+	if len(tail) < 2 || tail[0].Type != hclsyntax.TokenIdent || string(tail[0].Bytes) != fn || tail[1].Type != hclsyntax.TokenOParen {
+		return false
+	}
+	return len(head) == 0 || head[len(head)-1].Type != hclsyntax.TokenDot
+}
+
+// callsFunction checks if expression contains a call of function fn
+func callsFunction(exp Expression, fn string) bool {
+	toks := exp.Tokenize()
+	for i := range toks {
+		if isFunctionCallAt(toks[:i], toks[i:], fn) {
+			return true
+		}
+	}
+	return false
+}
+
 // Takes toks in form `fn(...)<TAIL>` and returns `fn(...)` and `<TAIL>`
 func trimFunctionCall(toks hclwrite.Tokens) (Expression, hclwrite.Tokens, error) {
 	if len(toks) < 3 {
@@ -108,6 +108,7 @@ func TestPartialEval(t *testing.T) {
 		{`upper("hello ${upper("world")}") + 7`, `"HELLO WORLD"+7`},
 		{`upper("a") + upper("b")`, `"A"+"B"`},
 		{`"hello ${upper("World")}"`, `"hello ${"WORLD"}"`},
+		{`var.upper`, `var.upper`}, // attribute, not a function call
 	}
 
 	for _, tc := range tests {
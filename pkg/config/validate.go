@@ -124,6 +124,9 @@ func validateModule(p ModulePath, m Module, bp Blueprint) error {
 	if m.ID == "vars" { // invalid module ID
 		errs.At(p.ID, errors.New("module id cannot be 'vars'"))
 	}
+	if m.ID == eachModule { // reserved for instances of replicated modules
+		errs.At(p.ID, errors.New("module id cannot be 'each'"))
+	}
 	return errs.
 		Add(validateSettings(p, m, info)).
 		Add(validateOutputs(p, m, info)).
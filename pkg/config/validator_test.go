@@ -137,6 +137,9 @@ func (s *zeroSuite) TestValidateModule(c *C) {
 		modulereader.SetModuleInfo(mod.Source, mod.Kind.String(), modulereader.ModuleInfo{})
 		err := validateModule(p, mod, dummyBp)
 		c.Check(err, IsNil)
+
+		mod.ID = "deployment" // not reserved, see deployment_output
+		c.Check(validateModule(p, mod, dummyBp), IsNil)
 	}
 }
 
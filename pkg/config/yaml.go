@@ -83,14 +83,53 @@ func parseYamlFile[T any](path string) (T, YamlCtx, error) {
 type YamlCtx struct {
 	pathToPos map[yPath]Pos
 	Lines     []string
+	// File is the path to the YAML file, only set for imported blueprint files.
+	File string
+	// imported is set for blueprints with imports or pruned modules, it locates
+	// every path of the resolved blueprint in the file that defines it.
+	imported map[yPath]yamlLoc
+}
+
+// yamlLoc is a position in a particular YAML file.
+type yamlLoc struct {
+	ctx *YamlCtx
+	pos Pos
 }
 
 // Pos returns a position of a given path if one is found.
+// NOTE: the position may refer to an imported file, use Source to get its context.
 func (c YamlCtx) Pos(p Path) (Pos, bool) {
-	pos, ok := c.pathToPos[yPath(p.String())]
+	_, pos, ok := c.Source(p)
 	return pos, ok
 }
 
+// Source returns the context of the file that defines a given path
+// and the position of the path in it.
+func (c YamlCtx) Source(p Path) (YamlCtx, Pos, bool) {
+	if c.imported != nil {
+		loc, ok := c.imported[yPath(p.String())]
+		if !ok {
+			return c, Pos{}, false
+		}
+		return *loc.ctx, loc.pos, true
+	}
+	pos, ok := c.pathToPos[yPath(p.String())]
+	return c, pos, ok
+}
+
+// locations returns positions of all paths in the context.
+func (c *YamlCtx) locations() map[yPath]yamlLoc {
+	if c.imported != nil {
+		return c.imported
+	}
+	plain := *c // don't refer to c, it may get imports later
+	m := map[yPath]yamlLoc{}
+	for p, pos := range c.pathToPos {
+		m[p] = yamlLoc{&plain, pos}
+	}
+	return m
+}
+
 func syntheticOutputsNode(name string, ln int, col int) *yaml.Node {
 	return &yaml.Node{
 		Kind: yaml.MappingNode,
@@ -146,7 +185,7 @@ func NewYamlCtx(data []byte) (YamlCtx, error) {
 
 	// error may happen if YAML is not valid, regardless of Blueprint schema
 	if err := yaml.Unmarshal(data, &c); err != nil {
-		return YamlCtx{m, lines}, parseYamlV3Error(err)
+		return YamlCtx{pathToPos: m, Lines: lines}, parseYamlV3Error(err)
 	}
 
 	var walk func(n *yaml.Node, p yPath, posOf *yaml.Node)
@@ -171,7 +210,7 @@ func NewYamlCtx(data []byte) (YamlCtx, error) {
 	if c.n != nil {
 		walk(c.n, "", nil)
 	}
-	return YamlCtx{m, lines}, nil
+	return YamlCtx{pathToPos: m, Lines: lines}, nil
 }
 
 type nodeCapturer struct{ n *yaml.Node }
@@ -16,6 +16,9 @@ package deploymentio
 
 import (
 	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
 // BaseFS is an extension of the io.fs interface with the functionality needed
@@ -34,9 +37,36 @@ type Deploymentio interface {
 
 var deploymentios = map[string]Deploymentio{
 	"local": new(Local),
+	"gcs":   new(GCS),
 }
 
 // GetDeploymentioLocal gets the instance writing blueprints to a local
 func GetDeploymentioLocal() Deploymentio {
 	return deploymentios["local"]
 }
+
+// GetDeploymentioGCS gets the instance writing blueprints to Cloud Storage
+func GetDeploymentioGCS() Deploymentio {
+	return deploymentios["gcs"]
+}
+
+// Get returns the Deploymentio managing the path, e.g. GCS for `gs://bucket/dir`
+func Get(path string) Deploymentio {
+	if IsRemote(path) {
+		return GetDeploymentioGCS()
+	}
+	return GetDeploymentioLocal()
+}
+
+// IsRemote checks if the path is not on the local file system
+func IsRemote(path string) bool {
+	return strings.HasPrefix(path, gcsScheme)
+}
+
+// Join joins elements to the path, keeping the scheme of remote paths intact
+func Join(base string, elem ...string) string {
+	if !IsRemote(base) {
+		return filepath.Join(append([]string{base}, elem...)...)
+	}
+	return gcsScheme + path.Join(append([]string{strings.TrimPrefix(base, gcsScheme)}, elem...)...)
+}
@@ -0,0 +1,432 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const gcsScheme = "gs://"
+
+// generationsFile keeps generations of objects pulled into a working copy,
+// relative to its root, see Pull.
+const generationsFile = ".gcluster-generations.json"
+
+// AnyGeneration is a generation precondition matched by any object, existing or not.
+const AnyGeneration int64 = -1
+
+// ErrConflict is returned if an object doesn't match the generation precondition,
+// i.e. it was changed by someone else.
+var ErrConflict = errors.New("the object was changed concurrently")
+
+// ObjectAttrs are attributes of an object used to synchronize it with a local file.
+type ObjectAttrs struct {
+	Name       string
+	Mode       fs.FileMode
+	Generation int64
+	CRC32C     uint32 // checksum of the data with the Castagnoli polynomial, see checksum
+}
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksum returns the CRC32C checksum of the data, as reported by Cloud Storage.
+func checksum(data []byte) uint32 {
+	return crc32.Checksum(data, crc32cTable)
+}
+
+// ObjectStore is a minimal interface of an object storage, e.g. Cloud Storage.
+// Writes and deletes take a generation precondition, the operation fails with
+// ErrConflict unless the object has the generation, 0 stands for a missing object.
+type ObjectStore interface {
+	// List returns attributes of objects with names starting with the prefix.
+	List(ctx context.Context, bucket string, prefix string) ([]ObjectAttrs, error)
+	// Read returns the data, file mode and generation of the object.
+	Read(ctx context.Context, bucket string, name string) ([]byte, fs.FileMode, int64, error)
+	// Write returns the generation of the written object.
+	Write(ctx context.Context, bucket string, name string, data []byte, mode fs.FileMode, gen int64) (int64, error)
+	Delete(ctx context.Context, bucket string, name string, gen int64) error
+}
+
+// GCS keeps deployment folders in Cloud Storage, paths are of the form `gs://bucket/dir`.
+// Cloud Storage has no directories, a directory exists if any object has its prefix.
+type GCS struct {
+	// Store defaults to Cloud Storage, can be replaced with a fake, see NewMemStore
+	Store ObjectStore
+}
+
+// SetObjectStore replaces the object store of the registered GCS Deploymentio.
+func SetObjectStore(s ObjectStore) {
+	deploymentios["gcs"].(*GCS).Store = s
+}
+
+func (g *GCS) store() ObjectStore {
+	if g.Store == nil {
+		g.Store = &gcsStore{}
+	}
+	return g.Store
+}
+
+func splitGCSPath(p string) (string, string, error) {
+	if !strings.HasPrefix(p, gcsScheme) {
+		return "", "", fmt.Errorf("%q is not a Cloud Storage path, expected gs://BUCKET/PATH", p)
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(p, gcsScheme), "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("%q is missing the bucket name", p)
+	}
+	return bucket, strings.Trim(path.Clean("/"+prefix), "/"), nil
+}
+
+// list returns attributes of objects in the directory, names are relative to it.
+func (g *GCS) list(dir string) ([]ObjectAttrs, error) {
+	bucket, prefix, err := splitGCSPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+	objs, err := g.store().List(context.Background(), bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	for i := range objs {
+		objs[i].Name = strings.TrimPrefix(objs[i].Name, prefix)
+	}
+	return objs, nil
+}
+
+func (g *GCS) write(dst string, data []byte, mode fs.FileMode, gen int64) (int64, error) {
+	bucket, name, err := splitGCSPath(dst)
+	if err != nil {
+		return 0, err
+	}
+	gen, err = g.store().Write(context.Background(), bucket, name, data, mode, gen)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return gen, nil
+}
+
+// CreateDirectory checks that the directory doesn't exist yet
+func (g *GCS) CreateDirectory(directory string) error {
+	objs, err := g.list(directory)
+	if err != nil {
+		return err
+	}
+	if len(objs) > 0 {
+		return fmt.Errorf("the directory already exists: %s", directory)
+	}
+	return nil // directories are implicit
+}
+
+// CopyFromPath uploads the source file or directory to the destination
+func (g *GCS) CopyFromPath(src string, dst string) error {
+	absPath, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(absPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(absPath, p)
+		if err != nil {
+			return err
+		}
+		_, err = g.uploadFile(p, Join(dst, filepath.ToSlash(rel)), AnyGeneration)
+		return err
+	})
+}
+
+func (g *GCS) uploadFile(src string, dst string, gen int64) (int64, error) {
+	data, mode, err := readFile(src)
+	if err != nil {
+		return 0, err
+	}
+	return g.write(dst, data, mode, gen)
+}
+
+func readFile(p string) ([]byte, fs.FileMode, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, info.Mode().Perm(), nil
+}
+
+// CopyFromFS uploads the embedded source file to the destination
+func (g *GCS) CopyFromFS(fs BaseFS, src string, dst string) error {
+	data, err := fs.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read source file %s: err=%w", src, err)
+	}
+	_, err = g.write(dst, data, 0644, AnyGeneration)
+	return err
+}
+
+// skipSync reports whether the relative path is not synchronized, i.e.
+// `.terraform` directories with providers and modules downloaded by `terraform init`
+// and the generations of pulled objects.
+func skipSync(rel string) bool {
+	if filepath.ToSlash(rel) == generationsFile {
+		return true
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if part == ".terraform" {
+			return true
+		}
+	}
+	return false
+}
+
+// readGenerations returns generations of objects recorded in the local directory by Pull
+// and Push, by relative slash-separated names. It's empty if nothing was recorded.
+func readGenerations(local string) (map[string]int64, error) {
+	gens := map[string]int64{}
+	data, err := os.ReadFile(filepath.Join(local, generationsFile))
+	if os.IsNotExist(err) {
+		return gens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &gens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(local, generationsFile), err)
+	}
+	return gens, nil
+}
+
+func writeGenerations(local string, gens map[string]int64) error {
+	data, err := json.Marshal(gens)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(local, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(local, generationsFile), data, 0644)
+}
+
+// Pull mirrors the remote directory into the local one, local files missing
+// in the remote directory are removed. Generations of the pulled objects are
+// recorded, Push only overwrites objects that weren't changed since.
+func (g *GCS) Pull(remote string, local string) error {
+	bucket, _, err := splitGCSPath(remote)
+	if err != nil {
+		return err
+	}
+	objs, err := g.list(remote)
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return os.RemoveAll(local)
+	}
+
+	want := map[string]bool{}
+	gens := map[string]int64{}
+	for _, o := range objs {
+		n := o.Name
+		want[filepath.FromSlash(n)] = true
+		_, name, _ := splitGCSPath(Join(remote, n))
+		data, mode, gen, err := g.store().Read(context.Background(), bucket, name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", Join(remote, n), err)
+		}
+		gens[n] = gen
+		dst := filepath.Join(local, filepath.FromSlash(n))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, mode); err != nil {
+			return err
+		}
+		if err := os.Chmod(dst, mode); err != nil { // WriteFile keeps the mode of existing files
+			return err
+		}
+	}
+
+	err = filepath.WalkDir(local, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(local, p)
+		switch {
+		case skipSync(rel):
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		case !d.IsDir() && !want[rel]:
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeGenerations(local, gens)
+}
+
+// Push mirrors the local directory into the remote one, remote objects missing
+// in the local directory are removed, objects with the same checksum and mode
+// as the local files are kept. Push fails with ErrConflict if the remote
+// directory was changed since it was pulled, e.g. by another user sharing the bucket.
+func (g *GCS) Push(local string, remote string) error {
+	bucket, _, err := splitGCSPath(remote)
+	if err != nil {
+		return err
+	}
+	gens, err := readGenerations(local)
+	if err != nil {
+		return err
+	}
+	changed := func(n string, err error) error {
+		return fmt.Errorf("remote deployment %s was changed since it was pulled into %s, %s: %w", remote, local, n, err)
+	}
+
+	// objects unknown to the working copy were added by someone else,
+	// check them first to leave the remote directory intact
+	objs, err := g.list(remote)
+	if err != nil {
+		return err
+	}
+	attrs := map[string]ObjectAttrs{}
+	for _, o := range objs {
+		if _, ok := gens[o.Name]; !ok {
+			return changed(o.Name, ErrConflict)
+		}
+		attrs[o.Name] = o
+	}
+
+	have := map[string]bool{}
+	err = filepath.WalkDir(local, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(local, p)
+		if skipSync(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		have[rel] = true
+		data, mode, err := readFile(p)
+		if err != nil {
+			return err
+		}
+		if o, ok := attrs[rel]; ok && o.Generation == gens[rel] && o.Mode == mode && o.CRC32C == checksum(data) {
+			return nil // unchanged since pulled
+		}
+		gen, err := g.write(Join(remote, rel), data, mode, gens[rel]) // missing objects have generation 0
+		if errors.Is(err, ErrConflict) {
+			return changed(rel, err)
+		}
+		if err != nil {
+			return err
+		}
+		gens[rel] = gen
+		return nil
+	})
+	if err != nil {
+		// keep generations of uploaded objects, to retry e.g. after a network error
+		return errors.Join(err, writeGenerations(local, gens))
+	}
+
+	for _, o := range objs {
+		n := o.Name
+		if have[n] {
+			continue
+		}
+		_, name, _ := splitGCSPath(Join(remote, n))
+		err := g.store().Delete(context.Background(), bucket, name, gens[n])
+		if errors.Is(err, ErrConflict) {
+			return changed(n, err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", Join(remote, n), err)
+		}
+		delete(gens, n)
+	}
+	return writeGenerations(local, gens)
+}
+
+// WorkingCopy pulls the remote deployment folder into a local directory, tools
+// like Terraform operate on it and the changes are uploaded back with Publish.
+// The directory is kept in the user cache, to reuse `.terraform` directories.
+func WorkingCopy(remote string) (string, error) {
+	bucket, prefix, err := splitGCSPath(remote)
+	if err != nil {
+		return "", err
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	local := filepath.Join(cache, "gcluster", "deployments", bucket, filepath.FromSlash(prefix))
+	if err := deploymentios["gcs"].(*GCS).Pull(remote, local); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+// Publish uploads the working copy of the remote deployment folder, see WorkingCopy.
+func Publish(local string, remote string) error {
+	return deploymentios["gcs"].(*GCS).Push(local, remote)
+}
+
+// ReadRemote reads a single object, e.g. `gs://bucket/dir/file`.
+func ReadRemote(p string) ([]byte, error) {
+	bucket, name, err := splitGCSPath(p)
+	if err != nil {
+		return nil, err
+	}
+	data, _, _, err := deploymentios["gcs"].(*GCS).store().Read(context.Background(), bucket, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return data, nil
+}
+
+// ListRemote returns names of objects in the remote directory, relative to it.
+func ListRemote(dir string) ([]string, error) {
+	objs, err := deploymentios["gcs"].(*GCS).list(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, o := range objs {
+		names = append(names, o.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// gcsStore is an ObjectStore backed by Cloud Storage, the client
+// is created on the first use with Application Default Credentials.
+type gcsStore struct {
+	once   sync.Once
+	client *storage.Client
+	err    error
+}
+
+func (s *gcsStore) bucket(ctx context.Context, bucket string) (*storage.BucketHandle, error) {
+	s.once.Do(func() {
+		s.client, s.err = storage.NewClient(ctx)
+	})
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.client.Bucket(bucket), nil
+}
+
+func (s *gcsStore) List(ctx context.Context, bucket string, prefix string) ([]ObjectAttrs, error) {
+	b, err := s.bucket(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	res := []ObjectAttrs{}
+	it := b.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ObjectAttrs{
+			Name:       attrs.Name,
+			Mode:       modeOf(attrs),
+			Generation: attrs.Generation,
+			CRC32C:     attrs.CRC32C,
+		})
+	}
+}
+
+func (s *gcsStore) Read(ctx context.Context, bucket string, name string) ([]byte, fs.FileMode, int64, error) {
+	b, err := s.bucket(ctx, bucket)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	attrs, err := b.Object(name).Attrs(ctx)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	// read the generation of the attributes, the object may have been overwritten since
+	r, err := b.Object(name).Generation(attrs.Generation).NewReader(ctx)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return data, modeOf(attrs), attrs.Generation, nil
+}
+
+// modeOf returns the file mode recorded in the metadata of the object by Write.
+func modeOf(attrs *storage.ObjectAttrs) fs.FileMode {
+	if m, err := strconv.ParseUint(attrs.Metadata["mode"], 8, 32); err == nil {
+		return fs.FileMode(m)
+	}
+	return 0644
+}
+
+// object returns the handle of the object with the generation precondition, see ObjectStore.
+func object(b *storage.BucketHandle, name string, gen int64) *storage.ObjectHandle {
+	o := b.Object(name)
+	switch {
+	case gen == 0:
+		return o.If(storage.Conditions{DoesNotExist: true})
+	case gen > 0:
+		return o.If(storage.Conditions{GenerationMatch: gen})
+	default: // AnyGeneration
+		return o
+	}
+}
+
+// conflict maps failed preconditions to ErrConflict.
+func conflict(err error) error {
+	var e *googleapi.Error
+	if errors.As(err, &e) && e.Code == http.StatusPreconditionFailed {
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	return err
+}
+
+func (s *gcsStore) Write(ctx context.Context, bucket string, name string, data []byte, mode fs.FileMode, gen int64) (int64, error) {
+	b, err := s.bucket(ctx, bucket)
+	if err != nil {
+		return 0, err
+	}
+	w := object(b, name, gen).NewWriter(ctx)
+	w.Metadata = map[string]string{"mode": strconv.FormatUint(uint64(mode.Perm()), 8)}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return 0, conflict(err)
+	}
+	if err := w.Close(); err != nil {
+		return 0, conflict(err)
+	}
+	return w.Attrs().Generation, nil
+}
+
+func (s *gcsStore) Delete(ctx context.Context, bucket string, name string, gen int64) error {
+	b, err := s.bucket(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	return conflict(object(b, name, gen).Delete(ctx))
+}
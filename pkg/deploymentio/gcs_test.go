@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *zeroSuite) TestJoinAndIsRemote(c *C) {
+	c.Check(IsRemote("gs://bucket/dir"), Equals, true)
+	c.Check(IsRemote("/tmp/dir"), Equals, false)
+	c.Check(Join("gs://bucket/dir/", "green", "a"), Equals, "gs://bucket/dir/green/a")
+	c.Check(Join("gs://bucket", "green"), Equals, "gs://bucket/green")
+	c.Check(Join("dir", "green"), Equals, filepath.Join("dir", "green"))
+	c.Check(Get("gs://bucket/dir"), Equals, GetDeploymentioGCS())
+	c.Check(Get("dir"), Equals, GetDeploymentioLocal())
+}
+
+func (s *zeroSuite) TestCreateDirectoryGCS(c *C) {
+	store := NewMemStore()
+	g := &GCS{Store: store}
+	c.Check(g.CreateDirectory("gs://bucket/dir"), IsNil)
+
+	c.Assert(g.CopyFromFS(getTestFS(), "pkg/modulewriter/deployment.gitignore.tmpl", "gs://bucket/dir/.gitignore"), IsNil)
+	c.Check(store.Names(), DeepEquals, []string{"gs://bucket/dir/.gitignore"})
+	c.Check(g.CreateDirectory("gs://bucket/dir"), ErrorMatches, "the directory already exists: .*")
+	c.Check(g.CreateDirectory("gs://bucket/di"), IsNil) // not a prefix of the directory
+
+	c.Check(g.CreateDirectory("gs:///dir"), ErrorMatches, ".*missing the bucket name")
+}
+
+func (s *zeroSuite) TestPushPullGCS(c *C) {
+	store := NewMemStore()
+	g := &GCS{Store: store}
+	src := c.MkDir()
+	write := func(dir string, rel string, mode os.FileMode) {
+		p := filepath.Join(dir, rel)
+		c.Assert(os.MkdirAll(filepath.Dir(p), 0755), IsNil)
+		c.Assert(os.WriteFile(p, []byte(rel), mode), IsNil)
+	}
+	write(src, "a/main.tf", 0644)
+	write(src, "a/script.sh", 0755)
+	write(src, "a/.terraform/providers/p", 0644)
+
+	c.Assert(g.Push(src, "gs://bucket/dep"), IsNil)
+	c.Check(store.Names(), DeepEquals, []string{"gs://bucket/dep/a/main.tf", "gs://bucket/dep/a/script.sh"})
+
+	dst := c.MkDir()
+	write(dst, "a/.terraform/cached", 0644)
+	write(dst, "stale.tf", 0644)
+	c.Assert(g.Pull("gs://bucket/dep", dst), IsNil)
+
+	got, err := os.ReadFile(filepath.Join(dst, "a/main.tf"))
+	c.Assert(err, IsNil)
+	c.Check(string(got), Equals, "a/main.tf")
+	info, err := os.Stat(filepath.Join(dst, "a/script.sh"))
+	c.Assert(err, IsNil)
+	c.Check(info.Mode().Perm(), Equals, os.FileMode(0755))
+	_, err = os.Stat(filepath.Join(dst, "stale.tf"))
+	c.Check(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(filepath.Join(dst, "a/.terraform/cached"))
+	c.Check(err, IsNil) // `.terraform` is kept
+
+	// Removed files are deleted from the remote directory
+	c.Assert(os.Remove(filepath.Join(src, "a/main.tf")), IsNil)
+	c.Assert(g.Push(src, "gs://bucket/dep"), IsNil)
+	c.Check(store.Names(), DeepEquals, []string{"gs://bucket/dep/a/script.sh"})
+
+	// Pulling a missing directory removes the local one
+	c.Assert(g.Pull("gs://bucket/missing", dst), IsNil)
+	_, err = os.Stat(dst)
+	c.Check(os.IsNotExist(err), Equals, true)
+}
+
+func (s *zeroSuite) TestPushConflictGCS(c *C) {
+	store := NewMemStore()
+	g := &GCS{Store: store}
+	ctx := context.Background()
+	_, err := store.Write(ctx, "bucket", "dep/a/terraform.tfstate", []byte("v1"), 0644, 0)
+	c.Assert(err, IsNil)
+	_, err = store.Write(ctx, "bucket", "dep/b/terraform.tfstate", []byte("v1"), 0644, 0)
+	c.Assert(err, IsNil)
+
+	alice, bob := c.MkDir(), c.MkDir()
+	c.Assert(g.Pull("gs://bucket/dep", alice), IsNil)
+	c.Assert(g.Pull("gs://bucket/dep", bob), IsNil)
+	c.Check(store.Names(), DeepEquals, []string{"gs://bucket/dep/a/terraform.tfstate", "gs://bucket/dep/b/terraform.tfstate"})
+
+	c.Assert(os.WriteFile(filepath.Join(alice, "a/terraform.tfstate"), []byte("alice"), 0644), IsNil)
+	c.Assert(g.Push(alice, "gs://bucket/dep"), IsNil)
+	// Pushing again doesn't conflict with its own changes
+	c.Assert(g.Push(alice, "gs://bucket/dep"), IsNil)
+
+	// Stale working copies don't overwrite changed objects
+	c.Assert(os.WriteFile(filepath.Join(bob, "a/terraform.tfstate"), []byte("bob"), 0644), IsNil)
+	c.Check(g.Push(bob, "gs://bucket/dep"), ErrorMatches, "remote deployment gs://bucket/dep was changed since it was pulled .*")
+	data, _, _, err := store.Read(ctx, "bucket", "dep/a/terraform.tfstate")
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "alice")
+
+	// nor delete objects added since
+	_, err = store.Write(ctx, "bucket", "dep/c/terraform.tfstate", []byte("v1"), 0644, 0)
+	c.Assert(err, IsNil)
+	c.Assert(g.Pull("gs://bucket/dep", bob), IsNil)
+	c.Assert(os.Remove(filepath.Join(alice, "b/terraform.tfstate")), IsNil)
+	err = g.Push(alice, "gs://bucket/dep")
+	c.Check(errors.Is(err, ErrConflict), Equals, true)
+	c.Check(store.Names(), HasLen, 3)
+
+	// Up to date working copies are pushed
+	c.Assert(os.Remove(filepath.Join(bob, "c/terraform.tfstate")), IsNil)
+	c.Assert(g.Push(bob, "gs://bucket/dep"), IsNil)
+	c.Check(store.Names(), DeepEquals, []string{"gs://bucket/dep/a/terraform.tfstate", "gs://bucket/dep/b/terraform.tfstate"})
+}
+
+func (s *zeroSuite) TestPushSkipsUnchangedGCS(c *C) {
+	store := NewMemStore()
+	g := &GCS{Store: store}
+	ctx := context.Background()
+	for _, n := range []string{"dep/a", "dep/b", "dep/c"} {
+		_, err := store.Write(ctx, "bucket", n, []byte("v1"), 0644, 0)
+		c.Assert(err, IsNil)
+	}
+	gen := func(n string) int64 {
+		_, _, g, err := store.Read(ctx, "bucket", n)
+		c.Assert(err, IsNil)
+		return g
+	}
+	a, b, cg := gen("dep/a"), gen("dep/b"), gen("dep/c")
+
+	local := c.MkDir()
+	c.Assert(g.Pull("gs://bucket/dep", local), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(local, "b"), []byte("v2"), 0644), IsNil)
+	c.Assert(os.Chmod(filepath.Join(local, "c"), 0755), IsNil)
+	c.Assert(g.Push(local, "gs://bucket/dep"), IsNil)
+
+	c.Check(gen("dep/a"), Equals, a) // not rewritten
+	c.Check(gen("dep/b"), Not(Equals), b)
+	c.Check(gen("dep/c"), Not(Equals), cg) // mode changed
+}
+
+func (s *zeroSuite) TestReadListRemote(c *C) {
+	store := NewMemStore()
+	SetObjectStore(store)
+	defer SetObjectStore(nil)
+	_, err := store.Write(context.Background(), "bucket", "dep/a/x", []byte("ax"), 0644, 0)
+	c.Assert(err, IsNil)
+	_, err = store.Write(context.Background(), "bucket", "dep/b", []byte("b"), 0644, 0)
+	c.Assert(err, IsNil)
+
+	data, err := ReadRemote("gs://bucket/dep/a/x")
+	c.Check(err, IsNil)
+	c.Check(string(data), Equals, "ax")
+	_, err = ReadRemote("gs://bucket/dep/c")
+	c.Check(err, ErrorMatches, "failed to read gs://bucket/dep/c: .*")
+
+	names, err := ListRemote("gs://bucket/dep")
+	c.Check(err, IsNil)
+	c.Check(names, DeepEquals, []string{"a/x", "b"})
+}
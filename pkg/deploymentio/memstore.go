@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentio
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+)
+
+type memObject struct {
+	data []byte
+	mode fs.FileMode
+	gen  int64
+}
+
+// MemStore is an in-memory ObjectStore, to be used as a fake of Cloud Storage in tests.
+type MemStore struct {
+	mu      sync.Mutex
+	objects map[string]memObject // by "bucket/name"
+	gen     int64                // last generation
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{objects: map[string]memObject{}}
+}
+
+func (s *MemStore) List(_ context.Context, bucket string, prefix string) ([]ObjectAttrs, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := []ObjectAttrs{}
+	for k, o := range s.objects {
+		if name, ok := strings.CutPrefix(k, bucket+"/"); ok && strings.HasPrefix(name, prefix) {
+			res = append(res, ObjectAttrs{Name: name, Mode: o.mode, Generation: o.gen, CRC32C: checksum(o.data)})
+		}
+	}
+	slices.SortFunc(res, func(a, b ObjectAttrs) int { return strings.Compare(a.Name, b.Name) })
+	return res, nil
+}
+
+func (s *MemStore) Read(_ context.Context, bucket string, name string) ([]byte, fs.FileMode, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[bucket+"/"+name]
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("object gs://%s/%s: %w", bucket, name, fs.ErrNotExist)
+	}
+	return slices.Clone(o.data), o.mode, o.gen, nil
+}
+
+// check returns ErrConflict if the generation of the object doesn't match, see ObjectStore.
+func (s *MemStore) check(key string, gen int64) error {
+	o, ok := s.objects[key]
+	switch {
+	case gen == AnyGeneration:
+		return nil
+	case gen == 0 && ok:
+		return fmt.Errorf("object gs://%s already exists: %w", key, ErrConflict)
+	case gen > 0 && o.gen != gen:
+		return fmt.Errorf("object gs://%s doesn't have generation %d: %w", key, gen, ErrConflict)
+	}
+	return nil
+}
+
+func (s *MemStore) Write(_ context.Context, bucket string, name string, data []byte, mode fs.FileMode, gen int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := bucket + "/" + name
+	if err := s.check(key, gen); err != nil {
+		return 0, err
+	}
+	s.gen++
+	s.objects[key] = memObject{data: slices.Clone(data), mode: mode, gen: s.gen}
+	return s.gen, nil
+}
+
+func (s *MemStore) Delete(_ context.Context, bucket string, name string, gen int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := bucket + "/" + name
+	if _, ok := s.objects[key]; !ok {
+		return fmt.Errorf("object gs://%s: %w", key, fs.ErrNotExist)
+	}
+	if err := s.check(key, gen); err != nil {
+		return err
+	}
+	delete(s.objects, key)
+	return nil
+}
+
+// Names returns all objects of the store as "gs://bucket/name", sorted.
+func (s *MemStore) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := []string{}
+	for _, k := range maps.Keys(s.objects) {
+		res = append(res, gcsScheme+k)
+	}
+	slices.Sort(res)
+	return res
+}
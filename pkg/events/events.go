@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events writes a stream of machine-readable events of gcluster
+// commands, one JSON object per line (NDJSON). Event types and their fields
+// are part of the interface of gcluster and must stay stable.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Type of an event
+type Type string
+
+// Event types
+const (
+	CommandStart    Type = "command_start"    // command, deployment
+	CommandFinish   Type = "command_finish"   // status success or failure, message of the failure
+	GroupStart      Type = "group_start"      // group, kind
+	GroupFinish     Type = "group_finish"     // group, status, message of the failure
+	InputsImported  Type = "inputs_imported"  // group, file
+	PlanSummary     Type = "plan_summary"     // group, counts of add, change, import and remove
+	Prompt          Type = "prompt"           // group, summary of proposed changes
+	PromptAnswer    Type = "prompt_answer"    // group, status apply, skip or stop
+	PackerStep      Type = "packer_step"      // group, step init, validate or build, status started, finished or failed
+	OutputsExported Type = "outputs_exported" // group, file, names of outputs
+	Retry           Type = "retry"            // attempt and max_attempts of the command
+	Log             Type = "log"              // level, message of a log message
+)
+
+// Statuses of events
+const (
+	StatusSuccess   = "success"
+	StatusFailure   = "failure"
+	StatusApplied   = "applied"
+	StatusDeclined  = "declined"
+	StatusPartial   = "partial"
+	StatusSkipped   = "skipped"
+	StatusDestroyed = "destroyed"
+	StatusFailed    = "failed"
+	StatusStarted   = "started"
+	StatusFinished  = "finished"
+)
+
+// Event is a single event of the stream
+type Event struct {
+	Time    time.Time      `json:"time"`
+	Type    Type           `json:"type"`
+	Group   string         `json:"group,omitempty"`
+	Status  string         `json:"status,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+var (
+	mu  sync.Mutex
+	enc *json.Encoder
+)
+
+// SetOutput starts writing events to w, nil stops the stream
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if w == nil {
+		enc = nil
+		return
+	}
+	enc = json.NewEncoder(w)
+}
+
+// Enabled reports whether events are written
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enc != nil
+}
+
+// Emit writes the event to the stream, if any; failures to write are ignored
+// not to interfere with the command
+func Emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if enc == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	enc.Encode(e)
+}
+
+// EmitGroup writes an event of the deployment group
+func EmitGroup(t Type, group string, status string, data map[string]any) {
+	Emit(Event{Type: t, Group: group, Status: status, Data: data})
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	Emit(Event{Type: Log}) // no stream, no-op
+	if Enabled() {
+		t.Fatal("want disabled stream")
+	}
+
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+	EmitGroup(GroupStart, "primary", "", map[string]any{"kind": "terraform"})
+	EmitGroup(GroupFinish, "primary", StatusApplied, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got:\n%s", buf.String())
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != GroupFinish || e.Group != "primary" || e.Status != StatusApplied || e.Time.IsZero() {
+		t.Errorf("unexpected event %#v", e)
+	}
+	if strings.Contains(lines[1], `"data"`) {
+		t.Errorf("empty data should be omitted: %s", lines[1])
+	}
+}
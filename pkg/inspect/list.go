@@ -15,8 +15,11 @@
 package inspect
 
 import (
+	"errors"
+	"hpc-toolkit/pkg/sourcereader"
 	"io/fs"
-	"path/filepath"
+	"os"
+	"path"
 	"strings"
 )
 
@@ -28,26 +31,27 @@ type SourceAndKind struct {
 
 // ListModules in directory
 func ListModules(root string, dir string) ([]SourceAndKind, error) {
+	return listModulesFS(os.DirFS(root), dir)
+}
+
+func listModulesFS(fsys fs.FS, dir string) ([]SourceAndKind, error) {
 	ret := []SourceAndKind{}
-	err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() && d.Name() == ".terraform" {
-			return filepath.SkipDir
-		}
-		src, err := filepath.Rel(root, filepath.Dir(path))
-		if err != nil {
-			return err
+			return fs.SkipDir
 		}
+		src := path.Dir(p)
 
-		if !d.IsDir() && filepath.Ext(d.Name()) == ".tf" {
+		if !d.IsDir() && path.Ext(d.Name()) == ".tf" {
 			ret = append(ret, SourceAndKind{src, "terraform"})
-			return filepath.SkipDir
+			return fs.SkipDir
 		}
 		if !d.IsDir() && strings.HasSuffix(d.Name(), ".pkr.hcl") {
 			ret = append(ret, SourceAndKind{src, "packer"})
-			return filepath.SkipDir
+			return fs.SkipDir
 		}
 		return nil
 	})
@@ -67,3 +71,19 @@ func LocalModules() ([]SourceAndKind, error) {
 	}
 	return ret, nil
 }
+
+// EmbeddedModules returns source and kind for all modules embedded into the binary
+func EmbeddedModules() ([]SourceAndKind, error) {
+	if sourcereader.ModuleFS == nil {
+		return nil, errors.New("embedded modules are not available")
+	}
+	ret := []SourceAndKind{}
+	for _, sub := range []string{"modules", "community/modules"} {
+		mods, err := listModulesFS(sourcereader.ModuleFS, sub)
+		if err != nil {
+			return []SourceAndKind{}, err
+		}
+		ret = append(ret, mods...)
+	}
+	return ret, nil
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/modulereader"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Schema is a JSON Schema document (draft-07)
+type Schema = map[string]any
+
+// ModuleSchemaInfo is a module to describe settings of in the blueprint schema.
+type ModuleSchemaInfo struct {
+	SourceAndKind
+	Info modulereader.ModuleInfo
+}
+
+// expressionRef refers to a blueprint expression definition, e.g. `$(vars.zone)` or `((var.zone))`.
+// Expression can be used in place of any value.
+var expressionRef = Schema{"$ref": "#/definitions/expression"}
+
+func anyOfExpression(s Schema) Schema {
+	return Schema{"anyOf": []any{s, expressionRef}}
+}
+
+func withDescription(s Schema, description string) Schema {
+	s["description"] = description
+	return s
+}
+
+func stringSchema(description string) Schema {
+	return Schema{"type": "string", "description": description}
+}
+
+// TypeSchema converts a cty type to JSON Schema, every value can be replaced with an expression.
+func TypeSchema(ty cty.Type) Schema {
+	switch {
+	case ty == cty.NilType || ty == cty.DynamicPseudoType:
+		return Schema{}
+	case ty == cty.String:
+		return anyOfExpression(Schema{"type": "string"})
+	case ty == cty.Number:
+		return anyOfExpression(Schema{"type": "number"})
+	case ty == cty.Bool:
+		return anyOfExpression(Schema{"type": "boolean"})
+	case ty.IsListType() || ty.IsSetType():
+		return anyOfExpression(Schema{"type": "array", "items": TypeSchema(ty.ElementType())})
+	case ty.IsTupleType():
+		items := []any{}
+		for _, ety := range ty.TupleElementTypes() {
+			items = append(items, TypeSchema(ety))
+		}
+		return anyOfExpression(Schema{"type": "array", "items": items, "additionalItems": false})
+	case ty.IsMapType():
+		return anyOfExpression(Schema{"type": "object", "additionalProperties": TypeSchema(ty.ElementType())})
+	case ty.IsObjectType():
+		props := Schema{}
+		required := []string{}
+		for name, aty := range ty.AttributeTypes() {
+			props[name] = TypeSchema(aty)
+			if !ty.AttributeOptional(name) {
+				required = append(required, name)
+			}
+		}
+		s := Schema{"type": "object", "properties": props}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s["required"] = required
+		}
+		return anyOfExpression(s)
+	default:
+		return Schema{}
+	}
+}
+
+// SettingsSchema describes module settings by module inputs and metadata validation rules.
+// Settings are not required by the schema as they can be provided by deployment variables or `use`.
+func SettingsSchema(info modulereader.ModuleInfo) Schema {
+	props := Schema{}
+	for _, input := range info.Inputs {
+		s := TypeSchema(input.Type)
+		if input.Description != "" {
+			s = withDescription(s, input.Description)
+		}
+		props[input.Name] = s
+	}
+	for _, rule := range info.Metadata.Ghpc.Validators {
+		applyValidationRule(props, rule)
+	}
+	return Schema{"type": "object", "properties": props, "additionalProperties": false}
+}
+
+// typed returns the part of the schema that describes the actual type (not an expression).
+func typed(s Schema) (Schema, bool) {
+	alts, ok := s["anyOf"].([]any)
+	if !ok || len(alts) == 0 {
+		return nil, false
+	}
+	t, ok := alts[0].(Schema)
+	return t, ok
+}
+
+// ruleTarget finds schema of the setting targeted by the validation rule,
+// e.g. `name` or `instance_image.family`.
+func ruleTarget(props Schema, target string) (Schema, bool) {
+	parts := strings.Split(target, ".")
+	cur, ok := props[parts[0]].(Schema)
+	for _, p := range parts[1:] {
+		if !ok {
+			return nil, false
+		}
+		t, isTyped := typed(cur)
+		if !isTyped {
+			return nil, false
+		}
+		var tProps Schema
+		if tProps, ok = t["properties"].(Schema); ok {
+			cur, ok = tProps[p].(Schema)
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	return typed(cur)
+}
+
+// applyValidationRule adds constraints of the `regex`, `allowed_enum` and `range`
+// metadata validation rules to the settings schema. Other rules can't be expressed
+// by JSON Schema and are ignored.
+func applyValidationRule(props Schema, rule modulereader.ValidationRule) {
+	targets, _ := rule.Inputs["vars"].([]any)
+	for _, tg := range targets {
+		name, ok := tg.(string)
+		if !ok {
+			continue
+		}
+		s, ok := ruleTarget(props, name)
+		if !ok {
+			continue
+		}
+		if items, ok := s["items"].(Schema); ok && s["type"] == "array" {
+			if rule.Validator == "range" && rule.Inputs["length_check"] == true {
+				setBounds(s, "minItems", "maxItems", rule.Inputs)
+				continue
+			}
+			if s, ok = typed(items); !ok {
+				continue
+			}
+		}
+
+		switch rule.Validator {
+		case "regex":
+			if p, ok := rule.Inputs["pattern"].(string); ok && s["type"] == "string" {
+				s["pattern"] = p
+			}
+		case "allowed_enum":
+			allowed, ok := rule.Inputs["allowed"].([]any)
+			if !ok || s["type"] != "string" || rule.Inputs["case_sensitive"] == false {
+				continue
+			}
+			enum := []any{}
+			for _, a := range allowed {
+				enum = append(enum, fmt.Sprintf("%v", a))
+			}
+			s["enum"] = enum
+		case "range":
+			if s["type"] == "number" {
+				setBounds(s, "minimum", "maximum", rule.Inputs)
+			}
+		}
+	}
+}
+
+func setBounds(s Schema, minKey string, maxKey string, inputs map[string]any) {
+	if m, ok := inputs["min"].(int); ok {
+		s[minKey] = m
+	}
+	if m, ok := inputs["max"].(int); ok {
+		s[maxKey] = m
+	}
+}
+
+func dictSchema(description string) Schema {
+	return Schema{"type": "object", "description": description}
+}
+
+func backendSchema() Schema {
+	return Schema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"type"},
+		"properties": Schema{
+			"type":          stringSchema("Type of the Terraform backend, e.g. gcs"),
+			"configuration": dictSchema("Configuration of the Terraform backend"),
+		},
+	}
+}
+
+func providersSchema() Schema {
+	return Schema{
+		"type":        "object",
+		"description": "Terraform providers to use, by name",
+		"additionalProperties": Schema{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": Schema{
+				"source":        stringSchema("Source of the Terraform provider"),
+				"version":       stringSchema("Version constraint of the Terraform provider"),
+				"configuration": dictSchema("Configuration of the Terraform provider"),
+			},
+		},
+	}
+}
+
+func moduleSchema(mods []ModuleSchemaInfo) Schema {
+	sources := []any{}
+	for _, m := range mods {
+		sources = append(sources, m.Source)
+	}
+	outputs := Schema{"type": "array", "items": Schema{"anyOf": []any{
+		Schema{"type": "string"},
+		Schema{
+			"type":                 "object",
+			"additionalProperties": false,
+			"required":             []string{"name"},
+			"properties": Schema{
+				"name":        Schema{"type": "string"},
+				"description": Schema{"type": "string"},
+				"sensitive":   Schema{"type": "boolean"},
+			},
+		},
+	}}}
+
+	s := Schema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"id", "source"},
+		"properties": Schema{
+			"id":       stringSchema("Unique identifier of the module"),
+			"source":   Schema{"type": "string", "description": "Source of the module", "examples": sources},
+			"kind":     Schema{"enum": []any{"terraform", "packer"}, "description": "Kind of the module, defaults to terraform"},
+			"use":      Schema{"type": "array", "items": Schema{"type": "string"}, "description": "IDs of modules to use outputs of"},
+			"outputs":  outputs,
+			"settings": dictSchema("Settings of the module"),
+			"enabled":  withDescription(anyOfExpression(Schema{"type": "boolean"}), "The module is omitted if false"),
+			"for_each": withDescription(anyOfExpression(Schema{"type": []any{"object", "array"}}), "The module is replicated for each element"),
+			// deprecated fields
+			"required_apis":    Schema{"deprecated": true},
+			"wrapsettingswith": Schema{"deprecated": true},
+		},
+	}
+
+	cases := []any{}
+	for _, m := range mods {
+		cases = append(cases, Schema{
+			"if":   Schema{"properties": Schema{"source": Schema{"const": m.Source}}, "required": []string{"source"}},
+			"then": Schema{"properties": Schema{"settings": SettingsSchema(m.Info)}},
+		})
+	}
+	if len(cases) > 0 {
+		s["allOf"] = cases
+	}
+	return s
+}
+
+func groupSchema() Schema {
+	return Schema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"group", "modules"},
+		"properties": Schema{
+			"group":               stringSchema("Name of the deployment group"),
+			"terraform_backend":   Schema{"$ref": "#/definitions/backend"},
+			"terraform_providers": Schema{"$ref": "#/definitions/providers"},
+			"modules":             Schema{"type": "array", "items": Schema{"$ref": "#/definitions/module"}},
+			"enabled":             withDescription(anyOfExpression(Schema{"type": "boolean"}), "The group is omitted if false"),
+			"kind":                Schema{"deprecated": true},
+		},
+	}
+}
+
+// BlueprintSchema returns JSON Schema of the blueprint, settings of
+// modules with the given sources are described by their inputs.
+func BlueprintSchema(mods []ModuleSchemaInfo) Schema {
+	return Schema{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "Cluster Toolkit blueprint",
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"blueprint_name", "deployment_groups"},
+		"properties": Schema{
+			"blueprint_name": stringSchema("Name of the blueprint"),
+			"ghpc_version":   stringSchema("Version of the toolkit used to create the blueprint"),
+			"imports":        Schema{"type": "array", "items": Schema{"type": "string"}, "description": "Blueprint files to import"},
+			"validators": Schema{"type": "array", "items": Schema{
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             []string{"validator"},
+				"properties": Schema{
+					"validator": stringSchema("Name of the validator"),
+					"inputs":    dictSchema("Inputs of the validator"),
+					"skip":      Schema{"type": "boolean"},
+				},
+			}},
+			"validation_level": Schema{"enum": []any{0, 1, 2}, "description": "0 - ignore, 1 - warning, 2 - error"},
+			"vars": Schema{
+				"type":        "object",
+				"description": "Deployment variables",
+				"properties": Schema{
+					"deployment_name": withDescription(TypeSchema(cty.String), "Name of the deployment"),
+					"project_id":      withDescription(TypeSchema(cty.String), "ID of the project to deploy to"),
+					"region":          withDescription(TypeSchema(cty.String), "Default region"),
+					"zone":            withDescription(TypeSchema(cty.String), "Default zone"),
+					"labels":          anyOfExpression(Schema{"type": "object", "additionalProperties": Schema{"type": "string"}}),
+				},
+			},
+			"deployment_groups":          Schema{"type": "array", "items": Schema{"$ref": "#/definitions/group"}},
+			"terraform_backend_defaults": Schema{"$ref": "#/definitions/backend"},
+			"terraform_providers":        Schema{"$ref": "#/definitions/providers"},
+			"toolkit_modules_url":        stringSchema("URL of the repository with toolkit modules"),
+			"toolkit_modules_version":    stringSchema("Version of the toolkit modules"),
+		},
+		"definitions": Schema{
+			"expression": Schema{
+				"type":        "string",
+				"pattern":     `\$\(|^\(\(.*\)\)$`,
+				"description": "Blueprint expression, e.g. $(vars.zone)",
+			},
+			"backend":   backendSchema(),
+			"providers": providersSchema(),
+			"group":     groupSchema(),
+			"module":    moduleSchema(mods),
+		},
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect_test
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/inspect"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/sourcereader"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type schema = inspect.Schema
+
+var expr = schema{"$ref": "#/definitions/expression"}
+
+func orExpr(s schema) schema {
+	return schema{"anyOf": []any{s, expr}}
+}
+
+func TestTypeSchema(t *testing.T) {
+	type test struct {
+		ty   cty.Type
+		want schema
+	}
+	for _, tc := range []test{
+		{cty.String, orExpr(schema{"type": "string"})},
+		{cty.DynamicPseudoType, schema{}},
+		{cty.List(cty.Number), orExpr(schema{"type": "array", "items": orExpr(schema{"type": "number"})})},
+		{cty.Map(cty.Bool), orExpr(schema{"type": "object", "additionalProperties": orExpr(schema{"type": "boolean"})})},
+		{cty.Tuple([]cty.Type{cty.String}), orExpr(schema{
+			"type": "array", "items": []any{orExpr(schema{"type": "string"})}, "additionalItems": false})},
+		{cty.ObjectWithOptionalAttrs(map[string]cty.Type{"a": cty.String, "b": cty.Number}, []string{"b"}), orExpr(schema{
+			"type": "object",
+			"properties": schema{
+				"a": orExpr(schema{"type": "string"}),
+				"b": orExpr(schema{"type": "number"}),
+			},
+			"required": []string{"a"}})},
+	} {
+		t.Run(tc.ty.FriendlyName(), func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, inspect.TypeSchema(tc.ty)); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSettingsSchemaValidationRules(t *testing.T) {
+	info := modulereader.ModuleInfo{
+		Inputs: []modulereader.VarInfo{
+			{Name: "name", Type: cty.String, Description: "Name"},
+			{Name: "tier", Type: cty.String},
+			{Name: "size_gb", Type: cty.Number},
+			{Name: "zones", Type: cty.List(cty.String)},
+			{Name: "image", Type: cty.Object(map[string]cty.Type{"family": cty.String})},
+		},
+		Metadata: modulereader.Metadata{Ghpc: modulereader.MetadataGhpc{Validators: []modulereader.ValidationRule{
+			{Validator: "regex", Inputs: map[string]any{"vars": []any{"name", "image.family"}, "pattern": "^[a-z]+$"}},
+			{Validator: "allowed_enum", Inputs: map[string]any{"vars": []any{"tier"}, "allowed": []any{"BASIC", "ZONAL"}}},
+			{Validator: "range", Inputs: map[string]any{"vars": []any{"size_gb"}, "min": 1024}},
+			{Validator: "range", Inputs: map[string]any{"vars": []any{"zones"}, "max": 3, "length_check": true}},
+			{Validator: "exclusive", Inputs: map[string]any{"vars": []any{"name", "tier"}}},
+		}}},
+	}
+
+	got := inspect.SettingsSchema(info)
+	want := schema{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": schema{
+			"name":    schema{"anyOf": []any{schema{"type": "string", "pattern": "^[a-z]+$"}, expr}, "description": "Name"},
+			"tier":    orExpr(schema{"type": "string", "enum": []any{"BASIC", "ZONAL"}}),
+			"size_gb": orExpr(schema{"type": "number", "minimum": 1024}),
+			"zones":   orExpr(schema{"type": "array", "items": orExpr(schema{"type": "string"}), "maxItems": 3}),
+			"image": orExpr(schema{
+				"type":       "object",
+				"properties": schema{"family": orExpr(schema{"type": "string", "pattern": "^[a-z]+$"})},
+				"required":   []string{"family"}}),
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+}
+
+func yamlFields(ty reflect.Type) []string {
+	res := []string{}
+	for i := 0; i < ty.NumField(); i++ {
+		f := ty.Field(i)
+		if !f.IsExported() || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		res = append(res, name)
+	}
+	return res
+}
+
+func TestBlueprintSchemaCoversConfig(t *testing.T) {
+	s := inspect.BlueprintSchema(nil)
+	defs := s["definitions"].(schema)
+	for _, tc := range []struct {
+		ty    reflect.Type
+		props schema
+	}{
+		{reflect.TypeOf(config.Blueprint{}), s["properties"].(schema)},
+		{reflect.TypeOf(config.Group{}), defs["group"].(schema)["properties"].(schema)},
+		{reflect.TypeOf(config.Module{}), defs["module"].(schema)["properties"].(schema)},
+		{reflect.TypeOf(config.Validator{}), s["properties"].(schema)["validators"].(schema)["items"].(schema)["properties"].(schema)},
+		{reflect.TypeOf(config.TerraformBackend{}), defs["backend"].(schema)["properties"].(schema)},
+	} {
+		for _, f := range yamlFields(tc.ty) {
+			if _, ok := tc.props[f]; !ok {
+				t.Errorf("%s: field %q is missing in the schema", tc.ty.Name(), f)
+			}
+		}
+	}
+
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("schema is not serializable: %v", err)
+	}
+}
+
+func TestEmbeddedModules(t *testing.T) {
+	// we can't use embedded FS here (defined in super-package), use local one.
+	defer func(fs sourcereader.BaseFS) { sourcereader.ModuleFS = fs }(sourcereader.ModuleFS)
+	sourcereader.ModuleFS = os.DirFS("../..").(sourcereader.BaseFS)
+
+	got, err := inspect.EmbeddedModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := inspect.LocalModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+}
@@ -16,6 +16,7 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
@@ -32,7 +33,8 @@ var (
 	infolog      *log.Logger
 	errorlog     *log.Logger
 	fatallog     *log.Logger
-	FatalHook    func(exitCode int) // FatalHook allows registering a callback to run before the program exits on a fatal error.
+	FatalHook    func(exitCode int)             // FatalHook allows registering a callback to run before the program exits on a fatal error.
+	MessageHook  func(level string, msg string) // MessageHook allows observing messages, e.g. to forward them to a structured event stream.
 	Exit         = os.Exit
 	TsColor      = color.New(color.FgMagenta)
 	WarningColor = color.New(color.FgYellow)
@@ -44,6 +46,17 @@ func init() {
 	fatallog = log.New(os.Stderr, "", 0)
 }
 
+// SetInfoOutput redirects info messages, e.g. when stdout is reserved for a protocol.
+func SetInfoOutput(w io.Writer) {
+	infolog.SetOutput(w)
+}
+
+func notify(level string, msg string) {
+	if MessageHook != nil {
+		MessageHook(level, msg)
+	}
+}
+
 // formatTs returns a timestamp
 func formatTs() string {
 	ts := time.Now().UTC().Format(time.RFC3339)
@@ -53,18 +66,21 @@ func formatTs() string {
 // Info prints info to stdout
 func Info(f string, a ...any) {
 	msg := fmt.Sprintf(f, a...)
+	notify("info", msg)
 	infolog.Printf("%s: %s", formatTs(), msg)
 }
 
 // Warn prints message to stderr but does not end the program
 func Warn(f string, a ...any) {
 	msg := fmt.Sprintf(f, a...)
+	notify("warning", msg)
 	errorlog.Printf("%s: %s", formatTs(), WarningColor.Sprint("WARNING: "+msg))
 }
 
 // Error prints message to stderr but does not end the program
 func Error(f string, a ...any) {
 	msg := fmt.Sprintf(f, a...)
+	notify("error", msg)
 	errorlog.Printf("%s: %s", formatTs(), msg)
 }
 
@@ -80,8 +96,10 @@ func ExitWithCode(exitCode int, f string, a ...any) {
 	msg := fmt.Sprintf(f, a...)
 
 	if exitCode == successExitCode {
+		notify("info", msg)
 		infolog.Printf("%s: %s", formatTs(), msg)
 	} else {
+		notify("fatal", msg)
 		fatallog.Printf("%s: %s", formatTs(), msg)
 	}
 
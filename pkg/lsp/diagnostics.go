@@ -0,0 +1,260 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/sourcereader"
+	"hpc-toolkit/pkg/validators"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// placeholderDeploymentName is used to lint blueprints that expect the
+// deployment name to be supplied with `--vars` or a deployment file.
+const placeholderDeploymentName = "deployment"
+
+// retryRemoteAfter is how long failures to download remote imports and modules are kept.
+const retryRemoteAfter = time.Minute
+
+// getModuleInfo is replaced in tests.
+var getModuleInfo = modulereader.GetModuleInfo
+
+// remoteSources keeps remote imports and modules resolved by previous analyses,
+// documents are analyzed on every change and should not download them again.
+// Analyses only use downloaded sources and record missing ones, which are
+// downloaded by fetch without blocking the server.
+// Successfully read modules are kept by modulereader.
+type remoteSources struct {
+	imports *config.ImportCache
+	// guards the modules, fetch runs concurrently with analyses
+	mu       sync.Mutex
+	read     map[string]bool          // by module source and kind
+	failures map[string]moduleFailure // by module source and kind
+	missing  map[string]remoteModule  // by module source and kind, requested since the last fetch
+}
+
+type remoteModule struct {
+	source string
+	kind   string
+}
+
+type moduleFailure struct {
+	err error
+	at  time.Time
+}
+
+func newRemoteSources() *remoteSources {
+	return &remoteSources{
+		imports:  config.NewImportCache(retryRemoteAfter),
+		read:     map[string]bool{},
+		failures: map[string]moduleFailure{},
+		missing:  map[string]remoteModule{},
+	}
+}
+
+// checkModules checks that remote modules of the blueprint were read, Lint
+// panics on modules that can't be read. Returns false if some modules
+// are not downloaded yet.
+func (r *remoteSources) checkModules(bp config.Blueprint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	errs := config.Errors{}
+	done := true
+	bp.WalkModulesSafe(func(mp config.ModulePath, m *config.Module) {
+		if !sourcereader.IsRemotePath(m.Source) {
+			return
+		}
+		kind := m.Kind
+		if kind == config.UnknownKind {
+			kind = config.TerraformKind
+		}
+		key := m.Source + " " + kind.String()
+		if r.read[key] {
+			return
+		}
+		if f, ok := r.failures[key]; ok && time.Since(f.at) < retryRemoteAfter {
+			errs.At(mp.Source, f.err)
+			return
+		}
+		r.missing[key] = remoteModule{m.Source, kind.String()}
+		done = false
+	})
+	return done, errs.OrNil()
+}
+
+// fetch downloads remote imports and modules requested by analyses since the
+// last fetch, it runs without the server lock.
+func (r *remoteSources) fetch() {
+	r.imports.Fetch()
+
+	r.mu.Lock()
+	missing := r.missing
+	r.missing = map[string]remoteModule{}
+	r.mu.Unlock()
+
+	for key, m := range missing {
+		r.mu.Lock()
+		f, failed := r.failures[key]
+		skip := r.read[key] || failed && time.Since(f.at) < retryRemoteAfter
+		r.mu.Unlock()
+		if skip { // requested again while read by the previous fetch
+			continue
+		}
+		_, err := getModuleInfo(m.source, m.kind)
+
+		r.mu.Lock()
+		if err != nil {
+			r.failures[key] = moduleFailure{err, time.Now()}
+		} else {
+			delete(r.failures, key)
+			r.read[key] = true
+		}
+		r.mu.Unlock()
+	}
+}
+
+// analyze parses the document and returns problems found in it. Returns false
+// instead if remote sources of the document are not downloaded yet, see remoteSources.
+func analyze(doc *document, text string, remote *remoteSources) ([]Diagnostic, bool) {
+	bp, ctx, err := config.ParseBlueprintCached(doc.path, []byte(text), remote.imports)
+	if errors.Is(err, config.ErrNotFetched) {
+		return nil, false
+	}
+	if err != nil {
+		return diagnostics(err, *ctx, doc), true
+	}
+	doc.bp, doc.ctx = &bp, ctx
+
+	// Lint relocates positions of pruned and replicated modules, use a copy of the context
+	lctx := *ctx
+	lbp := bp.Clone()
+	lbp.YamlCtx = &lctx
+	if !lbp.Vars.Has("deployment_name") {
+		lbp.Vars = lbp.Vars.With("deployment_name", cty.StringVal(placeholderDeploymentName))
+	}
+	done, err := remote.checkModules(lbp)
+	if !done {
+		return nil, false
+	}
+	if err != nil {
+		return diagnostics(err, lctx, doc), true
+	}
+	if err = lbp.Lint(); err == nil {
+		err = validators.ValidateWithMetadata(lbp)
+	}
+	return diagnostics(err, lctx, doc), true
+}
+
+func diagnostics(err error, ctx config.YamlCtx, doc *document) []Diagnostic {
+	res := []Diagnostic{}
+	if err == nil {
+		return res
+	}
+	if errs, ok := err.(config.Errors); ok {
+		for _, e := range errs.Errors {
+			res = append(res, diagnostics(e, ctx, doc)...)
+		}
+		return res
+	}
+
+	msg := errMessage(err)
+	src, pos, ok := locate(err, ctx)
+	if ok && src.File != "" { // defined in an imported file, point to imports
+		msg = fmt.Sprintf("in %s: %s", src.File, msg)
+		pos, ok = findPos(config.Root.Imports, ctx)
+	}
+	if !ok {
+		pos = config.Pos{Line: 1, Column: 1}
+	}
+	return append(res, Diagnostic{
+		Range:    lineRange(doc.lines, pos),
+		Severity: SeverityError,
+		Source:   "gcluster",
+		Message:  msg,
+	})
+}
+
+// findSource finds position of the path or its closest ancestor.
+func findSource(p config.Path, ctx config.YamlCtx) (config.YamlCtx, config.Pos, bool) {
+	src, pos, ok := ctx.Source(p)
+	for !ok && p.Parent() != nil {
+		p = p.Parent()
+		src, pos, ok = ctx.Source(p)
+	}
+	return src, pos, ok
+}
+
+func findPos(p config.Path, ctx config.YamlCtx) (config.Pos, bool) {
+	_, pos, ok := findSource(p, ctx)
+	return pos, ok
+}
+
+func locate(err error, ctx config.YamlCtx) (config.YamlCtx, config.Pos, bool) {
+	switch te := err.(type) {
+	case config.BpError:
+		return findSource(te.Path, ctx)
+	case config.ImportError:
+		return findSource(te.Path, ctx)
+	case config.PosError:
+		return ctx, te.Pos, true
+	case config.HintError:
+		return locate(te.Err, ctx)
+	case validators.ValidatorError:
+		return locate(te.Err, ctx)
+	default:
+		return ctx, config.Pos{}, false
+	}
+}
+
+// errMessage renders the error without its location.
+func errMessage(err error) string {
+	switch te := err.(type) {
+	case config.BpError:
+		return errMessage(te.Err)
+	case config.PosError:
+		return errMessage(te.Err)
+	case config.ImportError:
+		return fmt.Sprintf("in %s: %s", te.Ctx.File, errMessage(te.Err))
+	case config.HintError:
+		return fmt.Sprintf("%s - %s", errMessage(te.Err), te.Hint)
+	case validators.ValidatorError:
+		return fmt.Sprintf("validator %q failed: %s", te.Validator, errMessage(te.Err))
+	case config.Errors:
+		msgs := []string{}
+		for _, e := range te.Errors {
+			msgs = append(msgs, errMessage(e))
+		}
+		return strings.Join(msgs, "\n")
+	default:
+		return err.Error()
+	}
+}
+
+// lineRange spans from the position to the end of its line.
+func lineRange(lines []string, pos config.Pos) Range {
+	start := Position{Line: max(pos.Line-1, 0), Character: max(pos.Column-1, 0)}
+	end := start
+	if start.Line < len(lines) {
+		end.Character = max(len(strings.TrimRight(lines[start.Line], " ")), start.Character)
+	}
+	return Range{Start: start, End: end}
+}
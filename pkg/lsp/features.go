@@ -0,0 +1,267 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/inspect"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/sourcereader"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// moduleInfo returns info of the module of the sequence item at the line.
+// Only embedded and local modules are inspected, to not download modules while typing.
+func moduleInfo(lines []string, item int) (modulereader.ModuleInfo, bool) {
+	source, ok := itemValue(lines, item, "source")
+	if !ok || !(sourcereader.IsEmbeddedPath(source) || sourcereader.IsLocalPath(source)) {
+		return modulereader.ModuleInfo{}, false
+	}
+	kind, ok := itemValue(lines, item, "kind")
+	if !ok {
+		kind = config.TerraformKind.String()
+	}
+	info, err := modulereader.GetModuleInfo(source, kind)
+	if err != nil {
+		return modulereader.ModuleInfo{}, false
+	}
+	return info, true
+}
+
+// findModule finds the module by ID in the last parsed blueprint.
+func (d *document) findModule(id string) (config.ModulePath, *config.Module, bool) {
+	if d.bp == nil {
+		return config.ModulePath{}, nil, false
+	}
+	for ig, g := range d.bp.Groups {
+		for im := range g.Modules {
+			if string(g.Modules[im].ID) == id {
+				return config.Root.Groups.At(ig).Modules.At(im), &g.Modules[im], true
+			}
+		}
+	}
+	return config.ModulePath{}, nil, false
+}
+
+func (s *Server) complete(doc *document, pos Position) []CompletionItem {
+	line := doc.lines[pos.Line]
+	if expr, ok := openExpression(line, pos.Character); ok {
+		return completeReference(doc, expr)
+	}
+
+	l, _ := parseLine(line)
+	anc := ancestors(doc.lines, pos.Line, pos.Character)
+	if l.key == "source" && pos.Character > l.keyCol+len(l.key) {
+		if _, ok := moduleItemLine(l, pos.Line, anc); ok {
+			return s.completeSource()
+		}
+	}
+	if l.key == "" || pos.Character <= l.keyCol+len(l.key) {
+		if item, ok := enclosingModule(anc, true); ok {
+			return completeSettings(doc.lines, item)
+		}
+	}
+	return []CompletionItem{}
+}
+
+// moduleItemLine returns the line of the module item, the line at the
+// cursor is either the start of the item or inside of it.
+func moduleItemLine(l yamlLine, line int, anc []ancestor) (int, bool) {
+	if l.item {
+		return line, len(anc) > 0 && anc[0].key == "modules"
+	}
+	return enclosingModule(anc, false)
+}
+
+func (s *Server) completeSource() []CompletionItem {
+	if s.sources == nil {
+		sources, err := inspect.EmbeddedModules()
+		if err != nil {
+			logging.Error("lsp: failed to list embedded modules: %v", err)
+		}
+		s.sources = sources
+	}
+	res := []CompletionItem{}
+	for _, sk := range s.sources {
+		res = append(res, CompletionItem{Label: sk.Source, Kind: KindModule, Detail: sk.Kind})
+	}
+	return res
+}
+
+func inputDetail(v modulereader.VarInfo) string {
+	ty := "any"
+	if v.Type != cty.NilType {
+		ty = typeexpr.TypeString(v.Type)
+	}
+	if v.Required {
+		return ty + " (required)"
+	}
+	return ty
+}
+
+func completeSettings(lines []string, item int) []CompletionItem {
+	info, ok := moduleInfo(lines, item)
+	if !ok {
+		return []CompletionItem{}
+	}
+	res := []CompletionItem{}
+	for _, v := range info.Inputs {
+		res = append(res, CompletionItem{
+			Label:         v.Name,
+			Kind:          KindProperty,
+			Detail:        inputDetail(v),
+			Documentation: markdown(v.Description),
+		})
+	}
+	return res
+}
+
+func completeReference(doc *document, expr string) []CompletionItem {
+	res := []CompletionItem{}
+	if doc.bp == nil {
+		return res
+	}
+	parts := strings.Split(expr, ".")
+	switch {
+	case len(parts) == 1:
+		res = append(res, CompletionItem{Label: "vars", Kind: KindVariable, Detail: "deployment variables"})
+		for _, g := range doc.bp.Groups {
+			for _, m := range g.Modules {
+				res = append(res, CompletionItem{Label: string(m.ID), Kind: KindModule, Detail: m.Source})
+			}
+		}
+	case len(parts) == 2 && parts[0] == "vars":
+		keys := doc.bp.Vars.Keys()
+		slices.Sort(keys)
+		for _, k := range keys {
+			res = append(res, CompletionItem{Label: k, Kind: KindVariable})
+		}
+	case len(parts) == 2 && parts[0] == "each":
+		res = append(res,
+			CompletionItem{Label: "key", Kind: KindField, Detail: "key of the instance"},
+			CompletionItem{Label: "value", Kind: KindField, Detail: "value of the instance"})
+	case len(parts) == 2:
+		_, m, ok := doc.findModule(parts[0])
+		if !ok {
+			break
+		}
+		info, err := modulereader.GetModuleInfo(m.Source, kindOf(*m))
+		if err != nil {
+			break
+		}
+		for _, o := range info.Outputs {
+			res = append(res, CompletionItem{Label: o.Name, Kind: KindField, Documentation: markdown(o.Description)})
+		}
+	}
+	return res
+}
+
+func kindOf(m config.Module) string {
+	if m.Kind == config.UnknownKind {
+		return config.TerraformKind.String()
+	}
+	return m.Kind.String()
+}
+
+func hover(doc *document, pos Position) *Hover {
+	line := doc.lines[pos.Line]
+	word, start, end := wordAt(line, pos.Character)
+	rng := &Range{Position{pos.Line, start}, Position{pos.Line, end}}
+
+	if _, ok := openExpression(line, start); ok {
+		parts := strings.Split(word, ".")
+		if len(parts) < 2 || parts[0] == "vars" {
+			return nil
+		}
+		_, m, ok := doc.findModule(parts[0])
+		if !ok {
+			return nil
+		}
+		info, err := modulereader.GetModuleInfo(m.Source, kindOf(*m))
+		if err != nil {
+			return nil
+		}
+		for _, o := range info.Outputs {
+			if o.Name == parts[1] {
+				text := fmt.Sprintf("**%s** output of module `%s`\n\n%s", o.Name, m.ID, o.Description)
+				return &Hover{Contents: *markdown(text), Range: rng}
+			}
+		}
+		return nil
+	}
+
+	l, _ := parseLine(line)
+	if l.key == "" || pos.Character < l.keyCol || pos.Character > l.keyCol+len(l.key) {
+		return nil
+	}
+	item, ok := enclosingModule(ancestors(doc.lines, pos.Line, pos.Character), true)
+	if !ok {
+		return nil
+	}
+	info, ok := moduleInfo(doc.lines, item)
+	if !ok {
+		return nil
+	}
+	for _, v := range info.Inputs {
+		if v.Name == l.key {
+			text := fmt.Sprintf("**%s** `%s`\n\n%s", v.Name, inputDetail(v), v.Description)
+			return &Hover{Contents: *markdown(text), Range: rng}
+		}
+	}
+	return nil
+}
+
+// definition locates deployment variables and modules referred by the word at the position,
+// e.g. `$(vars.zone)`, `$(network.network_id)` or `use: [network]`.
+func definition(doc *document, pos Position) *Location {
+	if doc.bp == nil {
+		return nil
+	}
+	word, _, _ := wordAt(doc.lines[pos.Line], pos.Character)
+	parts := strings.Split(word, ".")
+
+	var p config.Path
+	if parts[0] == "vars" && len(parts) > 1 {
+		if !doc.bp.Vars.Has(parts[1]) {
+			return nil
+		}
+		p = config.Root.Vars.Dot(parts[1])
+	} else if mp, _, ok := doc.findModule(parts[0]); ok {
+		p = mp.ID
+	} else {
+		return nil
+	}
+
+	src, ypos, ok := doc.ctx.Source(p)
+	if !ok {
+		return nil
+	}
+	uri := doc.uri
+	if src.File != "" {
+		if !filepath.IsAbs(src.File) { // remote import
+			return nil
+		}
+		uri = pathToURI(src.File)
+	}
+	start := Position{Line: ypos.Line - 1, Character: max(ypos.Column-1, 0)}
+	return &Location{URI: uri, Range: Range{Start: start, End: start}}
+}
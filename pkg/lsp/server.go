@@ -0,0 +1,357 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lsp implements the Language Server Protocol for blueprints.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/inspect"
+	"hpc-toolkit/pkg/logging"
+	"io"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analysisDelay postpones the analysis of a changed document until typing pauses.
+const analysisDelay = 300 * time.Millisecond
+
+// document is a blueprint opened in the editor.
+type document struct {
+	uri   string
+	path  string
+	lines []string
+	// last successfully parsed blueprint, kept while the document is not valid YAML
+	bp  *config.Blueprint
+	ctx *config.YamlCtx
+	// pending analysis of the changed text, see Server.schedule
+	pending *time.Timer
+	text    string
+	// the analysis waits for remote sources, see Server.fetch
+	waiting bool
+}
+
+// Server is a language server for blueprint YAML files.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+	// guards the state and writes to out, pending analyses and downloads run on their own goroutines
+	mu       sync.Mutex
+	docs     map[string]*document
+	sources  []inspect.SourceAndKind // embedded modules, loaded lazily
+	remote   *remoteSources
+	fetches  sync.WaitGroup // downloads of remote sources, see fetch
+	fetching bool           // a download is in progress, there is at most one
+	stopped  bool           // no downloads are started anymore
+	delay    time.Duration  // of the analysis after a change, zero analyzes immediately
+	shutdown bool
+}
+
+// NewServer creates a server communicating over given streams.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:     bufio.NewReader(in),
+		out:    out,
+		docs:   map[string]*document{},
+		remote: newRemoteSources(),
+		delay:  analysisDelay,
+	}
+}
+
+// Serve handles messages until the `exit` notification or the end of input.
+func (s *Server) Serve() error {
+	defer s.stop()
+	for {
+		msg, err := readMessage(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit before shutdown")
+			}
+			return nil
+		}
+		s.mu.Lock()
+		err = s.handle(msg)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// stop cancels pending analyses and removes downloaded remote imports,
+// after the download in progress, if any, finishes.
+func (s *Server) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	for _, doc := range s.docs {
+		doc.cancel()
+	}
+	s.mu.Unlock()
+	s.fetches.Wait()
+	s.remote.imports.Close()
+}
+
+func (s *Server) handle(msg message) error {
+	result, rerr := s.dispatch(msg)
+	if msg.ID == nil { // notification, no response
+		if rerr != nil {
+			logging.Error("lsp: %s: %s", msg.Method, rerr.Message)
+		}
+		return nil
+	}
+	resp := message{ID: msg.ID, Error: rerr}
+	if rerr == nil {
+		res, err := json.Marshal(result) // `null` is a valid result
+		if err != nil {
+			return err
+		}
+		resp.Result = res
+	}
+	return writeMessage(s.out, resp)
+}
+
+func (s *Server) dispatch(msg message) (result any, rerr *responseError) {
+	defer func() { // don't let a bug in a handler kill the editor session
+		if r := recover(); r != nil {
+			result, rerr = nil, &responseError{codeInternalError, fmt.Sprintf("%v", r)}
+		}
+	}()
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // full content on every change
+				"completionProvider": map[string]any{"triggerCharacters": []string{"(", "."}},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "gcluster", "version": config.GetToolkitVersion()},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		return nil, s.update(p.TextDocument.URI, p.TextDocument.Text, false)
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		if len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text, true)
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		if doc, ok := s.docs[p.TextDocument.URI]; ok {
+			doc.cancel()
+		}
+		delete(s.docs, p.TextDocument.URI)
+		if err := s.publish(p.TextDocument.URI, []Diagnostic{}); err != nil {
+			return nil, &responseError{codeInternalError, err.Error()}
+		}
+		return nil, nil
+	case "textDocument/completion", "textDocument/hover", "textDocument/definition":
+		var p textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc, ok := s.docs[p.TextDocument.URI]
+		if !ok || p.Position.Line < 0 || p.Position.Line >= len(doc.lines) {
+			return nil, nil
+		}
+		if err := s.flush(doc); err != nil { // features use the blueprint parsed by the analysis
+			return nil, &responseError{codeInternalError, err.Error()}
+		}
+		switch msg.Method {
+		case "textDocument/completion":
+			return s.complete(doc, p.Position), nil
+		case "textDocument/hover":
+			if h := hover(doc, p.Position); h != nil {
+				return h, nil
+			}
+			return nil, nil
+		default:
+			if l := definition(doc, p.Position); l != nil {
+				return l, nil
+			}
+			return nil, nil
+		}
+	default:
+		if msg.ID == nil || strings.HasPrefix(msg.Method, "$/") {
+			return nil, nil // ignore unsupported notifications
+		}
+		return nil, &responseError{codeMethodNotFound, fmt.Sprintf("method %q is not supported", msg.Method)}
+	}
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{codeInvalidParams, err.Error()}
+}
+
+// update replaces content of the document and publishes its diagnostics,
+// with delay the analysis is postponed until no other change comes in meanwhile.
+func (s *Server) update(uri string, text string, delay bool) *responseError {
+	doc, ok := s.docs[uri]
+	if !ok {
+		path, err := uriToPath(uri)
+		if err != nil {
+			return invalidParams(err)
+		}
+		doc = &document{uri: uri, path: path}
+		s.docs[uri] = doc
+	}
+	doc.lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	doc.cancel()
+	doc.text = text
+	if delay && s.delay > 0 {
+		s.schedule(doc)
+		return nil
+	}
+	if err := s.analyze(doc); err != nil {
+		return &responseError{codeInternalError, err.Error()}
+	}
+	return nil
+}
+
+// analyze publishes diagnostics of the document, unless its remote sources
+// need to be downloaded first.
+func (s *Server) analyze(doc *document) error {
+	diags, done := analyze(doc, doc.text, s.remote)
+	doc.waiting = !done
+	if !done {
+		s.fetch()
+		return nil
+	}
+	return s.publish(doc.uri, diags)
+}
+
+// fetch downloads remote sources requested by analyses without holding the
+// lock, so that slow downloads don't block other requests, then analyzes
+// documents waiting for them again.
+func (s *Server) fetch() {
+	if s.fetching || s.stopped { // the download in progress analyzes the waiting documents
+		return
+	}
+	s.fetching = true
+	s.fetches.Add(1)
+	go func() {
+		defer s.fetches.Done()
+		s.remote.fetch()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetching = false
+		defer func() { // don't let a bug in the analysis kill the editor session
+			if r := recover(); r != nil {
+				logging.Error("lsp: analysis after download: %v", r)
+			}
+		}()
+		for _, uri := range slices.Sorted(maps.Keys(s.docs)) {
+			doc := s.docs[uri]
+			if !doc.waiting || doc.pending != nil { // a pending analysis comes anyway
+				continue
+			}
+			if err := s.analyze(doc); err != nil {
+				logging.Error("lsp: analysis of %s: %v", doc.uri, err)
+			}
+		}
+	}()
+}
+
+// schedule analyzes the document after the delay, unless it's rescheduled, flushed or canceled before.
+func (s *Server) schedule(doc *document) {
+	var t *time.Timer
+	t = time.AfterFunc(s.delay, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if doc.pending != t { // superseded while waiting for the lock
+			return
+		}
+		defer func() { // don't let a bug in the analysis kill the editor session
+			if r := recover(); r != nil {
+				logging.Error("lsp: analysis of %s: %v", doc.uri, r)
+			}
+		}()
+		if err := s.flush(doc); err != nil {
+			logging.Error("lsp: analysis of %s: %v", doc.uri, err)
+		}
+	})
+	doc.pending = t
+}
+
+// flush runs the pending analysis of the document, if any.
+func (s *Server) flush(doc *document) error {
+	if doc.pending == nil {
+		return nil
+	}
+	doc.cancel()
+	return s.analyze(doc)
+}
+
+// cancel drops the pending analysis of the document.
+func (doc *document) cancel() {
+	if doc.pending != nil {
+		doc.pending.Stop()
+		doc.pending = nil
+	}
+}
+
+func (s *Server) publish(uri string, diags []Diagnostic) error {
+	params, err := json.Marshal(publishDiagnosticsParams{URI: uri, Diagnostics: diags})
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, message{Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("only file URIs are supported, got %q", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,329 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/sourcereader"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBlueprint = `
+blueprint_name: lsp
+vars:
+  project_id: test-project
+  region: us-central1
+  zone: us-central1-a
+deployment_groups:
+- group: primary
+  modules:
+  - id: network
+    source: modules/network/vpc
+  - id: vm
+    source: modules/compute/vm-instance
+    use: [network]
+    settings:
+      machine_typo: n2-standard-2
+      name_prefix: $(vars.zone)
+`
+
+func request(id int, method string, params any) message {
+	raw := json.RawMessage(toJSON(id))
+	return message{ID: &raw, Method: method, Params: json.RawMessage(toJSON(params))}
+}
+
+func notification(method string, params any) message {
+	return message{Method: method, Params: json.RawMessage(toJSON(params))}
+}
+
+func toJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+// session runs the server over given messages and returns responses by ID
+// and the last published diagnostics.
+func session(t *testing.T, msgs ...message) (map[string]json.RawMessage, []Diagnostic) {
+	t.Helper()
+	responses, published := serve(t, func(*Server) {}, msgs...)
+	var diags []Diagnostic
+	if len(published) > 0 {
+		diags = published[len(published)-1].Diagnostics
+	}
+	return responses, diags
+}
+
+// serve runs the server configured by the function over given messages and
+// returns responses by ID and all published diagnostics.
+func serve(t *testing.T, configure func(*Server), msgs ...message) (map[string]json.RawMessage, []publishDiagnosticsParams) {
+	t.Helper()
+	defer func(fs sourcereader.BaseFS) { sourcereader.ModuleFS = fs }(sourcereader.ModuleFS)
+	sourcereader.ModuleFS = os.DirFS("../..").(sourcereader.BaseFS)
+
+	var in, out bytes.Buffer
+	for _, m := range msgs {
+		if err := writeMessage(&in, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewServer(&in, &out)
+	configure(s)
+	if err := s.Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := map[string]json.RawMessage{}
+	published := []publishDiagnosticsParams{}
+	r := bufio.NewReader(&out)
+	for {
+		m, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ID != nil {
+			if m.Error != nil {
+				t.Fatalf("request %s failed: %s", *m.ID, m.Error.Message)
+			}
+			responses[string(*m.ID)] = m.Result
+			continue
+		}
+		var p publishDiagnosticsParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			t.Fatal(err)
+		}
+		published = append(published, p)
+	}
+	return responses, published
+}
+
+func labels(t *testing.T, raw json.RawMessage) []string {
+	var items []CompletionItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		t.Fatal(err)
+	}
+	res := []string{}
+	for _, it := range items {
+		res = append(res, it.Label)
+	}
+	return res
+}
+
+func TestSession(t *testing.T) {
+	uri := pathToURI(filepath.Join(t.TempDir(), "bp.yaml"))
+	pos := func(line, char int) map[string]any {
+		return map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": line, "character": char}}
+	}
+	resp, diags := session(t,
+		request(1, "initialize", map[string]any{}),
+		notification("initialized", map[string]any{}),
+		notification("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": testBlueprint}}),
+		request(2, "textDocument/completion", pos(15, 6)),
+		request(3, "textDocument/hover", pos(16, 8)),
+		request(4, "textDocument/definition", pos(16, 29)),
+		request(5, "shutdown", nil),
+		notification("exit", nil))
+
+	if len(diags) != 1 {
+		t.Fatalf("want 1 diagnostic, got %#v", diags)
+	}
+	if d := diags[0]; d.Range.Start != (Position{15, 6}) || !strings.Contains(d.Message, `did you mean "machine_type"?`) {
+		t.Errorf("unexpected diagnostic %#v", d)
+	}
+
+	if got := labels(t, resp["2"]); !slices.Contains(got, "machine_type") {
+		t.Errorf("completion %v doesn't contain machine_type", got)
+	}
+
+	var h Hover
+	if err := json.Unmarshal(resp["3"], &h); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(h.Contents.Value, "**name_prefix** `string`") {
+		t.Errorf("unexpected hover %q", h.Contents.Value)
+	}
+
+	var loc Location
+	if err := json.Unmarshal(resp["4"], &loc); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Location{uri, Range{Position{5, 2}, Position{5, 2}}}); loc != want {
+		t.Errorf("got definition %#v, want %#v", loc, want)
+	}
+
+	if string(resp["5"]) != "null" {
+		t.Errorf("got shutdown result %s, want null", resp["5"])
+	}
+}
+
+func TestCompletionOfInvalidDocument(t *testing.T) {
+	uri := pathToURI(filepath.Join(t.TempDir(), "bp.yaml"))
+	edit := func(line string) string { // replaces the last line of the blueprint
+		return strings.TrimSuffix(testBlueprint, "      name_prefix: $(vars.zone)\n") + line
+	}
+	type test struct {
+		line string
+		want string
+	}
+	for _, tc := range []test{
+		{"      mach", "machine_type"},
+		{"      name_prefix: $(", "network"},
+		{"      name_prefix: $(vars.", "zone"},
+		{"      name_prefix: $(network.", "network_name"},
+		{"  - source: modules/", "modules/network/vpc"},
+	} {
+		t.Run(tc.line, func(t *testing.T) {
+			resp, _ := session(t,
+				notification("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": testBlueprint}}),
+				notification("textDocument/didChange", map[string]any{
+					"textDocument":   map[string]any{"uri": uri},
+					"contentChanges": []any{map[string]any{"text": edit(tc.line)}}}),
+				request(1, "textDocument/completion", map[string]any{
+					"textDocument": map[string]any{"uri": uri},
+					"position":     map[string]any{"line": 16, "character": len(tc.line)}}))
+			if got := labels(t, resp["1"]); !slices.Contains(got, tc.want) {
+				t.Errorf("completion %v doesn't contain %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestChangesAreAnalyzedOnPause(t *testing.T) {
+	uri := pathToURI(filepath.Join(t.TempDir(), "bp.yaml"))
+	change := func(text string) message {
+		return notification("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri},
+			"contentChanges": []any{map[string]any{"text": text}}})
+	}
+	fixed := strings.Replace(testBlueprint, "machine_typo", "machine_type", 1)
+	_, published := serve(t, func(s *Server) { s.delay = time.Hour },
+		notification("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": testBlueprint}}),
+		change(strings.Replace(testBlueprint, "machine_typo", "machine_t", 1)),
+		change(fixed),
+		request(1, "textDocument/hover", map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 16, "character": 8}}),
+		change(testBlueprint)) // canceled on exit
+
+	// the document is analyzed once opened and once before the hover, for the last change
+	if len(published) != 2 {
+		t.Fatalf("want 2 published diagnostics, got %#v", published)
+	}
+	if got := published[1].Diagnostics; len(got) != 0 {
+		t.Errorf("want no diagnostics of the fixed blueprint, got %#v", got)
+	}
+}
+
+func TestRemoteModuleFailuresAreKept(t *testing.T) {
+	defer func(f func(string, string) (modulereader.ModuleInfo, error)) { getModuleInfo = f }(getModuleInfo)
+	reads := 0
+	getModuleInfo = func(string, string) (modulereader.ModuleInfo, error) {
+		reads++
+		return modulereader.ModuleInfo{}, errors.New("no network")
+	}
+
+	uri := pathToURI(filepath.Join(t.TempDir(), "bp.yaml"))
+	text := testBlueprint + "  - id: remote\n    source: github.com/org/repo//modules/remote\n"
+	change := notification("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri},
+		"contentChanges": []any{map[string]any{"text": text}}})
+	_, published := serve(t, func(s *Server) { s.delay = 0 },
+		notification("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": text}}),
+		change,
+		change)
+
+	if reads != 1 {
+		t.Errorf("got %d reads of the failed module, want 1", reads)
+	}
+	for _, p := range published {
+		if len(p.Diagnostics) != 1 {
+			t.Fatalf("want 1 diagnostic, got %#v", p.Diagnostics)
+		}
+		if d := p.Diagnostics[0]; d.Range.Start.Line != 18 || !strings.Contains(d.Message, "no network") {
+			t.Errorf("unexpected diagnostic %#v", d)
+		}
+	}
+}
+
+func TestDownloadsDontBlockRequests(t *testing.T) {
+	defer func(fs sourcereader.BaseFS) { sourcereader.ModuleFS = fs }(sourcereader.ModuleFS)
+	sourcereader.ModuleFS = os.DirFS("../..").(sourcereader.BaseFS)
+	defer func(f func(string, string) (modulereader.ModuleInfo, error)) { getModuleInfo = f }(getModuleInfo)
+	release := make(chan struct{})
+	getModuleInfo = func(string, string) (modulereader.ModuleInfo, error) {
+		<-release
+		return modulereader.ModuleInfo{}, errors.New("no network")
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	s := NewServer(inR, outW)
+	s.delay = 0
+	served := make(chan error)
+	go func() { served <- s.Serve() }()
+	send := func(m message) {
+		if err := writeMessage(inW, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := bufio.NewReader(outR)
+	receive := func() message {
+		m, err := readMessage(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	uri := pathToURI(filepath.Join(t.TempDir(), "bp.yaml"))
+	text := testBlueprint + "  - id: remote\n    source: github.com/org/repo//modules/remote\n"
+	send(notification("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "text": text}}))
+	send(request(1, "textDocument/hover", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": 16, "character": 8}}))
+	if m := receive(); m.ID == nil || string(*m.ID) != "1" {
+		t.Fatalf("want response to the hover while the module is downloaded, got %#v", m)
+	}
+
+	close(release)
+	m := receive()
+	var p publishDiagnosticsParams
+	if err := json.Unmarshal(m.Params, &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Diagnostics) != 1 || !strings.Contains(p.Diagnostics[0].Message, "no network") {
+		t.Errorf("want diagnostic of the failed download, got %#v", p.Diagnostics)
+	}
+
+	inW.Close()
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+}
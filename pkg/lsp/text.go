@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Blueprints being edited are often not valid YAML, e.g. a setting name is
+// typed without a colon yet. Helpers below use indentation to find out
+// where the cursor is, rather than parsing the document.
+
+var keyRe = regexp.MustCompile(`^([A-Za-z0-9_.-]+)\s*:(\s|$)`)
+
+// yamlLine is a non-empty line of the YAML document.
+type yamlLine struct {
+	indent int    // column of the first non-space character
+	item   bool   // the line starts a sequence item, e.g. `- id: a`
+	key    string // key of the mapping entry on the line, if any
+	keyCol int    // column of the key
+	value  string // value of the mapping entry, comments are not stripped
+}
+
+func parseLine(s string) (yamlLine, bool) {
+	trimmed := strings.TrimLeft(s, " ")
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return yamlLine{}, false
+	}
+	l := yamlLine{indent: len(s) - len(trimmed)}
+	rest := trimmed
+	if rest == "-" || strings.HasPrefix(rest, "- ") {
+		l.item = true
+		rest = strings.TrimLeft(rest[1:], " ")
+	}
+	l.keyCol = len(s) - len(rest)
+	if m := keyRe.FindStringSubmatch(rest); m != nil {
+		l.key = m[1]
+		l.value = strings.TrimSpace(rest[len(m[0]):])
+	}
+	return l, true
+}
+
+// ancestor is a mapping key or a sequence item enclosing a line.
+type ancestor struct {
+	key  string // empty for sequence items
+	line int
+}
+
+// ancestors returns keys and sequence items enclosing the position, innermost first.
+func ancestors(lines []string, line int, col int) []ancestor {
+	keyLimit, itemLimit := col, col
+	if line < len(lines) {
+		if l, ok := parseLine(lines[line]); ok {
+			keyLimit, itemLimit = l.indent, l.indent
+			if l.item { // items can be at the same column as their key
+				keyLimit = l.indent + 1
+			}
+		}
+	}
+
+	res := []ancestor{}
+	for i := min(line, len(lines)) - 1; i >= 0 && keyLimit > 0; i-- {
+		l, ok := parseLine(lines[i])
+		if !ok {
+			continue
+		}
+		if l.key != "" && l.keyCol < keyLimit {
+			res = append(res, ancestor{key: l.key, line: i})
+			keyLimit, itemLimit = l.keyCol, l.keyCol
+		}
+		if l.item && l.indent < itemLimit {
+			res = append(res, ancestor{line: i})
+			keyLimit, itemLimit = l.indent+1, l.indent
+		}
+	}
+	return res
+}
+
+// enclosingModule returns the line of the module item if ancestors
+// are `settings` (if settings is set) of a module in `modules`.
+func enclosingModule(anc []ancestor, settings bool) (int, bool) {
+	if settings {
+		if len(anc) == 0 || anc[0].key != "settings" {
+			return 0, false
+		}
+		anc = anc[1:]
+	}
+	if len(anc) < 2 || anc[0].key != "" || anc[1].key != "modules" {
+		return 0, false
+	}
+	return anc[0].line, true
+}
+
+// itemValue returns a value of the key of the mapping sequence item starting at the line.
+func itemValue(lines []string, item int, key string) (string, bool) {
+	first, ok := parseLine(lines[item])
+	if !ok {
+		return "", false
+	}
+	col := first.keyCol
+	for i := item; i < len(lines); i++ {
+		l, ok := parseLine(lines[i])
+		if !ok {
+			continue
+		}
+		if i > item && l.indent <= first.indent {
+			break // next item or end of the sequence
+		}
+		if l.keyCol == col && l.key == key {
+			return unquote(stripComment(l.value)), true
+		}
+	}
+	return "", false
+}
+
+func stripComment(s string) string {
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// openExpression returns the text of the unclosed `$(` expression before the column.
+func openExpression(line string, col int) (string, bool) {
+	before := line[:min(col, len(line))]
+	i := strings.LastIndex(before, "$(")
+	if i < 0 || strings.Contains(before[i:], ")") {
+		return "", false
+	}
+	return before[i+2:], true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// wordAt returns a dotted word, e.g. `vars.zone`, at the column and its bounds.
+func wordAt(line string, col int) (string, int, int) {
+	start, end := min(col, len(line)), min(col, len(line))
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordChar(line[end]) {
+		end++
+	}
+	return line[start:end], start, end
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAncestors(t *testing.T) {
+	lines := strings.Split(strings.TrimPrefix(testBlueprint, "\n"), "\n")
+	type test struct {
+		line, col int
+		want      []ancestor
+	}
+	for _, tc := range []test{
+		{0, 0, []ancestor{}},
+		{4, 2, []ancestor{{"vars", 1}}},
+		{9, 4, []ancestor{{"", 8}, {"modules", 7}, {"", 6}, {"deployment_groups", 5}}},
+		{10, 2, []ancestor{{"modules", 7}, {"", 6}, {"deployment_groups", 5}}},
+		{14, 10, []ancestor{{"settings", 13}, {"", 10}, {"modules", 7}, {"", 6}, {"deployment_groups", 5}}},
+		{15, 6, []ancestor{{"settings", 13}, {"", 10}, {"modules", 7}, {"", 6}, {"deployment_groups", 5}}},
+	} {
+		got := ancestors(lines, tc.line, tc.col)
+		if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(ancestor{})); diff != "" {
+			t.Errorf("%d:%d diff (-want +got):\n%s", tc.line, tc.col, diff)
+		}
+	}
+
+	item, ok := enclosingModule(ancestors(lines, 15, 6), true)
+	if !ok || item != 10 {
+		t.Fatalf("got module item at %d (found=%t), want 10", item, ok)
+	}
+	if src, ok := itemValue(lines, item, "source"); !ok || src != "modules/compute/vm-instance" {
+		t.Errorf("got source %q (found=%t)", src, ok)
+	}
+}
+
+func TestOpenExpression(t *testing.T) {
+	type test struct {
+		line string
+		want string
+		ok   bool
+	}
+	for _, tc := range []test{
+		{"  a: $(vars.", "vars.", true},
+		{"  a: $(vars.zone)-$(net", "net", true},
+		{"  a: $(vars.zone)", "", false},
+		{"  a: b", "", false},
+	} {
+		got, ok := openExpression(tc.line, len(tc.line))
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%q: got (%q, %t), want (%q, %t)", tc.line, got, ok, tc.want, tc.ok)
+		}
+	}
+}
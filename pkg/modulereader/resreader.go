@@ -23,6 +23,7 @@ import (
 	"hpc-toolkit/pkg/sourcereader"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/hashicorp/go-getter"
 	"github.com/zclconf/go-cty/cty"
@@ -121,13 +122,31 @@ type sourceAndKind struct {
 var modInfoCache = map[sourceAndKind]ModuleInfo{}
 var modDownloadCache = map[string]string{} // Cache for downloaded module data
 
+// cacheMu guards the caches, the language server reads modules concurrently
+// with the analysis of blueprints. It's not held while reading a module.
+var cacheMu sync.Mutex
+
+func cachedModuleInfo(key sourceAndKind) (ModuleInfo, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	mi, ok := modInfoCache[key]
+	return mi, ok
+}
+
+func cachedDownload(pkgAddr string) (string, bool) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	p, ok := modDownloadCache[pkgAddr]
+	return p, ok
+}
+
 // GetModuleInfo gathers information about a module at a given source using the
 // tfconfig package. It will add details about required APIs to be
 // enabled for that module.
 // There is a cache to avoid re-reading the module info for the same source and kind.
 func GetModuleInfo(source string, kind string) (ModuleInfo, error) {
 	key := sourceAndKind{source, kind}
-	if mi, ok := modInfoCache[key]; ok {
+	if mi, ok := cachedModuleInfo(key); ok {
 		return mi, nil
 	}
 
@@ -140,7 +159,7 @@ func GetModuleInfo(source string, kind string) (ModuleInfo, error) {
 		}
 	default:
 		pkgAddr, subDir := getter.SourceDirSubdir(source)
-		if cachedModPath, ok := modDownloadCache[pkgAddr]; ok {
+		if cachedModPath, ok := cachedDownload(pkgAddr); ok {
 			modPath = filepath.Join(cachedModPath, subDir)
 		} else {
 			tmpDir, err := os.MkdirTemp("", "module-*")
@@ -158,7 +177,9 @@ func GetModuleInfo(source string, kind string) (ModuleInfo, error) {
 				}
 				return ModuleInfo{}, err
 			}
+			cacheMu.Lock()
 			modDownloadCache[pkgAddr] = pkgPath
+			cacheMu.Unlock()
 		}
 	}
 
@@ -168,13 +189,17 @@ func GetModuleInfo(source string, kind string) (ModuleInfo, error) {
 		return ModuleInfo{}, err
 	}
 	mi.Metadata = GetMetadataSafe(modPath)
+	cacheMu.Lock()
 	modInfoCache[key] = mi
+	cacheMu.Unlock()
 	return mi, nil
 }
 
 // SetModuleInfo sets the ModuleInfo for a given source and kind
 // NOTE: This is only used for testing
 func SetModuleInfo(source string, kind string, info ModuleInfo) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
 	modInfoCache[sourceAndKind{source, kind}] = info
 }
 
@@ -75,7 +75,23 @@ var kinds = map[config.ModuleKind]ModuleWriter{
 var templatesFS embed.FS
 
 // WriteDeployment writes a deployment directory using modules defined the environment blueprint.
+// Remote deployment directories, e.g. `gs://bucket/dir`, are written through a local working copy.
 func WriteDeployment(bp config.Blueprint, deploymentDir string) error {
+	if !deploymentio.IsRemote(deploymentDir) {
+		return writeDeployment(bp, deploymentDir)
+	}
+
+	local, err := deploymentio.WorkingCopy(deploymentDir)
+	if err != nil {
+		return err
+	}
+	if err := writeDeployment(bp, local); err != nil {
+		return err
+	}
+	return deploymentio.Publish(local, deploymentDir)
+}
+
+func writeDeployment(bp config.Blueprint, deploymentDir string) error {
 	expanded := bp.Clone() // clone to avoid modifying the original blueprint
 
 	// TODO: probably not a right place to do "materialize". Consider bubbling it up.
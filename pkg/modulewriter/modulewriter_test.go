@@ -23,6 +23,7 @@ import (
 	"hpc-toolkit/pkg/modulereader"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -123,6 +124,33 @@ func (s *zeroSuite) TestWriteDeployment(c *C) {
 	c.Check(WriteDeployment(bp, dir), IsNil)
 }
 
+func (s *zeroSuite) TestWriteDeploymentRemote(c *C) {
+	store := deploymentio.NewMemStore()
+	deploymentio.SetObjectStore(store)
+	defer deploymentio.SetObjectStore(nil)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", c.MkDir())
+
+	bp := config.Blueprint{
+		Vars: config.Dict{}.With("deployment_name", cty.StringVal("green")),
+		Groups: []config.Group{{
+			Name: "ozon",
+			Modules: []config.Module{{
+				Source: "some/path",
+				ID:     "whole",
+				Kind:   config.TerraformKind,
+			}},
+		}},
+	}
+	c.Assert(WriteDeployment(bp, "gs://bucket/depls/green"), IsNil)
+	names := store.Names()
+	c.Check(slices.Contains(names, "gs://bucket/depls/green/.ghpc/artifacts/expanded_blueprint.yaml"), Equals, true)
+	c.Check(slices.Contains(names, "gs://bucket/depls/green/ozon/main.tf"), Equals, true)
+
+	// Overwriting the deployment succeeds
+	c.Check(WriteDeployment(bp, "gs://bucket/depls/green"), IsNil)
+}
+
 func (s *zeroSuite) TestCreateGroupDir(c *C) {
 	deplDir := c.MkDir()
 
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// A held JobSet is suspended and has no Kueue queue-name label, so Kueue
+	// doesn't admit it. The queue is kept in an annotation until it's released.
+	heldLabel           = "gcluster.google.com/held"
+	heldQueueAnnotation = "gcluster.google.com/queue-name"
+	afterAnnotation     = "gcluster.google.com/after"
+
+	defaultDependencyPollInterval = 30 * time.Second
+)
+
+// formatDependencies renders dependencies as the value of afterAnnotation
+func formatDependencies(after []orchestrator.JobDependency) string {
+	deps := make([]string, len(after))
+	for i, d := range after {
+		deps[i] = d.String()
+	}
+	return strings.Join(deps, ",")
+}
+
+// checkDependencies returns true if the job has to be held until its
+// dependencies finish, and an error if one of them can't be satisfied
+func (g *GKEOrchestrator) checkDependencies(after []orchestrator.JobDependency) (bool, error) {
+	if len(after) == 0 {
+		return false, nil
+	}
+	if g.kubeClient == nil {
+		if _, err := g.getDynamicClient(); err != nil { // ensure kubeClient is initialized
+			return false, err
+		}
+	}
+	jobs, err := g.kubeClient.ListJobSets("gcluster.google.com/workload")
+	if err != nil {
+		return false, fmt.Errorf("failed to get status of dependencies: %w", err)
+	}
+	statuses := map[string]string{}
+	for _, j := range jobs {
+		statuses[j.Name] = j.Status
+	}
+
+	pending := false
+	for _, d := range after {
+		status, ok := statuses[d.Name]
+		if !ok {
+			return false, fmt.Errorf("dependency %q not found in the cluster; finished jobs are deleted after --gke-ttl-after-finished", d.Name)
+		}
+		satisfied, failed := d.Check(status)
+		if failed {
+			return false, fmt.Errorf("dependency %q finished with status %q but is required to succeed", d.Name, status)
+		}
+		pending = pending || !satisfied
+	}
+	return pending, nil
+}
+
+// parseDependencies parses the value of afterAnnotation
+func parseDependencies(s string) []orchestrator.JobDependency {
+	var res []orchestrator.JobDependency
+	for _, d := range strings.Split(s, ",") {
+		name, cond, _ := strings.Cut(strings.TrimSpace(d), ":")
+		if name == "" {
+			continue
+		}
+		if cond == "" {
+			cond = orchestrator.AfterSucceeded
+		}
+		res = append(res, orchestrator.JobDependency{Name: name, Condition: cond})
+	}
+	return res
+}
+
+// awaitDependencies waits for the dependencies of a held job and releases it.
+// The job is deleted if a dependency fails.
+func (g *GKEOrchestrator) awaitDependencies(name string, after []orchestrator.JobDependency) error {
+	interval := g.dependencyPollInterval
+	for waiting := false; ; waiting = true {
+		pending, err := g.checkDependencies(after)
+		if err != nil {
+			if ns, nsErr := g.kubeClient.GetJobNamespace(name); nsErr == nil {
+				if delErr := g.kubeClient.DeleteJobSet(ns, name); delErr == nil {
+					return fmt.Errorf("job '%s' was deleted without running: %w", name, err)
+				}
+			}
+			return fmt.Errorf("job '%s' is still held: %w", name, err)
+		}
+		if !pending {
+			return g.releaseJobSet(name)
+		}
+		if !waiting {
+			logging.Info("Job '%s' is held until its dependencies finish (%s). Waiting...", name, formatDependencies(after))
+		}
+		time.Sleep(interval)
+	}
+}
+
+// ReleaseJob queues a held JobSet in Kueue, which then admits and starts it.
+// A job submitted with --after is released once its dependencies finish.
+func (g *GKEOrchestrator) ReleaseJob(name string, opts orchestrator.ReleaseOptions) error {
+	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return err
+	}
+	if _, err := g.getDynamicClient(); err != nil {
+		return fmt.Errorf("failed to initialize k8s client: %w", err)
+	}
+	return g.releaseHeldJob(name)
+}
+
+// releaseHeldJob releases the JobSet, waiting for the dependencies recorded
+// in its afterAnnotation first
+func (g *GKEOrchestrator) releaseHeldJob(name string) error {
+	ns, err := g.kubeClient.GetJobNamespace(name)
+	if err != nil {
+		return err
+	}
+	js, err := g.kubeClient.GetJobSet(ns, name)
+	if err != nil {
+		return fmt.Errorf("failed to get job %s: %w", name, err)
+	}
+	annotations, _, _ := unstructured.NestedStringMap(js, "metadata", "annotations")
+	if after := parseDependencies(annotations[afterAnnotation]); len(after) > 0 {
+		return g.awaitDependencies(name, after)
+	}
+	return g.releaseJobSet(name)
+}
+
+func (g *GKEOrchestrator) releaseJobSet(name string) error {
+	ns, err := g.kubeClient.GetJobNamespace(name)
+	if err != nil {
+		return err
+	}
+	if err := g.kubeClient.ReleaseJobSet(ns, name); err != nil {
+		return fmt.Errorf("failed to release job %s: %w", name, err)
+	}
+	logging.Info("Job '%s' released to Kueue.", name)
+	return nil
+}
+
+func (d *DefaultKubeClient) ReleaseJobSet(namespace string, name string) error {
+	gvr := schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
+	js, err := d.dynClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if js.GetLabels()[heldLabel] != "true" {
+		return fmt.Errorf("job %s is not held", name)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				heldLabel:                   nil,
+				"kueue.x-k8s.io/queue-name": js.GetAnnotations()[heldQueueAnnotation],
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = d.dynClient.Resource(gvr).Namespace(namespace).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,263 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// depsKubeClient returns the next list of job statuses on every ListJobSets
+// call, repeating the last one, and records released and deleted jobs
+type depsKubeClient struct {
+	MockKubeClient
+	lists       [][]orchestrator.JobStatus
+	annotations map[string]interface{}
+	released    []string
+	deleted     []string
+}
+
+func (c *depsKubeClient) ListJobSets(labelSelector string) ([]orchestrator.JobStatus, error) {
+	res := c.lists[0]
+	if len(c.lists) > 1 {
+		c.lists = c.lists[1:]
+	}
+	return res, nil
+}
+
+func (c *depsKubeClient) ReleaseJobSet(namespace string, name string) error {
+	c.released = append(c.released, name)
+	return nil
+}
+
+func (c *depsKubeClient) DeleteJobSet(namespace string, name string) error {
+	c.deleted = append(c.deleted, name)
+	return nil
+}
+
+func (c *depsKubeClient) GetJobSet(namespace string, name string) (map[string]interface{}, error) {
+	return map[string]interface{}{"metadata": map[string]interface{}{
+		"name":        name,
+		"labels":      map[string]interface{}{heldLabel: "true"},
+		"annotations": c.annotations,
+	}}, nil
+}
+
+func newDepsOrchestrator(kc *depsKubeClient) *GKEOrchestrator {
+	orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+	orc.kubeClient = kc
+	return orc
+}
+
+func TestCheckDependencies(t *testing.T) {
+	jobs := []orchestrator.JobStatus{
+		{Name: "prep", Status: "Succeeded"},
+		{Name: "train", Status: "Running"},
+		{Name: "broken", Status: "Failed"},
+	}
+	tests := []struct {
+		name        string
+		after       []orchestrator.JobDependency
+		wantPending bool
+		wantErr     string
+	}{
+		{"None", nil, false, ""},
+		{"Succeeded", []orchestrator.JobDependency{{Name: "prep", Condition: orchestrator.AfterSucceeded}}, false, ""},
+		{"Running", []orchestrator.JobDependency{{Name: "prep", Condition: orchestrator.AfterSucceeded}, {Name: "train", Condition: orchestrator.AfterAny}}, true, ""},
+		{"FailedAny", []orchestrator.JobDependency{{Name: "broken", Condition: orchestrator.AfterAny}}, false, ""},
+		{"FailedSucceeded", []orchestrator.JobDependency{{Name: "broken", Condition: orchestrator.AfterSucceeded}}, false, `dependency "broken" finished with status "Failed"`},
+		{"Missing", []orchestrator.JobDependency{{Name: "gone", Condition: orchestrator.AfterAny}}, false, `dependency "gone" not found`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orc := newDepsOrchestrator(&depsKubeClient{lists: [][]orchestrator.JobStatus{jobs}})
+			pending, err := orc.checkDependencies(tt.after)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || pending != tt.wantPending {
+				t.Errorf("checkDependencies() = %v, %v, want %v", pending, err, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestParseDependencies(t *testing.T) {
+	after := []orchestrator.JobDependency{
+		{Name: "prep", Condition: orchestrator.AfterSucceeded},
+		{Name: "train", Condition: orchestrator.AfterAny},
+	}
+	if got := parseDependencies(formatDependencies(after)); !reflect.DeepEqual(got, after) {
+		t.Errorf("parseDependencies() = %v, want %v", got, after)
+	}
+	if got := parseDependencies(""); len(got) != 0 {
+		t.Errorf("parseDependencies(\"\") = %v, want none", got)
+	}
+}
+
+func TestAwaitDependencies(t *testing.T) {
+	after := []orchestrator.JobDependency{{Name: "train", Condition: orchestrator.AfterSucceeded}}
+
+	kc := &depsKubeClient{lists: [][]orchestrator.JobStatus{
+		{{Name: "train", Status: "Running"}},
+		{{Name: "train", Status: "Running"}},
+		{{Name: "train", Status: "Succeeded"}},
+	}}
+	if err := newDepsOrchestrator(kc).awaitDependencies("eval", after); err != nil {
+		t.Fatalf("awaitDependencies() failed: %v", err)
+	}
+	if len(kc.released) != 1 || len(kc.deleted) != 0 {
+		t.Errorf("released %v, deleted %v, want the job released", kc.released, kc.deleted)
+	}
+
+	kc = &depsKubeClient{lists: [][]orchestrator.JobStatus{
+		{{Name: "train", Status: "Running"}},
+		{{Name: "train", Status: "Failed"}},
+	}}
+	err := newDepsOrchestrator(kc).awaitDependencies("eval", after)
+	if err == nil || !strings.Contains(err.Error(), "deleted without running") {
+		t.Errorf("expected failed dependency error, got %v", err)
+	}
+	if len(kc.released) != 0 || len(kc.deleted) != 1 {
+		t.Errorf("released %v, deleted %v, want the job deleted", kc.released, kc.deleted)
+	}
+}
+
+func TestReleaseHeldJob(t *testing.T) {
+	// held with --hold only, released right away
+	kc := &depsKubeClient{lists: [][]orchestrator.JobStatus{{}}}
+	if err := newDepsOrchestrator(kc).releaseHeldJob("eval"); err != nil {
+		t.Fatalf("releaseHeldJob() failed: %v", err)
+	}
+	if len(kc.released) != 1 {
+		t.Errorf("released %v, want the job released", kc.released)
+	}
+
+	// held by --after, released once the dependencies finish
+	kc = &depsKubeClient{
+		lists: [][]orchestrator.JobStatus{
+			{{Name: "train", Status: "Running"}},
+			{{Name: "train", Status: "Succeeded"}},
+		},
+		annotations: map[string]interface{}{afterAnnotation: "train:succeeded"},
+	}
+	if err := newDepsOrchestrator(kc).releaseHeldJob("eval"); err != nil {
+		t.Fatalf("releaseHeldJob() failed: %v", err)
+	}
+	if len(kc.released) != 1 || len(kc.lists) != 1 {
+		t.Errorf("released %v, want the job released after its dependency succeeded", kc.released)
+	}
+}
+
+func TestGenerateGKEManifest_Held(t *testing.T) {
+	setupMockMachineConfig(t)
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe n2-standard-2 --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
+	}
+	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
+	opts := ManifestOptions{
+		WorkloadName:    "eval",
+		FullImageName:   "test-image:latest",
+		CommandToRun:    "python eval.py",
+		ComputeType:     "n2-standard-2",
+		MachineType:     "n2-standard-2",
+		ClusterLocation: "us-central1-a",
+		KueueQueueName:  "multislice-queue",
+		Held:            true,
+		After:           formatDependencies([]orchestrator.JobDependency{{Name: "train", Condition: orchestrator.AfterAny}}),
+	}
+
+	manifest, err := orc.GenerateGKEManifest(opts, JobProfile{IsCPUMachine: true})
+	if err != nil {
+		t.Fatalf("GenerateGKEManifest failed: %v", err)
+	}
+	var js struct {
+		Metadata metav1.ObjectMeta
+		Spec     struct{ Suspend bool }
+	}
+	if err := k8syaml.Unmarshal([]byte(manifest), &js); err != nil {
+		t.Fatalf("manifest is not valid YAML: %v\n%s", err, manifest)
+	}
+	if _, ok := js.Metadata.Labels["kueue.x-k8s.io/queue-name"]; ok || js.Metadata.Labels[heldLabel] != "true" {
+		t.Errorf("held job must not be queued, labels: %v", js.Metadata.Labels)
+	}
+	if js.Metadata.Annotations[heldQueueAnnotation] != "multislice-queue" || js.Metadata.Annotations[afterAnnotation] != "train:any" {
+		t.Errorf("unexpected annotations: %v", js.Metadata.Annotations)
+	}
+	if !js.Spec.Suspend {
+		t.Errorf("held job must be suspended\n%s", manifest)
+	}
+
+	opts.Held, opts.After = false, ""
+	if manifest, err = orc.GenerateGKEManifest(opts, JobProfile{IsCPUMachine: true}); err != nil {
+		t.Fatalf("GenerateGKEManifest failed: %v", err)
+	}
+	if !strings.Contains(manifest, "kueue.x-k8s.io/queue-name: multislice-queue") || strings.Contains(manifest, "suspend:") {
+		t.Errorf("job should be queued\n%s", manifest)
+	}
+}
+
+func TestGeneratePathwaysManifest_Held(t *testing.T) {
+	setupMockMachineConfig(t)
+	job := orchestrator.JobDefinition{
+		WorkloadName:    "pathways-eval",
+		CommandToRun:    "echo hello",
+		ClusterLocation: "us-central1",
+		ComputeType:     "n2-standard-2",
+		KueueQueueName:  "multislice-queue",
+		Hold:            true,
+		After:           []orchestrator.JobDependency{{Name: "train", Condition: orchestrator.AfterSucceeded}},
+		Pathways: orchestrator.PathwaysJobDefinition{
+			GCSLocation:  "gs://my-bucket",
+			HeadNodePool: "pathways-np",
+		},
+	}
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe n2-standard-2 --zone=us-central1 --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
+	}
+	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
+	orc.projectID = "mock-project"
+	orc.clusterDesc.NodePools = []gkeJobNodePool{
+		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
+	}
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	if err != nil {
+		t.Fatalf("resolveHardwareRequirements failed: %v", err)
+	}
+	manifest, err := orc.GeneratePathwaysManifest(job, "test-image:latest", profile, isDynamicSlicing, isStaticSlicing)
+	if err != nil {
+		t.Fatalf("GeneratePathwaysManifest failed: %v", err)
+	}
+	var js struct {
+		Metadata metav1.ObjectMeta
+		Spec     struct{ Suspend bool }
+	}
+	if err := k8syaml.Unmarshal([]byte(manifest), &js); err != nil {
+		t.Fatalf("manifest is not valid YAML: %v\n%s", err, manifest)
+	}
+	if !js.Spec.Suspend || js.Metadata.Labels[heldLabel] != "true" || js.Metadata.Annotations[afterAnnotation] != "train:succeeded" {
+		t.Errorf("unexpected held Pathways manifest\n%s", manifest)
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	replicatedJobLabel = "jobset.sigs.k8s.io/replicatedjob-name"
+
+	// number of most recent events shown by DescribeJob
+	maxJobEvents = 20
+)
+
+// DescribeJob aggregates the JobSet of a job with its pods, its Kueue
+// workload and the recent events of all of them
+func (g *GKEOrchestrator) DescribeJob(name string, opts orchestrator.DescribeOptions) (*orchestrator.JobDescription, error) {
+	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return nil, err
+	}
+	if _, err := g.getDynamicClient(); err != nil {
+		return nil, fmt.Errorf("failed to initialize k8s client: %w", err)
+	}
+	return g.describeJobSet(name)
+}
+
+func (g *GKEOrchestrator) describeJobSet(name string) (*orchestrator.JobDescription, error) {
+	ns, err := g.kubeClient.GetJobNamespace(name)
+	if err != nil {
+		return nil, err
+	}
+	js, err := g.kubeClient.GetJobSet(ns, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobset %s: %w", name, err)
+	}
+
+	d := describeJobSetSpec(js)
+	d.Namespace = ns
+
+	// the remaining details only add to the JobSet, so failing to get them
+	// doesn't fail the description
+	pods, err := g.kubeClient.ListPods(ns, "gcluster.google.com/workload="+name)
+	if err != nil {
+		logging.Warn("Failed to list pods of job '%s': %v", name, err)
+	}
+	g.describePods(&d, pods)
+	if d.Status == "Running" {
+		d.Status = aggregatePodStatus(pods)
+	}
+
+	uid, _, _ := unstructured.NestedString(js, "metadata", "uid")
+	wls, err := g.kubeClient.ListKueueWorkloads(ns, "kueue.x-k8s.io/job-uid="+uid)
+	if err != nil {
+		logging.Warn("Failed to get the Kueue workload of job '%s': %v", name, err)
+	}
+	if len(wls) > 0 {
+		d.Admission = g.describeWorkload(wls[len(wls)-1])
+		if s := d.Admission.Status; s == "QuotaReserved" || s == "Evicted" {
+			d.Status = s
+		}
+		d.Reason = admissionReason(d.Admission)
+	}
+
+	events, err := g.kubeClient.ListEvents(ns)
+	if err != nil {
+		logging.Warn("Failed to list events of job '%s': %v", name, err)
+	}
+	d.Events = jobEvents(events, d, pods)
+	return &d, nil
+}
+
+// describeJobSetSpec summarizes the spec and status of a JobSet
+func describeJobSetSpec(js map[string]interface{}) orchestrator.JobDescription {
+	d := orchestrator.JobDescription{}
+	d.Name, _, _ = unstructured.NestedString(js, "metadata", "name")
+	d.CreationTime, _, _ = unstructured.NestedString(js, "metadata", "creationTimestamp")
+	d.Status, d.CompletionTime = parseJobStatus(js)
+
+	labels, _, _ := unstructured.NestedStringMap(js, "metadata", "labels")
+	annotations, _, _ := unstructured.NestedStringMap(js, "metadata", "annotations")
+	d.Queue = labels["kueue.x-k8s.io/queue-name"]
+	if labels[heldLabel] == "true" {
+		d.Queue = annotations[heldQueueAnnotation]
+		if d.Status == "Suspended" {
+			d.Status = "Held"
+		}
+		if after := annotations[afterAnnotation]; after != "" {
+			d.Reason = "waiting for " + after
+		}
+	}
+
+	restarts, _, _ := unstructured.NestedInt64(js, "status", "restarts")
+	maxRestarts, _, _ := unstructured.NestedInt64(js, "spec", "failurePolicy", "maxRestarts")
+	d.Restarts, d.MaxRestarts = int(restarts), int(maxRestarts)
+
+	rjobs, _, _ := unstructured.NestedSlice(js, "spec", "replicatedJobs")
+	for _, r := range rjobs {
+		rj, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		desc := orchestrator.ReplicatedJobDescription{}
+		desc.Name, _, _ = unstructured.NestedString(rj, "name")
+		replicas, _, _ := unstructured.NestedInt64(rj, "replicas")
+		parallelism, _, _ := unstructured.NestedInt64(rj, "template", "spec", "parallelism")
+		desc.Replicas, desc.Parallelism = int(replicas), int(parallelism)
+		desc.NodeSelector, _, _ = unstructured.NestedStringMap(rj, "template", "spec", "template", "spec", "nodeSelector")
+		if containers, _, _ := unstructured.NestedSlice(rj, "template", "spec", "template", "spec", "containers"); len(containers) > 0 {
+			if c, ok := containers[0].(map[string]interface{}); ok {
+				desc.Image, _, _ = unstructured.NestedString(c, "image")
+			}
+		}
+		if d.Priority == "" {
+			d.Priority, _, _ = unstructured.NestedString(rj, "template", "spec", "template", "spec", "priorityClassName")
+		}
+		d.ReplicatedJobs = append(d.ReplicatedJobs, desc)
+	}
+	return d
+}
+
+// describePods adds the phases, waiting containers, restarts and node pools
+// of the pods to their replicated jobs
+func (g *GKEOrchestrator) describePods(d *orchestrator.JobDescription, pods []map[string]interface{}) {
+	nodePools := map[string]string{}
+	for _, p := range pods {
+		rjName, _, _ := unstructured.NestedString(p, "metadata", "labels", replicatedJobLabel)
+		rj := replicatedJob(d, rjName)
+
+		phase, _, _ := unstructured.NestedString(p, "status", "phase")
+		if rj.Pods == nil {
+			rj.Pods = map[string]int{}
+		}
+		rj.Pods[phase]++
+
+		statuses, _, _ := unstructured.NestedSlice(p, "status", "containerStatuses")
+		for _, s := range statuses {
+			cs, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			restarts, _, _ := unstructured.NestedInt64(cs, "restartCount")
+			rj.ContainerRestarts += int(restarts)
+			if reason, _, _ := unstructured.NestedString(cs, "state", "waiting", "reason"); reason != "" {
+				if rj.WaitingReasons == nil {
+					rj.WaitingReasons = map[string]int{}
+				}
+				rj.WaitingReasons[reason]++
+			}
+		}
+
+		node, _, _ := unstructured.NestedString(p, "spec", "nodeName")
+		if node == "" {
+			continue
+		}
+		pool, ok := nodePools[node]
+		if !ok {
+			labels, err := g.kubeClient.GetNodeLabels(node)
+			if err != nil {
+				logging.Warn("Failed to get node %s: %v", node, err)
+			}
+			pool = labels[nodePoolLabel]
+			nodePools[node] = pool
+		}
+		if pool != "" && !slices.Contains(rj.NodePools, pool) {
+			rj.NodePools = append(rj.NodePools, pool)
+			sort.Strings(rj.NodePools)
+		}
+	}
+}
+
+// replicatedJob returns the replicated job with the given name, adding it
+// if the JobSet spec doesn't have it
+func replicatedJob(d *orchestrator.JobDescription, name string) *orchestrator.ReplicatedJobDescription {
+	for i := range d.ReplicatedJobs {
+		if d.ReplicatedJobs[i].Name == name {
+			return &d.ReplicatedJobs[i]
+		}
+	}
+	d.ReplicatedJobs = append(d.ReplicatedJobs, orchestrator.ReplicatedJobDescription{Name: name})
+	return &d.ReplicatedJobs[len(d.ReplicatedJobs)-1]
+}
+
+// describeWorkload summarizes the admission of a Kueue workload
+func (g *GKEOrchestrator) describeWorkload(wl map[string]interface{}) *orchestrator.AdmissionDescription {
+	a := &orchestrator.AdmissionDescription{Status: g.parseKueueWorkloadStatus(wl)}
+	a.Workload, _, _ = unstructured.NestedString(wl, "metadata", "name")
+	a.ClusterQueue, _, _ = unstructured.NestedString(wl, "status", "admission", "clusterQueue")
+
+	assignments, _, _ := unstructured.NestedSlice(wl, "status", "admission", "podSetAssignments")
+	for _, item := range assignments {
+		psa, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(psa, "name")
+		flavors, _, _ := unstructured.NestedStringMap(psa, "flavors")
+		names := []string{}
+		for _, f := range flavors {
+			if !slices.Contains(names, f) {
+				names = append(names, f)
+			}
+		}
+		sort.Strings(names)
+		if a.Flavors == nil {
+			a.Flavors = map[string]string{}
+		}
+		a.Flavors[name] = strings.Join(names, ",")
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(wl, "status", "conditions")
+	for _, item := range conditions {
+		c, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cond := orchestrator.JobCondition{}
+		cond.Type, _, _ = unstructured.NestedString(c, "type")
+		cond.Status, _, _ = unstructured.NestedString(c, "status")
+		cond.Reason, _, _ = unstructured.NestedString(c, "reason")
+		cond.Message, _, _ = unstructured.NestedString(c, "message")
+		cond.LastTransitionTime, _, _ = unstructured.NestedString(c, "lastTransitionTime")
+		a.Conditions = append(a.Conditions, cond)
+	}
+
+	checks, _, _ := unstructured.NestedSlice(wl, "status", "admissionChecks")
+	for _, item := range checks {
+		c, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		check := orchestrator.AdmissionCheck{}
+		check.Name, _, _ = unstructured.NestedString(c, "name")
+		check.State, _, _ = unstructured.NestedString(c, "state")
+		check.Message, _, _ = unstructured.NestedString(c, "message")
+		a.AdmissionChecks = append(a.AdmissionChecks, check)
+	}
+	return a
+}
+
+// admissionReason returns why a workload is not running: the message of a
+// pending admission check, of an eviction, or of missing quota
+func admissionReason(a *orchestrator.AdmissionDescription) string {
+	for _, c := range a.AdmissionChecks {
+		if c.State != "Ready" && c.Message != "" {
+			return fmt.Sprintf("admission check %s: %s", c.Name, c.Message)
+		}
+	}
+	for _, c := range a.Conditions {
+		if c.Type == "Evicted" && c.Status == "True" {
+			return c.Message
+		}
+	}
+	for _, c := range a.Conditions {
+		if c.Type == "QuotaReserved" && c.Status == "False" {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// jobEvents returns the most recent events of the JobSet, its jobs, pods and
+// Kueue workload, oldest first
+func jobEvents(events []map[string]interface{}, d orchestrator.JobDescription, pods []map[string]interface{}) []orchestrator.JobEvent {
+	objects := map[string]bool{d.Name: true}
+	if d.Admission != nil {
+		objects[d.Admission.Workload] = true
+	}
+	for _, p := range pods {
+		name, _, _ := unstructured.NestedString(p, "metadata", "name")
+		objects[name] = true
+	}
+	// jobs of a JobSet are named <jobset>-<replicated job>-<index>
+	jobNames := []string{}
+	for _, rj := range d.ReplicatedJobs {
+		jobNames = append(jobNames, regexp.QuoteMeta(rj.Name))
+	}
+	jobRe := regexp.MustCompile(fmt.Sprintf(`^%s-(%s)-\d+$`, regexp.QuoteMeta(d.Name), strings.Join(jobNames, "|")))
+
+	res := []orchestrator.JobEvent{}
+	for _, e := range events {
+		kind, _, _ := unstructured.NestedString(e, "involvedObject", "kind")
+		obj, _, _ := unstructured.NestedString(e, "involvedObject", "name")
+		if !objects[obj] && !(kind == "Job" && jobRe.MatchString(obj)) {
+			continue
+		}
+		ev := orchestrator.JobEvent{Object: strings.ToLower(kind) + "/" + obj}
+		ev.Type, _, _ = unstructured.NestedString(e, "type")
+		ev.Reason, _, _ = unstructured.NestedString(e, "reason")
+		ev.Message, _, _ = unstructured.NestedString(e, "message")
+		count, _, _ := unstructured.NestedInt64(e, "count")
+		ev.Count = int(count)
+		for _, f := range []string{"lastTimestamp", "eventTime", "firstTimestamp"} {
+			if ev.Time, _, _ = unstructured.NestedString(e, f); ev.Time != "" {
+				break
+			}
+		}
+		if ev.Time == "" {
+			ev.Time, _, _ = unstructured.NestedString(e, "metadata", "creationTimestamp")
+		}
+		res = append(res, ev)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Time < res[j].Time })
+	if len(res) > maxJobEvents {
+		res = res[len(res)-maxJobEvents:]
+	}
+	return res
+}
+
+func (d *DefaultKubeClient) GetJobSet(namespace string, name string) (map[string]interface{}, error) {
+	gvr := schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
+	js, err := d.dynClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return js.Object, nil
+}
+
+func (d *DefaultKubeClient) ListPods(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	return d.listObjects(gvr, namespace, labelSelector)
+}
+
+func (d *DefaultKubeClient) ListKueueWorkloads(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	gvr := schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "workloads"}
+	return d.listObjects(gvr, namespace, labelSelector)
+}
+
+func (d *DefaultKubeClient) ListEvents(namespace string) ([]map[string]interface{}, error) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}
+	return d.listObjects(gvr, namespace, "")
+}
+
+func (d *DefaultKubeClient) GetNodeLabels(name string) (map[string]string, error) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}
+	node, err := d.dynClient.Resource(gvr).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return node.GetLabels(), nil
+}
+
+func (d *DefaultKubeClient) listObjects(gvr schema.GroupVersionResource, namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	list, err := d.dynClient.Resource(gvr).Namespace(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]map[string]interface{}, 0, len(list.Items))
+	for _, item := range list.Items {
+		res = append(res, item.Object)
+	}
+	return res, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeKubeClient serves a fixed JobSet with its pods, workload, events
+// and nodes
+type describeKubeClient struct {
+	MockKubeClient
+	jobSet    map[string]interface{}
+	pods      []map[string]interface{}
+	workloads []map[string]interface{}
+	events    []map[string]interface{}
+	nodes     map[string]map[string]string
+}
+
+func (c *describeKubeClient) GetJobSet(namespace string, name string) (map[string]interface{}, error) {
+	return c.jobSet, nil
+}
+
+func (c *describeKubeClient) ListPods(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	return c.pods, nil
+}
+
+func (c *describeKubeClient) ListKueueWorkloads(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	return c.workloads, nil
+}
+
+func (c *describeKubeClient) ListEvents(namespace string) ([]map[string]interface{}, error) {
+	return c.events, nil
+}
+
+func (c *describeKubeClient) GetNodeLabels(name string) (map[string]string, error) {
+	return c.nodes[name], nil
+}
+
+func testPod(name string, rjob string, phase string, node string, restarts int64, waiting string) map[string]interface{} {
+	cs := map[string]interface{}{"restartCount": restarts, "state": map[string]interface{}{}}
+	if waiting != "" {
+		cs["state"] = map[string]interface{}{"waiting": map[string]interface{}{"reason": waiting}}
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "labels": map[string]interface{}{replicatedJobLabel: rjob}},
+		"spec":     map[string]interface{}{"nodeName": node},
+		"status":   map[string]interface{}{"phase": phase, "containerStatuses": []interface{}{cs}},
+	}
+}
+
+func testEvent(kind string, name string, reason string, time string) map[string]interface{} {
+	return map[string]interface{}{
+		"involvedObject": map[string]interface{}{"kind": kind, "name": name},
+		"type":           "Normal",
+		"reason":         reason,
+		"message":        reason + " " + name,
+		"lastTimestamp":  time,
+	}
+}
+
+func TestDescribeJobSet(t *testing.T) {
+	kc := &describeKubeClient{
+		MockKubeClient: MockKubeClient{Namespace: "team-a"},
+		jobSet: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":              "train",
+				"uid":               "uid-1",
+				"creationTimestamp": "2026-01-01T00:00:00Z",
+				"labels":            map[string]interface{}{"kueue.x-k8s.io/queue-name": "lq"},
+			},
+			"spec": map[string]interface{}{
+				"suspend":       false,
+				"failurePolicy": map[string]interface{}{"maxRestarts": int64(3)},
+				"replicatedJobs": []interface{}{
+					map[string]interface{}{
+						"name":     "workers",
+						"replicas": int64(2),
+						"template": map[string]interface{}{"spec": map[string]interface{}{
+							"parallelism": int64(2),
+							"template": map[string]interface{}{"spec": map[string]interface{}{
+								"priorityClassName": "high",
+								"nodeSelector":      map[string]interface{}{"cloud.google.com/gke-accelerator": "nvidia-l4"},
+								"containers":        []interface{}{map[string]interface{}{"image": "trainer:v1"}},
+							}},
+						}},
+					},
+				},
+			},
+			"status": map[string]interface{}{"restarts": int64(1)},
+		},
+		pods: []map[string]interface{}{
+			testPod("train-workers-0-0-a", "workers", "Running", "node-1", 2, ""),
+			testPod("train-workers-0-1-b", "workers", "Running", "node-1", 0, ""),
+			testPod("train-workers-1-0-c", "workers", "Pending", "node-2", 0, "ImagePullBackOff"),
+		},
+		workloads: []map[string]interface{}{{
+			"metadata": map[string]interface{}{"name": "jobset-train-123"},
+			"status": map[string]interface{}{
+				"admission": map[string]interface{}{
+					"clusterQueue": "cq",
+					"podSetAssignments": []interface{}{
+						map[string]interface{}{"name": "workers", "flavors": map[string]interface{}{"cpu": "l4", "nvidia.com/gpu": "l4"}},
+					},
+				},
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "QuotaReserved", "status": "True", "reason": "QuotaReserved", "lastTransitionTime": "2026-01-01T00:01:00Z"},
+					map[string]interface{}{"type": "Admitted", "status": "True", "reason": "Admitted", "lastTransitionTime": "2026-01-01T00:02:00Z"},
+				},
+				"admissionChecks": []interface{}{
+					map[string]interface{}{"name": "provisioning", "state": "Ready"},
+				},
+			},
+		}},
+		events: []map[string]interface{}{
+			testEvent("Pod", "train-workers-1-0-c", "BackOff", "2026-01-01T00:05:00Z"),
+			testEvent("JobSet", "train", "Created", "2026-01-01T00:00:00Z"),
+			testEvent("Job", "train-workers-1", "SuccessfulCreate", "2026-01-01T00:03:00Z"),
+			testEvent("Workload", "jobset-train-123", "Admitted", "2026-01-01T00:02:00Z"),
+			testEvent("Job", "train-2-workers-0", "SuccessfulCreate", "2026-01-01T00:03:00Z"),
+			testEvent("Pod", "other-pod", "Pulled", "2026-01-01T00:04:00Z"),
+		},
+		nodes: map[string]map[string]string{
+			"node-1": {nodePoolLabel: "l4-pool"},
+			"node-2": {nodePoolLabel: "l4-pool-2"},
+		},
+	}
+	orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+	orc.kubeClient = kc
+
+	d, err := orc.describeJobSet("train")
+	if err != nil {
+		t.Fatalf("describeJobSet() error = %v", err)
+	}
+
+	if d.Name != "train" || d.Namespace != "team-a" || d.Status != "Running" || d.Queue != "lq" || d.Priority != "high" || d.Restarts != 1 || d.MaxRestarts != 3 {
+		t.Errorf("unexpected job summary: %+v", d)
+	}
+
+	wantRJ := []orchestrator.ReplicatedJobDescription{{
+		Name:              "workers",
+		Replicas:          2,
+		Parallelism:       2,
+		Image:             "trainer:v1",
+		NodeSelector:      map[string]string{"cloud.google.com/gke-accelerator": "nvidia-l4"},
+		Pods:              map[string]int{"Running": 2, "Pending": 1},
+		WaitingReasons:    map[string]int{"ImagePullBackOff": 1},
+		ContainerRestarts: 2,
+		NodePools:         []string{"l4-pool", "l4-pool-2"},
+	}}
+	if !reflect.DeepEqual(d.ReplicatedJobs, wantRJ) {
+		t.Errorf("replicated jobs = %+v, want %+v", d.ReplicatedJobs, wantRJ)
+	}
+
+	a := d.Admission
+	if a == nil || a.Workload != "jobset-train-123" || a.Status != "Admitted" || a.ClusterQueue != "cq" || a.Flavors["workers"] != "l4" || len(a.Conditions) != 2 || len(a.AdmissionChecks) != 1 {
+		t.Fatalf("unexpected admission: %+v", a)
+	}
+
+	got := []string{}
+	for _, e := range d.Events {
+		got = append(got, e.Object)
+	}
+	want := "jobset/train,workload/jobset-train-123,job/train-workers-1,pod/train-workers-1-0-c"
+	if strings.Join(got, ",") != want {
+		t.Errorf("events = %v, want %s", got, want)
+	}
+}
+
+func TestDescribeJobSet_Pending(t *testing.T) {
+	kc := &describeKubeClient{
+		MockKubeClient: MockKubeClient{Namespace: "default"},
+		jobSet: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":        "eval",
+				"labels":      map[string]interface{}{heldLabel: "true"},
+				"annotations": map[string]interface{}{heldQueueAnnotation: "lq", afterAnnotation: "train:succeeded"},
+			},
+			"spec": map[string]interface{}{"suspend": true},
+		},
+	}
+	orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+	orc.kubeClient = kc
+
+	d, err := orc.describeJobSet("eval")
+	if err != nil {
+		t.Fatalf("describeJobSet() error = %v", err)
+	}
+	if d.Status != "Held" || d.Queue != "lq" || d.Reason != "waiting for train:succeeded" {
+		t.Errorf("unexpected held job description: %+v", d)
+	}
+
+	kc.jobSet["spec"] = map[string]interface{}{"suspend": true}
+	kc.jobSet["metadata"] = map[string]interface{}{"name": "eval", "labels": map[string]interface{}{"kueue.x-k8s.io/queue-name": "lq"}}
+	kc.workloads = []map[string]interface{}{{
+		"metadata": map[string]interface{}{"name": "jobset-eval-1"},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "QuotaReserved", "status": "False", "reason": "Pending", "message": "couldn't assign flavors to pod set workers: insufficient quota for nvidia.com/gpu"},
+		}},
+	}}
+	if d, err = orc.describeJobSet("eval"); err != nil {
+		t.Fatalf("describeJobSet() error = %v", err)
+	}
+	if d.Status != "Suspended" || !strings.Contains(d.Reason, "insufficient quota for nvidia.com/gpu") {
+		t.Errorf("unexpected pending job description: %+v", d)
+	}
+}
@@ -0,0 +1,575 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const reservationNameLabel = "cloud.google.com/reservation-name"
+
+// Kueue reports missing quota as e.g. "insufficient quota for cpu in flavor
+// flavor-default, request > maximum capacity (8 > 4)" or "insufficient unused
+// quota for cpu in flavor flavor-default, 4 more needed"
+var kueueQuotaRe = regexp.MustCompile(`insufficient (unused )?quota for (\S+) in flavor ([^\s,]+)`)
+
+// clusterRef identifies the cluster in the suggested commands
+type clusterRef struct {
+	name     string
+	location string
+	project  string
+}
+
+func (c clusterRef) flags() string {
+	return fmt.Sprintf("--cluster %s --location %s --project %s", c.name, c.location, c.project)
+}
+
+// DiagnoseJob explains why a job is not scheduled from the state of its
+// JobSet, pods and Kueue workload, checked against the ClusterQueue and the
+// capacity of the cluster
+func (g *GKEOrchestrator) DiagnoseJob(name string, opts orchestrator.DescribeOptions) (*orchestrator.JobDiagnosis, error) {
+	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return nil, err
+	}
+	if _, err := g.getDynamicClient(); err != nil {
+		return nil, fmt.Errorf("failed to initialize k8s client: %w", err)
+	}
+	d, err := g.describeJobSet(name)
+	if err != nil {
+		return nil, err
+	}
+	diag := &orchestrator.JobDiagnosis{Name: d.Name, Status: d.Status}
+	if !isWaiting(d) {
+		return diag, nil
+	}
+
+	// the cluster model only refines the suggested fixes, so the blockers
+	// found in the job state are reported without it
+	job := orchestrator.JobDefinition{ProjectID: opts.ProjectID, ClusterName: opts.ClusterName, ClusterLocation: opts.ClusterLocation}
+	if err := g.populateClusterMetadata(&job); err != nil {
+		logging.Warn("Failed to get the capacity of cluster '%s': %v", opts.ClusterName, err)
+	}
+	cq := ""
+	if d.Admission != nil {
+		cq = d.Admission.ClusterQueue
+	}
+	if cq == "" && d.Queue != "" {
+		if cq, err = g.getClusterQueueName(d.Queue); err != nil {
+			logging.Warn("Failed to get the ClusterQueue of job '%s': %v", name, err)
+		}
+	}
+
+	diag.Blockers = g.diagnose(d, clusterRef{opts.ClusterName, opts.ClusterLocation, job.ProjectID}, cq)
+	return diag, nil
+}
+
+// isWaiting tells whether a job, or some of its pods, wait to be scheduled
+func isWaiting(d *orchestrator.JobDescription) bool {
+	switch d.Status {
+	case "Pending", "Suspended", "Held", "QuotaReserved", "Evicted":
+		return true
+	case "Running":
+		for _, rj := range d.ReplicatedJobs {
+			if rj.Pods["Pending"] > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// diagnose lists the blockers of a waiting job, from those keeping it out of
+// Kueue to those keeping its pods from starting
+func (g *GKEOrchestrator) diagnose(d *orchestrator.JobDescription, c clusterRef, cq string) []orchestrator.JobBlocker {
+	res := []orchestrator.JobBlocker{}
+	if d.Status == "Held" {
+		res = append(res, heldBlocker(d))
+	}
+	res = append(res, g.queueBlockers(d, cq)...)
+	if d.Admission != nil {
+		res = append(res, g.admissionBlockers(d, c, cq)...)
+	}
+	res = append(res, g.eventBlockers(d, c)...)
+	res = append(res, g.imagePullBlockers(d)...)
+
+	if len(res) == 0 && d.Reason != "" {
+		res = append(res, orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerUnclassified,
+			Message: d.Reason,
+			Fixes:   []string{fmt.Sprintf("Inspect the job with `gcluster job describe %s`.", d.Name)},
+		})
+	}
+
+	// Kueue repeats the same message in several conditions
+	uniq := []orchestrator.JobBlocker{}
+	for _, b := range res {
+		if !slices.ContainsFunc(uniq, func(u orchestrator.JobBlocker) bool { return u.Message == b.Message }) {
+			uniq = append(uniq, b)
+		}
+	}
+	return uniq
+}
+
+func heldBlocker(d *orchestrator.JobDescription) orchestrator.JobBlocker {
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerHeld,
+		Message: "The job is held and won't be queued until it is released.",
+		Fixes:   []string{fmt.Sprintf("Release it with `gcluster job release %s`.", d.Name)},
+	}
+	if d.Reason != "" {
+		b.Message = fmt.Sprintf("The job is held %s, it stays held until it is released.", d.Reason)
+		b.Fixes = []string{
+			"Check the state of its dependencies with `gcluster job list`.",
+			fmt.Sprintf("Run `gcluster job release %s`, it waits for the dependencies and releases the job.", d.Name),
+		}
+	}
+	return b
+}
+
+// queueBlockers checks that the queue of a job exists and that its
+// ClusterQueue covers the resources every job requests
+func (g *GKEOrchestrator) queueBlockers(d *orchestrator.JobDescription, cq string) []orchestrator.JobBlocker {
+	if d.Admission != nil && d.Admission.ClusterQueue != "" {
+		return nil // admitted
+	}
+	if d.Admission == nil && d.Status == "Suspended" && d.Queue != "" {
+		if exists, err := g.checkLocalQueueExists(d.Queue); err == nil && !exists {
+			return []orchestrator.JobBlocker{{
+				Kind:    orchestrator.BlockerQuota,
+				Message: fmt.Sprintf("LocalQueue %q doesn't exist, so Kueue never admits the job.", d.Queue),
+				Fixes: []string{
+					"Submit the job again with `--queue` set to an existing LocalQueue (`kubectl get localqueues -n default`).",
+					fmt.Sprintf("Create LocalQueue %q pointing to a ClusterQueue (`kubectl get clusterqueues`).", d.Queue),
+				},
+			}}
+		}
+	}
+	if cq == "" {
+		return nil
+	}
+
+	hasCoverage, isEmpty, err := g.checkClusterQueueCoverage(cq)
+	if err != nil {
+		logging.Warn("Failed to check ClusterQueue '%s': %v", cq, err)
+		return nil
+	}
+	if hasCoverage {
+		return nil
+	}
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerQuota,
+		Message: fmt.Sprintf("ClusterQueue %q has no quota for cpu and memory, which every job requests.", cq),
+		Fixes:   []string{fmt.Sprintf("Add `cpu` and `memory` to the coveredResources of a resource group, with their quota (`kubectl edit clusterqueue %s`).", cq)},
+	}
+	if isEmpty {
+		b.Message = fmt.Sprintf("ClusterQueue %q has no resource groups, so it can't admit any job.", cq)
+		b.Fixes = []string{fmt.Sprintf("Add the flavors of the cluster with their quota to it (`kubectl edit clusterqueue %s`).", cq)}
+	}
+	if summary := g.capacitySummary(); summary != "" {
+		b.Fixes = append(b.Fixes, "The node pools of the cluster provide "+summary+".")
+	}
+	return []orchestrator.JobBlocker{b}
+}
+
+// admissionBlockers explains pending admission checks and the conditions
+// keeping Kueue from admitting the workload
+func (g *GKEOrchestrator) admissionBlockers(d *orchestrator.JobDescription, c clusterRef, cq string) []orchestrator.JobBlocker {
+	res := []orchestrator.JobBlocker{}
+	for _, check := range d.Admission.AdmissionChecks {
+		if check.State == "Ready" || check.Message == "" {
+			continue
+		}
+		msg := fmt.Sprintf("admission check %s: %s", check.Name, check.Message)
+		if b, ok := g.provisioningBlocker(msg, d, c); ok {
+			res = append(res, b)
+		} else {
+			res = append(res, orchestrator.JobBlocker{
+				Kind:    orchestrator.BlockerUnclassified,
+				Message: fmt.Sprintf("Admission check %s is %s: %s", check.Name, check.State, check.Message),
+				Fixes:   []string{fmt.Sprintf("Inspect it with `kubectl describe workload %s -n %s`.", d.Admission.Workload, d.Namespace)},
+			})
+		}
+	}
+	for _, cond := range d.Admission.Conditions {
+		pending := cond.Type == "QuotaReserved" && cond.Status == "False"
+		evicted := cond.Type == "Evicted" && cond.Status == "True"
+		if (pending || evicted) && cond.Message != "" {
+			res = append(res, g.kueueBlocker(cond.Message, d, c, cq))
+		}
+	}
+	return res
+}
+
+// kueueBlocker classifies the message Kueue gives for not admitting a workload
+func (g *GKEOrchestrator) kueueBlocker(msg string, d *orchestrator.JobDescription, c clusterRef, cq string) orchestrator.JobBlocker {
+	if m := kueueQuotaRe.FindStringSubmatch(msg); m != nil {
+		return g.quotaBlocker(msg, m[1] != "", m[2], m[3], cq)
+	}
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "topology"):
+		return g.topologyBlocker(msg, d, c)
+	case strings.Contains(lower, "node affinity"), strings.Contains(lower, "untolerated taint"),
+		strings.Contains(lower, "unavailable in clusterqueue"), strings.Contains(lower, "couldn't assign flavors"):
+		return g.noFlavorBlocker(fmt.Sprintf("No ResourceFlavor of ClusterQueue %q matches the nodes the job asks for (%s).", cq, msg), c)
+	case strings.Contains(lower, "preempt"):
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerQuota,
+			Message: fmt.Sprintf("The job was preempted to free quota for a job with a higher priority (%s).", msg),
+			Fixes:   []string{"Kueue queues it again once quota is free; submit it with a higher `--priority` to avoid preemption."},
+		}
+	}
+	return orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerUnclassified,
+		Message: msg,
+		Fixes:   []string{fmt.Sprintf("Inspect the workload with `kubectl describe workload %s -n %s`.", d.Admission.Workload, d.Namespace)},
+	}
+}
+
+func (g *GKEOrchestrator) quotaBlocker(msg string, unused bool, resource, flavor, cq string) orchestrator.JobBlocker {
+	if unused {
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerQuota,
+			Message: fmt.Sprintf("Other jobs use the %s quota of flavor %s in ClusterQueue %q (%s).", resource, flavor, cq, msg),
+			Fixes: []string{
+				"Wait for running jobs to finish, or cancel the ones you don't need (`gcluster job list --status Running`).",
+				"Submit with a higher `--priority` so Kueue can preempt jobs with a lower priority, if the ClusterQueue allows preemption.",
+			},
+		}
+	}
+
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerQuota,
+		Message: fmt.Sprintf("The job requests more %s than the quota of flavor %s in ClusterQueue %q (%s).", resource, flavor, cq, msg),
+		Fixes:   []string{"Submit a smaller job with fewer `--num-slices` or `--num-nodes`."},
+	}
+	if fc, ok := g.capacity.Flavors[flavor]; ok {
+		b.Fixes = append(b.Fixes, fmt.Sprintf("The cluster provides %v of %s in flavor %s; if the quota is lower, raise its nominalQuota (`kubectl edit clusterqueue %s`).",
+			g.getNominalQuota(resource, fc, flavor), resource, flavor, cq))
+	}
+	grow := "Add capacity by raising the maximum size of the node pools"
+	if g.napEnabled {
+		grow += " or the Node Auto-Provisioning limits of the cluster"
+	}
+	b.Fixes = append(b.Fixes, grow+", then raise the nominalQuota of the ClusterQueue.")
+	return b
+}
+
+func (g *GKEOrchestrator) topologyBlocker(msg string, d *orchestrator.JobDescription, c clusterRef) orchestrator.JobBlocker {
+	requested, accel := "", ""
+	for _, rj := range d.ReplicatedJobs {
+		if requested == "" {
+			requested = rj.NodeSelector[tpuTopologyLabel]
+		}
+		if accel == "" {
+			accel = rj.NodeSelector["cloud.google.com/gke-tpu-accelerator"]
+		}
+	}
+
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerTopology,
+		Message: fmt.Sprintf("No free nodes have the topology the job requests (%s).", msg),
+	}
+	offered := []string{}
+	for _, np := range g.matchingNodePools(d, tpuTopologyLabel) {
+		if np.PlacementPolicy != nil && np.PlacementPolicy.TpuTopology != "" && !slices.Contains(offered, np.PlacementPolicy.TpuTopology) {
+			offered = append(offered, np.PlacementPolicy.TpuTopology)
+		}
+	}
+	sort.Strings(offered)
+	if len(offered) > 0 {
+		b.Fixes = append(b.Fixes, fmt.Sprintf("The node pools of the cluster offer topologies %s; submit with one of them using `--topology`.", strings.Join(offered, ", ")))
+	}
+	if requested != "" {
+		b.Fixes = append(b.Fixes, fmt.Sprintf("Wait for the jobs using slices of topology %s to finish.", requested))
+		machineType := g.acceleratorToMachineType[strings.ToLower(accel)]
+		if machineType == "" {
+			machineType = "<MACHINE_TYPE>"
+		}
+		b.Fixes = append(b.Fixes, fmt.Sprintf("Add a node pool with that topology: `gcloud container node-pools create <POOL> %s --machine-type %s --tpu-topology %s`.", c.flags(), machineType, requested))
+	} else {
+		b.Fixes = append(b.Fixes, "Wait for running jobs to free nodes in one topology domain, or submit with fewer `--num-nodes` per slice.")
+	}
+	return b
+}
+
+func (g *GKEOrchestrator) noFlavorBlocker(msg string, c clusterRef) orchestrator.JobBlocker {
+	b := orchestrator.JobBlocker{Kind: orchestrator.BlockerNoFlavor, Message: msg}
+	flavors := []string{}
+	for f := range g.capacity.Flavors {
+		flavors = append(flavors, f)
+	}
+	sort.Strings(flavors)
+	if len(flavors) > 0 {
+		b.Fixes = append(b.Fixes, fmt.Sprintf("The cluster provides flavors %s; submit with a `--compute-type` one of them serves.", strings.Join(flavors, ", ")))
+	}
+	add := fmt.Sprintf("Add a node pool of the requested type: `gcloud container node-pools create <POOL> %s --machine-type <MACHINE_TYPE>`", c.flags())
+	if g.napEnabled {
+		add += ", or add its accelerator to the Node Auto-Provisioning limits"
+	}
+	b.Fixes = append(b.Fixes, add+".")
+	return b
+}
+
+// eventBlockers explains why admitted pods can't get nodes, from the events
+// of the cluster autoscaler and the scheduler, most recent first
+func (g *GKEOrchestrator) eventBlockers(d *orchestrator.JobDescription, c clusterRef) []orchestrator.JobBlocker {
+	res := []orchestrator.JobBlocker{}
+	for i := len(d.Events) - 1; i >= 0; i-- {
+		e := d.Events[i]
+		if e.Type != "Warning" && e.Reason != "NotTriggerScaleUp" {
+			continue
+		}
+		b, ok := g.provisioningBlocker(e.Message, d, c)
+		if !ok {
+			lower := strings.ToLower(e.Message)
+			switch {
+			case strings.Contains(lower, "max node group size reached"):
+				b, ok = g.nodePoolMaxBlocker(e.Message, d, c), true
+			case strings.Contains(lower, "max cluster") && strings.Contains(lower, "limit reached"):
+				b, ok = g.napLimitBlocker(e.Message, c), true
+			case e.Reason == "NotTriggerScaleUp" && strings.Contains(lower, "node affinity"):
+				b, ok = g.noFlavorBlocker(fmt.Sprintf("No node pool can create the nodes the job asks for (%s).", e.Message), c), true
+			}
+		}
+		if ok && !slices.ContainsFunc(res, func(r orchestrator.JobBlocker) bool { return r.Kind == b.Kind }) {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// provisioningBlocker recognizes failures to create VMs for the job: an
+// exhausted reservation or a zone without capacity
+func (g *GKEOrchestrator) provisioningBlocker(msg string, d *orchestrator.JobDescription, c clusterRef) (orchestrator.JobBlocker, bool) {
+	lower := strings.ToLower(msg)
+	if strings.Contains(lower, "reservation") && (strings.Contains(lower, "exhausted") || strings.Contains(lower, "not have available") ||
+		strings.Contains(lower, "insufficient") || strings.Contains(lower, "capacity")) {
+		return reservationBlocker(msg, d, c), true
+	}
+	if strings.Contains(lower, "out of resources") || strings.Contains(lower, "resource_pool_exhausted") ||
+		strings.Contains(lower, "resource pool exhausted") || strings.Contains(lower, "stockout") {
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerCapacity,
+			Message: fmt.Sprintf("Compute Engine has no capacity left for the machines of the job in the zones of the cluster (%s).", msg),
+			Fixes: []string{
+				"Use reserved capacity with `--gke-nap-provisioning reservation --gke-nap-reservation <NAME>`.",
+				"Use Spot VMs with `--gke-nap-provisioning spot`.",
+				fmt.Sprintf("Retry later, or add a node pool in another zone of %s.", c.location),
+			},
+		}, true
+	}
+	return orchestrator.JobBlocker{}, false
+}
+
+func reservationBlocker(msg string, d *orchestrator.JobDescription, c clusterRef) orchestrator.JobBlocker {
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerReservation,
+		Message: fmt.Sprintf("The reservation has no capacity left for the nodes of the job (%s).", msg),
+	}
+	reservation := ""
+	for _, rj := range d.ReplicatedJobs {
+		if r := rj.NodeSelector[reservationNameLabel]; r != "" {
+			reservation = r
+		}
+	}
+	if reservation != "" {
+		zone := c.location
+		if strings.Count(zone, "-") != 2 { // a region
+			zone = "<ZONE>"
+		}
+		b.Message = fmt.Sprintf("Reservation %s has no capacity left for the nodes of the job (%s).", reservation, msg)
+		b.Fixes = append(b.Fixes, fmt.Sprintf("Check how much of it is in use with `gcloud compute reservations describe %s --project %s --zone %s`.", reservation, c.project, zone))
+	}
+	b.Fixes = append(b.Fixes,
+		"Wait for the jobs using the reservation to finish.",
+		"Submit with another reservation (`--gke-nap-reservation <NAME>`), or without one (`--gke-nap-provisioning on-demand` or `spot`).")
+	return b
+}
+
+func (g *GKEOrchestrator) nodePoolMaxBlocker(msg string, d *orchestrator.JobDescription, c clusterRef) orchestrator.JobBlocker {
+	pools := g.matchingNodePools(d)
+	names := []string{}
+	fixes := []string{}
+	for _, np := range pools {
+		size := g.getNodeCount(np)
+		names = append(names, fmt.Sprintf("%s (%d nodes)", np.Name, size))
+		switch {
+		case !np.Autoscaling.Enabled:
+			fixes = append(fixes, fmt.Sprintf("Resize node pool %s, now %d nodes: `gcloud container clusters resize %s --node-pool %s --location %s --project %s --num-nodes <N>`.",
+				np.Name, size, c.name, np.Name, c.location, c.project))
+		case np.Autoscaling.TotalMaxNodeCount > 0:
+			fixes = append(fixes, fmt.Sprintf("Raise the maximum size of node pool %s, now %d nodes: `gcloud container node-pools update %s %s --enable-autoscaling --total-max-nodes <N>`.",
+				np.Name, np.Autoscaling.TotalMaxNodeCount, np.Name, c.flags()))
+		default:
+			fixes = append(fixes, fmt.Sprintf("Raise the maximum size of node pool %s, now %d nodes per zone: `gcloud container node-pools update %s %s --enable-autoscaling --max-nodes <N>`.",
+				np.Name, np.Autoscaling.MaxNodeCount, np.Name, c.flags()))
+		}
+	}
+
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerNodePoolMax,
+		Message: fmt.Sprintf("The node pools the job can run on reached their maximum size (%s).", msg),
+	}
+	if len(names) > 0 {
+		b.Message = fmt.Sprintf("The node pools the job can run on reached their maximum size: %s (%s).", strings.Join(names, ", "), msg)
+	} else {
+		fixes = append(fixes, fmt.Sprintf("Raise the maximum size of the node pools the job runs on: `gcloud container node-pools update <POOL> %s --enable-autoscaling --max-nodes <N>`.", c.flags()))
+	}
+	b.Fixes = append(fixes, "Submit a smaller job with fewer `--num-slices` or `--num-nodes`.")
+	return b
+}
+
+func (g *GKEOrchestrator) napLimitBlocker(msg string, c clusterRef) orchestrator.JobBlocker {
+	limits := []string{}
+	for k, v := range g.napLimits {
+		// skip the totals parseNAPLimits adds over all accelerators
+		if v > 0 && !strings.Contains(k, "/") {
+			limits = append(limits, fmt.Sprintf("%s=%d", k, v))
+		}
+	}
+	sort.Strings(limits)
+	b := orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerNodePoolMax,
+		Message: fmt.Sprintf("Node Auto-Provisioning can't add nodes, the cluster reached its resource limits (%s).", msg),
+		Fixes: []string{
+			fmt.Sprintf("Raise the limits: `gcloud container clusters update %s --location %s --project %s --enable-autoprovisioning --max-cpu <N> --max-memory <N>`, adding `--max-accelerator type=<TYPE>,count=<N>` for accelerators.",
+				c.name, c.location, c.project),
+			"Submit a smaller job with fewer `--num-slices` or `--num-nodes`.",
+		},
+	}
+	if len(limits) > 0 {
+		b.Message = fmt.Sprintf("Node Auto-Provisioning can't add nodes, the cluster reached its resource limits %s (%s).", strings.Join(limits, ", "), msg)
+	}
+	return b
+}
+
+// imagePullBlockers reports containers that can't pull their image
+func (g *GKEOrchestrator) imagePullBlockers(d *orchestrator.JobDescription) []orchestrator.JobBlocker {
+	res := []orchestrator.JobBlocker{}
+	for _, rj := range d.ReplicatedJobs {
+		n := rj.WaitingReasons["ImagePullBackOff"] + rj.WaitingReasons["ErrImagePull"] + rj.WaitingReasons["InvalidImageName"]
+		if n == 0 {
+			continue
+		}
+		b := orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerImagePull,
+			Message: fmt.Sprintf("%d containers of %s can't pull image %s.", n, rj.Name, rj.Image),
+			Fixes:   g.imagePullFixes(rj.Image),
+		}
+		for i := len(d.Events) - 1; i >= 0; i-- {
+			if e := d.Events[i]; e.Reason == "Failed" && strings.Contains(e.Message, "pull") {
+				b.Message += " " + e.Message
+				break
+			}
+		}
+		res = append(res, b)
+	}
+	return res
+}
+
+func (g *GKEOrchestrator) imagePullFixes(image string) []string {
+	// Artifact Registry images are named LOCATION-docker.pkg.dev/PROJECT/REPOSITORY/IMAGE
+	parts := strings.Split(image, "/")
+	if len(parts) < 4 || !strings.HasSuffix(parts[0], "-docker.pkg.dev") {
+		return []string{
+			fmt.Sprintf("Check that image %s exists and is spelled correctly.", image),
+			"If the registry is private, submit with `--image-pull-secret`.",
+		}
+	}
+	location := strings.TrimSuffix(parts[0], "-docker.pkg.dev")
+	fixes := []string{fmt.Sprintf("Check that the image exists: `gcloud artifacts docker images describe %s`.", image)}
+	sas := g.nodePoolSAs
+	if len(sas) == 0 {
+		sas = []string{"<GKE_NODE_SERVICE_ACCOUNT>"}
+	}
+	for _, sa := range sas {
+		fixes = append(fixes, fmt.Sprintf("Let the nodes read the repository: `gcloud artifacts repositories add-iam-policy-binding %s --location %s --project %s --member serviceAccount:%s --role roles/artifactregistry.reader`.",
+			parts[2], location, parts[1], sa))
+	}
+	return fixes
+}
+
+// matchingNodePools returns the node pools whose nodes match the node
+// selector of any replicated job, ignoring the given labels
+func (g *GKEOrchestrator) matchingNodePools(d *orchestrator.JobDescription, ignore ...string) []gkeJobNodePool {
+	res := []gkeJobNodePool{}
+	for _, np := range g.clusterDesc.NodePools {
+		if g.isSystemPool(np) {
+			continue
+		}
+		for _, rj := range d.ReplicatedJobs {
+			if g.nodePoolMatches(np, rj.NodeSelector, ignore) {
+				res = append(res, np)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func (g *GKEOrchestrator) nodePoolMatches(np gkeJobNodePool, selector map[string]string, ignore []string) bool {
+	for k, v := range selector {
+		if slices.Contains(ignore, k) {
+			continue
+		}
+		switch k {
+		case nodePoolLabel:
+			if np.Name != v {
+				return false
+			}
+		case "cloud.google.com/gke-accelerator", "cloud.google.com/gke-tpu-accelerator":
+			hasAccel := slices.ContainsFunc(np.Config.Accelerators, func(a gkeAccelerator) bool { return a.AcceleratorType == v })
+			if !hasAccel && np.Config.Labels[k] != v && g.acceleratorToMachineType[strings.ToLower(v)] != np.Config.MachineType {
+				return false
+			}
+		case tpuTopologyLabel:
+			if np.PlacementPolicy == nil || np.PlacementPolicy.TpuTopology != v {
+				return false
+			}
+		default:
+			// labels GKE sets on the nodes, e.g. for Spot VMs, aren't part of
+			// the node pool config, so only configured labels can mismatch
+			if lv, ok := np.Config.Labels[k]; ok && lv != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// capacitySummary describes the capacity calculated for the node pools
+func (g *GKEOrchestrator) capacitySummary() string {
+	parts := []string{}
+	if g.capacity.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%d CPUs", g.capacity.CPUs))
+	}
+	if g.capacity.MemoryGi > 0 {
+		parts = append(parts, fmt.Sprintf("%dGi of memory", g.capacity.MemoryGi))
+	}
+	if g.capacity.GPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%d GPUs", g.capacity.GPUs))
+	}
+	if g.capacity.TPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%d TPU chips", g.capacity.TPUs))
+	}
+	return strings.Join(parts, ", ")
+}
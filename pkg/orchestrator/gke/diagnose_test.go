@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"strings"
+	"testing"
+)
+
+var testCluster = clusterRef{name: "c1", location: "us-central1-a", project: "p1"}
+
+func TestDiagnose(t *testing.T) {
+	l4Selector := map[string]string{"cloud.google.com/gke-accelerator": "nvidia-l4"}
+	pending := func(msg string) *orchestrator.JobDescription {
+		return &orchestrator.JobDescription{
+			Name:           "train",
+			Namespace:      "default",
+			Status:         "Suspended",
+			ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{Name: "workers", NodeSelector: l4Selector}},
+			Admission: &orchestrator.AdmissionDescription{Workload: "jobset-train-1", Status: "Pending", Conditions: []orchestrator.JobCondition{
+				{Type: "QuotaReserved", Status: "False", Reason: "Pending", Message: msg},
+			}},
+		}
+	}
+	admitted := func(events ...orchestrator.JobEvent) *orchestrator.JobDescription {
+		return &orchestrator.JobDescription{
+			Name:           "train",
+			Status:         "Pending",
+			ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{Name: "workers", NodeSelector: l4Selector, Pods: map[string]int{"Pending": 2}}},
+			Admission:      &orchestrator.AdmissionDescription{Workload: "jobset-train-1", Status: "Admitted", ClusterQueue: "cq"},
+			Events:         events,
+		}
+	}
+	warning := func(reason string, msg string) orchestrator.JobEvent {
+		return orchestrator.JobEvent{Type: "Warning", Reason: reason, Object: "pod/train-workers-0-0", Message: msg}
+	}
+
+	tests := []struct {
+		name     string
+		d        *orchestrator.JobDescription
+		kind     string
+		wantText string
+	}{
+		{"quota", pending("couldn't assign flavors to pod set workers: insufficient quota for nvidia.com/gpu in flavor flavor-nvidia-l4, request > maximum capacity (16 > 4)"),
+			orchestrator.BlockerQuota, "The cluster provides 8 of nvidia.com/gpu in flavor flavor-nvidia-l4"},
+		{"unused quota", pending("couldn't assign flavors to pod set workers: insufficient unused quota for nvidia.com/gpu in flavor flavor-nvidia-l4, 4 more needed"),
+			orchestrator.BlockerQuota, "--priority"},
+		{"no flavor", pending("couldn't assign flavors to pod set workers: flavor flavor-nvidia-l4 doesn't match node affinity"),
+			orchestrator.BlockerNoFlavor, "flavor-default, flavor-nvidia-l4"},
+		{"topology", &orchestrator.JobDescription{
+			Name:   "train",
+			Status: "Suspended",
+			ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{Name: "workers", NodeSelector: map[string]string{
+				"cloud.google.com/gke-tpu-accelerator": "tpu-v6e-slice", tpuTopologyLabel: "4x4",
+			}}},
+			Admission: &orchestrator.AdmissionDescription{Workload: "jobset-train-1", Conditions: []orchestrator.JobCondition{
+				{Type: "QuotaReserved", Status: "False", Message: `couldn't assign flavors to pod set workers: topology "tpu" doesn't allow to fit any of 4 pod(s)`},
+			}},
+		}, orchestrator.BlockerTopology, "offer topologies 2x4"},
+		{"topology without request", pending(`topology "default" doesn't allow to fit any of 4 pod(s)`),
+			orchestrator.BlockerTopology, "fewer `--num-nodes` per slice"},
+		{"node pool at max", admitted(warning("NotTriggerScaleUp", "pod didn't trigger scale-up: 1 max node group size reached")),
+			orchestrator.BlockerNodePoolMax, "gcloud container node-pools update l4-pool --cluster c1 --location us-central1-a --project p1 --enable-autoscaling --total-max-nodes <N>"},
+		{"nap limit", admitted(warning("NotTriggerScaleUp", "pod didn't trigger scale-up: max cluster cpu, memory limit reached")),
+			orchestrator.BlockerNodePoolMax, "cpu=64"},
+		{"reservation", admitted(warning("FailedScaleUp", "Node scale up in zones us-central1-a associated with this pod failed: specified reservation res-1 does not have available resources for the request.")),
+			orchestrator.BlockerReservation, "--gke-nap-provisioning on-demand"},
+		{"stockout", admitted(warning("FailedScaleUp", "Node scale up in zones us-central1-a associated with this pod failed: GCE out of resources. Pod is at risk of not being scheduled.")),
+			orchestrator.BlockerCapacity, "--gke-nap-provisioning spot"},
+		{"image pull", &orchestrator.JobDescription{Name: "train", Status: "Pending", ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{
+			Name: "workers", Image: "us-docker.pkg.dev/p1/repo/trainer:v1", Pods: map[string]int{"Pending": 1}, WaitingReasons: map[string]int{"ImagePullBackOff": 1},
+		}}}, orchestrator.BlockerImagePull, "add-iam-policy-binding repo --location us --project p1 --member serviceAccount:nodes@p1.iam.gserviceaccount.com"},
+		{"held", &orchestrator.JobDescription{Name: "eval", Status: "Held", Reason: "waiting for train:succeeded"},
+			orchestrator.BlockerHeld, "gcluster job release eval"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cq, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"resourceGroups": []interface{}{
+				map[string]interface{}{"coveredResources": []interface{}{"cpu", "memory", "nvidia.com/gpu"}},
+			}}})
+			orc := newTestGKEOrchestrator(NewMockExecutor(map[string][]shell.CommandResult{
+				"kubectl get clusterqueue cq": {{ExitCode: 0, Stdout: string(cq)}},
+			}))
+			orc.capacity = ClusterCapacity{CPUs: 96, Flavors: map[string]FlavorCapacity{
+				"flavor-default":   {CPUs: 32},
+				"flavor-nvidia-l4": {CPUs: 64, GPUs: 8},
+			}}
+			orc.clusterDesc = gkeCluster{NodePools: []gkeJobNodePool{
+				{Name: "cpu-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-32"}},
+				{Name: "l4-pool", Config: gkeNodePoolConfig{MachineType: "g2-standard-48", Accelerators: []gkeAccelerator{{AcceleratorCount: "4", AcceleratorType: "nvidia-l4"}}},
+					Autoscaling: gkeAutoscaling{Enabled: true, TotalMaxNodeCount: 2}},
+				{Name: "v6e-pool", Config: gkeNodePoolConfig{MachineType: "ct6e-standard-4t"}, PlacementPolicy: &gkePlacementPolicy{TpuTopology: "2x4"}},
+			}}
+			orc.acceleratorToMachineType["tpu-v6e-slice"] = "ct6e-standard-4t"
+			orc.napLimits = map[string]int64{"cpu": 64, "memory": 256, "nvidia-l4": 8, "nvidia.com/gpu": 8}
+			orc.nodePoolSAs = []string{"nodes@p1.iam.gserviceaccount.com"}
+
+			blockers := orc.diagnose(tc.d, testCluster, "cq")
+			if len(blockers) == 0 {
+				t.Fatalf("diagnose() found no blockers")
+			}
+			b := blockers[0]
+			text := b.Message + "\n" + strings.Join(b.Fixes, "\n")
+			if b.Kind != tc.kind || !strings.Contains(text, tc.wantText) {
+				t.Errorf("diagnose() = %+v, want kind %q mentioning %q", b, tc.kind, tc.wantText)
+			}
+		})
+	}
+}
+
+func TestDiagnose_ClusterQueueCoverage(t *testing.T) {
+	orc := newTestGKEOrchestrator(NewMockExecutor(map[string][]shell.CommandResult{
+		"kubectl get clusterqueue cq": {{ExitCode: 0, Stdout: `{"spec": {}}`}},
+	}))
+	orc.capacity = ClusterCapacity{CPUs: 32, MemoryGi: 128}
+	d := &orchestrator.JobDescription{
+		Name:      "train",
+		Status:    "Suspended",
+		Admission: &orchestrator.AdmissionDescription{Workload: "jobset-train-1", Status: "Pending"},
+	}
+
+	blockers := orc.diagnose(d, testCluster, "cq")
+	if len(blockers) != 1 || blockers[0].Kind != orchestrator.BlockerQuota || !strings.Contains(blockers[0].Message, "no resource groups") {
+		t.Fatalf("diagnose() = %+v, want a quota blocker for the empty ClusterQueue", blockers)
+	}
+	if fixes := strings.Join(blockers[0].Fixes, "\n"); !strings.Contains(fixes, "32 CPUs, 128Gi of memory") {
+		t.Errorf("fixes = %q, want the capacity of the cluster", fixes)
+	}
+}
+
+func TestIsWaiting(t *testing.T) {
+	tests := []struct {
+		d    orchestrator.JobDescription
+		want bool
+	}{
+		{orchestrator.JobDescription{Status: "Suspended"}, true},
+		{orchestrator.JobDescription{Status: "Held"}, true},
+		{orchestrator.JobDescription{Status: "Succeeded"}, false},
+		{orchestrator.JobDescription{Status: "Running", ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{Pods: map[string]int{"Running": 2}}}}, false},
+		{orchestrator.JobDescription{Status: "Running", ReplicatedJobs: []orchestrator.ReplicatedJobDescription{{Pods: map[string]int{"Running": 1, "Pending": 1}}}}, true},
+	}
+	for _, tc := range tests {
+		if got := isWaiting(&tc.d); got != tc.want {
+			t.Errorf("isWaiting(%+v) = %v, want %v", tc.d, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	corev1 "k8s.io/api/core/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// secretsVolumeName is the volume of Secret Manager secrets, mounted by
+	// the GKE Secret Manager add-on
+	secretsVolumeName = "gcluster-secrets"
+	secretsMountPath  = "/var/run/gcluster/secrets"
+	secretsCSIDriver  = "secrets-store-gke.csi.k8s.io"
+)
+
+var (
+	// variables set by the jobset template with --verbose
+	verboseTPUEnv = []string{"TPU_STDERR_LOG_LEVEL", "TPU_MIN_LOG_LEVEL", "TF_CPP_MIN_LOG_LEVEL", "TPU_VMODULE"}
+	verboseGPUEnv = []string{"NCCL_DEBUG"}
+	// variables set by the Pathways template on the workers
+	pathwaysWorkerEnv = []string{
+		"XCLOUD_ENVIRONMENT", "MEGASCALE_GRPC_ENABLE_XOR_TRACER", "MEGASCALE_NUM_SLICES", "JOBSET_NAME",
+		"REPLICATED_JOB_NAME", "MEGASCALE_SLICE_ID", "PATHWAYS_HEAD", "MEGASCALE_COORDINATOR_ADDRESS"}
+	pathwaysVerboseEnv = []string{"TPU_MIN_LOG_LEVEL", "TF_CPP_MIN_LOG_LEVEL"}
+)
+
+// jobSetBuiltinEnv returns names of variables set by the jobset template
+func jobSetBuiltinEnv(verbose, isTPU, isGPU bool) []string {
+	switch {
+	case !verbose:
+		return nil
+	case isTPU:
+		return verboseTPUEnv
+	case isGPU:
+		return verboseGPUEnv
+	default:
+		return nil
+	}
+}
+
+// pathwaysBuiltinEnv returns names of variables set by the Pathways template
+func pathwaysBuiltinEnv(verbose bool) []string {
+	if verbose {
+		return append(append([]string{}, pathwaysWorkerEnv...), pathwaysVerboseEnv...)
+	}
+	return pathwaysWorkerEnv
+}
+
+// checkEnvCollisions makes sure that user variables don't override variables set by gcluster
+func checkEnvCollisions(env []orchestrator.EnvVar, builtin []string) error {
+	reserved := map[string]bool{}
+	for _, n := range builtin {
+		reserved[n] = true
+	}
+	for _, e := range env {
+		if reserved[e.Name] {
+			return fmt.Errorf("environment variable %q is set by gcluster for this workload and cannot be overridden", e.Name)
+		}
+	}
+	return nil
+}
+
+// buildEnvString renders literal variables and Kubernetes Secret references,
+// Secret Manager variables are exported by the command, see secretManagerEnv
+func buildEnvString(env []orchestrator.EnvVar, indent int) (string, error) {
+	vars := []corev1.EnvVar{}
+	for _, e := range env {
+		switch {
+		case e.SecretVersion != "":
+			continue
+		case e.SecretName != "":
+			vars = append(vars, corev1.EnvVar{Name: e.Name, ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: e.SecretName},
+					Key:                  e.SecretKey,
+				}}})
+		default:
+			vars = append(vars, corev1.EnvVar{Name: e.Name, Value: e.Value})
+		}
+	}
+	if len(vars) == 0 {
+		return "", nil
+	}
+	b, err := k8syaml.Marshal(vars)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal environment variables: %w", err)
+	}
+	return indentYaml(string(b), indent), nil
+}
+
+// secretManagerEnv prepares Secret Manager variables of the job. The GKE Secret
+// Manager add-on mounts the secret versions listed in a SecretProviderClass as
+// files, the returned command prefix exports their contents. Returns an empty
+// prefix if the job has no Secret Manager variables.
+func secretManagerEnv(job orchestrator.JobDefinition) (MountInfo, string, string, error) {
+	type secret struct {
+		ResourceName string `json:"resourceName"`
+		Path         string `json:"path"`
+	}
+	secrets := []secret{}
+	exports := []string{}
+	for _, e := range job.Env {
+		if e.SecretVersion == "" {
+			continue
+		}
+		secrets = append(secrets, secret{ResourceName: e.SecretVersion, Path: e.Name})
+		exports = append(exports, fmt.Sprintf("%s=\"$(cat %s)\"", e.Name, path.Join(secretsMountPath, e.Name)))
+	}
+	if len(secrets) == 0 {
+		return MountInfo{}, "", "", nil
+	}
+
+	params, err := k8syaml.Marshal(secrets)
+	if err != nil {
+		return MountInfo{}, "", "", fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	className := job.WorkloadName + "-secrets"
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "secrets-store.csi.x-k8s.io/v1",
+		"kind":       "SecretProviderClass",
+		"metadata":   map[string]interface{}{"name": className},
+		"spec": map[string]interface{}{
+			"provider":   "gke",
+			"parameters": map[string]interface{}{"secrets": string(params)},
+		},
+	})
+	if err != nil {
+		return MountInfo{}, "", "", fmt.Errorf("failed to marshal SecretProviderClass: %w", err)
+	}
+
+	mount := MountInfo{
+		Name:      secretsVolumeName,
+		Source:    className,
+		MountPath: secretsMountPath,
+		Type:      "secretmanager",
+		ReadOnly:  true,
+	}
+	return mount, string(manifest), "export " + strings.Join(exports, " ") + "; ", nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+var testEnv = []orchestrator.EnvVar{
+	{Name: "LOG_LEVEL", Value: "debug: \"all\""},
+	{Name: "API_TOKEN", SecretName: "tokens", SecretKey: "api"},
+	{Name: "DB_PASSWORD", SecretVersion: "projects/p/secrets/db/versions/latest"},
+}
+
+// containerEnv returns env of the first container named `name` in the manifest
+func containerEnv(t *testing.T, manifest string, name string) []interface{} {
+	docs := strings.Split(manifest, "\n---\n")
+	var obj map[string]interface{}
+	if err := k8syaml.Unmarshal([]byte(docs[len(docs)-1]), &obj); err != nil {
+		t.Fatalf("manifest is not valid YAML: %v\n%s", err, manifest)
+	}
+	var found []interface{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if v["name"] == name && v["image"] != nil && found == nil {
+				found, _ = v["env"].([]interface{})
+				if found == nil {
+					found = []interface{}{}
+				}
+				return
+			}
+			for _, c := range v {
+				walk(c)
+			}
+		case []interface{}:
+			for _, c := range v {
+				walk(c)
+			}
+		}
+	}
+	walk(obj)
+	if found == nil {
+		t.Fatalf("container %q not found in manifest\n%s", name, manifest)
+	}
+	return found
+}
+
+func envNames(env []interface{}) []string {
+	res := []string{}
+	for _, e := range env {
+		res = append(res, e.(map[string]interface{})["name"].(string))
+	}
+	return res
+}
+
+func TestGenerateGKEManifest_Env(t *testing.T) {
+	setupMockMachineConfig(t)
+
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe nvidia-l4 --zone=us-central1-a --format=json": {
+			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1}]}`},
+		},
+	}
+	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
+	orc.clusterDesc.NodePools = []gkeJobNodePool{
+		{Config: gkeNodePoolConfig{MachineType: "nvidia-l4"}},
+	}
+	opts := ManifestOptions{
+		WorkloadName:    "test-workload",
+		FullImageName:   "test-image:latest",
+		CommandToRun:    "python app.py",
+		ComputeType:     "nvidia-l4",
+		MachineType:     "nvidia-l4",
+		ClusterLocation: "us-central1-a",
+		Env:             testEnv,
+		Verbose:         true,
+	}
+
+	manifest, err := orc.GenerateGKEManifest(opts, JobProfile{})
+	if err != nil {
+		t.Fatalf("GenerateGKEManifest failed: %v", err)
+	}
+	env := containerEnv(t, manifest, "workload-container")
+	if got := strings.Join(envNames(env), ","); got != "NCCL_DEBUG,LOG_LEVEL,API_TOKEN" {
+		t.Errorf("unexpected env %q", got)
+	}
+	if v := env[1].(map[string]interface{})["value"]; v != "debug: \"all\"" {
+		t.Errorf("value is not preserved, got %q", v)
+	}
+	if !strings.Contains(manifest, "secretKeyRef") || !strings.Contains(manifest, "name: tokens") {
+		t.Errorf("manifest missing secret reference.\nManifest: %s", manifest)
+	}
+
+	opts.Env = []orchestrator.EnvVar{{Name: "NCCL_DEBUG", Value: "WARN"}}
+	if _, err := orc.GenerateGKEManifest(opts, JobProfile{}); err == nil || !strings.Contains(err.Error(), "NCCL_DEBUG") {
+		t.Errorf("expected collision error, got %v", err)
+	}
+	opts.Verbose = false
+	if _, err := orc.GenerateGKEManifest(opts, JobProfile{}); err != nil {
+		t.Errorf("NCCL_DEBUG is not set by gcluster without --verbose, got %v", err)
+	}
+}
+
+func TestGeneratePathwaysManifest_Env(t *testing.T) {
+	setupMockMachineConfig(t)
+	job := orchestrator.JobDefinition{
+		WorkloadName:    "pathways-test",
+		CommandToRun:    "echo hello",
+		NumSlices:       2,
+		ClusterLocation: "us-central1",
+		ComputeType:     "n2-standard-2",
+		Env:             testEnv[:2],
+		Pathways: orchestrator.PathwaysJobDefinition{
+			GCSLocation:  "gs://my-bucket",
+			HeadNodePool: "pathways-np",
+		},
+	}
+
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe n2-standard-2 --zone=us-central1 --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
+	}
+	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
+	orc.projectID = "mock-project"
+	orc.clusterDesc.NodePools = []gkeJobNodePool{
+		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
+	}
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	if err != nil {
+		t.Fatalf("resolveHardwareRequirements failed: %v", err)
+	}
+	manifest, err := orc.GeneratePathwaysManifest(job, "test-image:latest", profile, isDynamicSlicing, isStaticSlicing)
+	if err != nil {
+		t.Fatalf("GeneratePathwaysManifest failed: %v", err)
+	}
+	if got := strings.Join(envNames(containerEnv(t, manifest, "workload-container")), ","); got != "LOG_LEVEL,API_TOKEN" {
+		t.Errorf("unexpected workload env %q", got)
+	}
+	if got := envNames(containerEnv(t, manifest, "pathways-worker")); len(got) < 2 || strings.Join(got[len(got)-2:], ",") != "LOG_LEVEL,API_TOKEN" {
+		t.Errorf("unexpected worker env %q", got)
+	}
+
+	job.Env = []orchestrator.EnvVar{{Name: "PATHWAYS_HEAD", Value: "x"}}
+	if _, err := orc.GeneratePathwaysManifest(job, "test-image:latest", profile, isDynamicSlicing, isStaticSlicing); err == nil {
+		t.Error("expected collision error")
+	}
+}
+
+func TestSecretManagerEnv(t *testing.T) {
+	mount, manifest, exports, err := secretManagerEnv(orchestrator.JobDefinition{WorkloadName: "w", Env: testEnv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exports != `export DB_PASSWORD="$(cat /var/run/gcluster/secrets/DB_PASSWORD)"; ` {
+		t.Errorf("unexpected exports %q", exports)
+	}
+	if mount.Source != "w-secrets" || mount.MountPath != secretsMountPath || !mount.ReadOnly {
+		t.Errorf("unexpected mount %#v", mount)
+	}
+	var spc struct {
+		Kind string
+		Spec struct {
+			Provider   string
+			Parameters map[string]string
+		}
+	}
+	if err := k8syaml.Unmarshal([]byte(manifest), &spc); err != nil {
+		t.Fatal(err)
+	}
+	if spc.Kind != "SecretProviderClass" || spc.Spec.Provider != "gke" ||
+		!strings.Contains(spc.Spec.Parameters["secrets"], "resourceName: projects/p/secrets/db/versions/latest") {
+		t.Errorf("unexpected SecretProviderClass\n%s", manifest)
+	}
+	csi := buildVolumeSpec(mount)["csi"].(map[string]interface{})
+	if csi["driver"] != secretsCSIDriver {
+		t.Errorf("unexpected volume %#v", csi)
+	}
+
+	_, _, exports, _ = secretManagerEnv(orchestrator.JobDefinition{WorkloadName: "w", Env: testEnv[:2]})
+	if exports != "" {
+		t.Errorf("expected no exports, got %q", exports)
+	}
+}
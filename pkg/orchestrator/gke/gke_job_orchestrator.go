@@ -61,6 +61,7 @@ func NewGKEOrchestrator() *GKEOrchestrator {
 		topologyCache:            make(map[string]string),
 		dynamicSlicingCache:      make(map[string]bool),
 		staticSlicingCache:       make(map[string]bool),
+		dependencyPollInterval:   defaultDependencyPollInterval,
 	}
 }
 
@@ -116,6 +117,15 @@ func (g *GKEOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
 		return err
 	}
 
+	// The CLI doesn't wait for dependencies, the job is held until it's released
+	pendingDependencies := len(job.After) > 0
+	if job.DryRunManifest == "" {
+		if pendingDependencies, err = g.checkDependencies(job.After); err != nil {
+			return err
+		}
+	}
+	job.Hold = job.Hold || pendingDependencies
+
 	fullImageName, err := g.BuildContainerImage(job)
 	if err != nil {
 		return err
@@ -129,7 +139,14 @@ func (g *GKEOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
 		g.printConsoleLinks(job)
 	}
 
-	if job.AwaitJobCompletion && job.DryRunManifest == "" {
+	if pendingDependencies && job.DryRunManifest == "" {
+		logging.Info("Job '%s' is held until its dependencies finish (%s). It stays held until you run 'gcluster job release %s', which waits for them and releases it.",
+			job.WorkloadName, formatDependencies(job.After), job.WorkloadName)
+	}
+
+	if job.AwaitJobCompletion && job.DryRunManifest == "" && job.Hold {
+		logging.Warn("Job '%s' is held, not waiting for its completion.", job.WorkloadName)
+	} else if job.AwaitJobCompletion && job.DryRunManifest == "" {
 		err = g.awaitJobCompletion(job.WorkloadName, job.ClusterName, job.ClusterLocation, job.ProjectID, job.Timeout)
 		if err != nil {
 			return err
@@ -344,10 +361,17 @@ func (g *GKEOrchestrator) GeneratePathwaysManifest(job orchestrator.JobDefinitio
 		logging.Warn("Warning: failed to calculate resource limits for Pathways job: %v", err)
 	}
 
+	if err := checkEnvCollisions(opts.Env, pathwaysBuiltinEnv(opts.Verbose)); err != nil {
+		return "", err
+	}
+
 	cmdSlice := []string{"/bin/bash", "-c", opts.CommandToRun}
 	isTPU := tpuLimit != ""
 	isGPU := gpuLimit != ""
 	data := g.prepareJobSetTemplateData(opts, cmdSlice, resStr, isTPU, isGPU)
+	if data.EnvYAML, err = buildEnvString(opts.Env, 14); err != nil {
+		return "", err
+	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -1278,6 +1302,8 @@ func (g *GKEOrchestrator) prepareJobSetTemplateData(opts ManifestOptions, comman
 		Verbose:                       opts.Verbose,
 		IsTPU:                         isTPU,
 		IsGPU:                         isGPU,
+		Held:                          opts.Held,
+		After:                         opts.After,
 	}
 }
 
@@ -1401,15 +1427,25 @@ func (g *GKEOrchestrator) getPodAggregatedStatus(client dynamic.Interface, ns st
 		return "", err
 	}
 
-	if len(podList.Items) == 0 {
-		return "Pending", nil
+	pods := make([]map[string]interface{}, 0, len(podList.Items))
+	for _, p := range podList.Items {
+		pods = append(pods, p.Object)
+	}
+	return aggregatePodStatus(pods), nil
+}
+
+// aggregatePodStatus is the status of a running JobSet from its pods: Pending
+// until one of them leaves the Pending phase
+func aggregatePodStatus(pods []map[string]interface{}) string {
+	if len(pods) == 0 {
+		return "Pending"
 	}
 
 	allPending := true
 	atLeastOneRunning := false
 
-	for _, p := range podList.Items {
-		podStatus, ok := p.Object["status"].(map[string]interface{})
+	for _, p := range pods {
+		podStatus, ok := p["status"].(map[string]interface{})
 		if !ok {
 			continue
 		}
@@ -1425,12 +1461,12 @@ func (g *GKEOrchestrator) getPodAggregatedStatus(client dynamic.Interface, ns st
 	}
 
 	if allPending {
-		return "Pending", nil
+		return "Pending"
 	}
 	if atLeastOneRunning {
-		return "Running", nil
+		return "Running"
 	}
-	return "Running", nil
+	return "Running"
 }
 
 func (g *GKEOrchestrator) getJobStatus(name string) (string, error) {
@@ -1890,6 +1926,9 @@ func (d *DefaultKubeClient) ListJobSets(labelSelector string) ([]orchestrator.Jo
 		creationTime := creationParams.Format(time.RFC3339)
 
 		statusStr, completionTime := parseJobStatus(item.Object)
+		if item.GetLabels()[heldLabel] == "true" && statusStr == "Suspended" {
+			statusStr = "Held"
+		}
 
 		jobs = append(jobs, orchestrator.JobStatus{
 			Name:           name,
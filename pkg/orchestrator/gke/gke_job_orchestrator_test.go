@@ -131,6 +131,30 @@ func (m *MockKubeClient) ListJobSets(labelSelector string) ([]orchestrator.JobSt
 	return []orchestrator.JobStatus{}, m.Err
 }
 
+func (m *MockKubeClient) ReleaseJobSet(namespace string, name string) error {
+	return m.Err
+}
+
+func (m *MockKubeClient) GetJobSet(namespace string, name string) (map[string]interface{}, error) {
+	return nil, m.Err
+}
+
+func (m *MockKubeClient) ListPods(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	return nil, m.Err
+}
+
+func (m *MockKubeClient) ListKueueWorkloads(namespace string, labelSelector string) ([]map[string]interface{}, error) {
+	return nil, m.Err
+}
+
+func (m *MockKubeClient) ListEvents(namespace string) ([]map[string]interface{}, error) {
+	return nil, m.Err
+}
+
+func (m *MockKubeClient) GetNodeLabels(name string) (map[string]string, error) {
+	return nil, m.Err
+}
+
 func TestGenerateGKEManifest_Accelerators(t *testing.T) {
 	setupMockMachineConfig(t)
 
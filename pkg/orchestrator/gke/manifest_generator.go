@@ -52,7 +52,13 @@ func (g *GKEOrchestrator) GenerateGKEManifest(opts ManifestOptions, profile JobP
 
 	isTPU := tpuLimit != ""
 	isGPU := gpuLimit != ""
+	if err := checkEnvCollisions(opts.Env, jobSetBuiltinEnv(opts.Verbose, isTPU, isGPU)); err != nil {
+		return "", err
+	}
 	data := g.prepareJobSetTemplateData(opts, cmdSlice, resourcesString, isTPU, isGPU)
+	if data.EnvYAML, err = buildEnvString(opts.Env, 16); err != nil {
+		return "", err
+	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -148,7 +154,10 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 		AwaitJobCompletion:            job.AwaitJobCompletion,
 		PriorityClassName:             job.PriorityClassName,
 		Topology:                      schedOpts.Topology,
+		Env:                           job.Env,
 		Verbose:                       job.Verbose,
+		Held:                          job.Hold,
+		After:                         formatDependencies(job.After),
 	}
 
 	if err := g.fillManifestStrings(&opts, schedOpts, job, isDynamicSlicing, isStaticSlicing, profile.IsCPUMachine); err != nil {
@@ -162,6 +171,19 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 	}
 	opts.AdditionalManifests = manifests
 
+	secretsMount, secretsManifest, exports, err := secretManagerEnv(job)
+	if err != nil {
+		return ManifestOptions{}, err
+	}
+	if exports != "" {
+		if job.IsPathwaysJob && job.Pathways.Headless {
+			return ManifestOptions{}, fmt.Errorf("Secret Manager environment variables are exported by the workload command, which --pathways-headless jobs don't run")
+		}
+		opts.CommandToRun = exports + opts.CommandToRun
+		mountInfos = append(mountInfos, secretsMount)
+		opts.AdditionalManifests = append(opts.AdditionalManifests, secretsManifest)
+	}
+
 	sm.AddVolumeOptions(&opts, mountInfos)
 
 	_, err = g.resolveResourcesAndGates(&opts, profile.IsCPUMachine, profile.CapacityCount, job)
@@ -347,6 +347,14 @@ func buildVolumeSpec(v MountInfo) map[string]interface{} {
 		spec["persistentVolumeClaim"] = map[string]interface{}{
 			"claimName": v.Source,
 		}
+	case "secretmanager":
+		spec["csi"] = map[string]interface{}{
+			"driver":   secretsCSIDriver,
+			"readOnly": true,
+			"volumeAttributes": map[string]interface{}{
+				"secretProviderClass": v.Source,
+			},
+		}
 	}
 	return spec
 }
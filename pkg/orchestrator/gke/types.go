@@ -22,6 +22,7 @@ import (
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/filestore/apiv1/filestorepb"
 	compute "google.golang.org/api/compute/v1"
@@ -46,6 +47,13 @@ type KubeClient interface {
 	ListWorkloads(namespace string, workloadName string) ([]string, error)
 	DeleteJobSet(namespace string, name string) error
 	ListJobSets(labelSelector string) ([]orchestrator.JobStatus, error)
+	// ReleaseJobSet queues a JobSet submitted held in its Kueue queue
+	ReleaseJobSet(namespace string, name string) error
+	GetJobSet(namespace string, name string) (map[string]interface{}, error)
+	ListPods(namespace string, labelSelector string) ([]map[string]interface{}, error)
+	ListKueueWorkloads(namespace string, labelSelector string) ([]map[string]interface{}, error)
+	ListEvents(namespace string) ([]map[string]interface{}, error)
+	GetNodeLabels(name string) (map[string]string, error)
 }
 
 type MachineTypeClient interface {
@@ -84,6 +92,7 @@ type GKEOrchestrator struct {
 	dynamicSlicingCache         map[string]bool
 	staticSlicingCache          map[string]bool
 	topologyCache               map[string]string
+	dependencyPollInterval      time.Duration
 }
 
 // Types for GetClusterInfo unmarshaling
@@ -176,8 +185,11 @@ type ManifestOptions struct {
 	IsStaticSlicing               bool
 	IsCPUMachine                  bool
 	Pathways                      orchestrator.PathwaysJobDefinition
+	Env                           []orchestrator.EnvVar
 	Verbose                       bool
 	AdditionalManifests           []string
+	Held                          bool
+	After                         string
 }
 
 // StorageManager handles parsing and validation of storage mounts.
@@ -333,7 +345,10 @@ type jobSetTemplateData struct {
 	HostNetworkEnabled            bool
 	Pathways                      orchestrator.PathwaysJobDefinition
 	ExclusiveTopologyAnnotation   string
+	EnvYAML                       string
 	Verbose                       bool
 	IsTPU                         bool
 	IsGPU                         bool
+	Held                          bool
+	After                         string
 }
@@ -47,6 +47,52 @@ type VolumeDefinition struct {
 	ReadOnly  bool
 }
 
+// EnvVar is an environment variable of the workload container, set to either
+// a literal Value or the value of a secret
+type EnvVar struct {
+	Name  string
+	Value string
+	// Kubernetes Secret name and key, empty for literal values
+	SecretName string
+	SecretKey  string
+	// Secret Manager secret version, e.g. projects/p/secrets/s/versions/latest
+	SecretVersion string
+}
+
+// IsSecret returns true if the variable is set from a secret
+func (e EnvVar) IsSecret() bool {
+	return e.SecretName != "" || e.SecretVersion != ""
+}
+
+const (
+	// AfterSucceeded starts a job once its dependency succeeded
+	AfterSucceeded = "succeeded"
+	// AfterAny starts a job once its dependency finished, successfully or not
+	AfterAny = "any"
+)
+
+// JobDependency makes a job wait for another job to finish
+type JobDependency struct {
+	Name      string
+	Condition string // AfterSucceeded or AfterAny
+}
+
+// Check reports whether a dependency in the given job status lets the job
+// start, or can no longer do so
+func (d JobDependency) Check(status string) (satisfied bool, failed bool) {
+	switch status {
+	case "Succeeded":
+		return true, false
+	case "Failed":
+		return d.Condition == AfterAny, d.Condition != AfterAny
+	}
+	return false, false
+}
+
+func (d JobDependency) String() string {
+	return d.Name + ":" + d.Condition
+}
+
 type JobDefinition struct {
 	ImageName       string
 	BaseImage       string
@@ -90,6 +136,12 @@ type JobDefinition struct {
 	Pathways      PathwaysJobDefinition // Embedded struct for Pathways-specific args
 
 	RawMounts []string
+	Env       []EnvVar
+
+	// Jobs that have to finish before this job starts
+	After []JobDependency
+	// Hold submits the job without starting it, see JobOrchestrator.ReleaseJob
+	Hold bool
 
 	Verbose bool
 }
@@ -123,11 +175,126 @@ type LogsOptions struct {
 	Follow          bool
 }
 
+type ReleaseOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+}
+
+type DescribeOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+}
+
+// JobDescription is the detailed state of a job, fields an orchestrator
+// doesn't know about are left empty
+type JobDescription struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    string `json:"status"`
+	// Reason explains the status, e.g. why the job is not admitted yet
+	Reason         string                     `json:"reason,omitempty"`
+	CreationTime   string                     `json:"creationTime,omitempty"`
+	CompletionTime string                     `json:"completionTime,omitempty"`
+	Queue          string                     `json:"queue,omitempty"`
+	Priority       string                     `json:"priority,omitempty"`
+	Restarts       int                        `json:"restarts"`
+	MaxRestarts    int                        `json:"maxRestarts"`
+	Nodes          string                     `json:"nodes,omitempty"`
+	ReplicatedJobs []ReplicatedJobDescription `json:"replicatedJobs,omitempty"`
+	Admission      *AdmissionDescription      `json:"admission,omitempty"`
+	Events         []JobEvent                 `json:"events,omitempty"`
+}
+
+// ReplicatedJobDescription is a group of identical jobs of a job and their pods
+type ReplicatedJobDescription struct {
+	Name         string            `json:"name"`
+	Replicas     int               `json:"replicas"`
+	Parallelism  int               `json:"parallelism"`
+	Image        string            `json:"image,omitempty"`
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// number of pods per phase
+	Pods map[string]int `json:"pods,omitempty"`
+	// number of containers per reason they are waiting for, e.g. ImagePullBackOff
+	WaitingReasons    map[string]int `json:"waitingReasons,omitempty"`
+	ContainerRestarts int            `json:"containerRestarts"`
+	NodePools         []string       `json:"nodePools,omitempty"`
+}
+
+// AdmissionDescription is the state of the Kueue workload of a job
+type AdmissionDescription struct {
+	Workload     string `json:"workload"`
+	Status       string `json:"status"`
+	ClusterQueue string `json:"clusterQueue,omitempty"`
+	// flavors assigned to each pod set
+	Flavors         map[string]string `json:"flavors,omitempty"`
+	Conditions      []JobCondition    `json:"conditions,omitempty"`
+	AdmissionChecks []AdmissionCheck  `json:"admissionChecks,omitempty"`
+}
+
+type JobCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+type AdmissionCheck struct {
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Message string `json:"message,omitempty"`
+}
+
+type JobEvent struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Object  string `json:"object"`
+	Message string `json:"message"`
+	Count   int    `json:"count,omitempty"`
+}
+
+// Kinds of JobBlocker
+const (
+	BlockerHeld         = "held"
+	BlockerQuota        = "quota"
+	BlockerNoFlavor     = "no-matching-flavor"
+	BlockerTopology     = "topology"
+	BlockerNodePoolMax  = "node-pool-at-max"
+	BlockerReservation  = "reservation-exhausted"
+	BlockerCapacity     = "capacity-unavailable"
+	BlockerImagePull    = "image-pull"
+	BlockerUnclassified = "unclassified"
+)
+
+// JobDiagnosis explains why a job is not running
+type JobDiagnosis struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Blockers keep the job from being scheduled, the most specific first.
+	// Empty if the job is not pending or no reason was found
+	Blockers []JobBlocker `json:"blockers,omitempty"`
+}
+
+// JobBlocker is a reason a job can't be scheduled and the ways to resolve it
+type JobBlocker struct {
+	Kind    string   `json:"kind"`
+	Message string   `json:"message"`
+	Fixes   []string `json:"fixes,omitempty"`
+}
+
 type JobOrchestrator interface {
 	SubmitJob(job JobDefinition) error
 	ListJobs(opts ListOptions) ([]JobStatus, error)
 	CancelJob(name string, opts CancelOptions) error
 	GetJobLogs(name string, opts LogsOptions) (string, error)
+	// ReleaseJob starts a job submitted with Hold
+	ReleaseJob(name string, opts ReleaseOptions) error
+	DescribeJob(name string, opts DescribeOptions) (*JobDescription, error)
+	// DiagnoseJob explains why a pending job is not scheduled
+	DiagnoseJob(name string, opts DescribeOptions) (*JobDiagnosis, error)
 }
 
 type ClusterStatus struct {
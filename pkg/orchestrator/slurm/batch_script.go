@@ -0,0 +1,194 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*
+var templatesFS embed.FS
+
+// GenerateBatchScript renders the sbatch script for a job definition.
+//
+// The JobDefinition fields map onto Slurm as follows:
+//   - ComputeType selects the partition.
+//   - NumSlices * NodesPerSlice is the node count.
+//   - NodeConstraint is converted to a --constraint feature expression.
+//   - Timeout becomes the job time limit (--time).
+//   - PriorityClassName selects the QOS.
+//   - RestartOnExitCodes requeues the job, up to MaxRestarts times, when the
+//     command exits with one of the listed codes.
+//   - Env is exported before srun, which passes it on to the tasks.
+//   - Hold submits the job held (--hold).
+//
+// After is left out, since Slurm dependencies refer to job IDs, which are
+// resolved when the job is submitted.
+func GenerateBatchScript(job orchestrator.JobDefinition) (string, error) {
+	return generateBatchScript(job, "")
+}
+
+// generateBatchScript renders the sbatch script with a --dependency value.
+func generateBatchScript(job orchestrator.JobDefinition, dependency string) (string, error) {
+	timeLimit, err := formatTimeLimit(job.Timeout)
+	if err != nil {
+		return "", err
+	}
+
+	runCommand, err := buildRunCommand(job)
+	if err != nil {
+		return "", err
+	}
+
+	data := batchScriptData{
+		JobName:     job.WorkloadName,
+		OutputPath:  path.Join(jobsDirName, job.WorkloadName, "slurm-%j.out"),
+		Nodes:       nodeCount(job),
+		Partition:   job.ComputeType,
+		Constraint:  buildConstraint(job.NodeConstraint),
+		TimeLimit:   timeLimit,
+		QOS:         job.PriorityClassName,
+		Dependency:  dependency,
+		Hold:        job.Hold,
+		EnvExports:  buildEnvExports(job.Env),
+		RunCommand:  runCommand,
+		MaxRestarts: job.MaxRestarts,
+	}
+	if len(job.RestartOnExitCodes) > 0 {
+		codes := make([]string, len(job.RestartOnExitCodes))
+		for i, c := range job.RestartOnExitCodes {
+			codes[i] = strconv.Itoa(c)
+		}
+		data.Requeue = true
+		data.RestartOnExitCodes = strings.Join(codes, " ")
+	}
+
+	tmpl, err := template.ParseFS(templatesFS, "templates/sbatch.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse sbatch template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute sbatch template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func nodeCount(job orchestrator.JobDefinition) int {
+	slices := max(job.NumSlices, 1)
+	nodes := max(job.NodesPerSlice, 1)
+	return slices * nodes
+}
+
+// buildConstraint converts node constraint pairs into a Slurm feature
+// expression. A pair whose value is empty or "true" requires the feature named
+// by its key; otherwise the value is used as the feature name. All features
+// are required (joined with '&'), in key order so the output is stable.
+func buildConstraint(constraints map[string]string) string {
+	keys := make([]string, 0, len(constraints))
+	for k := range constraints {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	features := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := constraints[k]
+		if v == "" || strings.EqualFold(v, "true") {
+			features = append(features, k)
+		} else {
+			features = append(features, v)
+		}
+	}
+	return strings.Join(features, "&")
+}
+
+// formatTimeLimit converts a gcluster timeout (e.g. "90m" or "3600") into the
+// Slurm D-HH:MM:SS format. Non-positive timeouts mean no limit.
+func formatTimeLimit(timeout string) (string, error) {
+	d, err := parseTimeout(timeout)
+	if err != nil {
+		return "", err
+	}
+	if d <= 0 {
+		return "", nil
+	}
+
+	total := int(d.Round(time.Second).Seconds())
+	days := total / 86400
+	hours := (total % 86400) / 3600
+	minutes := (total % 3600) / 60
+	seconds := total % 60
+	return fmt.Sprintf("%d-%02d:%02d:%02d", days, hours, minutes, seconds), nil
+}
+
+// parseTimeout parses a --timeout value, a duration or a number of seconds.
+// Empty and negative values mean no timeout and are returned as 0.
+func parseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(timeout)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid timeout %q: expected a duration (e.g. 1h, 30m) or a number of seconds", timeout)
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+	return max(d, 0), nil
+}
+
+// buildRunCommand returns the srun line that launches the user command on
+// every allocated node. When an image is given, it is run with the pyxis
+// container plugin and mounts are passed through as container mounts.
+func buildRunCommand(job orchestrator.JobDefinition) (string, error) {
+	args := []string{"srun"}
+	if job.ImageName != "" {
+		args = append(args, "--container-image="+shellQuote(job.ImageName))
+		if len(job.RawMounts) > 0 {
+			args = append(args, "--container-mounts="+shellQuote(strings.Join(job.RawMounts, ",")))
+		}
+	} else if len(job.RawMounts) > 0 {
+		return "", fmt.Errorf("mounts on Slurm are container mounts and require --image")
+	}
+	args = append(args, "/bin/bash", "-c", shellQuote(job.CommandToRun))
+	return strings.Join(args, " "), nil
+}
+
+// buildEnvExports returns export lines of the job environment, secrets are
+// rejected by validateJobDefinition.
+func buildEnvExports(env []orchestrator.EnvVar) string {
+	var sb strings.Builder
+	for _, e := range env {
+		sb.WriteString(fmt.Sprintf("export %s=%s\n", e.Name, shellQuote(e.Value)))
+	}
+	return sb.String()
+}
+
+// shellQuote wraps s in single quotes so that a POSIX shell passes it through verbatim.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBatchScript(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		WorkloadName:       "train",
+		CommandToRun:       "python train.py --name 'a b'",
+		ComputeType:        "a3",
+		NumSlices:          2,
+		NodesPerSlice:      4,
+		NodeConstraint:     map[string]string{"gpu": "h100", "ib": "true"},
+		Timeout:            "90m",
+		PriorityClassName:  "high",
+		RestartOnExitCodes: []int{42, 143},
+		MaxRestarts:        3,
+	}
+
+	script, err := GenerateBatchScript(job)
+	if err != nil {
+		t.Fatalf("GenerateBatchScript() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"#!/bin/bash\n",
+		"#SBATCH --job-name=train\n",
+		"#SBATCH --output=.gcluster/slurm/train/slurm-%j.out\n",
+		"#SBATCH --nodes=8\n",
+		"#SBATCH --partition=a3\n",
+		"#SBATCH --constraint=h100&ib\n",
+		"#SBATCH --time=0-01:30:00\n",
+		"#SBATCH --qos=high\n",
+		"#SBATCH --requeue\n",
+		`srun /bin/bash -c 'python train.py --name '\''a b'\'''`,
+		`case " 42 143 " in`,
+		`-lt 3 ]`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestGenerateBatchScript_Minimal(t *testing.T) {
+	script, err := GenerateBatchScript(orchestrator.JobDefinition{
+		WorkloadName: "hello",
+		CommandToRun: "hostname",
+		Timeout:      "-1s",
+	})
+	if err != nil {
+		t.Fatalf("GenerateBatchScript() error = %v", err)
+	}
+
+	if !strings.Contains(script, "#SBATCH --nodes=1\n") {
+		t.Errorf("expected a single node allocation:\n%s", script)
+	}
+	for _, unwanted := range []string{"--partition", "--constraint", "--time", "--qos", "--requeue", "scontrol requeue"} {
+		if strings.Contains(script, unwanted) {
+			t.Errorf("script unexpectedly contains %q:\n%s", unwanted, script)
+		}
+	}
+}
+
+func TestGenerateBatchScript_Container(t *testing.T) {
+	script, err := GenerateBatchScript(orchestrator.JobDefinition{
+		WorkloadName: "ctr",
+		CommandToRun: "nvidia-smi",
+		ImageName:    "nvcr.io/nvidia/pytorch:24.01-py3",
+		RawMounts:    []string{"/home:/home", "/data:/data:ro"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateBatchScript() error = %v", err)
+	}
+	want := `srun --container-image='nvcr.io/nvidia/pytorch:24.01-py3' --container-mounts='/home:/home,/data:/data:ro' /bin/bash -c 'nvidia-smi'`
+	if !strings.Contains(script, want) {
+		t.Errorf("script missing %q:\n%s", want, script)
+	}
+
+	_, err = GenerateBatchScript(orchestrator.JobDefinition{
+		WorkloadName: "ctr",
+		CommandToRun: "ls",
+		RawMounts:    []string{"/home:/home"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "require --image") {
+		t.Errorf("expected mounts without image to fail, got %v", err)
+	}
+}
+
+func TestGenerateBatchScript_Env(t *testing.T) {
+	script, err := GenerateBatchScript(orchestrator.JobDefinition{
+		WorkloadName: "env",
+		CommandToRun: "env",
+		Env:          []orchestrator.EnvVar{{Name: "GREETING", Value: "it's me"}, {Name: "EMPTY"}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateBatchScript() error = %v", err)
+	}
+	want := "export GREETING='it'\\''s me'\nexport EMPTY=''\nsrun /bin/bash -c 'env'\n"
+	if !strings.Contains(script, want) {
+		t.Errorf("script missing %q:\n%s", want, script)
+	}
+}
+
+func TestFormatTimeLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"-1s", "", false},
+		{"0", "", false},
+		{"10m", "0-00:10:00", false},
+		{"3600", "0-01:00:00", false},
+		{"50h30m15s", "2-02:30:15", false},
+		{"soon", "", true},
+	}
+	for _, tc := range tests {
+		got, err := formatTimeLimit(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("formatTimeLimit(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("formatTimeLimit(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestBuildConstraint(t *testing.T) {
+	if got := buildConstraint(nil); got != "" {
+		t.Errorf("buildConstraint(nil) = %q, want empty", got)
+	}
+	got := buildConstraint(map[string]string{"zone": "us-central1-a", "a100": "", "spot": "TRUE"})
+	if want := "a100&spot&us-central1-a"; got != want {
+		t.Errorf("buildConstraint() = %q, want %q", got, want)
+	}
+}
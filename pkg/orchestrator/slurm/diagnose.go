@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"sort"
+	"strings"
+)
+
+// DiagnoseJob translates the reason Slurm gives for a pending job into a
+// blocker with suggested fixes.
+func (s *SlurmOrchestrator) DiagnoseJob(name string, opts orchestrator.DescribeOptions) (*orchestrator.JobDiagnosis, error) {
+	s.setTarget(opts.ClusterLocation, opts.ProjectID)
+
+	ids, err := s.findActiveJobIDs(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		d, err := s.DescribeJob(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &orchestrator.JobDiagnosis{Name: name, Status: d.Status}, nil
+	}
+
+	sort.Slice(ids, func(a, b int) bool { return compareJobIDs(ids[a], ids[b]) })
+	f, err := s.showJob(ids[len(ids)-1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe job %s: %w", name, err)
+	}
+	diag := &orchestrator.JobDiagnosis{Name: name, Status: jobStatusFromState(normalizeState(f["JobState"]))}
+	if diag.Status != "Pending" {
+		return diag, nil
+	}
+	if b, ok := pendingReasonBlocker(name, f); ok {
+		diag.Blockers = []orchestrator.JobBlocker{b}
+	}
+	return diag, nil
+}
+
+// pendingReasonBlocker explains the Reason field of `scontrol show job`, see
+// https://slurm.schedmd.com/job_reason_codes.html
+func pendingReasonBlocker(name string, f map[string]string) (orchestrator.JobBlocker, bool) {
+	reason := normalizeReason(f["Reason"])
+	partition, qos, nodes := f["Partition"], f["QOS"], f["NumNodes"]
+	switch {
+	case reason == "":
+		return orchestrator.JobBlocker{}, false
+	case reason == "JobHeldUser" || reason == "JobHeldAdmin":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerHeld,
+			Message: "The job is held and won't start until it is released.",
+			Fixes:   []string{fmt.Sprintf("Release it with `gcluster job release %s --orchestrator slurm`.", name)},
+		}, true
+	case reason == "Dependency":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerHeld,
+			Message: fmt.Sprintf("The job waits for its dependencies to finish (%s).", normalizeReason(f["Dependency"])),
+			Fixes:   []string{"Check the dependencies with `gcluster job list --orchestrator slurm`."},
+		}, true
+	case reason == "DependencyNeverSatisfied":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerHeld,
+			Message: fmt.Sprintf("A dependency of the job failed, so it can never start (%s).", normalizeReason(f["Dependency"])),
+			Fixes:   []string{fmt.Sprintf("Cancel it with `gcluster job cancel %s --orchestrator slurm` and submit it again once the dependencies succeed.", name)},
+		}, true
+	case strings.HasPrefix(reason, "QOS") || strings.HasPrefix(reason, "Assoc"):
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerQuota,
+			Message: fmt.Sprintf("The job would exceed a limit of QOS %q or of your account (%s).", qos, reason),
+			Fixes: []string{
+				"Wait for your other jobs to finish, or cancel some of them.",
+				fmt.Sprintf("Inspect the limits with `sacctmgr show qos %s` and `sacctmgr show assoc user=$USER`.", qos),
+				"Submit with another QOS using `--priority`.",
+			},
+		}, true
+	case reason == "Priority":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerQuota,
+			Message: fmt.Sprintf("Jobs with a higher priority are queued ahead of it in partition %q.", partition),
+			Fixes: []string{
+				"Wait for the jobs ahead of it, see `squeue -p " + partition + " --state=PENDING --sort=-p`.",
+				"Submit with a higher priority QOS using `--priority`.",
+			},
+		}, true
+	case reason == "PartitionNodeLimit":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerNodePoolMax,
+			Message: fmt.Sprintf("The job asks for %s nodes, more than partition %q allows.", nodes, partition),
+			Fixes: []string{
+				"Submit with fewer nodes using `--num-nodes` and `--num-slices`.",
+				fmt.Sprintf("Raise the size of the nodesets of partition %q (`node_count_dynamic_max` in the blueprint) and redeploy the cluster.", partition),
+			},
+		}, true
+	case reason == "BadConstraints":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerNoFlavor,
+			Message: fmt.Sprintf("No node of partition %q has the features %q the job asks for.", partition, f["Features"]),
+			Fixes: []string{
+				fmt.Sprintf("List the features of the nodes with `sinfo -p %s -o \"%%N %%f\"`.", partition),
+				"Submit with matching `--node-constraint` or another `--compute-type`.",
+			},
+		}, true
+	case reason == "Reservation":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerReservation,
+			Message: fmt.Sprintf("The job waits for its reservation %q to become available.", f["Reservation"]),
+			Fixes:   []string{"Check the reservation with `scontrol show reservation`."},
+		}, true
+	case reason == "Resources" || reason == "NodeDown" || reason == "PartitionDown" ||
+		reason == "PartitionInactive" || strings.HasPrefix(reason, "ReqNodeNotAvail"):
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerCapacity,
+			Message: fmt.Sprintf("Not enough nodes of partition %q are available for the %s nodes of the job (%s).", partition, nodes, reason),
+			Fixes: []string{
+				fmt.Sprintf("Check the state of the nodes with `sinfo -p %s` and the reasons they are down with `sinfo -R`.", partition),
+				"Wait for running jobs to free up nodes, or for cloud nodes to be created.",
+			},
+		}, true
+	case reason == "PartitionTimeLimit":
+		return orchestrator.JobBlocker{
+			Kind:    orchestrator.BlockerUnclassified,
+			Message: fmt.Sprintf("The time limit of the job exceeds the limit of partition %q.", partition),
+			Fixes:   []string{"Submit with a shorter `--timeout`."},
+		}, true
+	}
+	return orchestrator.JobBlocker{
+		Kind:    orchestrator.BlockerUnclassified,
+		Message: fmt.Sprintf("Slurm reports the job is pending because of %q.", reason),
+		Fixes:   []string{"See https://slurm.schedmd.com/job_reason_codes.html for the meaning of the reason."},
+	}, true
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseJob(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'": {{ExitCode: 0, Stdout: "10\n"}, {ExitCode: 0, Stdout: "11\n"}},
+		"bash -c scontrol show job --oneliner 10": {{ExitCode: 0, Stdout: "JobId=10 JobName=train JobState=PENDING Reason=QOSMaxJobsPerUserLimit " +
+			"Partition=compute NumNodes=2 QOS=normal\n"}},
+		"bash -c scontrol show job --oneliner 11": {{ExitCode: 0, Stdout: "JobId=11 JobName=train JobState=RUNNING Reason=None Partition=compute\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	diag, err := s.DiagnoseJob("train", orchestrator.DescribeOptions{})
+	if err != nil {
+		t.Fatalf("DiagnoseJob() error = %v", err)
+	}
+	if diag.Status != "Pending" || len(diag.Blockers) != 1 {
+		t.Fatalf("DiagnoseJob() = %+v, want one blocker of a pending job", diag)
+	}
+	if b := diag.Blockers[0]; b.Kind != orchestrator.BlockerQuota || !strings.Contains(b.Message, "QOSMaxJobsPerUserLimit") || len(b.Fixes) == 0 {
+		t.Errorf("DiagnoseJob() blocker = %+v", b)
+	}
+
+	if diag, err = s.DiagnoseJob("train", orchestrator.DescribeOptions{}); err != nil {
+		t.Fatalf("DiagnoseJob() error = %v", err)
+	}
+	if diag.Status != "Running" || len(diag.Blockers) != 0 {
+		t.Errorf("DiagnoseJob() = %+v, want a running job without blockers", diag)
+	}
+}
+
+func TestPendingReasonBlocker(t *testing.T) {
+	tests := []struct {
+		reason string
+		kind   string
+	}{
+		{"JobHeldUser", orchestrator.BlockerHeld},
+		{"Dependency", orchestrator.BlockerHeld},
+		{"AssocGrpGRES", orchestrator.BlockerQuota},
+		{"Priority", orchestrator.BlockerQuota},
+		{"PartitionNodeLimit", orchestrator.BlockerNodePoolMax},
+		{"BadConstraints", orchestrator.BlockerNoFlavor},
+		{"Reservation", orchestrator.BlockerReservation},
+		{"Resources", orchestrator.BlockerCapacity},
+		{"ReqNodeNotAvail,_UnavailableNodes:node-1", orchestrator.BlockerCapacity},
+		{"Licenses", orchestrator.BlockerUnclassified},
+	}
+	for _, tc := range tests {
+		b, ok := pendingReasonBlocker("train", map[string]string{"Reason": tc.reason, "Partition": "compute"})
+		if !ok || b.Kind != tc.kind {
+			t.Errorf("pendingReasonBlocker(%q) = %+v, %v, want kind %q", tc.reason, b, ok, tc.kind)
+		}
+	}
+	if _, ok := pendingReasonBlocker("train", map[string]string{"Reason": "None"}); ok {
+		t.Errorf("pendingReasonBlocker(None) found a blocker")
+	}
+}
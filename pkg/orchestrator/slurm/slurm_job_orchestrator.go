@@ -0,0 +1,570 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	squeueFormat = "%i|%j|%T|%V|%e"
+	sacctFormat  = "JobID,JobName,State,Submit,End"
+)
+
+var scontrolFieldRe = regexp.MustCompile(`(\w+)=(\S*)`)
+
+func NewSlurmOrchestrator() *SlurmOrchestrator {
+	return &SlurmOrchestrator{
+		executor:     &DefaultExecutor{},
+		pollInterval: defaultPollInterval,
+	}
+}
+
+func (s *SlurmOrchestrator) SetExecutor(e Executor) {
+	s.executor = e
+}
+
+// SetLoginNode configures the login node VM through which Slurm commands are
+// run. When empty, Slurm commands are run on the local machine.
+func (s *SlurmOrchestrator) SetLoginNode(name string) {
+	s.loginNode = name
+}
+
+// SubmitJob renders a batch script for the job and submits it with sbatch.
+// When DryRunManifest is set, the script is written to that path instead.
+func (s *SlurmOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
+	logging.Info("Starting gcluster job submit workflow...")
+	if err := validateJobDefinition(job); err != nil {
+		return err
+	}
+	s.setTarget(job.ClusterLocation, job.ProjectID)
+
+	dependency := ""
+	if job.DryRunManifest == "" {
+		var err error
+		if dependency, err = s.resolveDependencies(job.After); err != nil {
+			return err
+		}
+	} else if len(job.After) > 0 {
+		logging.Info("Dependencies are resolved to Slurm job IDs on submission and are not part of the saved script.")
+	}
+
+	script, err := generateBatchScript(job, dependency)
+	if err != nil {
+		return err
+	}
+
+	if job.DryRunManifest != "" {
+		logging.Info("Saving Slurm batch script to %s", job.DryRunManifest)
+		if err := os.WriteFile(job.DryRunManifest, []byte(script), 0644); err != nil {
+			return fmt.Errorf("failed to write Slurm batch script to file %s: %w", job.DryRunManifest, err)
+		}
+		logging.Info("Slurm batch script saved successfully.")
+		return nil
+	}
+
+	active, err := s.findActiveJobIDs(job.WorkloadName)
+	if err != nil {
+		return err
+	}
+	if len(active) > 0 {
+		return fmt.Errorf("a job named %q is already queued or running (job IDs: %s); cancel it or choose a different name", job.WorkloadName, strings.Join(active, ", "))
+	}
+
+	jobID, err := s.submitBatchScript(job.WorkloadName, script)
+	if err != nil {
+		return err
+	}
+	logging.Info("Slurm job '%s' submitted with job ID %s.", job.WorkloadName, jobID)
+
+	if job.AwaitJobCompletion {
+		if err := s.awaitJobCompletion(job.WorkloadName, jobID, job.Timeout); err != nil {
+			return err
+		}
+	}
+	logging.Info("gcluster job submit workflow completed.")
+	return nil
+}
+
+// ListJobs returns the current user's Slurm jobs. Queued and running jobs come
+// from squeue; finished jobs are added from sacct when accounting is enabled.
+func (s *SlurmOrchestrator) ListJobs(opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+	logging.Info("Listing jobs in Slurm cluster '%s'...", opts.ClusterName)
+	s.setTarget(opts.ClusterLocation, opts.ProjectID)
+
+	res := s.run(fmt.Sprintf("squeue --me --noheader --format=%s", shellQuote(squeueFormat)))
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to list Slurm jobs with squeue: %s\n%s", res.Stderr, res.Stdout)
+	}
+	jobs := map[string]slurmJob{}
+	for _, j := range parseJobTable(res.Stdout) {
+		jobs[j.ID] = j
+	}
+
+	res = s.run(fmt.Sprintf("sacct --noheader --parsable2 --allocations --starttime=now-7days --format=%s", sacctFormat))
+	if res.ExitCode != 0 {
+		logging.Info("WARNING: Unable to query Slurm accounting; finished jobs will not be listed: %s", strings.TrimSpace(res.Stderr))
+	} else {
+		for _, j := range parseJobTable(res.Stdout) {
+			if _, ok := jobs[j.ID]; !ok {
+				jobs[j.ID] = j
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(jobs))
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return compareJobIDs(ids[a], ids[b]) })
+
+	var filteredJobs []orchestrator.JobStatus
+	for _, id := range ids {
+		status := jobs[id].toJobStatus()
+		if opts.NameContains != "" && !strings.Contains(status.Name, opts.NameContains) {
+			continue
+		}
+		if opts.Status != "" && !strings.EqualFold(status.Status, opts.Status) {
+			continue
+		}
+		filteredJobs = append(filteredJobs, status)
+	}
+	return filteredJobs, nil
+}
+
+// CancelJob cancels every queued or running job of the current user with the given name.
+func (s *SlurmOrchestrator) CancelJob(name string, opts orchestrator.CancelOptions) error {
+	s.setTarget(opts.ClusterLocation, opts.ProjectID)
+
+	ids, err := s.findActiveJobIDs(name)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("job %s not found in the Slurm queue", name)
+	}
+
+	logging.Info("Canceling job '%s' (job IDs: %s) in Slurm cluster '%s'...", name, strings.Join(ids, ", "), opts.ClusterName)
+	res := s.run("scancel " + strings.Join(ids, " "))
+	if res.ExitCode != 0 {
+		return fmt.Errorf("cancel operation failed for %s: %s\n%s", name, res.Stderr, res.Stdout)
+	}
+	logging.Info("Cancel operation on Job '%s' completed successfully.", name)
+	return nil
+}
+
+// ReleaseJob releases the queued jobs of the current user with the given name
+// that were submitted held.
+func (s *SlurmOrchestrator) ReleaseJob(name string, opts orchestrator.ReleaseOptions) error {
+	s.setTarget(opts.ClusterLocation, opts.ProjectID)
+
+	ids, err := s.findActiveJobIDs(name)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("job %s not found in the Slurm queue", name)
+	}
+
+	res := s.run("scontrol release " + strings.Join(ids, " "))
+	if res.ExitCode != 0 {
+		return fmt.Errorf("failed to release job %s: %s\n%s", name, res.Stderr, res.Stdout)
+	}
+	logging.Info("Job '%s' (job IDs: %s) released.", name, strings.Join(ids, ", "))
+	return nil
+}
+
+// DescribeJob describes the most recent run of the named job, from scontrol
+// while it is queued or running and from accounting once it finished.
+func (s *SlurmOrchestrator) DescribeJob(name string, opts orchestrator.DescribeOptions) (*orchestrator.JobDescription, error) {
+	s.setTarget(opts.ClusterLocation, opts.ProjectID)
+
+	ids, err := s.findActiveJobIDs(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		sort.Slice(ids, func(a, b int) bool { return compareJobIDs(ids[a], ids[b]) })
+		f, err := s.showJob(ids[len(ids)-1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe job %s: %w", name, err)
+		}
+		state := normalizeState(f["JobState"])
+		d := &orchestrator.JobDescription{
+			Name:         name,
+			Status:       jobStatusFromState(state),
+			Reason:       normalizeReason(f["Reason"]),
+			CreationTime: normalizeTime(f["SubmitTime"]),
+			Queue:        f["Partition"],
+			Priority:     f["QOS"],
+			Nodes:        normalizeReason(f["NodeList"]),
+		}
+		d.Restarts, _ = strconv.Atoi(f["Restarts"])
+		if isTerminalState(state) {
+			d.CompletionTime = normalizeTime(f["EndTime"])
+		}
+		return d, nil
+	}
+
+	res := s.run(fmt.Sprintf("sacct --noheader --parsable2 --allocations --starttime=now-7days --name=%s --format=State,Partition,QOS,Submit,End,NodeList,Reason", shellQuote(name)))
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to describe job %s: %s\n%s", name, res.Stderr, res.Stdout)
+	}
+	lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+	fields := strings.Split(lines[len(lines)-1], "|")
+	if len(fields) != 7 {
+		return nil, fmt.Errorf("job %s not found in the Slurm queue or accounting", name)
+	}
+	state := normalizeState(fields[0])
+	return &orchestrator.JobDescription{
+		Name:           name,
+		Status:         jobStatusFromState(state),
+		Reason:         normalizeReason(fields[6]),
+		CreationTime:   normalizeTime(fields[3]),
+		CompletionTime: normalizeTime(fields[4]),
+		Queue:          fields[1],
+		Priority:       fields[2],
+		Nodes:          normalizeReason(fields[5]),
+	}, nil
+}
+
+// showJob returns the fields `scontrol show job` prints for a job ID
+func (s *SlurmOrchestrator) showJob(id string) (map[string]string, error) {
+	res := s.run("scontrol show job --oneliner " + id)
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("%s\n%s", res.Stderr, res.Stdout)
+	}
+	f := map[string]string{}
+	for _, m := range scontrolFieldRe.FindAllStringSubmatch(res.Stdout, -1) {
+		f[m[1]] = m[2]
+	}
+	return f, nil
+}
+
+// GetJobLogs returns the output of the most recent run of the named job.
+func (s *SlurmOrchestrator) GetJobLogs(name string, opts orchestrator.LogsOptions) (string, error) {
+	logging.Info("Fetching logs for job '%s' in Slurm cluster '%s'...", name, opts.ClusterName)
+	s.setTarget(opts.ClusterLocation, opts.ProjectID)
+
+	jobDir := shellQuote(path.Join(jobsDirName, name))
+	res := s.run(fmt.Sprintf("cd \"$HOME\" && ls -t %s/slurm-*.out 2>/dev/null | head -n 1", jobDir))
+	logFile := strings.TrimSpace(res.Stdout)
+	if res.ExitCode != 0 || logFile == "" {
+		return "", fmt.Errorf("no logs found for job %s; the job may not have started yet", name)
+	}
+
+	if opts.Follow {
+		logging.Info("Streaming logs for job '%s'...", name)
+		return "", s.stream("cd \"$HOME\" && tail -f " + shellQuote(logFile))
+	}
+
+	res = s.run("cd \"$HOME\" && cat " + shellQuote(logFile))
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("failed to get logs: %s\n%s", res.Stderr, res.Stdout)
+	}
+	if strings.TrimSpace(res.Stdout) == "" {
+		return "Job exists but has not produced any output yet", nil
+	}
+	return res.Stdout, nil
+}
+
+func validateJobDefinition(job orchestrator.JobDefinition) error {
+	if job.IsPathwaysJob {
+		return fmt.Errorf("pathways jobs are not supported on Slurm")
+	}
+	if job.BaseImage != "" || job.BuildContext != "" {
+		return fmt.Errorf("image builds (--base-image, --build-context) are not supported on Slurm; use --image with a pre-built image")
+	}
+	if job.CommandToRun == "" {
+		return fmt.Errorf("a command to run is required")
+	}
+	for _, e := range job.Env {
+		if e.IsSecret() {
+			return fmt.Errorf("secret environment variables (--secret-env) are not supported on Slurm")
+		}
+	}
+	return nil
+}
+
+func (s *SlurmOrchestrator) setTarget(zone, projectID string) {
+	s.zone = zone
+	s.projectID = projectID
+}
+
+// submitBatchScript writes the script next to the job output on the login node
+// and submits it, returning the Slurm job ID.
+func (s *SlurmOrchestrator) submitBatchScript(name, script string) (string, error) {
+	jobDir := path.Join(jobsDirName, name)
+	scriptPath := path.Join(jobDir, "job.sbatch")
+	cmd := fmt.Sprintf("cd \"$HOME\" && mkdir -p %s && cat > %s <<'%s' && sbatch --parsable %s\n%s%s\n",
+		shellQuote(jobDir), shellQuote(scriptPath), heredocDelimiter, shellQuote(scriptPath), script, heredocDelimiter)
+
+	logging.Info("Submitting Slurm batch job...")
+	res := s.run(cmd)
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("failed to submit Slurm job: %s\n%s", res.Stderr, res.Stdout)
+	}
+	// --parsable prints "jobid" or "jobid;cluster"
+	jobID, _, _ := strings.Cut(strings.TrimSpace(res.Stdout), ";")
+	if jobID == "" {
+		return "", fmt.Errorf("sbatch did not return a job ID: %s", res.Stdout)
+	}
+	return jobID, nil
+}
+
+// resolveDependencies converts dependencies into a --dependency value. Queued
+// and running jobs become afterok/afterany dependencies on their job IDs,
+// finished jobs are checked from accounting since Slurm forgets them.
+func (s *SlurmOrchestrator) resolveDependencies(after []orchestrator.JobDependency) (string, error) {
+	var deps []string
+	for _, d := range after {
+		ids, err := s.findActiveJobIDs(d.Name)
+		if err != nil {
+			return "", err
+		}
+		if len(ids) > 0 {
+			kind := "afterok"
+			if d.Condition == orchestrator.AfterAny {
+				kind = "afterany"
+			}
+			deps = append(deps, kind+":"+strings.Join(ids, ":"))
+			continue
+		}
+
+		res := s.run(fmt.Sprintf("sacct --noheader --parsable2 --allocations --starttime=now-7days --name=%s --format=State", shellQuote(d.Name)))
+		if res.ExitCode != 0 {
+			return "", fmt.Errorf("failed to get status of dependency %q: %s\n%s", d.Name, res.Stderr, res.Stdout)
+		}
+		lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+		if lines[0] == "" {
+			return "", fmt.Errorf("dependency %q not found in the Slurm queue or accounting", d.Name)
+		}
+		state := normalizeState(lines[len(lines)-1])
+		status := jobStatusFromState(state)
+		satisfied, failed := d.Check(status)
+		switch {
+		case satisfied:
+		case failed:
+			return "", fmt.Errorf("dependency %q finished with status %q (Slurm state %s) but is required to succeed", d.Name, status, state)
+		case status == "Unknown":
+			return "", fmt.Errorf("dependency %q is in unexpected Slurm state %s", d.Name, state)
+		default:
+			// sacct lists jobs of all users, squeue --me only the own ones
+			return "", fmt.Errorf("dependency %q is %s (Slurm state %s) but not in your queue, only your own jobs can be waited for", d.Name, strings.ToLower(status), state)
+		}
+	}
+	return strings.Join(deps, ","), nil
+}
+
+func (s *SlurmOrchestrator) findActiveJobIDs(name string) ([]string, error) {
+	res := s.run(fmt.Sprintf("squeue --me --noheader --name=%s --format=%%i", shellQuote(name)))
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to query the Slurm queue: %s\n%s", res.Stderr, res.Stdout)
+	}
+	return strings.Fields(res.Stdout), nil
+}
+
+// awaitJobCompletion polls the state of a job until it finishes or the
+// timeout expires. Without a timeout it waits for at most maxAwaitTimeout,
+// the longest `kubectl wait` waits for GKE jobs.
+func (s *SlurmOrchestrator) awaitJobCompletion(name, jobID, timeout string) error {
+	d, err := parseTimeout(timeout)
+	if err != nil {
+		return err
+	}
+	if d == 0 {
+		d = maxAwaitTimeout
+	}
+	deadline := time.Now().Add(d)
+
+	logging.Info("Waiting for job '%s' to complete...", name)
+	for {
+		state, err := s.getJobState(jobID)
+		if err != nil {
+			return err
+		}
+		if isTerminalState(state) {
+			if state != "COMPLETED" {
+				logging.Error("Job '%s' finished with state '%s'.", name, state)
+				return fmt.Errorf("job completed unsuccessfully with status: %s", state)
+			}
+			logging.Info("Job '%s' completed successfully.", name)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			logging.Error("Timed out waiting for job '%s' (job ID %s) to finish, it is still %s.", name, jobID, state)
+			return fmt.Errorf("job timed out")
+		}
+		time.Sleep(s.pollInterval)
+	}
+}
+
+// getJobState returns the Slurm state of a job, preferring squeue and falling
+// back to sacct once the job has left the queue.
+func (s *SlurmOrchestrator) getJobState(jobID string) (string, error) {
+	res := s.run(fmt.Sprintf("squeue --noheader --jobs=%s --format=%%T", jobID))
+	if res.ExitCode == 0 {
+		if state := strings.TrimSpace(res.Stdout); state != "" {
+			return state, nil
+		}
+	}
+
+	res = s.run(fmt.Sprintf("sacct --noheader --parsable2 --allocations --jobs=%s --format=State", jobID))
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("failed to get status of job %s: %s\n%s", jobID, res.Stderr, res.Stdout)
+	}
+	state := normalizeState(strings.TrimSpace(res.Stdout))
+	if state == "" {
+		return "", fmt.Errorf("job %s not found in squeue or sacct", jobID)
+	}
+	return state, nil
+}
+
+// run executes a shell command line on the login node, or locally if no login node is configured.
+func (s *SlurmOrchestrator) run(cmdLine string) shell.CommandResult {
+	name, args := s.command(cmdLine)
+	return s.executor.ExecuteCommand(name, args...)
+}
+
+func (s *SlurmOrchestrator) stream(cmdLine string) error {
+	name, args := s.command(cmdLine)
+	return s.executor.ExecuteCommandStream(name, args...)
+}
+
+func (s *SlurmOrchestrator) command(cmdLine string) (string, []string) {
+	if s.loginNode == "" {
+		return "bash", []string{"-c", cmdLine}
+	}
+	args := []string{"compute", "ssh", s.loginNode, "--tunnel-through-iap", "--quiet"}
+	if s.zone != "" {
+		args = append(args, "--zone="+s.zone)
+	}
+	if s.projectID != "" {
+		args = append(args, "--project="+s.projectID)
+	}
+	return "gcloud", append(args, "--command="+cmdLine)
+}
+
+// parseJobTable parses '|'-separated job records. squeueFormat and sacctFormat
+// select the same columns in the same order, so both outputs share this parser.
+func parseJobTable(out string) []slurmJob {
+	var jobs []slurmJob
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 5 {
+			continue
+		}
+		jobs = append(jobs, slurmJob{
+			ID:         fields[0],
+			Name:       fields[1],
+			State:      normalizeState(fields[2]),
+			SubmitTime: normalizeTime(fields[3]),
+			EndTime:    normalizeTime(fields[4]),
+		})
+	}
+	return jobs
+}
+
+// normalizeState strips decorations such as "CANCELLED by 1000" or "RUNNING+".
+func normalizeState(state string) string {
+	state = strings.TrimSpace(state)
+	if i := strings.IndexAny(state, " +"); i >= 0 {
+		state = state[:i]
+	}
+	return strings.ToUpper(state)
+}
+
+// normalizeReason drops the placeholders Slurm prints for empty fields
+func normalizeReason(r string) string {
+	switch r {
+	case "None", "(null)", "None assigned":
+		return ""
+	}
+	return r
+}
+
+func normalizeTime(t string) string {
+	switch t {
+	case "", "N/A", "Unknown", "None":
+		return ""
+	}
+	return t
+}
+
+// jobStatusFromState maps a Slurm job state onto the statuses used by `gcluster job list`.
+func jobStatusFromState(state string) string {
+	switch state {
+	case "PENDING", "REQUEUED", "REQUEUE_HOLD", "REQUEUE_FED", "RESV_DEL_HOLD":
+		return "Pending"
+	case "RUNNING", "CONFIGURING", "COMPLETING", "RESIZING", "SIGNALING", "STAGE_OUT":
+		return "Running"
+	case "SUSPENDED", "STOPPED":
+		return "Suspended"
+	case "COMPLETED":
+		return "Succeeded"
+	case "FAILED", "CANCELLED", "TIMEOUT", "NODE_FAIL", "BOOT_FAIL", "DEADLINE", "OUT_OF_MEMORY", "PREEMPTED", "REVOKED", "SPECIAL_EXIT":
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+func isTerminalState(state string) bool {
+	status := jobStatusFromState(state)
+	return status == "Succeeded" || status == "Failed"
+}
+
+func (j slurmJob) toJobStatus() orchestrator.JobStatus {
+	status := orchestrator.JobStatus{
+		Name:         j.Name,
+		Status:       jobStatusFromState(j.State),
+		CreationTime: j.SubmitTime,
+	}
+	if isTerminalState(j.State) {
+		status.CompletionTime = j.EndTime
+	}
+	return status
+}
+
+// compareJobIDs orders numeric job IDs numerically and anything else lexically.
+func compareJobIDs(a, b string) bool {
+	ai, aErr := strconv.Atoi(a)
+	bi, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+func (d *DefaultExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	return shell.ExecuteCommand(name, args...)
+}
+
+func (d *DefaultExecutor) ExecuteCommandStream(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
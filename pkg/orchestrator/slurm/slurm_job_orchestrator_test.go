@@ -0,0 +1,421 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type MockExecutor struct {
+	responses map[string][]shell.CommandResult
+	callCount map[string]int
+	commands  []string
+	streamed  []string
+}
+
+func NewMockExecutor(responses map[string][]shell.CommandResult) *MockExecutor {
+	return &MockExecutor{
+		responses: responses,
+		callCount: make(map[string]int),
+	}
+}
+
+func (m *MockExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	cmdKey := name + " " + strings.Join(args, " ")
+	m.commands = append(m.commands, cmdKey)
+
+	for key, results := range m.responses {
+		if strings.HasPrefix(cmdKey, key) {
+			count := m.callCount[key]
+			if count < len(results) {
+				m.callCount[key]++
+				return results[count]
+			}
+		}
+	}
+
+	return shell.CommandResult{
+		ExitCode: 1,
+		Stderr:   fmt.Sprintf("mock error: unexpected command: %s", cmdKey),
+	}
+}
+
+func (m *MockExecutor) ExecuteCommandStream(name string, args ...string) error {
+	m.streamed = append(m.streamed, name+" "+strings.Join(args, " "))
+	return nil
+}
+
+func newTestSlurmOrchestrator(executor Executor) *SlurmOrchestrator {
+	s := NewSlurmOrchestrator()
+	s.SetExecutor(executor)
+	s.pollInterval = 0
+	return s
+}
+
+func TestSubmitJob(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'": {{ExitCode: 0}},
+		"bash -c cd \"$HOME\" && mkdir -p":              {{ExitCode: 0, Stdout: "1234;cluster\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	err := s.SubmitJob(orchestrator.JobDefinition{
+		WorkloadName: "train",
+		CommandToRun: "python train.py",
+		ComputeType:  "compute",
+	})
+	if err != nil {
+		t.Fatalf("SubmitJob() error = %v", err)
+	}
+
+	submit := mock.commands[len(mock.commands)-1]
+	for _, want := range []string{
+		"cat > '.gcluster/slurm/train/job.sbatch' <<'GCLUSTER_SBATCH_EOF' && sbatch --parsable '.gcluster/slurm/train/job.sbatch'\n#!/bin/bash\n",
+		"#SBATCH --partition=compute\n",
+		"\nGCLUSTER_SBATCH_EOF\n",
+	} {
+		if !strings.Contains(submit, want) {
+			t.Errorf("submit command missing %q:\n%s", want, submit)
+		}
+	}
+}
+
+func TestSubmitJob_Conflict(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'": {{ExitCode: 0, Stdout: "77\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "train", CommandToRun: "true"})
+	if err == nil || !strings.Contains(err.Error(), "already queued or running (job IDs: 77)") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+func TestSubmitJob_Await(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name=":  {{ExitCode: 0}},
+		"bash -c cd \"$HOME\" && mkdir -p":        {{ExitCode: 0, Stdout: "55\n"}},
+		"bash -c squeue --noheader --jobs=55":     {{ExitCode: 0, Stdout: "PENDING\n"}, {ExitCode: 0, Stdout: "RUNNING\n"}, {ExitCode: 0}},
+		"bash -c sacct --noheader --parsable2 --": {{ExitCode: 0, Stdout: "FAILED\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "w", CommandToRun: "false", AwaitJobCompletion: true})
+	if err == nil || !strings.Contains(err.Error(), "status: FAILED") {
+		t.Fatalf("expected failed job error, got %v", err)
+	}
+}
+
+func TestSubmitJob_AwaitTimeout(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name=": {{ExitCode: 0}},
+		"bash -c cd \"$HOME\" && mkdir -p":       {{ExitCode: 0, Stdout: "55\n"}},
+		"bash -c squeue --noheader --jobs=55":    {{ExitCode: 0, Stdout: "PENDING\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "w", CommandToRun: "true", AwaitJobCompletion: true, Timeout: "1ns"})
+	if err == nil || err.Error() != "job timed out" {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestSubmitJob_DryRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "job.sbatch")
+	mock := NewMockExecutor(nil)
+	s := newTestSlurmOrchestrator(mock)
+
+	if err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "dry", CommandToRun: "hostname", DryRunManifest: out}); err != nil {
+		t.Fatalf("SubmitJob() error = %v", err)
+	}
+	if len(mock.commands) != 0 {
+		t.Errorf("dry run executed commands: %v", mock.commands)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "#SBATCH --job-name=dry") {
+		t.Errorf("unexpected dry run output:\n%s", b)
+	}
+}
+
+func TestSubmitJob_Unsupported(t *testing.T) {
+	s := newTestSlurmOrchestrator(NewMockExecutor(nil))
+	if err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "p", CommandToRun: "x", IsPathwaysJob: true}); err == nil {
+		t.Error("expected pathways job to be rejected")
+	}
+	if err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "b", CommandToRun: "x", BaseImage: "python", BuildContext: "."}); err == nil {
+		t.Error("expected image build to be rejected")
+	}
+	secret := []orchestrator.EnvVar{{Name: "TOKEN", SecretName: "tokens", SecretKey: "api"}}
+	if err := s.SubmitJob(orchestrator.JobDefinition{WorkloadName: "s", CommandToRun: "x", Env: secret}); err == nil {
+		t.Error("expected secret environment variable to be rejected")
+	}
+}
+
+func TestSubmitJob_After(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'":      {{ExitCode: 0}},
+		"bash -c squeue --me --noheader --name='prep'":       {{ExitCode: 0, Stdout: "11\n12\n"}},
+		"bash -c squeue --me --noheader --name='fetch'":      {{ExitCode: 0}},
+		"bash -c sacct --noheader --parsable2 --allocations": {{ExitCode: 0, Stdout: "FAILED\nCOMPLETED\n"}},
+		"bash -c cd \"$HOME\" && mkdir -p":                   {{ExitCode: 0, Stdout: "13\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	err := s.SubmitJob(orchestrator.JobDefinition{
+		WorkloadName: "train",
+		CommandToRun: "python train.py",
+		After: []orchestrator.JobDependency{
+			{Name: "prep", Condition: orchestrator.AfterAny},
+			{Name: "fetch", Condition: orchestrator.AfterSucceeded},
+		},
+		Hold: true,
+	})
+	if err != nil {
+		t.Fatalf("SubmitJob() error = %v", err)
+	}
+
+	submit := mock.commands[len(mock.commands)-1]
+	for _, want := range []string{
+		"#SBATCH --dependency=afterany:11:12\n#SBATCH --kill-on-invalid-dep=yes\n#SBATCH --hold\n",
+	} {
+		if !strings.Contains(submit, want) {
+			t.Errorf("submit command missing %q:\n%s", want, submit)
+		}
+	}
+}
+
+func TestSubmitJob_AfterFailed(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name=":             {{ExitCode: 0}, {ExitCode: 0}},
+		"bash -c sacct --noheader --parsable2 --allocations": {{ExitCode: 0, Stdout: "CANCELLED by 42\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	err := s.SubmitJob(orchestrator.JobDefinition{
+		WorkloadName: "train",
+		CommandToRun: "true",
+		After:        []orchestrator.JobDependency{{Name: "prep", Condition: orchestrator.AfterSucceeded}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `dependency "prep" finished with status "Failed" (Slurm state CANCELLED)`) {
+		t.Fatalf("expected failed dependency error, got %v", err)
+	}
+}
+
+func TestSubmitJob_AfterNotFinished(t *testing.T) {
+	for state, want := range map[string]string{
+		"RUNNING\n":  `dependency "prep" is running (Slurm state RUNNING) but not in your queue`,
+		"PENDING\n":  `dependency "prep" is pending (Slurm state PENDING) but not in your queue`,
+		"LAUNCHED\n": `dependency "prep" is in unexpected Slurm state LAUNCHED`,
+	} {
+		mock := NewMockExecutor(map[string][]shell.CommandResult{
+			"bash -c squeue --me --noheader --name=":             {{ExitCode: 0}, {ExitCode: 0}},
+			"bash -c sacct --noheader --parsable2 --allocations": {{ExitCode: 0, Stdout: state}},
+		})
+		s := newTestSlurmOrchestrator(mock)
+
+		err := s.SubmitJob(orchestrator.JobDefinition{
+			WorkloadName: "train",
+			CommandToRun: "true",
+			After:        []orchestrator.JobDependency{{Name: "prep", Condition: orchestrator.AfterAny}},
+		})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error %q, got %v", want, err)
+		}
+	}
+}
+
+func TestReleaseJob(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'": {{ExitCode: 0, Stdout: "21\n22\n"}, {ExitCode: 0}},
+		"bash -c scontrol release 21 22":                {{ExitCode: 0}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	if err := s.ReleaseJob("train", orchestrator.ReleaseOptions{}); err != nil {
+		t.Fatalf("ReleaseJob() error = %v", err)
+	}
+	if err := s.ReleaseJob("train", orchestrator.ReleaseOptions{}); err == nil {
+		t.Error("expected error releasing a job that is not queued")
+	}
+}
+
+func TestDescribeJob(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'": {{ExitCode: 0, Stdout: "9\n10\n"}, {ExitCode: 0}},
+		"bash -c scontrol show job --oneliner 10": {{ExitCode: 0, Stdout: "JobId=10 JobName=train JobState=PENDING Reason=Resources Dependency=(null) Restarts=1 " +
+			"SubmitTime=2026-01-01T10:00:00 EndTime=Unknown Partition=compute NodeList=(null) QOS=normal\n"}},
+		"bash -c sacct --noheader --parsable2 --allocations --starttime=now-7days --name='train'": {{ExitCode: 0, Stdout: "FAILED|compute|normal|2026-01-01T10:00:00|2026-01-01T11:00:00|node-[1-2]|None\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	d, err := s.DescribeJob("train", orchestrator.DescribeOptions{})
+	if err != nil {
+		t.Fatalf("DescribeJob() error = %v", err)
+	}
+	want := orchestrator.JobDescription{Name: "train", Status: "Pending", Reason: "Resources", CreationTime: "2026-01-01T10:00:00", Queue: "compute", Priority: "normal", Restarts: 1}
+	if !reflect.DeepEqual(*d, want) {
+		t.Errorf("DescribeJob() = %+v, want %+v", *d, want)
+	}
+
+	if d, err = s.DescribeJob("train", orchestrator.DescribeOptions{}); err != nil {
+		t.Fatalf("DescribeJob() error = %v", err)
+	}
+	want = orchestrator.JobDescription{Name: "train", Status: "Failed", CreationTime: "2026-01-01T10:00:00", CompletionTime: "2026-01-01T11:00:00", Queue: "compute", Priority: "normal", Nodes: "node-[1-2]"}
+	if !reflect.DeepEqual(*d, want) {
+		t.Errorf("DescribeJob() = %+v, want %+v", *d, want)
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me": {{ExitCode: 0, Stdout: "12|train|RUNNING|2026-01-02T10:00:00|2026-01-03T10:00:00\n13|eval|PENDING|2026-01-02T11:00:00|N/A\n"}},
+		"bash -c sacct": {{ExitCode: 0, Stdout: "" +
+			"9|prep|COMPLETED|2026-01-01T09:00:00|2026-01-01T09:30:00\n" +
+			"10|bad|CANCELLED by 1000|2026-01-01T09:40:00|2026-01-01T09:41:00\n" +
+			"12|train|RUNNING|2026-01-02T10:00:00|Unknown\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	jobs, err := s.ListJobs(orchestrator.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	want := []orchestrator.JobStatus{
+		{Name: "prep", Status: "Succeeded", CreationTime: "2026-01-01T09:00:00", CompletionTime: "2026-01-01T09:30:00"},
+		{Name: "bad", Status: "Failed", CreationTime: "2026-01-01T09:40:00", CompletionTime: "2026-01-01T09:41:00"},
+		{Name: "train", Status: "Running", CreationTime: "2026-01-02T10:00:00"},
+		{Name: "eval", Status: "Pending", CreationTime: "2026-01-02T11:00:00"},
+	}
+	if !reflect.DeepEqual(jobs, want) {
+		t.Errorf("ListJobs() = %+v, want %+v", jobs, want)
+	}
+}
+
+func TestListJobs_FiltersAndNoAccounting(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me": {{ExitCode: 0, Stdout: "12|train-a|RUNNING|t1|N/A\n13|train-b|PENDING|t2|N/A\n14|eval|RUNNING|t3|N/A\n"}},
+		"bash -c sacct":       {{ExitCode: 1, Stderr: "Slurm accounting storage is disabled"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	jobs, err := s.ListJobs(orchestrator.ListOptions{NameContains: "train", Status: "running"})
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Name != "train-a" {
+		t.Errorf("ListJobs() = %+v, want only train-a", jobs)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='train'": {{ExitCode: 0, Stdout: "12\n15\n"}},
+		"bash -c scancel 12 15":                         {{ExitCode: 0}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	if err := s.CancelJob("train", orchestrator.CancelOptions{}); err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+
+	mock = NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c squeue --me --noheader --name='gone'": {{ExitCode: 0}},
+	})
+	s = newTestSlurmOrchestrator(mock)
+	if err := s.CancelJob("gone", orchestrator.CancelOptions{}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestGetJobLogs(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c cd \"$HOME\" && ls -t '.gcluster/slurm/train'/slurm-*.out": {{ExitCode: 0, Stdout: ".gcluster/slurm/train/slurm-12.out\n"}},
+		"bash -c cd \"$HOME\" && cat '.gcluster/slurm/train/slurm-12.out'":  {{ExitCode: 0, Stdout: "epoch 1\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	logs, err := s.GetJobLogs("train", orchestrator.LogsOptions{})
+	if err != nil {
+		t.Fatalf("GetJobLogs() error = %v", err)
+	}
+	if logs != "epoch 1\n" {
+		t.Errorf("GetJobLogs() = %q", logs)
+	}
+
+	if _, err := s.GetJobLogs("train", orchestrator.LogsOptions{}); err == nil {
+		t.Error("expected error when no log file is found")
+	}
+}
+
+func TestGetJobLogs_Follow(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"bash -c cd \"$HOME\" && ls -t": {{ExitCode: 0, Stdout: ".gcluster/slurm/train/slurm-12.out\n"}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+
+	if _, err := s.GetJobLogs("train", orchestrator.LogsOptions{Follow: true}); err != nil {
+		t.Fatalf("GetJobLogs() error = %v", err)
+	}
+	want := []string{"bash -c cd \"$HOME\" && tail -f '.gcluster/slurm/train/slurm-12.out'"}
+	if !reflect.DeepEqual(mock.streamed, want) {
+		t.Errorf("streamed = %v, want %v", mock.streamed, want)
+	}
+}
+
+func TestLoginNodeCommand(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud compute ssh login-0 --tunnel-through-iap --quiet --zone=us-central1-a --project=proj --command=squeue --me": {{ExitCode: 0}},
+		"gcloud compute ssh login-0 --tunnel-through-iap --quiet --zone=us-central1-a --project=proj --command=sacct":       {{ExitCode: 0}},
+	})
+	s := newTestSlurmOrchestrator(mock)
+	s.SetLoginNode("login-0")
+
+	if _, err := s.ListJobs(orchestrator.ListOptions{ClusterLocation: "us-central1-a", ProjectID: "proj"}); err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+}
+
+func TestJobStatusFromState(t *testing.T) {
+	tests := map[string]string{
+		"PENDING":       "Pending",
+		"REQUEUED":      "Pending",
+		"RUNNING":       "Running",
+		"COMPLETING":    "Running",
+		"SUSPENDED":     "Suspended",
+		"COMPLETED":     "Succeeded",
+		"TIMEOUT":       "Failed",
+		"OUT_OF_MEMORY": "Failed",
+		"WEIRD":         "Unknown",
+	}
+	for state, want := range tests {
+		if got := jobStatusFromState(state); got != want {
+			t.Errorf("jobStatusFromState(%q) = %q, want %q", state, got, want)
+		}
+	}
+	if got := normalizeState("CANCELLED by 1000"); got != "CANCELLED" {
+		t.Errorf("normalizeState() = %q", got)
+	}
+}
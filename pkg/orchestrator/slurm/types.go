@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slurm
+
+import (
+	"hpc-toolkit/pkg/shell"
+	"time"
+)
+
+const (
+	// jobsDirName is the directory, relative to the submitting user's home on
+	// the login node, where batch scripts and job output are kept.
+	jobsDirName = ".gcluster/slurm"
+	// heredocDelimiter terminates the batch script when it is written on the login node.
+	heredocDelimiter = "GCLUSTER_SBATCH_EOF"
+	// defaultPollInterval is the delay between status checks when awaiting job completion.
+	defaultPollInterval = 30 * time.Second
+	// maxAwaitTimeout bounds waiting for a job submitted without --timeout.
+	maxAwaitTimeout = 7 * 24 * time.Hour
+)
+
+type Executor interface {
+	ExecuteCommand(name string, args ...string) shell.CommandResult
+	ExecuteCommandStream(name string, args ...string) error
+}
+
+type DefaultExecutor struct{}
+
+// SlurmOrchestrator implements orchestrator.JobOrchestrator for Slurm clusters
+// deployed by the toolkit. Slurm commands are run through the Executor, either
+// locally (when gcluster runs on the login node) or over `gcloud compute ssh`
+// when a login node is configured.
+type SlurmOrchestrator struct {
+	executor     Executor
+	loginNode    string
+	zone         string
+	projectID    string
+	pollInterval time.Duration
+}
+
+// batchScriptData holds the values rendered into templates/sbatch.tmpl.
+type batchScriptData struct {
+	JobName            string
+	OutputPath         string
+	Nodes              int
+	Partition          string
+	Constraint         string
+	TimeLimit          string
+	QOS                string
+	Requeue            bool
+	Dependency         string
+	Hold               bool
+	EnvExports         string
+	RunCommand         string
+	RestartOnExitCodes string
+	MaxRestarts        int
+}
+
+// slurmJob is a single job record parsed from squeue or sacct output.
+type slurmJob struct {
+	ID         string
+	Name       string
+	State      string
+	SubmitTime string
+	EndTime    string
+}
@@ -22,20 +22,32 @@ import (
 	"crypto/rand"
 	"fmt"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/events"
 	"hpc-toolkit/pkg/logging"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"golang.org/x/sys/unix"
 )
 
+var promptMu sync.Mutex
+
+// LockPrompt makes prompts of concurrently deployed or destroyed groups to be
+// asked one at a time, the returned function is called once the answer is read
+func LockPrompt() (unlock func()) {
+	promptMu.Lock()
+	return promptMu.Unlock
+}
+
 // ProposedChanges provides summary and full description of proposed changes
 // to cloud infrastructure
 type ProposedChanges struct {
 	Summary string
 	Full    string
+	Group   string // deployment group of the changes, reported in events
 }
 
 // CommandResult holds the output and exit code of an executed command.
@@ -171,7 +183,11 @@ func CheckWritableDir(path string) error {
 // skip making the proposed changes and continue execution (in deploy command)
 // only if the user responds with "y" or "yes" (case-insensitive)
 func ApplyChangesChoice(c ProposedChanges) bool {
+	// prompts of concurrently deployed groups are asked one at a time
+	promptMu.Lock()
+	defer promptMu.Unlock()
 	logging.Info("Summary of proposed changes: %s", strings.TrimSpace(c.Summary))
+	events.Emit(events.Event{Type: events.Prompt, Group: c.Group, Message: strings.TrimSpace(c.Summary)})
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
@@ -188,12 +204,15 @@ Please select an option [d,a,s,c]: `)
 
 		switch strings.ToLower(strings.TrimSpace(in)) {
 		case "a":
+			events.EmitGroup(events.PromptAnswer, c.Group, "apply", nil)
 			return true
 		case "c":
+			events.EmitGroup(events.PromptAnswer, c.Group, "skip", nil)
 			return false
 		case "d":
 			fmt.Println(c.Full)
 		case "s":
+			events.EmitGroup(events.PromptAnswer, c.Group, "stop", nil)
 			logging.ExitWithCode(0, "user chose to stop execution of gcluster rather than make proposed changes to infrastructure")
 		}
 	}
@@ -0,0 +1,261 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/modulewriter"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyJson "github.com/zclconf/go-cty/cty/json"
+)
+
+// DeploymentOutputsName is the name of the file within the artifacts directory
+// that exposes outputs of the deployment to other deployments
+const DeploymentOutputsName = "deployment_outputs.json"
+
+// tfStateName is the name of the Terraform state object of the default
+// workspace within the prefix of the gcs backend
+const tfStateName = "default.tfstate"
+
+// DeploymentOutput is a single output of a deployment
+type DeploymentOutput struct {
+	Group config.GroupName `json:"group"`
+	// empty for outputs read from Terraform state, Name is the Terraform output name then
+	Module config.ModuleID `json:"module,omitempty"`
+	Name   string          `json:"name"`
+	Type   json.RawMessage `json:"type"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// DeploymentOutputs are outputs of a deployment, written by "gcluster deploy"
+// and "gcluster export-outputs", that other deployments can reference with
+// `$(deployment_output("<deployment_name>", "<output>"))`
+type DeploymentOutputs struct {
+	DeploymentName string             `json:"deployment_name"`
+	BlueprintName  string             `json:"blueprint_name,omitempty"`
+	ProjectID      string             `json:"project_id,omitempty"`
+	Outputs        []DeploymentOutput `json:"outputs"`
+
+	source string
+}
+
+func newDeploymentOutput(g config.GroupName, m config.ModuleID, name string, val cty.Value) (DeploymentOutput, error) {
+	ty, err := ctyJson.MarshalType(val.Type())
+	if err != nil {
+		return DeploymentOutput{}, err
+	}
+	js, err := ctyJson.Marshal(val, val.Type())
+	if err != nil {
+		return DeploymentOutput{}, fmt.Errorf("failed to encode output %q of module %q: %w", name, m, err)
+	}
+	return DeploymentOutput{Group: g, Module: m, Name: name, Type: ty, Value: js}, nil
+}
+
+// ExportDeploymentOutputs collects outputs of modules, listed in the `outputs`
+// of the blueprint, from the outputs of deployment groups in the artifacts
+// directory and writes them to DeploymentOutputsName
+func ExportDeploymentOutputs(artifactsDir string, bp config.Blueprint) error {
+	d := DeploymentOutputs{
+		DeploymentName: bp.DeploymentName(),
+		BlueprintName:  bp.BlueprintName,
+		Outputs:        []DeploymentOutput{},
+	}
+	if bp.Vars.Has("project_id") {
+		if p := bp.Vars.Get("project_id"); p.Type() == cty.String && p.IsKnown() && !p.IsNull() {
+			d.ProjectID = p.AsString()
+		}
+	}
+
+	for _, g := range bp.Groups {
+		if g.Kind() != config.TerraformKind {
+			continue
+		}
+		file := outputsFile(artifactsDir, g.Name)
+		if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+			continue // group has no outputs or was not deployed yet
+		}
+		vals, err := modulereader.ReadHclAttributes(file)
+		if err != nil {
+			return err
+		}
+		for _, m := range g.Modules {
+			for _, o := range m.Outputs {
+				val, ok := vals[config.AutomaticOutputName(o.Name, m.ID)]
+				if !ok {
+					continue
+				}
+				do, err := newDeploymentOutput(g.Name, m.ID, o.Name, val)
+				if err != nil {
+					return err
+				}
+				d.Outputs = append(d.Outputs, do)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(artifactsDir, DeploymentOutputsName), data, 0644)
+}
+
+// LoadDeploymentOutputs reads outputs of a deployment from its deployment
+// directory, local or in Cloud Storage. A Cloud Storage location that holds no
+// deployment directory is read as the prefix of a gcs Terraform backend, outputs
+// of every `<group>/default.tfstate` under it are read then and the deployment
+// name is the last element of the prefix.
+func LoadDeploymentOutputs(location string) (DeploymentOutputs, error) {
+	manifest := deploymentio.Join(location, modulewriter.HiddenGhpcDirName, modulewriter.ArtifactsDirName, DeploymentOutputsName)
+	var data []byte
+	var err error
+	if deploymentio.IsRemote(location) {
+		data, err = deploymentio.ReadRemote(manifest)
+		if err != nil {
+			if d, serr := loadStateOutputs(location); serr == nil {
+				return d, nil
+			}
+		}
+	} else {
+		data, err = os.ReadFile(manifest)
+	}
+	if err != nil {
+		return DeploymentOutputs{}, config.HintError{
+			Hint: fmt.Sprintf("run \"gcluster deploy\" of the deployment in %s or pass its location with --deployment-source", location),
+			Err:  fmt.Errorf("no outputs of deployment found in %s: %w", location, err)}
+	}
+
+	d := DeploymentOutputs{source: location}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return DeploymentOutputs{}, fmt.Errorf("failed to parse %s: %w", manifest, err)
+	}
+	return d, nil
+}
+
+type tfState struct {
+	Outputs map[string]struct {
+		Value json.RawMessage `json:"value"`
+		Type  json.RawMessage `json:"type"`
+	} `json:"outputs"`
+}
+
+func loadStateOutputs(prefix string) (DeploymentOutputs, error) {
+	names, err := deploymentio.ListRemote(prefix)
+	if err != nil {
+		return DeploymentOutputs{}, err
+	}
+	_, p, _ := strings.Cut(strings.TrimPrefix(prefix, "gs://"), "/")
+	d := DeploymentOutputs{
+		DeploymentName: path.Base(strings.TrimSuffix(p, "/")),
+		Outputs:        []DeploymentOutput{},
+		source:         prefix,
+	}
+	found := false
+	for _, n := range names {
+		group, file := path.Split(n)
+		if file != tfStateName || group == "" || strings.Contains(strings.TrimSuffix(group, "/"), "/") {
+			continue
+		}
+		found = true
+		data, err := deploymentio.ReadRemote(deploymentio.Join(prefix, n))
+		if err != nil {
+			return DeploymentOutputs{}, err
+		}
+		var st tfState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return DeploymentOutputs{}, fmt.Errorf("failed to parse Terraform state %s: %w", n, err)
+		}
+		for name, o := range st.Outputs {
+			d.Outputs = append(d.Outputs, DeploymentOutput{
+				Group: config.GroupName(strings.TrimSuffix(group, "/")),
+				Name:  name, Type: o.Type, Value: o.Value})
+		}
+	}
+	if !found {
+		return DeploymentOutputs{}, fmt.Errorf("no Terraform state found in %s", prefix)
+	}
+	sort.Slice(d.Outputs, func(i, j int) bool {
+		a, b := d.Outputs[i], d.Outputs[j]
+		return a.Group < b.Group || (a.Group == b.Group && a.Name < b.Name)
+	})
+	return d, nil
+}
+
+// CheckIdentity makes sure that the outputs belong to the named deployment
+func (d DeploymentOutputs) CheckIdentity(name string) error {
+	if d.DeploymentName != name {
+		return fmt.Errorf("%s holds outputs of deployment %q, expected deployment %q", d.source, d.DeploymentName, name)
+	}
+	return nil
+}
+
+func (o DeploymentOutput) matches(ref config.Reference) bool {
+	if ref.Module == "" {
+		return o.Name == ref.Name
+	}
+	if o.Module == "" { // read from Terraform state
+		return o.Name == config.AutomaticOutputName(ref.Name, ref.Module)
+	}
+	return o.Module == ref.Module && o.Name == ref.Name
+}
+
+// Lookup returns value of the referenced output, the value is checked to be
+// of the type recorded by the exporting deployment
+func (d DeploymentOutputs) Lookup(ref config.Reference) (cty.Value, error) {
+	found := []DeploymentOutput{}
+	for _, o := range d.Outputs {
+		if o.matches(ref) {
+			found = append(found, o)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return cty.NilVal, fmt.Errorf("deployment %q has no output %q", d.DeploymentName, ref)
+	case 1:
+	default:
+		mods := []string{}
+		for _, o := range found {
+			mods = append(mods, string(o.Module))
+		}
+		return cty.NilVal, config.HintError{
+			Hint: fmt.Sprintf("qualify the output with a module, one of %q, e.g. $(deployment_output(%q, %q, %q))", mods, d.DeploymentName, mods[0], ref.Name),
+			Err:  fmt.Errorf("output %q of deployment %q is ambiguous", ref.Name, d.DeploymentName)}
+	}
+
+	o := found[0]
+	ty, err := ctyJson.UnmarshalType(o.Type)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid type of output %q of deployment %q: %w", ref, d.DeploymentName, err)
+	}
+	val, err := ctyJson.Unmarshal(o.Value, ty)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("output %q of deployment %q doesn't match its type %s: %w", ref, d.DeploymentName, ty.FriendlyName(), err)
+	}
+	return val, nil
+}
@@ -0,0 +1,115 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"context"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/deploymentio"
+	"hpc-toolkit/pkg/modulereader"
+	"os"
+	"path/filepath"
+
+	"github.com/zclconf/go-cty/cty"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestExportLoadDeploymentOutputs(c *C) {
+	deplDir := c.MkDir()
+	artDir := filepath.Join(deplDir, ".ghpc", "artifacts")
+	c.Assert(os.MkdirAll(artDir, 0755), IsNil)
+	c.Assert(os.WriteFile(outputsFile(artDir, "primary"), []byte(
+		"network_self_link_network = \"projects/p/networks/n\"\n"+
+			"subnetworks_network = [\"a\", \"b\"]\n"+
+			"network_self_link_other = \"projects/p/networks/o\"\n"), 0644), IsNil)
+
+	out := func(names ...string) []modulereader.OutputInfo {
+		res := []modulereader.OutputInfo{}
+		for _, n := range names {
+			res = append(res, modulereader.OutputInfo{Name: n})
+		}
+		return res
+	}
+	bp := config.Blueprint{
+		BlueprintName: "net",
+		Vars: config.NewDict(map[string]cty.Value{
+			"deployment_name": cty.StringVal("shared-network"),
+			"project_id":      cty.StringVal("apple")}),
+		Groups: []config.Group{
+			{Name: "primary", Modules: []config.Module{
+				{ID: "network", Source: "modules/network/vpc", Kind: config.TerraformKind, Outputs: out("network_self_link", "subnetworks")},
+				{ID: "other", Source: "modules/network/vpc", Kind: config.TerraformKind, Outputs: out("network_self_link")}}},
+			{Name: "later", Modules: []config.Module{
+				{ID: "late", Source: "modules/network/vpc", Kind: config.TerraformKind, Outputs: out("id")}}}},
+	}
+	c.Assert(ExportDeploymentOutputs(artDir, bp), IsNil)
+
+	d, err := LoadDeploymentOutputs(deplDir)
+	c.Assert(err, IsNil)
+	c.Check(d.DeploymentName, Equals, "shared-network")
+	c.Check(d.ProjectID, Equals, "apple")
+	c.Check(d.Outputs, HasLen, 3)
+	c.Check(d.CheckIdentity("shared-network"), IsNil)
+	c.Check(d.CheckIdentity("other-network"), NotNil)
+
+	v, err := d.Lookup(config.DeploymentRef("shared-network", "", "subnetworks"))
+	c.Assert(err, IsNil)
+	c.Check(v, DeepEquals, cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}))
+
+	v, err = d.Lookup(config.DeploymentRef("shared-network", "other", "network_self_link"))
+	c.Assert(err, IsNil)
+	c.Check(v, DeepEquals, cty.StringVal("projects/p/networks/o"))
+
+	_, err = d.Lookup(config.DeploymentRef("shared-network", "", "network_self_link"))
+	c.Check(err, ErrorMatches, `.*ambiguous.*`)
+	_, err = d.Lookup(config.DeploymentRef("shared-network", "", "id"))
+	c.Check(err, ErrorMatches, `deployment "shared-network" has no output .*`)
+
+	_, err = LoadDeploymentOutputs(c.MkDir())
+	c.Check(err, ErrorMatches, `no outputs of deployment found in .*`)
+}
+
+func (s *MySuite) TestLoadDeploymentOutputsFromState(c *C) {
+	store := deploymentio.NewMemStore()
+	deploymentio.SetObjectStore(store)
+	defer deploymentio.SetObjectStore(nil)
+
+	state := `{"version": 4, "outputs": {
+		"network_self_link_network": {"value": "projects/p/networks/n", "type": "string"},
+		"size_network": {"value": {"big": 3}, "type": "number"}}}`
+	_, err := store.Write(context.Background(), "pail", "net/shared-network/primary/default.tfstate", []byte(state), 0644, 0)
+	c.Assert(err, IsNil)
+
+	d, err := LoadDeploymentOutputs("gs://pail/net/shared-network")
+	c.Assert(err, IsNil)
+	c.Check(d.DeploymentName, Equals, "shared-network")
+	c.Check(d.Outputs, HasLen, 2)
+
+	v, err := d.Lookup(config.DeploymentRef("shared-network", "network", "network_self_link"))
+	c.Assert(err, IsNil)
+	c.Check(v, DeepEquals, cty.StringVal("projects/p/networks/n"))
+
+	v, err = d.Lookup(config.DeploymentRef("shared-network", "", "network_self_link_network"))
+	c.Assert(err, IsNil)
+	c.Check(v, DeepEquals, cty.StringVal("projects/p/networks/n"))
+
+	_, err = d.Lookup(config.DeploymentRef("shared-network", "network", "size"))
+	c.Check(err, ErrorMatches, `output .* doesn't match its type number.*`)
+
+	_, err = LoadDeploymentOutputs("gs://pail/elsewhere")
+	c.Check(err, ErrorMatches, `no outputs of deployment found in gs://pail/elsewhere.*`)
+}
@@ -0,0 +1,190 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// GroupDrift holds drift of a deployment group, i.e. resources changed or
+// deleted outside of Terraform and missing images built by Packer groups
+type GroupDrift struct {
+	Group         config.GroupName `json:"group"`
+	Drifted       bool             `json:"drifted"`
+	Summary       string           `json:"summary"`
+	Resources     []ResourceChange `json:"resources,omitempty"`
+	MissingImages []string         `json:"missing_images,omitempty"`
+	Note          string           `json:"note,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+// DriftReport holds drift of a deployment
+type DriftReport struct {
+	Drifted bool         `json:"drifted"`
+	Groups  []GroupDrift `json:"groups"`
+}
+
+// NewDriftReport summarizes drift of groups
+func NewDriftReport(groups []GroupDrift) DriftReport {
+	r := DriftReport{Groups: groups}
+	for i := range r.Groups {
+		g := &r.Groups[i]
+		g.Drifted = len(g.Resources) > 0 || len(g.MissingImages) > 0
+		g.Summary = g.summary()
+		r.Drifted = r.Drifted || g.Drifted
+	}
+	return r
+}
+
+// HasErrors reports whether drift of some groups could not be detected
+func (r DriftReport) HasErrors() bool {
+	for _, g := range r.Groups {
+		if g.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (g GroupDrift) summary() string {
+	if g.Error != "" {
+		return "drift is unknown"
+	}
+	counts := map[string]int{}
+	for _, c := range g.Resources {
+		counts[c.Action]++
+	}
+	parts := []string{}
+	for _, a := range []string{ActionUpdate, ActionDelete} {
+		if counts[a] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %sd outside of Terraform", counts[a], a))
+		}
+	}
+	if n := len(g.MissingImages); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d image(s) missing", n))
+	}
+	if len(parts) == 0 {
+		return "no drift"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Text renders the report as plain text
+func (r DriftReport) Text() string {
+	var b strings.Builder
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "Group %s: %s\n", g.Group, g.Summary)
+		for _, c := range g.Resources {
+			fmt.Fprintf(&b, "  %-8s %s\n", c.Action, c.Address)
+		}
+		for _, img := range g.MissingImages {
+			fmt.Fprintf(&b, "  missing  %s\n", img)
+		}
+		if g.Error != "" {
+			fmt.Fprintf(&b, "  error: %s\n", g.Error)
+		}
+		if g.Note != "" {
+			fmt.Fprintf(&b, "  note: %s\n", g.Note)
+		}
+	}
+	return b.String()
+}
+
+// DriftGroup runs a refresh-only "terraform plan" in the Terraform working
+// directory and returns resources whose live state diverged from the state
+func DriftGroup(tf *tfexec.Terraform, group config.GroupName) (GroupDrift, error) {
+	if err := initModule(tf); err != nil {
+		return GroupDrift{}, err
+	}
+
+	f, err := os.CreateTemp("", "plan-")
+	if err != nil {
+		return GroupDrift{}, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if _, err := tf.Plan(context.Background(), tfexec.Out(f.Name()), tfexec.RefreshOnly(true)); err != nil {
+		return GroupDrift{}, config.HintError{
+			Hint: fmt.Sprintf("refresh-only terraform plan for deployment group %s failed", tf.WorkingDir()),
+			Err:  err}
+	}
+	plan, err := tf.ShowPlanFile(context.Background(), f.Name())
+	if err != nil {
+		return GroupDrift{}, fmt.Errorf("failed to read the plan of deployment group %s: %w", tf.WorkingDir(), err)
+	}
+	return GroupDrift{Group: group, Resources: PlanDrift(plan)}, nil
+}
+
+// PlanDrift returns resources changed or deleted outside of Terraform in the
+// output of "terraform show -json" of a refresh-only plan
+func PlanDrift(plan *tfjson.Plan) []ResourceChange {
+	res := []ResourceChange{}
+	for _, rc := range plan.ResourceDrift {
+		if rc.Change == nil || rc.Mode == tfjson.DataResourceMode {
+			continue
+		}
+		action := changeAction(rc.Change)
+		if action != ActionUpdate && action != ActionDelete {
+			continue
+		}
+		res = append(res, ResourceChange{Address: rc.Address, Type: rc.Type, Action: action})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Address < res[j].Address })
+	return res
+}
+
+// PackerManifestImages returns names of images built by the last run of Packer
+// recorded in the manifest written by the "manifest" post-processor
+func PackerManifestImages(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Builds []struct {
+			ArtifactID  string `json:"artifact_id"`
+			BuilderType string `json:"builder_type"`
+			RunUUID     string `json:"packer_run_uuid"`
+		} `json:"builds"`
+		LastRunUUID string `json:"last_run_uuid"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse Packer manifest %s: %w", path, err)
+	}
+
+	images := []string{}
+	for _, b := range manifest.Builds {
+		if b.BuilderType != "googlecompute" || b.ArtifactID == "" {
+			continue
+		}
+		if manifest.LastRunUUID != "" && b.RunUUID != manifest.LastRunUUID {
+			continue // images of previous runs may have been replaced deliberately
+		}
+		images = append(images, b.ArtifactID)
+	}
+	return images, nil
+}
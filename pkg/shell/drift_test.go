@@ -0,0 +1,77 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shell
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestPlanDrift(c *C) {
+	var plan tfjson.Plan
+	c.Assert(json.Unmarshal([]byte(`{
+  "format_version": "1.2",
+  "resource_drift": [
+    {"address": "module.vm.google_compute_instance.vm[0]", "mode": "managed", "type": "google_compute_instance", "change": {"actions": ["update"]}},
+    {"address": "module.fs.google_filestore_instance.fs", "mode": "managed", "type": "google_filestore_instance", "change": {"actions": ["delete"]}},
+    {"address": "data.google_project.p", "mode": "data", "type": "google_project", "change": {"actions": ["update"]}}
+  ]
+}`), &plan), IsNil)
+
+	drift := PlanDrift(&plan)
+	c.Check(drift, DeepEquals, []ResourceChange{
+		{"module.fs.google_filestore_instance.fs", "google_filestore_instance", ActionDelete},
+		{"module.vm.google_compute_instance.vm[0]", "google_compute_instance", ActionUpdate},
+	})
+
+	r := NewDriftReport([]GroupDrift{
+		{Group: "primary", Resources: drift},
+		{Group: "image", MissingImages: []string{"projects/p/global/images/img"}},
+		{Group: "cluster", Error: "boom"},
+		{Group: "storage", Resources: []ResourceChange{}},
+	})
+	c.Check(r.Drifted, Equals, true)
+	c.Check(r.HasErrors(), Equals, true)
+	c.Check(r.Groups[0].Summary, Equals, "1 updated outside of Terraform, 1 deleted outside of Terraform")
+	c.Check(r.Groups[1].Summary, Equals, "1 image(s) missing")
+	c.Check(r.Groups[2].Summary, Equals, "drift is unknown")
+	c.Check(r.Groups[3].Drifted, Equals, false)
+	c.Check(r.Groups[3].Summary, Equals, "no drift")
+}
+
+func (s *MySuite) TestPackerManifestImages(c *C) {
+	path := filepath.Join(c.MkDir(), "packer-manifest.json")
+	c.Assert(os.WriteFile(path, []byte(`{
+  "builds": [
+    {"builder_type": "googlecompute", "artifact_id": "img-1", "packer_run_uuid": "old"},
+    {"builder_type": "googlecompute", "artifact_id": "img-2", "packer_run_uuid": "new"},
+    {"builder_type": "null", "artifact_id": "x", "packer_run_uuid": "new"}
+  ],
+  "last_run_uuid": "new"
+}`), 0644), IsNil)
+
+	images, err := PackerManifestImages(path)
+	c.Assert(err, IsNil)
+	c.Check(images, DeepEquals, []string{"img-2"})
+
+	_, err = PackerManifestImages(filepath.Join(c.MkDir(), "missing.json"))
+	c.Check(err, NotNil)
+}
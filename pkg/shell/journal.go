@@ -0,0 +1,290 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulereader"
+	"hpc-toolkit/pkg/modulewriter"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyJson "github.com/zclconf/go-cty/cty/json"
+)
+
+// JournalName is the name of the deployment journal within the .ghpc directory
+const JournalName = "deploy_journal.json"
+
+// GroupStatus is the status of a deployment group recorded in the journal
+type GroupStatus string
+
+// Statuses of deployment groups
+const (
+	GroupApplying  GroupStatus = "applying"
+	GroupApplied   GroupStatus = "applied"
+	GroupDeclined  GroupStatus = "declined"
+	GroupPartial   GroupStatus = "partial"
+	GroupFailed    GroupStatus = "failed"
+	GroupDestroyed GroupStatus = "destroyed"
+)
+
+// GroupRecord is the journal entry of a single deployment group
+type GroupRecord struct {
+	Status GroupStatus `json:"status"`
+	// hash of the expanded blueprint the group was last deployed from
+	BlueprintHash string `json:"blueprint_hash"`
+	// hash of the group directory, including imported inputs
+	GroupHash   string                             `json:"group_hash"`
+	Updated     time.Time                          `json:"updated"`
+	LastApplied *time.Time                         `json:"last_applied,omitempty"`
+	Error       string                             `json:"error,omitempty"`
+	Outputs     map[string]ctyJson.SimpleJSONValue `json:"outputs,omitempty"`
+}
+
+// Journal records progress of "gcluster deploy" per deployment group, it is
+// persisted in the .ghpc directory so that it survives "gcluster create -w"
+type Journal struct {
+	Groups map[config.GroupName]*GroupRecord `json:"groups"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// JournalPath returns path of the journal of the deployment
+func JournalPath(deplDir string) string {
+	return filepath.Join(modulewriter.HiddenGhpcDir(deplDir), JournalName)
+}
+
+// LoadJournal reads the journal of the deployment, a missing journal is
+// treated as empty
+func LoadJournal(deplDir string) (*Journal, error) {
+	j := &Journal{Groups: map[config.GroupName]*GroupRecord{}, path: JournalPath(deplDir)}
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment journal %s: %w", j.path, err)
+	}
+	if j.Groups == nil {
+		j.Groups = map[config.GroupName]*GroupRecord{}
+	}
+	return j, nil
+}
+
+// Record returns a copy of the journal entry of the group
+func (j *Journal) Record(group config.GroupName) (GroupRecord, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r, ok := j.Groups[group]
+	if !ok {
+		return GroupRecord{}, false
+	}
+	return *r, true
+}
+
+// Unchanged returns true if the group was applied and neither the group
+// directory nor its inputs have changed since
+func (j *Journal) Unchanged(group config.GroupName, groupHash string) bool {
+	r, ok := j.Record(group)
+	return ok && r.Status == GroupApplied && r.GroupHash == groupHash
+}
+
+// Start records that deployment of the group has begun
+func (j *Journal) Start(group config.GroupName, bpHash string, groupHash string) error {
+	return j.update(group, func(r *GroupRecord) {
+		r.Status = GroupApplying
+		r.BlueprintHash = bpHash
+		r.GroupHash = groupHash
+		r.Error = ""
+	})
+}
+
+// Fail records that deployment of the group has failed
+func (j *Journal) Fail(group config.GroupName, err error) error {
+	return j.update(group, func(r *GroupRecord) {
+		r.Status = GroupFailed
+		r.Error = err.Error()
+	})
+}
+
+// Decline records that proposed changes of the group were not applied
+func (j *Journal) Decline(group config.GroupName) error {
+	return j.update(group, func(r *GroupRecord) {
+		r.Status = GroupDeclined
+	})
+}
+
+// Partial records that only some modules of the group were applied, e.g. with
+// "gcluster deploy --module"; the group is deployed again on resume
+func (j *Journal) Partial(group config.GroupName) error {
+	return j.update(group, func(r *GroupRecord) {
+		r.Status = GroupPartial
+	})
+}
+
+// Destroy records that the group was destroyed, e.g. with "gcluster destroy";
+// its outputs are forgotten and the group is deployed again on resume
+func (j *Journal) Destroy(group config.GroupName) error {
+	return j.update(group, func(r *GroupRecord) {
+		r.Status = GroupDestroyed
+		r.Error = ""
+		r.Outputs = nil
+	})
+}
+
+// Apply records that the group was applied and the outputs it exported
+// to the artifacts directory
+func (j *Journal) Apply(group config.GroupName, artifactsDir string) error {
+	outputs := map[string]ctyJson.SimpleJSONValue{}
+	path := outputsFile(artifactsDir, group)
+	if _, err := os.Stat(path); err == nil {
+		vals, err := modulereader.ReadHclAttributes(path)
+		if err != nil {
+			return err
+		}
+		for k, v := range vals {
+			outputs[k] = ctyJson.SimpleJSONValue{Value: v}
+		}
+	}
+
+	now := time.Now().UTC()
+	return j.update(group, func(r *GroupRecord) {
+		r.Status = GroupApplied
+		r.LastApplied = &now
+		r.Outputs = outputs
+	})
+}
+
+// RestoreOutputs writes outputs recorded for the group to the artifacts
+// directory, as if they were exported by the skipped deployment
+func (j *Journal) RestoreOutputs(group config.GroupName, artifactsDir string) error {
+	r, _ := j.Record(group)
+	if len(r.Outputs) == 0 {
+		return nil
+	}
+	vals := map[string]cty.Value{}
+	for k, v := range r.Outputs {
+		vals[k] = v.Value
+	}
+	return modulewriter.WriteHclAttributes(vals, outputsFile(artifactsDir, group))
+}
+
+func (j *Journal) update(group config.GroupName, fn func(r *GroupRecord)) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r, ok := j.Groups[group]
+	if !ok {
+		r = &GroupRecord{}
+		j.Groups[group] = r
+	}
+	fn(r)
+	r.Updated = time.Now().UTC()
+	return j.save()
+}
+
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first to never leave a truncated journal
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// HashFile returns SHA-256 checksum of the file
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// files produced by running Terraform or Packer in the group directory,
+// they are not part of the deployment
+func isRuntimeFile(name string) bool {
+	return name == ".terraform" ||
+		name == ".terraform.lock.hcl" ||
+		name == "packer-manifest.json" ||
+		strings.Contains(name, ".tfstate")
+}
+
+// HashGroup returns SHA-256 checksum over the contents of the group directory,
+// ignoring files produced by running Terraform or Packer
+func HashGroup(groupDir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(groupDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if isRuntimeFile(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(groupDir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		h.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
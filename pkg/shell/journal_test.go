@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shell
+
+import (
+	"errors"
+	"hpc-toolkit/pkg/modulereader"
+	"os"
+	"path/filepath"
+
+	"github.com/zclconf/go-cty/cty"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestJournal(c *C) {
+	deplDir := c.MkDir()
+	artDir := filepath.Join(deplDir, ".ghpc", "artifacts")
+	c.Assert(os.MkdirAll(artDir, 0755), IsNil)
+
+	j, err := LoadJournal(deplDir)
+	c.Assert(err, IsNil)
+	c.Check(j.Groups, HasLen, 0)
+	c.Check(j.Unchanged("primary", "h1"), Equals, false)
+
+	c.Assert(j.Start("primary", "bp", "h1"), IsNil)
+	c.Check(j.Unchanged("primary", "h1"), Equals, false)
+	c.Assert(os.WriteFile(outputsFile(artDir, "primary"), []byte("network_id = \"net\"\n"), 0644), IsNil)
+	c.Assert(j.Apply("primary", artDir), IsNil)
+
+	c.Assert(j.Start("cluster", "bp", "h2"), IsNil)
+	c.Assert(j.Fail("cluster", errors.New("boom")), IsNil)
+
+	// journal is persisted
+	j, err = LoadJournal(deplDir)
+	c.Assert(err, IsNil)
+	c.Check(j.Unchanged("primary", "h1"), Equals, true)
+	c.Check(j.Unchanged("primary", "other"), Equals, false)
+	c.Check(j.Unchanged("cluster", "h2"), Equals, false)
+
+	r, ok := j.Record("primary")
+	c.Assert(ok, Equals, true)
+	c.Check(r.Status, Equals, GroupApplied)
+	c.Check(r.BlueprintHash, Equals, "bp")
+	c.Check(r.LastApplied, NotNil)
+	r, _ = j.Record("cluster")
+	c.Check(r.Status, Equals, GroupFailed)
+	c.Check(r.Error, Equals, "boom")
+
+	// outputs are restored into a fresh artifacts directory
+	c.Assert(os.Remove(outputsFile(artDir, "primary")), IsNil)
+	c.Assert(j.RestoreOutputs("primary", artDir), IsNil)
+	c.Assert(j.RestoreOutputs("cluster", artDir), IsNil)
+	vals, err := modulereader.ReadHclAttributes(outputsFile(artDir, "primary"))
+	c.Assert(err, IsNil)
+	c.Check(vals["network_id"].Equals(cty.StringVal("net")), Equals, cty.True)
+
+	// destroyed groups are deployed again
+	c.Assert(j.Destroy("primary"), IsNil)
+	c.Check(j.Unchanged("primary", "h1"), Equals, false)
+	r, _ = j.Record("primary")
+	c.Check(r.Status, Equals, GroupDestroyed)
+	c.Check(r.Outputs, HasLen, 0)
+}
+
+func (s *MySuite) TestHashGroup(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(dir, "main.tf"), []byte("a"), 0644), IsNil)
+	h1, err := HashGroup(dir)
+	c.Assert(err, IsNil)
+
+	// runtime files are ignored
+	c.Assert(os.MkdirAll(filepath.Join(dir, ".terraform", "modules"), 0755), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, ".terraform", "modules", "m.json"), []byte("{}"), 0644), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte("{}"), 0644), IsNil)
+	h2, err := HashGroup(dir)
+	c.Assert(err, IsNil)
+	c.Check(h2, Equals, h1)
+
+	c.Assert(os.WriteFile(filepath.Join(dir, "primary_inputs.auto.tfvars"), []byte("x = 1"), 0644), IsNil)
+	h3, err := HashGroup(dir)
+	c.Assert(err, IsNil)
+	c.Check(h3, Not(Equals), h1)
+}
@@ -60,8 +60,8 @@ func ExecPackerCmd(workingDir string, printToScreen bool, args ...string) error
 	var errBuf io.Writer
 
 	if printToScreen {
-		outBuf = newTimestampWriter(os.Stdout)
-		errBuf = newTimestampWriter(os.Stderr)
+		outBuf = newDirWriter(os.Stdout, workingDir)
+		errBuf = newDirWriter(os.Stderr, workingDir)
 	} else {
 		outBuf = bytes.NewBuffer([]byte{})
 		errBuf = bytes.NewBuffer([]byte{})
@@ -0,0 +1,220 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// Actions of resource changes reported by PlanGroup
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionReplace = "replace"
+	ActionDelete  = "delete"
+	ActionImport  = "import"
+	ActionForget  = "forget"
+)
+
+// PlanActions lists actions in the order of reports
+var PlanActions = []string{ActionCreate, ActionUpdate, ActionReplace, ActionDelete, ActionImport, ActionForget}
+
+// ResourceChange is a proposed change of a single resource
+type ResourceChange struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Action  string `json:"action"`
+}
+
+// GroupPlan holds proposed changes of a deployment group
+type GroupPlan struct {
+	Group   config.GroupName `json:"group"`
+	Changes []ResourceChange `json:"changes"`
+	// Unknown is set if changes of the group could not be planned, e.g. Packer
+	// groups or groups depending on outputs of groups that are not deployed yet
+	Unknown bool   `json:"unknown,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// PlanReport holds proposed changes of a deployment
+type PlanReport struct {
+	Groups []GroupPlan `json:"groups"`
+}
+
+// HasChanges reports whether changes of cloud infrastructure are proposed,
+// groups with unknown changes are not counted, see UnknownGroups
+func (r PlanReport) HasChanges() bool {
+	for _, g := range r.Groups {
+		if len(g.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownGroups returns groups whose changes could not be planned
+func (r PlanReport) UnknownGroups() []config.GroupName {
+	res := []config.GroupName{}
+	for _, g := range r.Groups {
+		if g.Unknown {
+			res = append(res, g.Group)
+		}
+	}
+	return res
+}
+
+// Counts returns the number of changes by action
+func (r PlanReport) Counts() map[string]int {
+	res := map[string]int{}
+	for _, g := range r.Groups {
+		for _, c := range g.Changes {
+			res[c.Action]++
+		}
+	}
+	return res
+}
+
+// PlanGroup runs "terraform plan" in the Terraform working directory without
+// applying it, and returns proposed changes of resources
+func PlanGroup(tf *tfexec.Terraform, group config.GroupName, destroy bool) (GroupPlan, error) {
+	if err := initModule(tf); err != nil {
+		return GroupPlan{}, err
+	}
+
+	f, err := os.CreateTemp("", "plan-")
+	if err != nil {
+		return GroupPlan{}, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if _, err := planModule(tf, f.Name(), destroy, nil); err != nil {
+		return GroupPlan{}, err
+	}
+	plan, err := tf.ShowPlanFile(context.Background(), f.Name())
+	if err != nil {
+		return GroupPlan{}, fmt.Errorf("failed to read the plan of deployment group %s: %w", tf.WorkingDir(), err)
+	}
+	return GroupPlan{Group: group, Changes: PlanChanges(plan)}, nil
+}
+
+// PlanChanges returns changes of resources in the output of "terraform show -json",
+// resources without changes and data sources being read are omitted
+func PlanChanges(plan *tfjson.Plan) []ResourceChange {
+	res := []ResourceChange{}
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		action := changeAction(rc.Change)
+		if action == "" {
+			continue
+		}
+		res = append(res, ResourceChange{Address: rc.Address, Type: rc.Type, Action: action})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Address < res[j].Address })
+	return res
+}
+
+func changeAction(c *tfjson.Change) string {
+	a := c.Actions
+	switch {
+	case a.Replace():
+		return ActionReplace
+	case a.Create():
+		return ActionCreate
+	case a.Update():
+		return ActionUpdate
+	case a.Delete():
+		return ActionDelete
+	case a.Forget():
+		return ActionForget
+	case c.Importing != nil:
+		return ActionImport
+	default: // no-op or read
+		return ""
+	}
+}
+
+// Summary returns counts of changes by action, e.g. "2 to create, 1 to delete"
+func (r PlanReport) Summary() string {
+	counts := r.Counts()
+	parts := []string{}
+	for _, a := range PlanActions {
+		if counts[a] > 0 {
+			parts = append(parts, fmt.Sprintf("%d to %s", counts[a], a))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no changes")
+	}
+	if n := len(r.UnknownGroups()); n == 1 {
+		parts = append(parts, "1 group unknown")
+	} else if n > 1 {
+		parts = append(parts, fmt.Sprintf("%d groups unknown", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Text renders the report as plain text
+func (r PlanReport) Text() string {
+	var b strings.Builder
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "Group %s:\n", g.Group)
+		if g.Unknown {
+			fmt.Fprintf(&b, "  changes are unknown: %s\n", g.Note)
+		} else if len(g.Changes) == 0 {
+			fmt.Fprintln(&b, "  no changes")
+		}
+		for _, c := range g.Changes {
+			fmt.Fprintf(&b, "  %-8s %s\n", c.Action, c.Address)
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %s\n", r.Summary())
+	return b.String()
+}
+
+// Markdown renders the report as Markdown, e.g. for comments on pull requests
+func (r PlanReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Plan: %s\n", r.Summary())
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "\n#### Group `%s`\n\n", g.Group)
+		if g.Unknown {
+			fmt.Fprintf(&b, "Changes are unknown: %s\n", g.Note)
+			continue
+		}
+		if len(g.Changes) == 0 {
+			fmt.Fprintln(&b, "No changes.")
+			continue
+		}
+		fmt.Fprintln(&b, "| Action | Resource |")
+		fmt.Fprintln(&b, "| --- | --- |")
+		for _, c := range g.Changes {
+			fmt.Fprintf(&b, "| %s | `%s` |\n", c.Action, c.Address)
+		}
+	}
+	return b.String()
+}
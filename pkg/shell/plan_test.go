@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shell
+
+import (
+	"encoding/json"
+	"hpc-toolkit/pkg/config"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	. "gopkg.in/check.v1"
+)
+
+const testPlanJson = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "module.vpc.google_compute_network.net", "type": "google_compute_network", "change": {"actions": ["create"]}},
+    {"address": "module.vm.google_compute_instance.vm[0]", "type": "google_compute_instance", "change": {"actions": ["delete", "create"]}},
+    {"address": "module.fs.google_filestore_instance.fs", "type": "google_filestore_instance", "change": {"actions": ["update"]}},
+    {"address": "module.fs.google_storage_bucket.b", "type": "google_storage_bucket", "change": {"actions": ["no-op"]}},
+    {"address": "module.fs.google_compute_disk.d", "type": "google_compute_disk", "change": {"actions": ["no-op"], "importing": {"id": "d"}}},
+    {"address": "data.google_project.p", "type": "google_project", "change": {"actions": ["read"]}},
+    {"address": "module.old.google_compute_address.a", "type": "google_compute_address", "change": {"actions": ["delete"]}}
+  ]
+}`
+
+func (s *MySuite) TestPlanChanges(c *C) {
+	var plan tfjson.Plan
+	c.Assert(json.Unmarshal([]byte(testPlanJson), &plan), IsNil)
+
+	c.Check(PlanChanges(&plan), DeepEquals, []ResourceChange{
+		{"module.fs.google_compute_disk.d", "google_compute_disk", ActionImport},
+		{"module.fs.google_filestore_instance.fs", "google_filestore_instance", ActionUpdate},
+		{"module.old.google_compute_address.a", "google_compute_address", ActionDelete},
+		{"module.vm.google_compute_instance.vm[0]", "google_compute_instance", ActionReplace},
+		{"module.vpc.google_compute_network.net", "google_compute_network", ActionCreate},
+	})
+}
+
+func (s *MySuite) TestPlanReport(c *C) {
+	empty := PlanReport{Groups: []GroupPlan{{Group: "primary", Changes: []ResourceChange{}}}}
+	c.Check(empty.HasChanges(), Equals, false)
+	c.Check(empty.Summary(), Equals, "no changes")
+	c.Check(empty.Text(), Equals, "Group primary:\n  no changes\nPlan: no changes\n")
+
+	r := PlanReport{Groups: []GroupPlan{
+		{Group: "primary", Changes: []ResourceChange{
+			{"module.a.google_compute_network.net", "google_compute_network", ActionCreate},
+			{"module.b.google_compute_address.a", "google_compute_address", ActionDelete},
+		}},
+		{Group: "image", Unknown: true, Note: "packer groups are not planned"},
+	}}
+	c.Check(r.HasChanges(), Equals, true)
+	c.Check(r.UnknownGroups(), DeepEquals, []config.GroupName{"image"})
+	c.Check(r.Summary(), Equals, "1 to create, 1 to delete, 1 group unknown")
+	c.Check(r.Text(), Equals, `Group primary:
+  create   module.a.google_compute_network.net
+  delete   module.b.google_compute_address.a
+Group image:
+  changes are unknown: packer groups are not planned
+Plan: 1 to create, 1 to delete, 1 group unknown
+`)
+	md := r.Markdown()
+	c.Check(strings.HasPrefix(md, "### Plan: 1 to create, 1 to delete, 1 group unknown\n"), Equals, true)
+	c.Check(strings.Contains(md, "| create | `module.a.google_compute_network.net` |\n"), Equals, true)
+
+	// Groups that couldn't be planned are reported apart from changes
+	unknown := PlanReport{Groups: []GroupPlan{{Group: "image", Unknown: true}, {Group: "cluster", Unknown: true}}}
+	c.Check(unknown.HasChanges(), Equals, false)
+	c.Check(unknown.Summary(), Equals, "no changes, 2 groups unknown")
+}
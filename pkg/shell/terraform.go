@@ -22,17 +22,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/events"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/modulereader"
 	"hpc-toolkit/pkg/modulewriter"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
@@ -114,6 +118,51 @@ func initModule(tf *tfexec.Terraform) error {
 	return err
 }
 
+// MigrateState initializes the deployment group copying existing state into
+// the backend configured in the group, e.g. after the backend was changed
+// from local state to a Cloud Storage bucket.
+func MigrateState(tf *tfexec.Terraform) error {
+	logging.Info("Migrating state of deployment group %s", tf.WorkingDir())
+	if err := tf.Init(context.Background(), tfexec.ForceCopy(true)); err != nil {
+		return config.HintError{
+			Hint: fmt.Sprintf("migration of state of deployment group %s failed; run `terraform init -migrate-state` manually", tf.WorkingDir()),
+			Err:  err}
+	}
+	return nil
+}
+
+// StateAttributes returns values of the attribute of resources of the type in
+// the Terraform state of the deployment group, e.g. names of its networks
+func StateAttributes(tf *tfexec.Terraform, resourceType string, attribute string) ([]string, error) {
+	if err := initModule(tf); err != nil {
+		return nil, err
+	}
+	state, err := tf.Show(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the state of deployment group %s: %w", tf.WorkingDir(), err)
+	}
+	if state.Values == nil {
+		return []string{}, nil
+	}
+	return moduleAttributes(state.Values.RootModule, resourceType, attribute), nil
+}
+
+func moduleAttributes(m *tfjson.StateModule, resourceType string, attribute string) []string {
+	res := []string{}
+	if m == nil {
+		return res
+	}
+	for _, r := range m.Resources {
+		if v, ok := r.AttributeValues[attribute].(string); ok && r.Type == resourceType && r.Mode == tfjson.ManagedResourceMode {
+			res = append(res, v)
+		}
+	}
+	for _, c := range m.ChildModules {
+		res = append(res, moduleAttributes(c, resourceType, attribute)...)
+	}
+	return res
+}
+
 func outputModule(tf *tfexec.Terraform) (map[string]cty.Value, error) {
 	logging.Info("Collecting terraform outputs from %s", tf.WorkingDir())
 	output, err := tf.Output(context.Background())
@@ -150,9 +199,19 @@ type Diagnostic struct {
 	Detail   string `json:"detail"`
 }
 
+// See https://developer.hashicorp.com/terraform/internals/machine-readable-ui#change-summary
+type ChangeSummary struct {
+	Add    int `json:"add"`
+	Change int `json:"change"`
+	Import int `json:"import"`
+	Remove int `json:"remove"`
+}
+
 type JsonMessage struct {
-	Level      string     `json:"@level"`
-	Diagnostic Diagnostic `json:"diagnostic"`
+	Level      string         `json:"@level"`
+	Type       string         `json:"type"`
+	Diagnostic Diagnostic     `json:"diagnostic"`
+	Changes    *ChangeSummary `json:"changes"`
 }
 
 func parseJsonMessages(data string) []JsonMessage {
@@ -183,16 +242,19 @@ func helpOnPlanError(msgs []JsonMessage) string {
 	}
 }
 
-func planModule(tf *tfexec.Terraform, path string, destroy bool) (bool, error) {
-	outOpt := tfexec.Out(path)
+func planModule(tf *tfexec.Terraform, path string, destroy bool, targets []string) (bool, error) {
+	opts := []tfexec.PlanOption{tfexec.Destroy(destroy)}
+	for _, t := range targets {
+		opts = append(opts, tfexec.Target(t))
+	}
 	var jsonOut strings.Builder
-	wantsChange, err := tf.PlanJSON(context.Background(), &jsonOut, outOpt, tfexec.Destroy(destroy))
+	wantsChange, err := tf.PlanJSON(context.Background(), &jsonOut, append(opts, tfexec.Out(path))...)
 	if err != nil {
 		// Invoke `Plan` to get human-readable error.
 		// TODO: implement rendering to avoid double-call.
 		// Note planned deprecration of Plan in favor of JSON-only format
 		// https://github.com/hashicorp/terraform-exec/blob/1b7714111a94813e92936051fb3014fec81218d5/tfexec/plan.go#L128-L129
-		_, plainError := tf.Plan(context.Background(), tfexec.Destroy(destroy))
+		_, plainError := tf.Plan(context.Background(), opts...)
 		if plainError == nil { // shouldn't happen
 			plainError = err // fallback to original error (simple `exit status 1`)
 		}
@@ -204,6 +266,15 @@ func planModule(tf *tfexec.Terraform, path string, destroy bool) (bool, error) {
 		return false, config.HintError{Hint: msg, Err: plainError}
 	}
 
+	if events.Enabled() {
+		for _, m := range parseJsonMessages(jsonOut.String()) {
+			if m.Type == "change_summary" && m.Changes != nil {
+				events.EmitGroup(events.PlanSummary, filepath.Base(tf.WorkingDir()), "", map[string]any{
+					"add": m.Changes.Add, "change": m.Changes.Change, "import": m.Changes.Import, "remove": m.Changes.Remove,
+					"destroy": destroy})
+			}
+		}
+	}
 	return wantsChange, nil
 }
 
@@ -223,10 +294,14 @@ func promptForApply(tf *tfexec.Terraform, path string, b ApplyBehavior) bool {
 		if summary == "" {
 			summary = fmt.Sprintf("Please review full proposed changes for deployment group %s", tf.WorkingDir())
 		}
+		if PrefixGroupOutput {
+			summary = fmt.Sprintf("[%s] %s", filepath.Base(tf.WorkingDir()), summary)
+		}
 
 		changes := ProposedChanges{
 			Summary: summary,
 			Full:    plan,
+			Group:   filepath.Base(tf.WorkingDir()),
 		}
 
 		return ApplyChangesChoice(changes)
@@ -251,8 +326,8 @@ func applyPlanJsonOutput(tf *tfexec.Terraform, path string) error {
 		return err
 	} else {
 		defer jsonFile.Close()
-		tf.SetStdout(newTimestampWriter(os.Stdout))
-		tf.SetStderr(newTimestampWriter(os.Stderr))
+		tf.SetStdout(newDirWriter(os.Stdout, tf.WorkingDir()))
+		tf.SetStderr(newDirWriter(os.Stderr, tf.WorkingDir()))
 		if err := tf.ApplyJSON(context.Background(), jsonFile, planFileOpt); err != nil {
 			return err
 		}
@@ -265,8 +340,8 @@ func applyPlanJsonOutput(tf *tfexec.Terraform, path string) error {
 func applyPlanConsoleOutput(tf *tfexec.Terraform, path string) error {
 	planFileOpt := tfexec.DirOrPlan(path)
 	logging.Info("Running terraform apply on deployment group %s", tf.WorkingDir())
-	tf.SetStdout(newTimestampWriter(os.Stdout))
-	tf.SetStderr(newTimestampWriter(os.Stderr))
+	tf.SetStdout(newDirWriter(os.Stdout, tf.WorkingDir()))
+	tf.SetStderr(newDirWriter(os.Stderr, tf.WorkingDir()))
 	if err := tf.Apply(context.Background(), planFileOpt); err != nil {
 		return err
 	}
@@ -278,7 +353,10 @@ func applyPlanConsoleOutput(tf *tfexec.Terraform, path string) error {
 // generate a Terraform plan to apply or destroy a module
 // recall "destroy" is just an alias for "apply -destroy"!
 // apply the plan automatically or after prompting the user
-func applyOrDestroy(tf *tfexec.Terraform, b ApplyBehavior, of OutputFormat, destroy bool) error {
+// only the targeted Terraform modules are planned, if any
+// returns whether the cloud infrastructure matches the module afterwards,
+// i.e. false if the user declined to apply proposed changes
+func applyOrDestroy(tf *tfexec.Terraform, b ApplyBehavior, of OutputFormat, destroy bool, targets []string) (bool, error) {
 	action := "adding or changing"
 	pastTense := "applied"
 	if destroy {
@@ -287,60 +365,58 @@ func applyOrDestroy(tf *tfexec.Terraform, b ApplyBehavior, of OutputFormat, dest
 	}
 
 	if err := initModule(tf); err != nil {
-		return err
+		return false, err
 	}
 
 	logging.Info("Testing if deployment group %s requires %s cloud infrastructure", tf.WorkingDir(), action)
 	// capture Terraform plan in a file
 	f, err := os.CreateTemp("", "plan-)")
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer os.Remove(f.Name())
-	wantsChange, err := planModule(tf, f.Name(), destroy)
+	wantsChange, err := planModule(tf, f.Name(), destroy, targets)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	var apply bool
-	if wantsChange {
-		logging.Info("Deployment group %s requires %s cloud infrastructure", tf.WorkingDir(), action)
-		apply = b == AutomaticApply || promptForApply(tf, f.Name(), b)
-	} else {
+	if !wantsChange {
 		logging.Info("Cloud infrastructure in deployment group %s is already %s", tf.WorkingDir(), pastTense)
+		return true, nil
 	}
 
-	if !apply {
-		return nil
+	logging.Info("Deployment group %s requires %s cloud infrastructure", tf.WorkingDir(), action)
+	if b != AutomaticApply && !promptForApply(tf, f.Name(), b) {
+		return false, nil
 	}
 
 	switch of {
 	case JsonOutput:
 		if err := applyPlanJsonOutput(tf, f.Name()); err != nil {
-			return err
+			return false, err
 		}
 	case TextOutput: // Text output to the console is also the default choice
 		if err := applyPlanConsoleOutput(tf, f.Name()); err != nil {
-			return err
+			return false, err
 		}
 	default:
 		panic("Unknown output format requested")
 	}
 
-	return nil
+	return true, nil
 }
 
-func getOutputs(tf *tfexec.Terraform, b ApplyBehavior, o OutputFormat) (map[string]cty.Value, error) {
-	err := applyOrDestroy(tf, b, o, false)
+func getOutputs(tf *tfexec.Terraform, b ApplyBehavior, o OutputFormat, targets []string) (map[string]cty.Value, bool, error) {
+	applied, err := applyOrDestroy(tf, b, o, false, targets)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	outputValues, err := outputModule(tf)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return outputValues, nil
+	return outputValues, applied, nil
 }
 
 func outputsFile(artifactsDir string, group config.GroupName) string {
@@ -350,12 +426,21 @@ func outputsFile(artifactsDir string, group config.GroupName) string {
 // ExportOutputs will run terraform output and capture data needed for
 // subsequent deployment groups
 func ExportOutputs(tf *tfexec.Terraform, artifactsDir string, applyBehavior ApplyBehavior, o OutputFormat) error {
+	_, err := ApplyAndExportOutputs(tf, artifactsDir, applyBehavior, o)
+	return err
+}
+
+// ApplyAndExportOutputs behaves like ExportOutputs and additionally reports
+// whether the cloud infrastructure matches the deployment group, that is false
+// if the user declined to apply proposed changes. Only changes of targeted
+// resources, e.g. "module.<id>", are applied if targets are given.
+func ApplyAndExportOutputs(tf *tfexec.Terraform, artifactsDir string, applyBehavior ApplyBehavior, o OutputFormat, targets ...string) (bool, error) {
 	thisGroup := config.GroupName(filepath.Base(tf.WorkingDir()))
 	filepath := outputsFile(artifactsDir, thisGroup)
 
-	outputValues, err := getOutputs(tf, applyBehavior, o)
+	outputValues, applied, err := getOutputs(tf, applyBehavior, o, targets)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// TODO: confirm that outputValues has keys we would expect from the
@@ -363,15 +448,17 @@ func ExportOutputs(tf *tfexec.Terraform, artifactsDir string, applyBehavior Appl
 	// whose values are null
 	if len(outputValues) == 0 {
 		logging.Info("Deployment group %s contains no artifacts to export", thisGroup)
-		return nil
+		return applied, nil
 	}
 
 	logging.Info("Writing outputs artifact from deployment group %s to file %s", thisGroup, filepath)
 	if err := modulewriter.WriteHclAttributes(outputValues, filepath); err != nil {
-		return err
+		return false, err
 	}
+	names := slices.Sorted(maps.Keys(outputValues))
+	events.EmitGroup(events.OutputsExported, string(thisGroup), "", map[string]any{"file": filepath, "outputs": names})
 
-	return nil
+	return applied, nil
 }
 
 // for each prior group, read all output values and filter for those needed as input values to this group
@@ -471,12 +558,18 @@ func ImportInputs(groupDir string, artifactsDir string, bp config.Blueprint) err
 
 	outPath := filepath.Join(groupDir, outFile)
 	logging.Info("Writing outputs for deployment group %s to file %s", g.Name, outPath)
-	return modulewriter.WriteHclAttributes(toImport, outPath)
+	if err := modulewriter.WriteHclAttributes(toImport, outPath); err != nil {
+		return err
+	}
+	events.EmitGroup(events.InputsImported, string(g.Name), "", map[string]any{"file": outPath})
+	return nil
 }
 
-// Destroy destroys all infrastructure in the module working directory
-func Destroy(tf *tfexec.Terraform, b ApplyBehavior, o OutputFormat) error {
-	return applyOrDestroy(tf, b, o, true)
+// Destroy destroys all infrastructure in the module working directory, or only
+// targeted resources and resources depending on them if targets are given
+func Destroy(tf *tfexec.Terraform, b ApplyBehavior, o OutputFormat, targets ...string) error {
+	_, err := applyOrDestroy(tf, b, o, true, targets)
+	return err
 }
 
 func TfVersion() (string, error) {
@@ -21,6 +21,7 @@ import (
 	"os/exec"
 	"testing"
 
+	tfjson "github.com/hashicorp/terraform-json"
 	. "gopkg.in/check.v1"
 )
 
@@ -50,3 +51,22 @@ func (s *MySuite) TestFindTerraform(c *C) {
 	os.Setenv("PATH", pathEnv)
 	c.Assert(err, NotNil)
 }
+
+func (s *MySuite) TestModuleAttributes(c *C) {
+	net := func(name string) *tfjson.StateResource {
+		return &tfjson.StateResource{Type: "google_compute_network", Mode: tfjson.ManagedResourceMode,
+			AttributeValues: map[string]interface{}{"name": name}}
+	}
+	root := &tfjson.StateModule{
+		Resources: []*tfjson.StateResource{
+			{Type: "google_compute_network", Mode: tfjson.DataResourceMode, AttributeValues: map[string]interface{}{"name": "default"}},
+			{Type: "google_compute_subnetwork", Mode: tfjson.ManagedResourceMode, AttributeValues: map[string]interface{}{"name": "subnet"}},
+		},
+		ChildModules: []*tfjson.StateModule{
+			{Resources: []*tfjson.StateResource{net("hpc-net")}},
+			{ChildModules: []*tfjson.StateModule{{Resources: []*tfjson.StateResource{net("gke-net")}}}},
+		},
+	}
+	c.Check(moduleAttributes(root, "google_compute_network", "name"), DeepEquals, []string{"hpc-net", "gke-net"})
+	c.Check(moduleAttributes(nil, "google_compute_network", "name"), DeepEquals, []string{})
+}
@@ -20,13 +20,19 @@ import (
 	"bytes"
 	"hpc-toolkit/pkg/logging"
 	"io"
+	"path/filepath"
 	"sync"
 	"time"
 )
 
+// PrefixGroupOutput enables prefixing of Terraform and Packer output lines with
+// the name of the working directory, e.g. when deployment groups run concurrently
+var PrefixGroupOutput = false
+
 type timestampWriter struct {
 	writer      io.Writer
 	startOfLine bool
+	prefix      string // added to every non-empty line
 	mu          sync.Mutex
 }
 
@@ -37,6 +43,15 @@ func newTimestampWriter(writer io.Writer) io.Writer {
 	}
 }
 
+// newDirWriter returns a timestamp writer, prefixed by the directory name if PrefixGroupOutput is set
+func newDirWriter(writer io.Writer, dir string) io.Writer {
+	w := newTimestampWriter(writer).(*timestampWriter)
+	if PrefixGroupOutput {
+		w.prefix = "[" + filepath.Base(dir) + "] "
+	}
+	return w
+}
+
 func (w *timestampWriter) Write(p []byte) (n int, err error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -81,6 +96,9 @@ func (w *timestampWriter) writeSegment(buf *bytes.Buffer, p []byte) {
 				coloredTs := logging.TsColor.Sprint(ts)
 				buf.WriteString(coloredTs + " ")
 			}
+			if p[0] != '\n' {
+				buf.WriteString(w.prefix)
+			}
 		}
 	}
 	buf.Write(p)
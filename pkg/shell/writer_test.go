@@ -19,6 +19,7 @@ package shell
 import (
 	"bytes"
 	"regexp"
+	"strings"
 
 	. "gopkg.in/check.v1"
 )
@@ -50,3 +51,17 @@ func (s *MySuite) TestTimestampWriter(c *C) {
 	// Since we wrote 3 lines, we expect 3 matches
 	c.Assert(len(matches), Equals, 3)
 }
+
+func (s *MySuite) TestDirWriterPrefix(c *C) {
+	defer func(p bool) { PrefixGroupOutput = p }(PrefixGroupOutput)
+	PrefixGroupOutput = true
+
+	var buf bytes.Buffer
+	w := newDirWriter(&buf, "/deployment/primary")
+	w.Write([]byte("Apply complete!\n  indented\n\n"))
+
+	lines := strings.Split(buf.String(), "\n")
+	c.Check(regexp.MustCompile(`^\S+ \[primary\] Apply complete!$`).MatchString(lines[0]), Equals, true)
+	c.Check(lines[1], Equals, "[primary]   indented")
+	c.Check(lines[2], Equals, "")
+}
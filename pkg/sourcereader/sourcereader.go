@@ -55,8 +55,7 @@ func Factory(modPath string) SourceReader {
 }
 
 func copyFromPath(modPath string, copyPath string) error {
-	// currently supporting only local blueprint directory
-	deploymentio := deploymentio.GetDeploymentioLocal()
+	deploymentio := deploymentio.Get(copyPath)
 
 	if err := deploymentio.CreateDirectory(copyPath); err != nil {
 		return err
@@ -0,0 +1,392 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/modulereader"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/api/compute/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// Pricing scenarios of an estimate
+const (
+	ScenarioOnDemand    = "on_demand"
+	ScenarioSpot        = "spot"
+	ScenarioReservation = "reservation"
+)
+
+//go:embed pricing.yaml
+var bundledPricing []byte
+
+// Price of a unit of a resource, see pricing.yaml
+type Price struct {
+	OnDemand    float64  `yaml:"on_demand"`
+	Spot        *float64 `yaml:"spot"`
+	Reservation *float64 `yaml:"reservation"`
+}
+
+// PriceTable holds prices of machine types and of quota metrics
+type PriceTable struct {
+	Currency            string           `yaml:"currency"`
+	HoursPerMonth       float64          `yaml:"hours_per_month"`
+	ReservationDiscount float64          `yaml:"reservation_discount"`
+	MachineTypes        map[string]Price `yaml:"machine_types"`
+	Hourly              map[string]Price `yaml:"hourly"`
+	Monthly             map[string]Price `yaml:"monthly"`
+}
+
+// Costs of a resource per hour in every pricing scenario
+type Costs struct {
+	OnDemand    float64 `json:"on_demand"`
+	Spot        float64 `json:"spot"`
+	Reservation float64 `json:"reservation"`
+}
+
+// Scenario returns the cost of the scenario
+func (c Costs) Scenario(s string) float64 {
+	switch s {
+	case ScenarioSpot:
+		return c.Spot
+	case ScenarioReservation:
+		return c.Reservation
+	default:
+		return c.OnDemand
+	}
+}
+
+func (c Costs) add(o Costs) Costs {
+	return Costs{OnDemand: c.OnDemand + o.OnDemand, Spot: c.Spot + o.Spot, Reservation: c.Reservation + o.Reservation}
+}
+
+func (c Costs) scale(f float64) Costs {
+	return Costs{OnDemand: c.OnDemand * f, Spot: c.Spot * f, Reservation: c.Reservation * f}
+}
+
+// CostItem is a priced resource of a module
+type CostItem struct {
+	// machine type or quota metric
+	Resource string  `json:"resource"`
+	Quantity float64 `json:"quantity"`
+	Hourly   Costs   `json:"hourly"`
+}
+
+// ModuleCost is the estimated cost of a module
+type ModuleCost struct {
+	Module string  `json:"module"`
+	Count  float64 `json:"count"`
+	// scenario configured by module settings, e.g. spot
+	Scenario   string     `json:"scenario"`
+	Items      []CostItem `json:"items"`
+	Hourly     Costs      `json:"hourly"`
+	Configured float64    `json:"configured_hourly"`
+	Notes      []string   `json:"notes,omitempty"`
+}
+
+// GroupCost is the estimated cost of a deployment group
+type GroupCost struct {
+	Group      string       `json:"group"`
+	Modules    []ModuleCost `json:"modules"`
+	Hourly     Costs        `json:"hourly"`
+	Configured float64      `json:"configured_hourly"`
+}
+
+// CostEstimate is the estimated cost of a blueprint, monthly costs are the
+// hourly ones multiplied by HoursPerMonth
+type CostEstimate struct {
+	Currency      string      `json:"currency"`
+	HoursPerMonth float64     `json:"hours_per_month"`
+	Groups        []GroupCost `json:"groups"`
+	Hourly        Costs       `json:"hourly"`
+	Configured    float64     `json:"configured_hourly"`
+}
+
+func parsePriceTable(data []byte) (PriceTable, error) {
+	var t PriceTable
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return PriceTable{}, err
+	}
+	return t, nil
+}
+
+// BundledPriceTable returns the pricing table shipped with gcluster
+func BundledPriceTable() PriceTable {
+	t, err := parsePriceTable(bundledPricing)
+	if err != nil {
+		panic(fmt.Errorf("bundled pricing table is malformed: %w", err))
+	}
+	return t
+}
+
+// LoadPriceTable reads the pricing file, its entries override entries
+// of the bundled table.
+func LoadPriceTable(path string) (PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PriceTable{}, err
+	}
+	o, err := parsePriceTable(data)
+	if err != nil {
+		return PriceTable{}, fmt.Errorf("failed to parse pricing file %q: %w", path, err)
+	}
+	t := BundledPriceTable()
+	if o.Currency != "" {
+		t.Currency = o.Currency
+	}
+	if o.HoursPerMonth != 0 {
+		t.HoursPerMonth = o.HoursPerMonth
+	}
+	if o.ReservationDiscount != 0 {
+		t.ReservationDiscount = o.ReservationDiscount
+	}
+	for _, m := range []struct{ dst, src map[string]Price }{
+		{t.MachineTypes, o.MachineTypes}, {t.Hourly, o.Hourly}, {t.Monthly, o.Monthly}} {
+		for k, v := range m.src {
+			m.dst[k] = v
+		}
+	}
+	return t, nil
+}
+
+func (t PriceTable) costs(p Price, quantity float64) Costs {
+	c := Costs{OnDemand: p.OnDemand, Spot: p.OnDemand, Reservation: p.OnDemand * (1 - t.ReservationDiscount)}
+	if p.Spot != nil {
+		c.Spot = *p.Spot
+	}
+	if p.Reservation != nil {
+		c.Reservation = *p.Reservation
+	}
+	return c.scale(quantity)
+}
+
+// offlineMachineTypes is a QuotaClient that derives vCPUs of predefined
+// machine types from their names, e.g. 32 of n2-standard-32, so that
+// estimates need no access to the Compute Engine API.
+type offlineMachineTypes struct{}
+
+func (offlineMachineTypes) GetProject(projectID string) (*compute.Project, error) {
+	return nil, fmt.Errorf("project %s is not available offline", projectID)
+}
+
+func (offlineMachineTypes) GetRegion(projectID, region string) (*compute.Region, error) {
+	return nil, fmt.Errorf("region %s is not available offline", region)
+}
+
+func (offlineMachineTypes) GetMachineType(projectID, zone, machineType string) (*compute.MachineType, error) {
+	cpus, err := strconv.ParseInt(machineType[strings.LastIndex(machineType, "-")+1:], 10, 64)
+	if err != nil || cpus <= 0 {
+		return nil, fmt.Errorf("number of vCPUs of %s is unknown", machineType)
+	}
+	return &compute.MachineType{Name: machineType, GuestCpus: cpus}, nil
+}
+
+// EstimateCosts estimates hourly costs of modules of the blueprint using
+// the same extraction of machine types, accelerators, disks and node counts
+// as the quota validator. It needs no access to Google Cloud APIs.
+func EstimateCosts(bp config.Blueprint, t PriceTable) CostEstimate {
+	defaultRegion := ""
+	if r, err := evalString(bp, bp.Vars.Get("region")); err == nil {
+		defaultRegion = r
+	}
+
+	est := CostEstimate{Currency: t.Currency, HoursPerMonth: t.HoursPerMonth, Groups: []GroupCost{}}
+	for _, g := range bp.Groups {
+		gc := GroupCost{Group: string(g.Name), Modules: []ModuleCost{}}
+		for im := range g.Modules {
+			m := &g.Modules[im]
+			if m.Kind == config.PackerKind {
+				continue // images are built once, the build is not running infrastructure
+			}
+			mc := estimateModule(bp, m, t, defaultRegion)
+			if len(mc.Items) == 0 && len(mc.Notes) == 0 {
+				continue
+			}
+			gc.Modules = append(gc.Modules, mc)
+			gc.Hourly = gc.Hourly.add(mc.Hourly)
+			gc.Configured += mc.Configured
+		}
+		if len(gc.Modules) == 0 {
+			continue
+		}
+		est.Groups = append(est.Groups, gc)
+		est.Hourly = est.Hourly.add(gc.Hourly)
+		est.Configured += gc.Configured
+	}
+	return est
+}
+
+func estimateModule(bp config.Blueprint, m *config.Module, t PriceTable, defaultRegion string) ModuleCost {
+	settings := withInputDefaults(m)
+	mc := ModuleCost{Module: string(m.ID), Items: []CostItem{}, Scenario: ScenarioOnDemand}
+	if isReservationUsed(bp, settings) {
+		mc.Scenario = ScenarioReservation
+	} else if checkSpotSettings(bp, settings) {
+		mc.Scenario = ScenarioSpot
+	}
+
+	zone, region := getModuleLocation(bp, settings, defaultRegion)
+	if region == "" {
+		region = "global" // prices do not depend on the region
+	}
+	if zone == "" {
+		zone = region + "-a"
+	}
+	mc.Count = estimateNodeCount(bp, string(m.ID), settings)
+	if mc.Count == 0 {
+		if settings.Has("machine_type") {
+			mc.Notes = append(mc.Notes, "number of nodes is unknown, not estimated")
+		}
+		return mc
+	}
+
+	totals := map[string]float64{}
+	if settings.Has("machine_type") {
+		if mt, err := evalString(bp, settings.Get("machine_type")); err == nil && mt != "" {
+			if p, ok := t.MachineTypes[mt]; ok {
+				mc.Items = append(mc.Items, CostItem{Resource: mt, Quantity: mc.Count, Hourly: t.costs(p, mc.Count)})
+			} else if _, err := (offlineMachineTypes{}).GetMachineType("", zone, mt); err == nil {
+				before := len(totals)
+				addVMQuota(bp, offlineMachineTypes{}, settings, "", region, zone, mc.Count, totals, string(m.ID))
+				if len(totals) == before {
+					mc.Notes = append(mc.Notes, fmt.Sprintf("no price for machine type %s", mt))
+				}
+			} else {
+				mc.Notes = append(mc.Notes, fmt.Sprintf("no price for machine type %s", mt))
+			}
+		}
+	}
+	addGPUMetrics(guestAccelerators(bp, settings), "", mc.Count, "", region, totals)
+	addDiskQuota(bp, settings, "", region, mc.Count, totals)
+	addFilestoreQuota(bp, settings, "", region, mc.Count, totals)
+	addTPUQuota(bp, settings, "", region, mc.Count, totals)
+
+	metrics := map[string]float64{}
+	for key, val := range totals {
+		metric := key[strings.LastIndex(key, "/")+1:]
+		if metric == "GPUS_ALL_REGIONS" {
+			continue // counted by the GPU metric of the model
+		}
+		// scenarios are priced regardless of the provisioning model
+		metrics[strings.TrimPrefix(metric, "PREEMPTIBLE_")] += val
+	}
+	names := make([]string, 0, len(metrics))
+	for n := range metrics {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		q := metrics[n]
+		if p, ok := t.Hourly[n]; ok {
+			mc.Items = append(mc.Items, CostItem{Resource: n, Quantity: q, Hourly: t.costs(p, q)})
+		} else if p, ok := t.Monthly[n]; ok && t.HoursPerMonth > 0 {
+			mc.Items = append(mc.Items, CostItem{Resource: n, Quantity: q, Hourly: t.costs(p, q/t.HoursPerMonth)})
+		} else {
+			mc.Notes = append(mc.Notes, fmt.Sprintf("no price for %s", n))
+		}
+	}
+
+	for _, it := range mc.Items {
+		mc.Hourly = mc.Hourly.add(it.Hourly)
+	}
+	mc.Configured = mc.Hourly.Scenario(mc.Scenario)
+	return mc
+}
+
+// estimateNodeCount extends getModuleCount with node counts of GKE node
+// pools, derived from the TPU topology if not set explicitly
+func estimateNodeCount(bp config.Blueprint, moduleID string, settings config.Dict) float64 {
+	if settings.Has("static_node_count") {
+		if v, err := evalToFloat64(bp, settings.Get("static_node_count")); err == nil {
+			return v
+		}
+	}
+	if settings.Has("tpu_topology") && settings.Has("machine_type") {
+		mt, errM := evalString(bp, settings.Get("machine_type"))
+		topo, errT := evalString(bp, settings.Get("tpu_topology"))
+		if errM == nil && errT == nil {
+			if n, err := config.CalculateAcceleratorNodes(mt, topo, 0); err == nil {
+				return float64(n)
+			}
+		}
+	}
+	return getModuleCount(bp, moduleID, settings)
+}
+
+// withInputDefaults adds defaults of module inputs missing in settings,
+// e.g. the default machine type of a node pool. Module info is read during
+// expansion, so this needs no downloads.
+func withInputDefaults(m *config.Module) config.Dict {
+	settings := m.Settings
+	if m.Kind != config.TerraformKind {
+		return settings
+	}
+	mi, err := modulereader.GetModuleInfo(m.Source, m.Kind.String())
+	if err != nil {
+		return settings
+	}
+	for _, in := range mi.Inputs {
+		if settings.Has(in.Name) {
+			continue
+		}
+		switch d := in.Default.(type) {
+		case string:
+			settings = settings.With(in.Name, cty.StringVal(d))
+		case bool:
+			settings = settings.With(in.Name, cty.BoolVal(d))
+		case int:
+			settings = settings.With(in.Name, cty.NumberIntVal(int64(d)))
+		case float64:
+			settings = settings.With(in.Name, cty.NumberFloatVal(d))
+		}
+	}
+	return settings
+}
+
+// guestAccelerators reads GPUs attached to VMs by the `guest_accelerator` setting
+func guestAccelerators(bp config.Blueprint, settings config.Dict) []*compute.MachineTypeAccelerators {
+	if !settings.Has("guest_accelerator") {
+		return nil
+	}
+	v, err := bp.Eval(settings.Get("guest_accelerator"))
+	if err != nil || !v.IsKnown() || v.IsNull() || !(v.Type().IsListType() || v.Type().IsTupleType()) {
+		return nil
+	}
+	res := []*compute.MachineTypeAccelerators{}
+	for it := v.ElementIterator(); it.Next(); {
+		_, e := it.Element()
+		if !e.Type().IsObjectType() || !e.Type().HasAttribute("type") || !e.Type().HasAttribute("count") {
+			continue
+		}
+		typ, cnt := e.GetAttr("type"), e.GetAttr("count")
+		if !typ.IsKnown() || typ.IsNull() || typ.Type() != cty.String || !cnt.IsKnown() || cnt.IsNull() {
+			continue
+		}
+		c, err := float64FromVal(cnt)
+		if err != nil {
+			continue
+		}
+		res = append(res, &compute.MachineTypeAccelerators{GuestAcceleratorType: typ.AsString(), GuestAcceleratorCount: int64(c)})
+	}
+	return res
+}
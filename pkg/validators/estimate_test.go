@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hpc-toolkit/pkg/config"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func ptr(f float64) *float64 { return &f }
+
+func testPriceTable() PriceTable {
+	return PriceTable{
+		Currency:            "USD",
+		HoursPerMonth:       730,
+		ReservationDiscount: 0.5,
+		MachineTypes: map[string]Price{
+			"a3-highgpu-8g": {OnDemand: 80, Spot: ptr(20)},
+		},
+		Hourly: map[string]Price{
+			"N2_CPUS":        {OnDemand: 0.05, Spot: ptr(0.01)},
+			"NVIDIA_T4_GPUS": {OnDemand: 0.35},
+		},
+		Monthly: map[string]Price{
+			"SSD_TOTAL_GB": {OnDemand: 0.146},
+		},
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestEstimateCosts(t *testing.T) {
+	bp := config.Blueprint{
+		Vars: config.NewDict(map[string]cty.Value{"region": cty.StringVal("us-central1")}),
+		Groups: []config.Group{
+			{Name: "primary", Modules: []config.Module{
+				{ID: "network", Source: "modules/network/vpc"},
+				{ID: "gpu", Settings: config.NewDict(map[string]cty.Value{
+					"machine_type":   cty.StringVal("a3-highgpu-8g"),
+					"node_count":     cty.NumberIntVal(2),
+					"enable_spot_vm": cty.True,
+				})},
+				{ID: "cpu", Settings: config.NewDict(map[string]cty.Value{
+					"machine_type":      cty.StringVal("n2-standard-32"),
+					"node_count_static": cty.NumberIntVal(4),
+					"disk_size_gb":      cty.NumberIntVal(100),
+					"disk_type":         cty.StringVal("pd-ssd"),
+					"guest_accelerator": cty.TupleVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+						"type":  cty.StringVal("nvidia-tesla-t4"),
+						"count": cty.NumberIntVal(1),
+					})}),
+				})},
+				{ID: "mystery", Settings: config.NewDict(map[string]cty.Value{
+					"machine_type": cty.StringVal("x9-custom"),
+				})},
+			}},
+			{Name: "image", Modules: []config.Module{
+				{ID: "builder", Kind: config.PackerKind, Settings: config.NewDict(map[string]cty.Value{
+					"machine_type": cty.StringVal("a3-highgpu-8g"),
+				})},
+			}},
+		},
+	}
+
+	est := EstimateCosts(bp, testPriceTable())
+	if len(est.Groups) != 1 {
+		t.Fatalf("want only primary group, got %#v", est.Groups)
+	}
+	mods := est.Groups[0].Modules
+	if len(mods) != 3 || mods[0].Module != "gpu" || mods[1].Module != "cpu" || mods[2].Module != "mystery" {
+		t.Fatalf("unexpected modules %#v", mods)
+	}
+
+	gpu := mods[0]
+	if gpu.Scenario != ScenarioSpot || !near(gpu.Hourly.OnDemand, 160) || !near(gpu.Hourly.Spot, 40) || !near(gpu.Hourly.Reservation, 80) {
+		t.Errorf("unexpected gpu module cost %#v", gpu)
+	}
+	if !near(gpu.Configured, 40) {
+		t.Errorf("want configured spot cost 40, got %v", gpu.Configured)
+	}
+
+	cpu := mods[1]
+	// 4 VMs: 32 vCPUs, 1 T4 and 100 GB of pd-ssd each
+	wantOnDemand := 4*32*0.05 + 4*0.35 + 400*0.146/730
+	if cpu.Scenario != ScenarioOnDemand || !near(cpu.Hourly.OnDemand, wantOnDemand) {
+		t.Errorf("want on-demand cost %v, got %#v", wantOnDemand, cpu)
+	}
+	wantSpot := 4*32*0.01 + 4*0.35 + 400*0.146/730
+	if !near(cpu.Hourly.Spot, wantSpot) {
+		t.Errorf("want spot cost %v, got %v", wantSpot, cpu.Hourly.Spot)
+	}
+	if len(cpu.Items) != 3 {
+		t.Errorf("want 3 items, got %#v", cpu.Items)
+	}
+
+	if len(mods[2].Notes) != 1 || len(mods[2].Items) != 0 {
+		t.Errorf("want note about missing price, got %#v", mods[2])
+	}
+
+	if !near(est.Configured, 40+wantOnDemand) {
+		t.Errorf("want configured total %v, got %v", 40+wantOnDemand, est.Configured)
+	}
+}
+
+func TestEstimateNodeCountFromTopology(t *testing.T) {
+	settings := config.NewDict(map[string]cty.Value{
+		"machine_type": cty.StringVal("ct5p-hightpu-4t"),
+		"tpu_topology": cty.StringVal("2x2x4"),
+	})
+	if got := estimateNodeCount(config.Blueprint{}, "tpu", settings); got != 4 {
+		t.Errorf("want 4 nodes, got %v", got)
+	}
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	if _, ok := BundledPriceTable().MachineTypes["a3-highgpu-8g"]; !ok {
+		t.Fatal("bundled table has no a3-highgpu-8g")
+	}
+
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	data := "currency: EUR\nmachine_types:\n  a3-highgpu-8g: {on_demand: 1, spot: 0.5}\n  n9-special-4: {on_demand: 2}\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tab, err := LoadPriceTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tab.Currency != "EUR" || tab.HoursPerMonth != 730 {
+		t.Errorf("unexpected table %q %v", tab.Currency, tab.HoursPerMonth)
+	}
+	if p := tab.MachineTypes["a3-highgpu-8g"]; p.OnDemand != 1 || *p.Spot != 0.5 {
+		t.Errorf("override is not applied: %#v", p)
+	}
+	if _, ok := tab.MachineTypes["n9-special-4"]; !ok {
+		t.Error("new entry is not added")
+	}
+	if _, ok := tab.MachineTypes["a2-highgpu-1g"]; !ok {
+		t.Error("bundled entry is lost")
+	}
+
+	if err := os.WriteFile(path, []byte("machine_types: [oops"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPriceTable(path); err == nil {
+		t.Error("want error for malformed file")
+	}
+}
@@ -144,15 +144,15 @@ func Execute(bp config.Blueprint) error {
 	}
 
 	// Run module-metadata-based validators
-	if err := validateBlueprintWithMetadata(bp); err != nil {
+	if err := ValidateWithMetadata(bp); err != nil {
 		errs.Add(err)
 	}
 
 	return errs.OrNil()
 }
 
-// validateBlueprintWithMetadata runs metadata-based validations.
-func validateBlueprintWithMetadata(bp config.Blueprint) error {
+// ValidateWithMetadata runs metadata-based validations, they don't require access to the cloud.
+func ValidateWithMetadata(bp config.Blueprint) error {
 	for _, group := range bp.Groups {
 		for j, mod := range group.Modules {
 			if mod.Kind != config.TerraformKind {